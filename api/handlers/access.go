@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// AccessHandler exposes role and permission management.
+type AccessHandler struct {
+	svc *services.AccessService
+}
+
+func NewAccessHandler(svc *services.AccessService) *AccessHandler {
+	return &AccessHandler{svc: svc}
+}
+
+func (h *AccessHandler) ListPermissions(c *gin.Context) {
+	list, err := h.svc.ListPermissions(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *AccessHandler) ListRoles(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListRoles(context.Background(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *AccessHandler) GetRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	r, err := h.svc.GetRole(context.Background(), tenantID, id)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// CreateRole expects {"name", "description"}.
+func (h *AccessHandler) CreateRole(c *gin.Context) {
+	var r models.Role
+	if err := c.BindJSON(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	id, err := h.svc.CreateRole(context.Background(), tenantID, c.GetString("currentUsername"), r)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *AccessHandler) UpdateRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	var r models.Role
+	if err := c.BindJSON(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.UpdateRole(context.Background(), tenantID, c.GetString("currentUsername"), id, r); err != nil {
+		accessError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *AccessHandler) DeleteRole(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	err := h.svc.DeleteRole(context.Background(), tenantID, c.GetString("currentUsername"), c.GetInt64("currentUser"), id)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RolePermissions returns the permission IDs granted to the role.
+func (h *AccessHandler) RolePermissions(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	ids, err := h.svc.RolePermissions(context.Background(), tenantID, id)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"role_id": id, "permission_ids": ids})
+}
+
+// SetRolePermissions expects {"permission_ids": [...]} and replaces the
+// role's grants.
+func (h *AccessHandler) SetRolePermissions(c *gin.Context) {
+	id, ok := roleID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		PermissionIDs []int64 `json:"permission_ids"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	err := h.svc.SetRolePermissions(context.Background(), tenantID, c.GetString("currentUsername"), c.GetInt64("currentUser"), id, body.PermissionIDs)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// UserRoles requires ?user_id=.
+func (h *AccessHandler) UserRoles(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.UserRoles(context.Background(), tenantID, userID)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// BulkSetUserRoles expects {"user_id", "role_ids": [...]} and replaces the
+// user's roles.
+func (h *AccessHandler) BulkSetUserRoles(c *gin.Context) {
+	var body struct {
+		UserID  int64   `json:"user_id"`
+		RoleIDs []int64 `json:"role_ids"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	err := h.svc.SetUserRoles(context.Background(), tenantID, c.GetString("currentUsername"), c.GetInt64("currentUser"), body.UserID, body.RoleIDs)
+	if err != nil {
+		accessError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Audit accepts optional ?target_type=role|user&target_id= and ?limit=.
+func (h *AccessHandler) Audit(c *gin.Context) {
+	targetID, _ := strconv.ParseInt(c.Query("target_id"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.AuditLog(context.Background(), tenantID, c.Query("target_type"), targetID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func roleID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func accessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repos.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "role or user not found"})
+	case errors.Is(err, repos.ErrLastAdminPermission), errors.Is(err, repos.ErrRoleNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type ActivityHandler struct {
+	svc *services.ActivityService
+}
+
+func NewActivityHandler(svc *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{svc: svc}
+}
+
+// List expects ?subject_type=buyer|letting_tenant&subject_id=.
+func (h *ActivityHandler) List(c *gin.Context) {
+	subjectID, err := strconv.ParseInt(c.Query("subject_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing subject_id"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListActivities(c.Request.Context(), tenantID, c.Query("subject_type"), subjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ActivityHandler) Create(c *gin.Context) {
+	var a models.Activity
+	if err := c.BindJSON(&a); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, err := h.svc.CreateActivity(c.Request.Context(), tenantID, currentUser, a)
+	if err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "buyer or letting not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (h *ActivityHandler) Complete(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid activity ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.CompleteActivity(c.Request.Context(), tenantID, currentUser, id64); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "activity not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *ActivityHandler) Delete(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid activity ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.DeleteActivity(c.Request.Context(), tenantID, currentUser, id64); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "activity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// BuyerTimeline returns the buyer's activities and plan/payment events,
+// oldest first.
+func (h *ActivityHandler) BuyerTimeline(c *gin.Context) {
+	buyerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid buyer ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.BuyerTimeline(c.Request.Context(), tenantID, buyerID)
+	if err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "buyer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
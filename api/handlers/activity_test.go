@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+func TestBuyerTimelineHidesOtherAgentsBuyers(t *testing.T) {
+	db := newTestDB(t)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	h := NewActivityHandler(services.NewActivityService(
+		repos.NewDBActivityRepo(db, "sqlite"),
+		buyerRepo,
+		repos.NewDBLettingsRepo(db, "sqlite"),
+		repos.NewDBInstallmentPlanRepo(db, "sqlite"),
+		repos.NewDBInstallmentRepo(db, "sqlite"),
+		repos.NewDBPaymentRepo(db, "sqlite"),
+		repos.NewDBAppointmentRepo(db, "sqlite"),
+	))
+
+	buyerID, err := buyerRepo.Create(context.Background(), &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		OwnerID: 1, CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/buyers/%d/timeline", buyerID)
+
+	for _, tc := range []struct {
+		user testUser
+		want int
+	}{
+		{testUser{ID: 1, Name: "alice"}, http.StatusOK},
+		{testUser{ID: 2, Name: "bob"}, http.StatusNotFound},
+		{testUser{ID: 3, Name: "admin", All: true}, http.StatusOK},
+	} {
+		r := newTestRouter(tc.user)
+		r.GET("/buyers/:id/timeline", h.BuyerTimeline)
+		if w := serve(t, r, http.MethodGet, path, nil); w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d: %s", tc.user.Name, w.Code, tc.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// APIKeyHandler manages the tenant's API keys. Requests made with an API
+// key cannot manage keys, so a key cannot mint a broader one.
+type APIKeyHandler struct {
+	svc *services.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	if !viaToken(c) {
+		return
+	}
+	list, err := h.svc.List(context.Background(), c.GetString("currentTenant"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *APIKeyHandler) Get(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok || !viaToken(c) {
+		return
+	}
+	k, err := h.svc.Get(context.Background(), c.GetString("currentTenant"), id)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// Create expects
+//
+//	{ "name", "permissions": [...], "allowed_ips": ["203.0.113.7", "10.0.0.0/8"], "expires_at" }
+//
+// and returns the key with "key", its only appearance; send it in the
+// X-API-Key header. The key acts as the caller, so it may only be given
+// permissions the caller holds.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	if !viaToken(c) {
+		return
+	}
+	var req models.APIKeyRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	k, err := h.svc.Create(context.Background(), c.GetString("currentTenant"), c.GetInt64("currentUser"), c.GetString("currentUsername"), req)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, k)
+}
+
+// Update takes the same body as Create; the key value does not change.
+func (h *APIKeyHandler) Update(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok || !viaToken(c) {
+		return
+	}
+	var req models.APIKeyRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	k, err := h.svc.Update(context.Background(), c.GetString("currentTenant"), c.GetInt64("currentUser"), c.GetString("currentUsername"), id, req)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+func (h *APIKeyHandler) Delete(c *gin.Context) {
+	id, ok := apiKeyID(c)
+	if !ok || !viaToken(c) {
+		return
+	}
+	if err := h.svc.Delete(context.Background(), c.GetString("currentTenant"), id); err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// viaToken refuses requests authenticated with an API key.
+func viaToken(c *gin.Context) bool {
+	if _, isKey := c.Get("apiKeyPerms"); isKey {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be managed with an API key"})
+		return false
+	}
+	return true
+}
+
+func apiKeyID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func apiKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repos.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, repos.ErrInvalidAPIKeyRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrPermissionNotHeld):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+	"github.com/newssourcecrawler/realtorinstall/internal/ical"
+)
+
+type AppointmentHandler struct {
+	svc *services.AppointmentService
+}
+
+func NewAppointmentHandler(svc *services.AppointmentService) *AppointmentHandler {
+	return &AppointmentHandler{svc: svc}
+}
+
+// List accepts optional from/to (RFC 3339), agent_id, property_id and
+// buyer_id filters.
+func (h *AppointmentHandler) List(c *gin.Context) {
+	f, ok := appointmentFilter(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListAppointments(c.Request.Context(), tenantID, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *AppointmentHandler) Create(c *gin.Context) {
+	var a models.Appointment
+	if err := c.BindJSON(&a); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, err := h.svc.CreateAppointment(c.Request.Context(), tenantID, currentUser, a)
+	if err != nil {
+		appointmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (h *AppointmentHandler) Update(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+	var a models.Appointment
+	if err := c.BindJSON(&a); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.UpdateAppointment(c.Request.Context(), tenantID, currentUser, id64, a); err != nil {
+		appointmentError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *AppointmentHandler) Cancel(c *gin.Context) {
+	h.setStatus(models.AppointmentCancelled)(c)
+}
+
+func (h *AppointmentHandler) Complete(c *gin.Context) {
+	h.setStatus(models.AppointmentCompleted)(c)
+}
+
+func (h *AppointmentHandler) setStatus(status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+			return
+		}
+		tenantID := c.GetString("currentTenant")
+		currentUser := c.GetString("currentUsername")
+		if err := h.svc.SetStatus(c.Request.Context(), tenantID, currentUser, id64, status); err != nil {
+			appointmentError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
+// Availability expects agent_id and/or property_id, from and to (RFC 3339)
+// and an optional duration_minutes (default 30).
+func (h *AppointmentHandler) Availability(c *gin.Context) {
+	f, ok := appointmentFilter(c)
+	if !ok {
+		return
+	}
+	if f.From.IsZero() || f.To.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required"})
+		return
+	}
+	var duration time.Duration
+	if v := c.Query("duration_minutes"); v != "" {
+		m, err := strconv.Atoi(v)
+		if err != nil || m <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration_minutes"})
+			return
+		}
+		duration = time.Duration(m) * time.Minute
+	}
+	tenantID := c.GetString("currentTenant")
+	slots, err := h.svc.Availability(c.Request.Context(), tenantID, f.AgentUserID, f.PropertyID, f.From, f.To, duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// Calendar exports the filtered appointments as iCalendar.
+func (h *AppointmentHandler) Calendar(c *gin.Context) {
+	f, ok := appointmentFilter(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	var buf bytes.Buffer
+	if err := h.svc.ExportICS(c.Request.Context(), tenantID, f, &buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="viewings.ics"`)
+	c.Data(http.StatusOK, ical.ContentType, buf.Bytes())
+}
+
+func appointmentFilter(c *gin.Context) (models.AppointmentFilter, bool) {
+	var f models.AppointmentFilter
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := c.Query(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.name + ", want RFC 3339"})
+				return f, false
+			}
+			*p.dst = t
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"agent_id", &f.AgentUserID}, {"property_id", &f.PropertyID}, {"buyer_id", &f.BuyerID}} {
+		if v := c.Query(p.name); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.name})
+				return f, false
+			}
+			*p.dst = id
+		}
+	}
+	return f, true
+}
+
+func appointmentError(c *gin.Context, err error) {
+	switch {
+	case err == repos.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment, property, buyer or agent not found"})
+	case errors.Is(err, repos.ErrAppointmentConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err == repos.ErrOutsideWorkingHours:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
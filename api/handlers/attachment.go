@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// AttachmentHandler serves the attachment routes nested under each parent
+// resource, e.g. /properties/:id/attachments. Each method takes the entity
+// type so the same handler can back every parent route.
+type AttachmentHandler struct {
+	svc *services.AttachmentService
+}
+
+func NewAttachmentHandler(svc *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+func (h *AttachmentHandler) List(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entityType + " ID"})
+			return
+		}
+		tenantID := c.GetString("currentTenant")
+		list, err := h.svc.ListAttachments(c.Request.Context(), tenantID, entityType, entityID)
+		if err != nil {
+			if err == repos.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": entityType + " not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// Upload expects a multipart form with a "file" field and an optional
+// "category" (photo, floor_plan, title_deed, contract, ...).
+func (h *AttachmentHandler) Upload(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entityType + " ID"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAttachmentSize+1<<20)
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		file, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		tenantID := c.GetString("currentTenant")
+		currentUser := c.GetString("currentUsername")
+		a, err := h.svc.Upload(c.Request.Context(), tenantID, currentUser, entityType, entityID, c.PostForm("category"), fh.Filename, file)
+		if err != nil {
+			switch err {
+			case repos.ErrNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": entityType + " not found"})
+			case repos.ErrAttachmentTooLarge:
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, a)
+	}
+}
+
+// Download streams the attachment content; with ?thumbnail=true it streams
+// the generated thumbnail instead.
+func (h *AttachmentHandler) Download(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entityType + " ID"})
+			return
+		}
+		attID, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+			return
+		}
+		thumb, _ := strconv.ParseBool(c.Query("thumbnail"))
+
+		tenantID := c.GetString("currentTenant")
+		a, rc, err := h.svc.Download(c.Request.Context(), tenantID, entityType, entityID, attID, thumb)
+		if err != nil {
+			if err == repos.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer rc.Close()
+
+		contentType := a.MimeType
+		if thumb {
+			contentType = "image/jpeg"
+		} else {
+			c.Header("Content-Length", strconv.FormatInt(a.SizeBytes, 10))
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.FileName))
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("ETag", `"`+a.Checksum+`"`)
+		c.Status(http.StatusOK)
+		io.Copy(c.Writer, rc)
+	}
+}
+
+func (h *AttachmentHandler) Delete(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entityType + " ID"})
+			return
+		}
+		attID, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+			return
+		}
+		tenantID := c.GetString("currentTenant")
+		currentUser := c.GetString("currentUsername")
+		if err := h.svc.DeleteAttachment(c.Request.Context(), tenantID, currentUser, entityType, entityID, attID); err != nil {
+			if err == repos.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/newssourcecrawler/realtorinstall/api/models"
@@ -38,11 +42,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	id, err := h.svc.Register(context.Background(), tenantID, currentUser, req.User, req.Password)
 	if err != nil {
 		// If username taken or other errors, return 400 or 500 accordingly
-		switch err {
-		case repos.ErrUserAlreadyExists:
+		switch {
+		case err == repos.ErrUserAlreadyExists:
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		case repos.ErrInvalidRegistration:
+		case err == repos.ErrInvalidRegistration, errors.Is(err, repos.ErrWeakPassword):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repos.ErrSeatLimit):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -54,13 +60,21 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // Login expects JSON:
 //
 //	{
+//	  "tenant": "acme",
 //	  "username": "someName",
 //	  "password": "someRawPassword"
 //	}
 //
-// On success, returns { "token": "<JWT string>" }.
+// "tenant" may be left out when the request names it another way; see
+// requestTenant.
+//
+// On success, returns the token pair; "token" repeats the access token
+// for older clients. If the user needs a second factor it returns
+// {"mfa_required": true, "mfa_token", "enrolment_required"} instead, to
+// be completed at POST /login/mfa.
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req struct {
+		Tenant   string `json:"tenant"`
 		UserName string `json:"username"`
 		Password string `json:"password"`
 	}
@@ -68,11 +82,178 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	tenantID := c.GetString("currentTenant")
-	token, err := h.svc.Login(context.Background(), tenantID, req.UserName, req.Password)
+	tenantID, ok := requestTenant(c, req.Tenant)
+	if !ok {
+		return
+	}
+	res, err := h.svc.Login(context.Background(), tenantID, req.UserName, req.Password, c.ClientIP())
+	if err != nil {
+		var locked *services.LockedError
+		switch {
+		case err == repos.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		case err == repos.ErrTenantSuspended:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.As(err, &locked):
+			lockedOut(c, locked)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	loginResponse(c, res)
+}
+
+// LoginMFA expects {"mfa_token", "code"} or {"mfa_token", "recovery_code"}
+// and completes a login that returned an MFA challenge. When the code
+// confirms a new enrolment, the response includes "recovery_codes".
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req struct {
+		MFAToken     string `json:"mfa_token" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.CompleteMFA(context.Background(), req.MFAToken, req.Code, req.RecoveryCode, c.ClientIP())
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	loginResponse(c, res)
+}
+
+// LoginMFAEnroll expects {"mfa_token"} from a challenge with
+// "enrolment_required" and returns the secret and provisioning URI to
+// set up an authenticator app; the first code is then sent to
+// POST /login/mfa.
+func (h *AuthHandler) LoginMFAEnroll(c *gin.Context) {
+	var req struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	e, err := h.svc.BeginChallengeEnrolment(context.Background(), req.MFAToken)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+// Refresh expects {"refresh_token"} and returns a new token pair. The
+// refresh token sent is spent; sending it again ends the session.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pair, err := h.svc.Refresh(context.Background(), req.RefreshToken)
+	if err != nil {
+		sessionError(c, err)
+		return
+	}
+	tokenResponse(c, pair)
+}
+
+// Logout expects {"refresh_token"} and ends that session.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.Logout(context.Background(), req.RefreshToken); err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeSessions ends every session of user :id.
+func (h *AuthHandler) RevokeSessions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.RevokeAllSessions(context.Background(), tenantID, c.GetString("currentUsername"), id); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func tokenResponse(c *gin.Context, pair *models.TokenPair) {
+	c.JSON(http.StatusOK, gin.H{
+		"token":         pair.AccessToken,
+		"access_token":  pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+	})
+}
+
+// requestTenant returns the tenant a public request is for: the one named
+// in its body, else the one TenantHint found in the X-Tenant-ID header or
+// the subdomain, else the configured default. It replies 400 if there is
+// none.
+func requestTenant(c *gin.Context, fromBody string) (string, bool) {
+	tenantID := strings.ToLower(strings.TrimSpace(fromBody))
+	if tenantID == "" {
+		tenantID = c.GetString("tenantHint")
+	}
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant is required"})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func loginResponse(c *gin.Context, res *models.LoginResult) {
+	if res.Tokens == nil {
+		c.JSON(http.StatusOK, res)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	body := gin.H{
+		"token":         res.Tokens.AccessToken,
+		"access_token":  res.Tokens.AccessToken,
+		"refresh_token": res.Tokens.RefreshToken,
+		"expires_in":    res.Tokens.ExpiresIn,
+	}
+	if len(res.RecoveryCodes) > 0 {
+		body["recovery_codes"] = res.RecoveryCodes
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+func sessionError(c *gin.Context, err error) {
+	switch err {
+	case repos.ErrInvalidRefreshToken, repos.ErrRefreshTokenReused:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case repos.ErrTenantSuspended:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// lockedOut answers 429 with Retry-After while logins are locked out.
+func lockedOut(c *gin.Context, locked *services.LockedError) {
+	retry := int(time.Until(locked.Until).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retry))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": repos.ErrAccountLocked.Error(), "retry_after": retry})
 }
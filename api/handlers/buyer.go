@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewBuyerHandler(svc *services.BuyerService) *BuyerHandler {
 
 func (h *BuyerHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListBuyers(context.Background(), tenantID)
+	list, err := h.svc.ListBuyers(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,7 +36,7 @@ func (h *BuyerHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateBuyer(context.Background(), tenantID, currentUser, b)
+	id, err := h.svc.CreateBuyer(c.Request.Context(), tenantID, currentUser, b)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +58,7 @@ func (h *BuyerHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateBuyer(context.Background(), tenantID, currentUser, id64, b); err != nil {
+	if err := h.svc.UpdateBuyer(c.Request.Context(), tenantID, currentUser, id64, b); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "buyer not found"})
 			return
@@ -79,7 +78,7 @@ func (h *BuyerHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteBuyer(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteBuyer(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "buyer not found"})
 			return
@@ -89,3 +88,59 @@ func (h *BuyerHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+// Duplicates lists likely duplicate buyer pairs; ?min_score= (0..1) tunes
+// the threshold.
+func (h *BuyerHandler) Duplicates(c *gin.Context) {
+	var minScore float64
+	if v := c.Query("min_score"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_score must be between 0 and 1"})
+			return
+		}
+		minScore = f
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.FindDuplicates(c.Request.Context(), tenantID, minScore)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Merge expects {"survivor_id": 1, "duplicate_id": 2, "reason": "..."}.
+func (h *BuyerHandler) Merge(c *gin.Context) {
+	var req struct {
+		SurvivorID  int64  `json:"survivor_id"`
+		DuplicateID int64  `json:"duplicate_id"`
+		Reason      string `json:"reason"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	m, err := h.svc.MergeBuyers(c.Request.Context(), tenantID, currentUser, req.SurvivorID, req.DuplicateID, req.Reason)
+	if err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "buyer not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+func (h *BuyerHandler) ListMerges(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListMerges(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+	"github.com/newssourcecrawler/realtorinstall/internal/ical"
+)
+
+type CalendarFeedHandler struct {
+	svc *services.CalendarFeedService
+}
+
+func NewCalendarFeedHandler(svc *services.CalendarFeedService) *CalendarFeedHandler {
+	return &CalendarFeedHandler{svc: svc}
+}
+
+// IssueToken rotates the caller's feed token. The token is only ever
+// returned here.
+func (h *CalendarFeedHandler) IssueToken(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	userID := c.GetInt64("currentUser")
+	token, err := h.svc.IssueToken(context.Background(), tenantID, userID)
+	if err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token, "url": "/calendar/feed/" + token + ".ics"})
+}
+
+func (h *CalendarFeedHandler) RevokeToken(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	userID := c.GetInt64("currentUser")
+	if err := h.svc.RevokeToken(context.Background(), tenantID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Feed serves /calendar/feed/:token.ics. Calendar apps can't send an
+// Authorization header, so the token in the path is the credential.
+func (h *CalendarFeedHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	var buf bytes.Buffer
+	if err := h.svc.WriteFeed(context.Background(), token, &buf); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown or revoked feed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, ical.ContentType, buf.Bytes())
+}
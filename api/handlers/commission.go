@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -35,7 +34,7 @@ func (h *CommissionHandler) List(c *gin.Context) {
 		}
 	}
 
-	list, err := h.svc.ListCommissions(context.Background(), tenantID, filterType, beneficiaryID)
+	list, err := h.svc.ListCommissions(c.Request.Context(), tenantID, filterType, beneficiaryID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -51,7 +50,7 @@ func (h *CommissionHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateCommission(context.Background(), tenantID, currentUser, cm)
+	id, err := h.svc.CreateCommission(c.Request.Context(), tenantID, currentUser, cm)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,7 +72,7 @@ func (h *CommissionHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateCommission(context.Background(), tenantID, currentUser, id64, cm); err != nil {
+	if err := h.svc.UpdateCommission(c.Request.Context(), tenantID, currentUser, id64, cm); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "commission not found"})
 			return
@@ -93,7 +92,7 @@ func (h *CommissionHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteCommission(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteCommission(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "commission not found"})
 			return
@@ -103,3 +102,25 @@ func (h *CommissionHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+func (h *CommissionHandler) Approve(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid commission ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUser")
+	if err := h.svc.ApproveCommission(c.Request.Context(), tenantID, currentUser, id64); err != nil {
+		switch err {
+		case repos.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "commission not found"})
+		case repos.ErrCommissionAlreadyApproved:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Status(http.StatusOK)
+}
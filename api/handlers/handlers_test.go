@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/migrate"
+)
+
+const testTenant = "acme"
+
+// newTestDB returns an in-memory sqlite database with every migration
+// applied, as used in single-database mode.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	for _, dir := range []string{"../../migrations/sqlite", "../../migrations/single/sqlite"} {
+		if err := migrate.MigrateSQL(db, dir); err != nil {
+			t.Fatalf("%s: %v", dir, err)
+		}
+	}
+	return db
+}
+
+// testUser is who a test request is made as. The request carries what
+// AuthMiddleware and RecordScope would have set for them.
+type testUser struct {
+	ID   int64
+	Name string
+	All  bool // sees every record
+}
+
+func newTestRouter(u testUser) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("currentUser", u.ID)
+		c.Set("currentUsername", u.Name)
+		c.Set("currentTenant", testTenant)
+		policy := &repos.AccessPolicy{UserID: u.ID, All: u.All, UserIDs: []int64{u.ID}}
+		c.Request = c.Request.WithContext(repos.WithAccessPolicy(c.Request.Context(), policy))
+		c.Next()
+	})
+	return r
+}
+
+// serve makes a request with body encoded as JSON, when not nil.
+func serve(t *testing.T, r *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewInstallmentHandler(svc *services.InstallmentService) *InstallmentHandler
 
 func (h *InstallmentHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListInstallments(context.Background(), tenantID)
+	list, err := h.svc.ListInstallments(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,7 +36,7 @@ func (h *InstallmentHandler) ListByPlan(c *gin.Context) {
 		return
 	}
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListByPlan(context.Background(), tenantID, planID)
+	list, err := h.svc.ListByPlan(c.Request.Context(), tenantID, planID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,7 +52,7 @@ func (h *InstallmentHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateInstallment(context.Background(), tenantID, currentUser, inst)
+	id, err := h.svc.CreateInstallment(c.Request.Context(), tenantID, currentUser, inst)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -75,7 +74,7 @@ func (h *InstallmentHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateInstallment(context.Background(), tenantID, currentUser, id64, inst); err != nil {
+	if err := h.svc.UpdateInstallment(c.Request.Context(), tenantID, currentUser, id64, inst); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "installment not found"})
 			return
@@ -95,7 +94,7 @@ func (h *InstallmentHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteInstallment(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteInstallment(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "installment not found"})
 			return
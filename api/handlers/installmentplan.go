@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewPlanHandler(svc *services.PlanService) *PlanHandler {
 
 func (h *PlanHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListPlans(context.Background(), tenantID)
+	list, err := h.svc.ListPlans(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,8 +36,12 @@ func (h *PlanHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreatePlan(context.Background(), tenantID, currentUser, p)
+	id, err := h.svc.CreatePlan(c.Request.Context(), tenantID, currentUser, p)
 	if err != nil {
+		if err == repos.ErrKYCNotApproved || err == repos.ErrKYCExpired {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -59,7 +62,7 @@ func (h *PlanHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdatePlan(context.Background(), tenantID, currentUser, id64, p); err != nil {
+	if err := h.svc.UpdatePlan(c.Request.Context(), tenantID, currentUser, id64, p); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "plan not found"})
 			return
@@ -79,7 +82,7 @@ func (h *PlanHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeletePlan(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeletePlan(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "plan not found"})
 			return
@@ -89,3 +92,35 @@ func (h *PlanHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+// CreateFromSale creates a plan for the installment sale in :id. The body
+// carries the plan terms; property, buyer and price come from the sale.
+func (h *PlanHandler) CreateFromSale(c *gin.Context) {
+	saleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale ID"})
+		return
+	}
+	var terms models.InstallmentPlan
+	if err := c.BindJSON(&terms); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUser")
+	plan, err := h.svc.CreatePlanFromSale(c.Request.Context(), tenantID, currentUser, saleID, terms)
+	if err != nil {
+		switch err {
+		case repos.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "sale not found"})
+		case repos.ErrSaleHasActivePlan:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case repos.ErrKYCNotApproved, repos.ErrKYCExpired:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewIntroductionsHandler(svc *services.IntroductionsService) *IntroductionsH
 
 func (h *IntroductionsHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListIntroductions(context.Background(), tenantID)
+	list, err := h.svc.ListIntroductions(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,7 +36,7 @@ func (h *IntroductionsHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateIntroduction(context.Background(), tenantID, currentUser, intro)
+	id, err := h.svc.CreateIntroduction(c.Request.Context(), tenantID, currentUser, intro)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +58,7 @@ func (h *IntroductionsHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateIntroduction(context.Background(), tenantID, currentUser, id64, intro); err != nil {
+	if err := h.svc.UpdateIntroduction(c.Request.Context(), tenantID, currentUser, id64, intro); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "introduction not found"})
 			return
@@ -79,7 +78,7 @@ func (h *IntroductionsHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteIntroduction(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteIntroduction(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "introduction not found"})
 			return
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type KYCHandler struct {
+	svc *services.KYCService
+}
+
+func NewKYCHandler(svc *services.KYCService) *KYCHandler {
+	return &KYCHandler{svc: svc}
+}
+
+func (h *KYCHandler) Get(c *gin.Context) {
+	buyerID, ok := kycBuyerID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	k, err := h.svc.GetKYC(c.Request.Context(), tenantID, buyerID)
+	if err != nil {
+		kycError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// Submit creates or replaces the buyer's KYC details; the record goes back to
+// pending until verified.
+func (h *KYCHandler) Submit(c *gin.Context) {
+	buyerID, ok := kycBuyerID(c)
+	if !ok {
+		return
+	}
+	var k models.BuyerKYC
+	if err := c.BindJSON(&k); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, err := h.svc.SubmitKYC(c.Request.Context(), tenantID, currentUser, buyerID, k)
+	if err != nil {
+		kycError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// Verify expects {"status": "approved"|"rejected", "notes": "..."}.
+func (h *KYCHandler) Verify(c *gin.Context) {
+	buyerID, ok := kycBuyerID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+		Notes  string `json:"notes"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.VerifyKYC(c.Request.Context(), tenantID, currentUser, buyerID, req.Status, req.Notes); err != nil {
+		kycError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// GrantOverride expects {"action": "create_plan"|"create_sale", "reason": "..."}.
+func (h *KYCHandler) GrantOverride(c *gin.Context) {
+	buyerID, ok := kycBuyerID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Action string `json:"action"`
+		Reason string `json:"reason"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, err := h.svc.GrantOverride(c.Request.Context(), tenantID, currentUser, buyerID, req.Action, req.Reason)
+	if err != nil {
+		kycError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (h *KYCHandler) ListOverrides(c *gin.Context) {
+	buyerID, ok := kycBuyerID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListOverrides(c.Request.Context(), tenantID, buyerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func kycBuyerID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid buyer ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func kycError(c *gin.Context, err error) {
+	switch err {
+	case repos.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "buyer, KYC record or document not found"})
+	case repos.ErrKYCExpired:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case repos.ErrKYCNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
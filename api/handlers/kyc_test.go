@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+func TestKYCRecordsActingUser(t *testing.T) {
+	db := newTestDB(t)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kycRepo := repos.NewDBKYCRepo(db, "sqlite")
+	h := NewKYCHandler(services.NewKYCService(kycRepo, buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite")))
+
+	buyerID, err := buyerRepo.Create(context.Background(), &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/buyers/%d/kyc", buyerID)
+	r := newTestRouter(testUser{ID: 7, Name: "alice", All: true})
+	r.PUT("/buyers/:id/kyc", h.Submit)
+	r.POST("/buyers/:id/kyc/verify", h.Verify)
+	r.POST("/buyers/:id/kyc/overrides", h.GrantOverride)
+
+	for _, req := range []struct {
+		method, path string
+		body         any
+	}{
+		{http.MethodPut, path, models.BuyerKYC{IDDocumentType: "passport", IDDocumentNumber: "X1", IDDocumentExpiry: time.Now().AddDate(1, 0, 0)}},
+		{http.MethodPost, path + "/verify", gin.H{"status": models.KYCApproved}},
+		{http.MethodPost, path + "/overrides", gin.H{"action": models.KYCActionCreatePlan, "reason": "director sign-off"}},
+	} {
+		if w := serve(t, r, req.method, req.path, req.body); w.Code != http.StatusOK {
+			t.Fatalf("%s %s: status %d: %s", req.method, req.path, w.Code, w.Body)
+		}
+	}
+
+	k, err := kycRepo.GetByBuyer(context.Background(), testTenant, buyerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.CreatedBy != "alice" || k.ModifiedBy != "alice" || k.VerifiedBy != "alice" {
+		t.Errorf("KYC created by %q, modified by %q, verified by %q, want alice", k.CreatedBy, k.ModifiedBy, k.VerifiedBy)
+	}
+	overrides, err := kycRepo.ListOverrides(context.Background(), testTenant, buyerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].GrantedBy != "alice" {
+		t.Errorf("overrides = %+v, want one granted by alice", overrides)
+	}
+}
+
+func TestKYCVerifyOnlyPending(t *testing.T) {
+	db := newTestDB(t)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	h := NewKYCHandler(services.NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite")))
+
+	buyerID, err := buyerRepo.Create(context.Background(), &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/buyers/%d/kyc", buyerID)
+	r := newTestRouter(testUser{ID: 7, Name: "alice", All: true})
+	r.PUT("/buyers/:id/kyc", h.Submit)
+	r.POST("/buyers/:id/kyc/verify", h.Verify)
+
+	kyc := models.BuyerKYC{IDDocumentType: "passport", IDDocumentNumber: "X1", IDDocumentExpiry: time.Now().AddDate(1, 0, 0)}
+	if w := serve(t, r, http.MethodPut, path, kyc); w.Code != http.StatusOK {
+		t.Fatalf("submit: status %d: %s", w.Code, w.Body)
+	}
+	if w := serve(t, r, http.MethodPost, path+"/verify", gin.H{"status": models.KYCRejected}); w.Code != http.StatusOK {
+		t.Fatalf("reject: status %d: %s", w.Code, w.Body)
+	}
+	// A rejected record cannot be approved without being resubmitted.
+	if w := serve(t, r, http.MethodPost, path+"/verify", gin.H{"status": models.KYCApproved}); w.Code != http.StatusConflict {
+		t.Fatalf("approve after reject: status %d, want %d", w.Code, http.StatusConflict)
+	}
+	if w := serve(t, r, http.MethodPut, path, kyc); w.Code != http.StatusOK {
+		t.Fatalf("resubmit: status %d: %s", w.Code, w.Body)
+	}
+	if w := serve(t, r, http.MethodPost, path+"/verify", gin.H{"status": models.KYCApproved}); w.Code != http.StatusOK {
+		t.Fatalf("approve after resubmit: status %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// maxLicenseSize bounds an uploaded license file.
+const maxLicenseSize = 64 << 10
+
+// LicenseHandler shows and installs tenant licenses.
+type LicenseHandler struct {
+	svc *services.LicenseService
+}
+
+func NewLicenseHandler(svc *services.LicenseService) *LicenseHandler {
+	return &LicenseHandler{svc: svc}
+}
+
+// Get describes the caller's tenant license, or the tenant named by :id
+// on the platform route.
+func (h *LicenseHandler) Get(c *gin.Context) {
+	st, err := h.svc.Status(context.Background(), licenseTenant(c))
+	if err != nil {
+		licenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// Install takes the license file, as written by the license tool, as the
+// request body.
+func (h *LicenseHandler) Install(c *gin.Context) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLicenseSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	st, err := h.svc.Install(context.Background(), licenseTenant(c), c.GetString("currentUsername"), data)
+	if err != nil {
+		licenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+func licenseTenant(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return c.GetString("currentTenant")
+}
+
+func licenseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repos.ErrLicenseInvalid), errors.Is(err, repos.ErrLicenseTenantMismatch), errors.Is(err, repos.ErrLicenseExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
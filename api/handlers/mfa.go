@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// MFAHandler lets users manage their own TOTP enrolment and lets
+// administrators reset enrolments and set the tenant's MFA policy.
+type MFAHandler struct {
+	svc *services.MFAService
+}
+
+func NewMFAHandler(svc *services.MFAService) *MFAHandler {
+	return &MFAHandler{svc: svc}
+}
+
+// Status returns {"enabled", "required"} for the current user.
+func (h *MFAHandler) Status(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	enabled, required, err := h.svc.Status(context.Background(), tenantID, c.GetInt64("currentUser"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": enabled, "required": required})
+}
+
+// Enroll starts enrolment and returns the secret and provisioning URI.
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	e, err := h.svc.BeginEnrolment(context.Background(), tenantID, c.GetInt64("currentUser"), c.GetString("currentUsername"))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+// Confirm expects {"code"} and returns {"recovery_codes"}, shown only once.
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	codes, err := h.svc.ConfirmEnrolment(context.Background(), tenantID, c.GetInt64("currentUser"), code)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes expects {"code"} and returns a new set of
+// {"recovery_codes"}; the old ones stop working.
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	codes, err := h.svc.RegenerateRecoveryCodes(context.Background(), tenantID, c.GetInt64("currentUser"), code)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable expects {"code"} and turns MFA off for the current user.
+func (h *MFAHandler) Disable(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Disable(context.Background(), tenantID, c.GetInt64("currentUser"), code); err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reset removes the MFA enrolment of user :id.
+func (h *MFAHandler) Reset(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Reset(context.Background(), tenantID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MFAHandler) GetPolicy(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	p, err := h.svc.Policy(context.Background(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// SetPolicy expects {"required_permissions": ["delete_payments", ...]}.
+func (h *MFAHandler) SetPolicy(c *gin.Context) {
+	var req struct {
+		RequiredPermissions []string `json:"required_permissions"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	p, err := h.svc.SetPolicy(context.Background(), tenantID, c.GetString("currentUsername"), req.RequiredPermissions)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func bindCode(c *gin.Context) (string, bool) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return req.Code, true
+}
+
+func mfaError(c *gin.Context, err error) {
+	var locked *services.LockedError
+	switch {
+	case errors.As(err, &locked):
+		lockedOut(c, locked)
+	case errors.Is(err, repos.ErrMFAInvalidCode), errors.Is(err, repos.ErrInvalidMFAToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrTenantSuspended):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrMFAAlreadyEnabled), errors.Is(err, repos.ErrMFARequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrMFANotEnrolled), errors.Is(err, repos.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
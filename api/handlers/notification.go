@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type NotificationHandler struct {
+	svc *services.NotificationService
+}
+
+func NewNotificationHandler(svc *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// ListPreferences serves GET /<subjects>/:id/notification-preferences.
+func (h *NotificationHandler) ListPreferences(subjectType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+			return
+		}
+		tenantID := c.GetString("currentTenant")
+		list, err := h.svc.ListPreferences(c.Request.Context(), tenantID, subjectType, id64)
+		if err != nil {
+			notificationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// SetPreference serves PUT /<subjects>/:id/notification-preferences/:channel
+// with {"address", "opted_out"}.
+func (h *NotificationHandler) SetPreference(subjectType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+			return
+		}
+		var p models.NotificationPreference
+		if err := c.BindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tenantID := c.GetString("currentTenant")
+		currentUser := c.GetString("currentUsername")
+		if err := h.svc.SetPreference(c.Request.Context(), tenantID, currentUser, subjectType, id64, c.Param("channel"), p); err != nil {
+			notificationError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
+// Send expects {"subject_type", "subject_id", "subject", "body"}.
+func (h *NotificationHandler) Send(c *gin.Context) {
+	var req struct {
+		SubjectType string `json:"subject_type"`
+		SubjectID   int64  `json:"subject_id"`
+		Subject     string `json:"subject"`
+		Body        string `json:"body"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	delivered, err := h.svc.Notify(c.Request.Context(), tenantID, req.SubjectType, req.SubjectID, req.Subject, req.Body)
+	switch {
+	case err == services.ErrNoNotifiers:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case err == repos.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "recipient not found"})
+	case err != nil && delivered == 0:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusOK, gin.H{"delivered": delivered, "error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"delivered": delivered})
+	}
+}
+
+// ListAttempts accepts optional ?subject_type=&subject_id=&limit=.
+func (h *NotificationHandler) ListAttempts(c *gin.Context) {
+	var subjectID int64
+	if v := c.Query("subject_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subject_id"})
+			return
+		}
+		subjectID = id
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListAttempts(c.Request.Context(), tenantID, c.Query("subject_type"), subjectID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func notificationError(c *gin.Context, err error) {
+	if err == repos.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "buyer or user not found"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type OfferHandler struct {
+	svc *services.OfferService
+}
+
+func NewOfferHandler(svc *services.OfferService) *OfferHandler {
+	return &OfferHandler{svc: svc}
+}
+
+// List accepts an optional ?property_id= filter.
+func (h *OfferHandler) List(c *gin.Context) {
+	var propertyID int64
+	if v := c.Query("property_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid property ID"})
+			return
+		}
+		propertyID = id
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListOffers(c.Request.Context(), tenantID, propertyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *OfferHandler) Get(c *gin.Context) {
+	id, ok := offerID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	o, err := h.svc.GetOffer(c.Request.Context(), tenantID, id)
+	if err != nil {
+		offerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
+func (h *OfferHandler) Create(c *gin.Context) {
+	var o models.Offer
+	if err := c.BindJSON(&o); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, err := h.svc.CreateOffer(c.Request.Context(), tenantID, currentUser, o)
+	if err != nil {
+		offerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (h *OfferHandler) Counter(c *gin.Context) {
+	id, ok := offerID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.CounterOffer(c.Request.Context(), tenantID, currentUser, id, req.Amount); err != nil {
+		offerError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *OfferHandler) Accept(c *gin.Context) {
+	h.transition(c, h.svc.AcceptOffer)
+}
+
+func (h *OfferHandler) Reject(c *gin.Context) {
+	h.transition(c, h.svc.RejectOffer)
+}
+
+func (h *OfferHandler) Withdraw(c *gin.Context) {
+	h.transition(c, h.svc.WithdrawOffer)
+}
+
+// AdvanceMilestone expects {"milestone": "...", "reached_at": "...", "notes": "..."};
+// reached_at defaults to now.
+func (h *OfferHandler) AdvanceMilestone(c *gin.Context) {
+	id, ok := offerID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Milestone string    `json:"milestone"`
+		ReachedAt time.Time `json:"reached_at"`
+		Notes     string    `json:"notes"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.AdvanceMilestone(c.Request.Context(), tenantID, currentUser, id, req.Milestone, req.ReachedAt, req.Notes); err != nil {
+		offerError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *OfferHandler) transition(c *gin.Context, fn func(context.Context, string, string, int64) error) {
+	id, ok := offerID(c)
+	if !ok {
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := fn(c.Request.Context(), tenantID, currentUser, id); err != nil {
+		offerError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func offerID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func offerError(c *gin.Context, err error) {
+	switch err {
+	case repos.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "offer, property or buyer not found"})
+	case repos.ErrInvalidOfferTransition, repos.ErrOfferExpired, repos.ErrPropertyUnavailable:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case repos.ErrKYCNotApproved, repos.ErrKYCExpired:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
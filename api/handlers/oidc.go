@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// OIDCHandler serves single sign-on through a tenant's OpenID Connect
+// provider and its configuration.
+type OIDCHandler struct {
+	svc *services.OIDCService
+	// callbackURL is /oidc/callback as browsers reach it; empty derives it
+	// from the request.
+	callbackURL string
+}
+
+func NewOIDCHandler(svc *services.OIDCService, callbackURL string) *OIDCHandler {
+	return &OIDCHandler{svc: svc, callbackURL: callbackURL}
+}
+
+// Login redirects the browser to the provider of the tenant named by
+// ?tenant=, the X-Tenant-ID header or the host name.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	tenantID, ok := requestTenant(c, c.Query("tenant"))
+	if !ok {
+		return
+	}
+	target, err := h.svc.Begin(context.Background(), tenantID, h.callback(c))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// Callback is where the provider sends the browser back. It answers like
+// /login.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		msg := e
+		if d := c.Query("error_description"); d != "" {
+			msg += ": " + d
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+		return
+	}
+	res, err := h.svc.Complete(context.Background(), c.Query("state"), c.Query("code"))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	loginResponse(c, res)
+}
+
+func (h *OIDCHandler) GetConfig(c *gin.Context) {
+	cfg, err := h.svc.Config(context.Background(), c.GetString("currentTenant"))
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cfg)
+}
+
+// SetConfig expects
+//
+//	{
+//	  "enabled": true, "issuer": "https://login.example.com", "client_id", "client_secret",
+//	  "scopes": ["profile", "email"], "username_claim": "preferred_username",
+//	  "tenant_claim": "org", "tenant_claim_value": "acme",
+//	  "role_claim": "groups", "role_mappings": {"sales": "agent"}, "default_role": "viewer"
+//	}
+func (h *OIDCHandler) SetConfig(c *gin.Context) {
+	var cfg models.OIDCConfig
+	if err := c.BindJSON(&cfg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	saved, err := h.svc.SaveConfig(context.Background(), c.GetString("currentTenant"), c.GetString("currentUsername"), cfg)
+	if err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+func (h *OIDCHandler) DeleteConfig(c *gin.Context) {
+	if err := h.svc.DeleteConfig(context.Background(), c.GetString("currentTenant")); err != nil {
+		oidcError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OIDCHandler) callback(c *gin.Context) string {
+	if h.callbackURL != "" {
+		return h.callbackURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if p := c.GetHeader("X-Forwarded-Proto"); p != "" {
+		scheme = strings.TrimSpace(strings.Split(p, ",")[0])
+	}
+	return scheme + "://" + c.Request.Host + "/oidc/callback"
+}
+
+func oidcError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repos.ErrNotFound), errors.Is(err, repos.ErrSSONotConfigured):
+		c.JSON(http.StatusNotFound, gin.H{"error": repos.ErrSSONotConfigured.Error()})
+	case errors.Is(err, repos.ErrInvalidSSOLogin):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrTenantSuspended), errors.Is(err, repos.ErrSeatLimit):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrInvalidOIDCConfig):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type OutboxHandler struct {
+	svc *services.OutboxService
+}
+
+func NewOutboxHandler(svc *services.OutboxService) *OutboxHandler {
+	return &OutboxHandler{svc: svc}
+}
+
+// List accepts optional ?domain=, ?status=pending|dispatched|dead and ?limit=.
+func (h *OutboxHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.List(context.Background(), tenantID, c.Query("domain"), c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *OutboxHandler) Retry(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Retry(context.Background(), tenantID, c.Param("domain"), c.Param("id")); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "outbox event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
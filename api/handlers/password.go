@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// PasswordHandler serves forgotten-password resets, password changes and
+// lockout administration.
+type PasswordHandler struct {
+	svc *services.PasswordService
+}
+
+func NewPasswordHandler(svc *services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{svc: svc}
+}
+
+// Forgot expects {"tenant", "username"} and emails a reset link if the user exists.
+// The response is the same either way.
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var req struct {
+		Tenant   string `json:"tenant"`
+		UserName string `json:"username" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID, ok := requestTenant(c, req.Tenant)
+	if !ok {
+		return
+	}
+	if err := h.svc.RequestReset(context.Background(), tenantID, req.UserName); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not send reset email"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the account exists, a reset link has been sent"})
+}
+
+// Reset expects {"token", "password"}.
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.Reset(context.Background(), req.Token, req.Password); err != nil {
+		passwordError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Change expects {"current_password", "new_password"} for the current user.
+func (h *PasswordHandler) Change(c *gin.Context) {
+	var req struct {
+		Current string `json:"current_password" binding:"required"`
+		New     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Change(context.Background(), tenantID, c.GetInt64("currentUser"), req.Current, req.New, c.ClientIP()); err != nil {
+		passwordError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Lockouts lists usernames and addresses currently locked out.
+func (h *PasswordHandler) Lockouts(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.Lockouts(context.Background(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Unlock lifts every lockout on user :id.
+func (h *PasswordHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Unlock(context.Background(), tenantID, id); err != nil {
+		if err == repos.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func passwordError(c *gin.Context, err error) {
+	var locked *services.LockedError
+	switch {
+	case errors.As(err, &locked):
+		lockedOut(c, locked)
+	case errors.Is(err, repos.ErrWeakPassword), errors.Is(err, repos.ErrPasswordReused):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrInvalidResetToken), errors.Is(err, repos.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -64,6 +64,10 @@ func (h *PaymentHandler) Update(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
 			return
 		}
+		if err == repos.ErrPaymentChanged {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,6 +88,10 @@ func (h *PaymentHandler) Delete(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
 			return
 		}
+		if err == repos.ErrPaymentChanged {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/newssourcecrawler/realtorinstall/api/models"
@@ -89,3 +91,50 @@ func (h *PricingHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+// Import expects a multipart form with a "file" field holding a CSV or XLSX
+// sheet with the columns zip_code, city, price_per_sqft and effective_date.
+// The format is taken from the "format" field or else the file extension.
+// With ?dry_run=true the computed diff is returned without writing anything.
+func (h *PricingHandler) Import(c *gin.Context) {
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+		dryRun = b
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := c.PostForm("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fh.Filename), ".")
+	}
+	file, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUser")
+	result, err := h.svc.ImportLocationPricing(context.Background(), tenantID, currentUser, format, file, dryRun)
+	if err != nil {
+		switch err {
+		case repos.ErrImportRowsInvalid:
+			c.JSON(http.StatusUnprocessableEntity, result)
+		case repos.ErrUnsupportedImportFormat:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
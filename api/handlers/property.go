@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewPropertyHandler(svc *services.PropertyService) *PropertyHandler {
 
 func (h *PropertyHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListProperties(context.Background(), tenantID)
+	list, err := h.svc.ListProperties(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,7 +36,7 @@ func (h *PropertyHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateProperty(context.Background(), tenantID, currentUser, p)
+	id, err := h.svc.CreateProperty(c.Request.Context(), tenantID, currentUser, p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +58,7 @@ func (h *PropertyHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateProperty(context.Background(), tenantID, currentUser, id64, p); err != nil {
+	if err := h.svc.UpdateProperty(c.Request.Context(), tenantID, currentUser, id64, p); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "property not found"})
 			return
@@ -79,7 +78,7 @@ func (h *PropertyHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteProperty(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteProperty(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "property not found"})
 			return
@@ -89,3 +88,47 @@ func (h *PropertyHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+// Search handles GET /properties/search?lat=&lng=&radius_km= and returns
+// matching properties nearest first, each with its distance_km.
+func (h *PropertyHandler) Search(c *gin.Context) {
+	var coords [3]float64
+	for i, name := range []string{"lat", "lng", "radius_km"} {
+		v, err := strconv.ParseFloat(c.Query(name), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing " + name})
+			return
+		}
+		coords[i] = v
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.SearchProperties(c.Request.Context(), tenantID, coords[0], coords[1], coords[2], c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ImportCentroids expects a multipart "file" field holding a CSV with the
+// columns postal_code, latitude and longitude.
+func (h *PropertyHandler) ImportCentroids(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+	tenantID := c.GetString("currentTenant")
+	n, err := h.svc.ImportPostalCentroids(c.Request.Context(), tenantID, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"imported": n})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type ReminderHandler struct {
+	svc *services.ReminderService
+}
+
+func NewReminderHandler(svc *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{svc: svc}
+}
+
+func (h *ReminderHandler) ListTemplates(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListTemplates(context.Background(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// SaveTemplate expects {"subject", "body"} for the kind in the path.
+func (h *ReminderHandler) SaveTemplate(c *gin.Context) {
+	var t models.ReminderTemplate
+	if err := c.BindJSON(&t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.SaveTemplate(context.Background(), tenantID, currentUser, c.Param("kind"), t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// ListSends accepts an optional ?installment_id=.
+func (h *ReminderHandler) ListSends(c *gin.Context) {
+	var installmentID int64
+	if v := c.Query("installment_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid installment_id"})
+			return
+		}
+		installmentID = id
+	}
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListSends(context.Background(), tenantID, installmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Run sends due reminders now instead of waiting for the scheduler.
+func (h *ReminderHandler) Run(c *gin.Context) {
+	res, err := h.svc.Run(context.Background(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +17,7 @@ func NewReportHandler(svc *services.ReportService) *ReportHandler {
 
 func (h *ReportHandler) TotalCommissionByBeneficiary(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	data, err := h.svc.TotalCommissionByBeneficiary(context.Background(), tenantID)
+	data, err := h.svc.TotalCommissionByBeneficiary(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -28,7 +27,7 @@ func (h *ReportHandler) TotalCommissionByBeneficiary(c *gin.Context) {
 
 func (h *ReportHandler) OutstandingInstallmentsByPlan(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	data, err := h.svc.OutstandingInstallmentsByPlan(context.Background(), tenantID)
+	data, err := h.svc.OutstandingInstallmentsByPlan(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -38,7 +37,7 @@ func (h *ReportHandler) OutstandingInstallmentsByPlan(c *gin.Context) {
 
 func (h *ReportHandler) MonthlySalesVolume(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	data, err := h.svc.MonthlySalesVolume(context.Background(), tenantID)
+	data, err := h.svc.MonthlySalesVolume(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,7 +47,7 @@ func (h *ReportHandler) MonthlySalesVolume(c *gin.Context) {
 
 func (h *ReportHandler) ActiveLettingsRentRoll(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	data, err := h.svc.ActiveLettingsRentRoll(context.Background(), tenantID)
+	data, err := h.svc.ActiveLettingsRentRoll(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,7 +57,7 @@ func (h *ReportHandler) ActiveLettingsRentRoll(c *gin.Context) {
 
 func (h *ReportHandler) TopPropertiesByPaymentVolume(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	data, err := h.svc.TopPropertiesByPaymentVolume(context.Background(), tenantID)
+	data, err := h.svc.TopPropertiesByPaymentVolume(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
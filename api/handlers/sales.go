@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
@@ -21,7 +20,7 @@ func NewSalesHandler(svc *services.SalesService) *SalesHandler {
 
 func (h *SalesHandler) List(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
-	list, err := h.svc.ListSales(context.Background(), tenantID)
+	list, err := h.svc.ListSales(c.Request.Context(), tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,8 +36,12 @@ func (h *SalesHandler) Create(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	id, err := h.svc.CreateSale(context.Background(), tenantID, currentUser, s)
+	id, err := h.svc.CreateSale(c.Request.Context(), tenantID, currentUser, s)
 	if err != nil {
+		if err == repos.ErrKYCNotApproved || err == repos.ErrKYCExpired {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -59,7 +62,7 @@ func (h *SalesHandler) Update(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.UpdateSale(context.Background(), tenantID, currentUser, id64, s); err != nil {
+	if err := h.svc.UpdateSale(c.Request.Context(), tenantID, currentUser, id64, s); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "sale not found"})
 			return
@@ -79,7 +82,7 @@ func (h *SalesHandler) Delete(c *gin.Context) {
 	}
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
-	if err := h.svc.DeleteSale(context.Background(), tenantID, currentUser, id64); err != nil {
+	if err := h.svc.DeleteSale(c.Request.Context(), tenantID, currentUser, id64); err != nil {
 		if err == repos.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "sale not found"})
 			return
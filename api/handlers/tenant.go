@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+// TenantHandler lets platform administrators provision and suspend
+// tenants.
+type TenantHandler struct {
+	svc *services.TenantService
+}
+
+func NewTenantHandler(svc *services.TenantService) *TenantHandler {
+	return &TenantHandler{svc: svc}
+}
+
+func (h *TenantHandler) List(c *gin.Context) {
+	list, err := h.svc.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *TenantHandler) Get(c *gin.Context) {
+	t, err := h.svc.Get(context.Background(), c.Param("id"))
+	if err != nil {
+		tenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Create expects
+//
+//	{
+//	  "id": "acme", "name": "Acme Lettings",
+//	  "admin": { "username", "first_name", "last_name", "email", "password" }
+//	}
+//
+// and creates the tenant with admin, agent and viewer roles and the admin
+// user holding the admin role.
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req models.TenantProvisionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := h.svc.Provision(context.Background(), c.GetString("currentUsername"), req)
+	if err != nil {
+		tenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// Suspend expects an optional {"reason"}.
+func (h *TenantHandler) Suspend(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	t, err := h.svc.Suspend(context.Background(), c.GetString("currentTenant"), c.GetString("currentUsername"), c.Param("id"), req.Reason)
+	if err != nil {
+		tenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *TenantHandler) Reactivate(c *gin.Context) {
+	t, err := h.svc.Reactivate(context.Background(), c.GetString("currentUsername"), c.Param("id"))
+	if err != nil {
+		tenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func tenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repos.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "tenant not found"})
+	case errors.Is(err, repos.ErrTenantExists), errors.Is(err, repos.ErrSuspendOwnTenant):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repos.ErrInvalidTenantID), errors.Is(err, repos.ErrInvalidRegistration), errors.Is(err, repos.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -38,6 +39,10 @@ func (h *UserHandler) Create(c *gin.Context) {
 	tenantID := c.GetString("currentTenant")
 	currentUser := c.GetString("currentUser")
 	id, err := h.svc.CreateUser(context.Background(), tenantID, currentUser, u)
+	if errors.Is(err, repos.ErrSeatLimit) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/api/services"
+)
+
+type WebhookHandler struct {
+	svc *services.WebhookService
+}
+
+func NewWebhookHandler(svc *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListSubscriptions(context.Background(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Create expects {"url", "events": [...]} and returns the signing secret,
+// which is not shown again.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var sub models.WebhookSubscription
+	if err := c.BindJSON(&sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	id, secret, err := h.svc.CreateSubscription(context.Background(), tenantID, currentUser, sub)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "secret": secret})
+}
+
+// Update expects {"url", "events", "active"}.
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	var sub models.WebhookSubscription
+	if err := c.BindJSON(&sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.UpdateSubscription(context.Background(), tenantID, currentUser, id64, sub); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	currentUser := c.GetString("currentUsername")
+	if err := h.svc.DeleteSubscription(context.Background(), tenantID, currentUser, id64); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Deliveries accepts optional ?status=pending|delivered|dead,
+// ?subscription_id= and ?limit=.
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	var subID int64
+	if v := c.Query("subscription_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription_id"})
+			return
+		}
+		subID = id
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tenantID := c.GetString("currentTenant")
+	list, err := h.svc.ListDeliveries(context.Background(), tenantID, c.Query("status"), subID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	id64, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+		return
+	}
+	tenantID := c.GetString("currentTenant")
+	if err := h.svc.Replay(context.Background(), tenantID, id64); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func webhookError(c *gin.Context, err error) {
+	if err == repos.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook or delivery not found"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/tls"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,11 +25,17 @@ import (
 	"github.com/360EntSecGroup-Skylar/excelize"
 
 	"github.com/newssourcecrawler/realtorinstall/api/handlers"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
 	apiRepos "github.com/newssourcecrawler/realtorinstall/api/repos"
 	apiServices "github.com/newssourcecrawler/realtorinstall/api/services"
 	"github.com/newssourcecrawler/realtorinstall/dbmigrations"
 	"github.com/newssourcecrawler/realtorinstall/internal/config"
 	"github.com/newssourcecrawler/realtorinstall/internal/db"
+	"github.com/newssourcecrawler/realtorinstall/internal/license"
+	"github.com/newssourcecrawler/realtorinstall/internal/mail"
+	"github.com/newssourcecrawler/realtorinstall/internal/notify"
+	"github.com/newssourcecrawler/realtorinstall/internal/oidc"
+	"github.com/newssourcecrawler/realtorinstall/internal/storage"
 	"github.com/newssourcecrawler/realtorinstall/migrate"
 )
 
@@ -64,19 +74,40 @@ func main() {
 		{cfg.RoleDBDriver, cfg.RoleDBDSN, "roles", nil},
 		{cfg.RolePermissionDBDriver, cfg.RolePermissionDBDSN, "rolepermissions", nil},
 		{cfg.UserRoleDBDriver, cfg.UserRoleDBDSN, "userroles", nil},
+		{cfg.AttachmentDBDriver, cfg.AttachmentDBDSN, "attachments", nil},
 	}
 
-	for i := range domains {
-		d := &domains[i]
-		d.dB, err = db.Open(db.Config{Driver: d.driver, DSN: d.dsn})
+	// 2. Open the databases. In single mode every domain shares one
+	// connection, migrated once with the foreign keys added on top.
+	var shared *sql.DB
+	if cfg.DBMode == "single" {
+		sc := db.SharedConfig(cfg.DBDriver, cfg.DBDSN)
+		shared, err = db.Open(sc)
 		if err != nil {
-			log.Fatalf("Failed to open %s DB: %v", d.domainName, err)
+			log.Fatalf("Failed to open shared DB: %v", err)
 		}
-		// Run migrations for SQL engines (SQLite & Postgres)
-		if d.driver == "sqlite" || d.driver == "postgres" {
-			migDir := fmt.Sprintf("./migrations/%s", d.domainName)
-			if err := migrate.MigrateSQL(d.dB, migDir); err != nil {
-				log.Fatalf("Migrations failed for %s: %v", d.domainName, err)
+		for _, migDir := range []string{"./migrations/" + sc.Driver, "./migrations/single/" + sc.Driver} {
+			if err := migrate.MigrateSQL(shared, migDir); err != nil {
+				log.Fatalf("Migrations failed for shared DB: %v", err)
+			}
+		}
+		for i := range domains {
+			domains[i].driver = sc.Driver
+			domains[i].dB = shared
+		}
+	} else {
+		for i := range domains {
+			d := &domains[i]
+			d.dB, err = db.Open(db.Config{Driver: d.driver, DSN: d.dsn})
+			if err != nil {
+				log.Fatalf("Failed to open %s DB: %v", d.domainName, err)
+			}
+			// Run migrations for SQL engines (SQLite & Postgres)
+			if d.driver == "sqlite" || d.driver == "postgres" {
+				migDir := fmt.Sprintf("./migrations/%s", d.domainName)
+				if err := migrate.MigrateSQL(d.dB, migDir); err != nil {
+					log.Fatalf("Migrations failed for %s: %v", d.domainName, err)
+				}
 			}
 		}
 	}
@@ -97,6 +128,51 @@ func main() {
 	roleRepo := repos.NewDBRoleRepo(domains[12].dB, domains[12].driver)
 	rolePermRepo := repos.NewDBRolePermissionRepo(domains[13].dB, domains[13].driver)
 	userRoleRepo := repos.NewDBUserRoleRepo(domains[14].dB, domains[14].driver)
+	accessAuditRepo := repos.NewDBAccessAuditRepo(domains[12].dB, domains[12].driver)
+	sessionRepo := repos.NewDBSessionRepo(domains[0].dB, domains[0].driver)
+	mfaRepo := repos.NewDBMFARepo(domains[0].dB, domains[0].driver)
+	passwordRepo := repos.NewDBPasswordRepo(domains[0].dB, domains[0].driver)
+	tenantRepo := repos.NewDBTenantRepo(domains[0].dB, domains[0].driver)
+	licenseRepo := repos.NewDBLicenseRepo(domains[0].dB, domains[0].driver)
+	apiKeyRepo := repos.NewDBAPIKeyRepo(domains[0].dB, domains[0].driver)
+	oidcRepo := repos.NewDBOIDCRepo(domains[0].dB, domains[0].driver)
+	attachmentRepo := repos.NewDBAttachmentRepo(domains[15].dB, domains[15].driver)
+	centroidRepo := repos.NewDBPostalCentroidRepo(domains[3].dB, domains[3].driver)
+	offerRepo := repos.NewDBOfferRepo(domains[1].dB, domains[1].driver)
+	kycRepo := repos.NewDBKYCRepo(domains[5].dB, domains[5].driver)
+	activityRepo := repos.NewDBActivityRepo(domains[5].dB, domains[5].driver)
+	appointmentRepo := repos.NewDBAppointmentRepo(domains[3].dB, domains[3].driver)
+	feedRepo := repos.NewDBCalendarFeedRepo(domains[0].dB, domains[0].driver)
+	reminderRepo := repos.NewDBReminderRepo(domains[7].dB, domains[7].driver)
+	notificationRepo := repos.NewDBNotificationRepo(domains[0].dB, domains[0].driver)
+	webhookRepo := repos.NewDBWebhookRepo(domains[0].dB, domains[0].driver)
+	salesOutbox := repos.NewDBOutboxRepo(domains[1].dB, domains[1].driver)
+	payOutbox := repos.NewDBOutboxRepo(domains[8].dB, domains[8].driver)
+	buyerMergeRepo := repos.NewDBBuyerMergeRepo(domains[5].dB, domains[5].driver,
+		repos.BuyerRef{DB: domains[6].dB, Driver: domains[6].driver, Table: "installment_plans", Column: "buyer_id"},
+		repos.BuyerRef{DB: domains[1].dB, Driver: domains[1].driver, Table: "sales", Column: "buyer_id"},
+		repos.BuyerRef{DB: domains[1].dB, Driver: domains[1].driver, Table: "offers", Column: "buyer_id"},
+		repos.BuyerRef{DB: domains[15].dB, Driver: domains[15].driver, Table: "attachments", Column: "entity_id", Where: "entity_type = 'buyer'"},
+		repos.BuyerRef{DB: domains[5].dB, Driver: domains[5].driver, Table: "activities", Column: "subject_id", Where: "subject_type = 'buyer'"},
+		repos.BuyerRef{DB: domains[3].dB, Driver: domains[3].driver, Table: "appointments", Column: "buyer_id"},
+		repos.BuyerRef{DB: domains[0].dB, Driver: domains[0].driver, Table: "notification_preferences", Column: "subject_id",
+			Where: "subject_type = 'buyer'", UniqueWith: []string{"subject_type", "channel"}},
+	)
+
+	// Attachment content lives outside the databases
+	store, err := storage.New(storage.Config{
+		Backend:        cfg.StorageBackend,
+		LocalDir:       cfg.StorageLocalDir,
+		S3Endpoint:     cfg.S3Endpoint,
+		S3Region:       cfg.S3Region,
+		S3Bucket:       cfg.S3Bucket,
+		S3AccessKey:    cfg.S3AccessKey,
+		S3SecretKey:    cfg.S3SecretKey,
+		S3UsePathStyle: cfg.S3UsePathStyle,
+	})
+	if err != nil {
+		log.Fatalf("Failed to open attachment storage: %v", err)
+	}
 
 	// 3. Construct services
 	jwtSecret := os.Getenv("APP_JWT_SECRET")
@@ -105,316 +181,930 @@ func main() {
 	}
 
 	authzSvc := apiServices.NewAuthZService(permRepo, rolePermRepo, userRoleRepo)
-	authSvc := apiServices.NewAuthService(userRepo, cfg.AppJWTSecret, time.Hour*24)
+	// Outgoing mail, for password resets and email notifications
+	var mailer mail.Sender
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPFrom)
+	}
+	var breached map[string]bool
+	if cfg.PasswordBreachedList != "" {
+		if breached, err = apiServices.LoadBreachedPasswords(cfg.PasswordBreachedList); err != nil {
+			log.Fatalf("Failed to read breached password list: %v", err)
+		}
+	}
+	passwordSvc := apiServices.NewPasswordService(passwordRepo, userRepo, sessionRepo, mailer, cfg.PasswordResetURL,
+		apiServices.PasswordPolicy{MinLength: cfg.PasswordMinLength, History: cfg.PasswordHistory, Breached: breached},
+		apiServices.LockoutPolicy{Threshold: cfg.LoginLockoutThreshold, Base: time.Duration(cfg.LoginLockoutSeconds) * time.Second},
+	)
+	platformTenant, platformAdmin := cfg.PlatformTenant, cfg.PlatformAdminUser
+	if platformTenant == "" {
+		platformTenant = "platform"
+	}
+	if platformAdmin == "" {
+		platformAdmin = "admin"
+	}
+	var licenseKey ed25519.PublicKey
+	if cfg.LicensePublicKey != "" {
+		if licenseKey, err = license.ParsePublicKey(cfg.LicensePublicKey); err != nil {
+			log.Fatalf("Invalid LICENSE_PUBLIC_KEY: %v", err)
+		}
+	}
+	licenseSvc := apiServices.NewLicenseService(licenseRepo, userRepo, licenseKey, time.Duration(cfg.LicenseGraceDays)*24*time.Hour, platformTenant)
+	accessSvc := apiServices.NewAccessService(roleRepo, permRepo, rolePermRepo, userRoleRepo, userRepo, accessAuditRepo, authzSvc, repos.NewTransactor(domains[12].dB))
+	tenantSvc := apiServices.NewTenantService(tenantRepo, userRepo, roleRepo, permRepo, accessSvc, authzSvc, passwordSvc)
+	mfaSvc := apiServices.NewMFAService(mfaRepo, authzSvc, "Realtor Installment")
+	apiKeySvc := apiServices.NewAPIKeyService(apiKeyRepo, userRepo, authzSvc, tenantSvc)
+	authSvc := apiServices.NewAuthService(userRepo, authzSvc, sessionRepo, mfaSvc, passwordSvc, tenantSvc, licenseSvc, apiKeySvc, cfg.AppJWTSecret, 15*time.Minute)
+	oidcSvc := apiServices.NewOIDCService(oidcRepo, userRepo, roleRepo, accessSvc, tenantSvc, licenseSvc, authSvc, oidc.NewClient(nil))
+	recordAccessSvc := apiServices.NewRecordAccessService(userRepo, permRepo, rolePermRepo, authzSvc)
 
-	propSvc := apiServices.NewPropertyService(propRepo, userRepo, pricingRepo)
+	propSvc := apiServices.NewPropertyService(propRepo, userRepo, pricingRepo, centroidRepo)
 
-	buyerSvc := apiServices.NewBuyerService(buyerRepo)
-	pricingSvc := apiServices.NewPricingService(pricingRepo)
-	planSvc := apiServices.NewPlanService(planRepo, instRepo)
-	instSvc := apiServices.NewInstallmentService(instRepo, payRepo)
-	paySvc := apiServices.NewPaymentService(payRepo, instSvc)
-	userSvc := apiServices.NewUserService(userRepo)
-	salesSvc := apiServices.NewSalesService(salesRepo)
+	buyerSvc := apiServices.NewBuyerService(buyerRepo, buyerMergeRepo, cfg.DefaultCallingCode)
+	kycSvc := apiServices.NewKYCService(kycRepo, buyerRepo, attachmentRepo)
+	workingHours, err := apiServices.ParseWorkingHours(cfg.WorkingHoursStart, cfg.WorkingHoursEnd, cfg.WorkingDays, cfg.WorkingTimezone)
+	if err != nil {
+		log.Fatalf("Invalid working hours config: %v", err)
+	}
+	appointmentSvc := apiServices.NewAppointmentService(appointmentRepo, propRepo, buyerRepo, userRepo, workingHours)
+	feedSvc := apiServices.NewCalendarFeedService(feedRepo, userRepo, planRepo, instRepo, lettingsRepo, appointmentSvc)
+	var notifiers []notify.Notifier
+	if mailer != nil {
+		notifiers = append(notifiers, &notify.EmailNotifier{Sender: mailer})
+	}
+	if cfg.SMSGatewayURL != "" {
+		notifiers = append(notifiers, &notify.SMSNotifier{URL: cfg.SMSGatewayURL, Token: cfg.SMSGatewayToken, From: cfg.SMSFrom})
+	}
+	if cfg.WebhookSecret != "" {
+		notifiers = append(notifiers, &notify.WebhookNotifier{Secret: cfg.WebhookSecret})
+	}
+	notificationSvc := apiServices.NewNotificationService(notificationRepo, buyerRepo, userRepo, notify.DefaultRetryPolicy, notifiers...)
+	reminderSvc := apiServices.NewReminderService(reminderRepo, instRepo, planRepo, buyerRepo, notificationSvc, cfg.ReminderDaysBefore)
+	activitySvc := apiServices.NewActivityService(activityRepo, buyerRepo, lettingsRepo, planRepo, instRepo, payRepo, appointmentRepo)
+	pricingSvc := apiServices.NewPricingService(pricingRepo, repos.NewTransactor(domains[4].dB))
+	planSvc := apiServices.NewPlanService(planRepo, instRepo, salesRepo, kycSvc, repos.NewTransactor(domains[6].dB))
+	if shared != nil {
+		planSvc.UseSharedDatabase()
+	}
+	webhookSvc := apiServices.NewWebhookService(webhookRepo, apiServices.NewWebhookClient(cfg.WebhookAllowInsecure), cfg.WebhookAllowInsecure)
+	instSvc := apiServices.NewInstallmentService(instRepo, payRepo, webhookSvc)
+	// Cross-database changes go through the producing domain's outbox and
+	// are applied to the consuming domain by the dispatcher. With a shared
+	// database they are applied in the producer's own transaction instead.
+	outboxSvc := apiServices.NewOutboxService()
+	outboxSvc.AddSource(domains[1].domainName, salesOutbox)
+	outboxSvc.AddSource(domains[8].domainName, payOutbox)
+	if shared != nil {
+		outboxSvc.UseSharedDatabase(repos.NewTransactor(shared), repos.NewDBOutboxRepo(shared, domains[0].driver))
+	}
+	outboxSvc.Handle(models.TopicPaymentApplied, instSvc.ApplyPaymentEvent)
+	outboxSvc.Handle(models.TopicPropertyStatusChanged, propSvc.ApplyStatusEvent)
+	paySvc := apiServices.NewPaymentService(payRepo, instSvc, webhookSvc, outboxSvc)
+	userSvc := apiServices.NewUserService(userRepo, licenseSvc, sessionRepo, accessSvc, repos.NewTransactor(domains[0].dB))
+	salesSvc := apiServices.NewSalesService(salesRepo, kycSvc, webhookSvc, repos.NewTransactor(domains[1].dB))
+	offerSvc := apiServices.NewOfferService(offerRepo, salesRepo, propRepo, buyerRepo, kycSvc, webhookSvc, outboxSvc, repos.NewTransactor(domains[1].dB))
 	introSvc := apiServices.NewIntroductionsService(introRepo, salesRepo, lettingsRepo, userRepo)
-	lettingsSvc := apiServices.NewLettingsService(lettingsRepo)
-	commissionSvc := apiServices.NewCommissionService(commissionRepo, salesRepo, lettingsRepo, introRepo, userRepo)
+	lettingsSvc := apiServices.NewLettingsService(lettingsRepo, webhookSvc)
+	commissionSvc := apiServices.NewCommissionService(commissionRepo, salesRepo, lettingsRepo, introRepo, userRepo, webhookSvc)
 
-	reportSvc := apiServices.NewReportService(commissionRepo)
+	reportSvc := apiServices.NewReportService(commissionRepo, planRepo, salesRepo, lettingsRepo, propRepo)
+	attachmentSvc := apiServices.NewAttachmentService(attachmentRepo, store, propRepo, salesRepo, lettingsRepo, planRepo, buyerRepo)
 
 	// 4. Instantiate handlers
 	authH := handlers.NewAuthHandler(authSvc)
 	propH := handlers.NewPropertyHandler(propSvc)
 	buyerH := handlers.NewBuyerHandler(buyerSvc)
+	kycH := handlers.NewKYCHandler(kycSvc)
+	activityH := handlers.NewActivityHandler(activitySvc)
+	appointmentH := handlers.NewAppointmentHandler(appointmentSvc)
+	feedH := handlers.NewCalendarFeedHandler(feedSvc)
+	reminderH := handlers.NewReminderHandler(reminderSvc)
+	notificationH := handlers.NewNotificationHandler(notificationSvc)
+	webhookH := handlers.NewWebhookHandler(webhookSvc)
+	outboxH := handlers.NewOutboxHandler(outboxSvc)
+	accessH := handlers.NewAccessHandler(accessSvc)
+	mfaH := handlers.NewMFAHandler(mfaSvc)
+	passwordH := handlers.NewPasswordHandler(passwordSvc)
+	tenantH := handlers.NewTenantHandler(tenantSvc)
+	licenseH := handlers.NewLicenseHandler(licenseSvc)
+	apiKeyH := handlers.NewAPIKeyHandler(apiKeySvc)
+	oidcH := handlers.NewOIDCHandler(oidcSvc, cfg.OIDCCallbackURL)
 	priceH := handlers.NewPricingHandler(pricingSvc)
 	planH := handlers.NewPlanHandler(planSvc)
 	instH := handlers.NewInstallmentHandler(instSvc)
 	payH := handlers.NewPaymentHandler(paySvc)
 	userH := handlers.NewUserHandler(userSvc)
 	salesH := handlers.NewSalesHandler(salesSvc)
+	offerH := handlers.NewOfferHandler(offerSvc)
 	introH := handlers.NewIntroductionsHandler(introSvc)
 	lettingsH := handlers.NewLettingsHandler(lettingsSvc)
 	commissionH := handlers.NewCommissionHandler(commissionSvc)
 	reportH := handlers.NewReportHandler(reportSvc)
+	attachmentH := handlers.NewAttachmentHandler(attachmentSvc)
 
 	// 5. Build Gin router with CORS + JWT middleware
 	router := gin.Default()
 	router.Use(cors.Default())
+	router.Use(TenantHint(cfg.TenantBaseDomain, cfg.DefaultTenant))
 
 	// 6. Authentication routes
 	router.POST("/login", authH.Login)
-	router.Use(AuthMiddleware(authSvc, userRepo))
+	// The second login step authenticates with the MFA challenge token
+	router.POST("/login/mfa", authH.LoginMFA)
+	router.POST("/login/mfa/enroll", authH.LoginMFAEnroll)
+	// Single sign-on through the tenant's OpenID Connect provider
+	router.GET("/oidc/login", oidcH.Login)
+	router.GET("/oidc/callback", oidcH.Callback)
+	router.POST("/password/forgot", passwordH.Forgot)
+	router.POST("/password/reset", passwordH.Reset)
+	router.POST("/token/refresh", authH.Refresh)
+	router.POST("/logout", authH.Logout)
+	// Calendar feeds authenticate with the token in the URL
+	router.GET("/calendar/feed/:token", feedH.Feed)
+	router.Use(AuthMiddleware(authSvc, userRepo, licenseSvc))
+	router.Use(RecordScope(recordAccessSvc))
 	router.POST("/register",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "register_user"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "register_user"),
 		authH.Register,
 	)
 
 	// 7. User CRUD routes
-	router.GET("/users", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_user"),
+	router.GET("/users", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_user"),
 		userH.List,
 	)
-	router.POST("/users", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_user"),
+	router.POST("/users", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_user"),
 		userH.Create,
 	)
-	router.PUT("/users/:id", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_user"),
+	router.PUT("/users/:id", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_user"),
 		userH.Update,
 	)
 	router.DELETE("/users/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_user"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_user"),
 		userH.Delete,
 	)
+	router.POST("/users/:id/sessions/revoke",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "revoke_sessions"),
+		authH.RevokeSessions,
+	)
+	router.POST("/password/change", AuthMiddleware(authSvc, userRepo, licenseSvc), passwordH.Change)
+	router.GET("/lockouts",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermUnlockUsers),
+		passwordH.Lockouts,
+	)
+	router.POST("/users/:id/unlock",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermUnlockUsers),
+		passwordH.Unlock,
+	)
 
 	// 8. Property routes
-	router.GET("/properties", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_property"),
+	router.GET("/properties", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_property"),
 		propH.List,
 	)
+	router.GET("/properties/search",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_property"),
+		propH.Search,
+	)
+	router.POST("/geo/centroids",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "import_centroids"),
+		propH.ImportCentroids,
+	)
 	router.POST("/properties",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_property"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_property"),
 		propH.Create,
 	)
 	router.PUT("/properties/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_property"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_property"),
 		propH.Update,
 	)
 	router.DELETE("/properties/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_property"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_property"),
 		propH.Delete,
 	)
 
 	// 9. Buyer routes
 	router.GET("/buyers",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_buyer"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
 		buyerH.List,
 	)
 	router.POST("/buyers",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_buyer"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_buyer"),
 		buyerH.Create,
 	)
+	router.GET("/buyers/duplicates",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
+		buyerH.Duplicates,
+	)
+	router.GET("/buyers/merges",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
+		buyerH.ListMerges,
+	)
+	router.POST("/buyers/merge",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "merge_buyers"),
+		buyerH.Merge,
+	)
 	router.PUT("/buyers/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_buyer"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_buyer"),
 		buyerH.Update,
 	)
 	router.DELETE("/buyers/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_buyer"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_buyer"),
 		buyerH.Delete,
 	)
 
+	// CRM activities and buyer timeline
+	router.GET("/buyers/:id/timeline",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
+		activityH.BuyerTimeline,
+	)
+	router.GET("/activities",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_activity"),
+		activityH.List,
+	)
+	router.POST("/activities",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_activity"),
+		activityH.Create,
+	)
+	router.POST("/activities/:id/complete",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_activity"),
+		activityH.Complete,
+	)
+	router.DELETE("/activities/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_activity"),
+		activityH.Delete,
+	)
+
+	// Viewing appointments
+	router.GET("/appointments",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_appointment"),
+		appointmentH.List,
+	)
+	router.GET("/appointments/availability",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_appointment"),
+		appointmentH.Availability,
+	)
+	router.GET("/appointments/calendar.ics",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_appointment"),
+		appointmentH.Calendar,
+	)
+	router.POST("/appointments",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_appointment"),
+		appointmentH.Create,
+	)
+	router.PUT("/appointments/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_appointment"),
+		appointmentH.Update,
+	)
+	router.POST("/appointments/:id/cancel",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_appointment"),
+		appointmentH.Cancel,
+	)
+	router.POST("/appointments/:id/complete",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_appointment"),
+		appointmentH.Complete,
+	)
+
+	// Calendar feed token for the current user
+	router.POST("/calendar/feed-token",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		feedH.IssueToken,
+	)
+	router.DELETE("/calendar/feed-token",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		feedH.RevokeToken,
+	)
+
+	// Installment reminder emails
+	router.GET("/reminders/templates",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_reminders"),
+		reminderH.ListTemplates,
+	)
+	router.PUT("/reminders/templates/:kind",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_reminders"),
+		reminderH.SaveTemplate,
+	)
+	router.GET("/reminders/sends",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_reminders"),
+		reminderH.ListSends,
+	)
+	router.POST("/reminders/run",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_reminders"),
+		reminderH.Run,
+	)
+
+	// Notification channel preferences and delivery log
+	router.GET("/buyers/:id/notification-preferences",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
+		notificationH.ListPreferences(models.NotifySubjectBuyer),
+	)
+	router.PUT("/buyers/:id/notification-preferences/:channel",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_buyer"),
+		notificationH.SetPreference(models.NotifySubjectBuyer),
+	)
+	router.GET("/users/:id/notification-preferences",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_user"),
+		notificationH.ListPreferences(models.NotifySubjectUser),
+	)
+	router.PUT("/users/:id/notification-preferences/:channel",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_user"),
+		notificationH.SetPreference(models.NotifySubjectUser),
+	)
+	router.POST("/notifications",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "send_notification"),
+		notificationH.Send,
+	)
+	router.GET("/notifications/attempts",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "send_notification"),
+		notificationH.ListAttempts,
+	)
+
+	// Buyer KYC; documents are uploaded as buyer attachments
+	router.GET("/buyers/:id/kyc",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_buyer"),
+		kycH.Get,
+	)
+	router.PUT("/buyers/:id/kyc",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_buyer"),
+		kycH.Submit,
+	)
+	router.POST("/buyers/:id/kyc/verify",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "verify_kyc"),
+		kycH.Verify,
+	)
+	router.GET("/buyers/:id/kyc/overrides",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "override_kyc"),
+		kycH.ListOverrides,
+	)
+	router.POST("/buyers/:id/kyc/overrides",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "override_kyc"),
+		kycH.GrantOverride,
+	)
+
 	// 10. Pricing routes
 	router.GET("/pricing",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_pricing"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_pricing"),
 		priceH.List,
 	)
 	router.POST("/pricing",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_pricing"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_pricing"),
 		priceH.Create,
 	)
+	router.POST("/pricing/import",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "import_pricing"),
+		priceH.Import,
+	)
 	router.PUT("/pricing/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_pricing"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_pricing"),
 		priceH.Update,
 	)
 	router.DELETE("/pricing/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_pricing"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_pricing"),
 		priceH.Delete,
 	)
 
 	// 11. Sales routes
-	router.GET("/sales", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_sale"),
+	router.GET("/sales", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_sale"),
 		salesH.List,
 	)
 	router.POST("/sales",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_sale"),
 		salesH.Create,
 	)
 	router.PUT("/sales/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_sale"),
 		salesH.Update,
 	)
 	router.DELETE("/sales/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_sale"),
 		salesH.Delete,
 	)
+	router.POST("/sales/:id/plan",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_sale"),
+		planH.CreateFromSale,
+	)
+
+	// Offers and conveyancing pipeline
+	router.GET("/offers",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_offer"),
+		offerH.List,
+	)
+	router.GET("/offers/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_offer"),
+		offerH.Get,
+	)
+	router.POST("/offers",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_offer"),
+		offerH.Create,
+	)
+	for path, h := range map[string]gin.HandlerFunc{
+		"/offers/:id/counter":    offerH.Counter,
+		"/offers/:id/accept":     offerH.Accept,
+		"/offers/:id/reject":     offerH.Reject,
+		"/offers/:id/withdraw":   offerH.Withdraw,
+		"/offers/:id/milestones": offerH.AdvanceMilestone,
+	} {
+		router.POST(path,
+			AuthMiddleware(authSvc, userRepo, licenseSvc),
+			RequirePermission(authzSvc, "update_offer"),
+			h,
+		)
+	}
 
 	// 12. Introduction routes
 	router.GET("/introductions",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_introduction"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_introduction"),
 		introH.List,
 	)
 	router.POST("/introductions",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_introduction"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_introduction"),
 		introH.Create,
 	)
 	router.PUT("/introductions/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_introduction"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_introduction"),
 		introH.Update,
 	)
 	router.DELETE("/introductions/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_introduction"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_introduction"),
 		introH.Delete,
 	)
 
 	// 13. Lettings routes
 	router.GET("/lettings",
-		RequirePermission(userRepo, "view_lettings"),
+		RequireModule(licenseSvc, license.ModuleLettings),
+		RequirePermission(authzSvc, "view_lettings"),
 		lettingsH.List,
 	)
 	router.POST("/lettings",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleLettings),
+		RequirePermission(authzSvc, "create_sale"),
 		lettingsH.Create,
 	)
 	router.PUT("/lettings/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleLettings),
+		RequirePermission(authzSvc, "create_sale"),
 		lettingsH.Update,
 	)
 	router.DELETE("/lettings/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleLettings),
+		RequirePermission(authzSvc, "create_sale"),
 		lettingsH.Delete,
 	)
 
 	// 14. Plan routes
 	router.GET("/plans",
-		RequirePermission(userRepo, "view_plans"),
+		RequirePermission(authzSvc, "view_plans"),
 		planH.List,
 	)
 	router.POST("/plans",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_sale"),
 		planH.Create,
 	)
 	router.PUT("/plans/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_sale"),
 		planH.Update,
 	)
 	router.DELETE("/plans/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_sale"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_sale"),
 		planH.Delete,
 	)
 
 	// 15. Installment routes
 	router.GET("/installments",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_installments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_installments"),
 		instH.List,
 	)
 	router.GET("/installments/plan/:planId",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_installments_byplan"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_installments_byplan"),
 		instH.ListByPlan,
 	)
 	router.POST("/installments",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_installments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_installments"),
 		instH.Create,
 	)
 	router.PUT("/installments/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_installments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_installments"),
 		instH.Update,
 	)
 	router.DELETE("/installments/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_installments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_installments"),
 		instH.Delete,
 	)
 
 	// 16. Payment routes
-	router.GET("/payments", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_payments"),
+	router.GET("/payments", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "view_payments"),
 		payH.List,
 	)
 	router.POST("/payments",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_payments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "create_payments"),
 		payH.Create,
 	)
 	router.PUT("/payments/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_payments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "update_payments"),
 		payH.Update,
 	)
 	router.DELETE("/payments/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_payments"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "delete_payments"),
 		payH.Delete,
 	)
 
 	// 17. Commission routes
-	router.GET("/commissions", AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_commission"),
+	router.GET("/commissions", AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleCommissions),
+		RequirePermission(authzSvc, "view_commission"),
 		commissionH.List,
 	)
 	router.POST("/commissions",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "create_commission"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleCommissions),
+		RequirePermission(authzSvc, "create_commission"),
 		commissionH.Create,
 	)
 	router.PUT("/commissions/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "update_commission"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleCommissions),
+		RequirePermission(authzSvc, "update_commission"),
 		commissionH.Update,
 	)
 	router.DELETE("/commissions/:id",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "delete_commission"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleCommissions),
+		RequirePermission(authzSvc, "delete_commission"),
 		commissionH.Delete,
 	)
+	router.POST("/commissions/:id/approve",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleCommissions),
+		RequirePermission(authzSvc, "approve_commission"),
+		commissionH.Approve,
+	)
+
+	// 17b. Outbound webhook subscriptions and their delivery log
+	router.GET("/webhooks",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.List,
+	)
+	router.POST("/webhooks",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.Create,
+	)
+	router.PUT("/webhooks/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.Update,
+	)
+	router.DELETE("/webhooks/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.Delete,
+	)
+	router.GET("/webhooks/deliveries",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.Deliveries,
+	)
+	router.POST("/webhooks/deliveries/:id/replay",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_webhooks"),
+		webhookH.Replay,
+	)
+
+	// 17a. Attachment routes, nested under each parent resource and guarded
+	// by that resource's own view/update permissions
+	for _, r := range []struct {
+		path, entity, viewPerm, editPerm string
+	}{
+		{"/properties", apiServices.AttachmentEntityProperty, "view_property", "update_property"},
+		{"/sales", apiServices.AttachmentEntitySale, "view_sale", "update_sale"},
+		{"/lettings", apiServices.AttachmentEntityLetting, "view_lettings", "create_sale"},
+		{"/plans", apiServices.AttachmentEntityPlan, "view_plans", "create_sale"},
+		{"/buyers", apiServices.AttachmentEntityBuyer, "view_buyer", "update_buyer"},
+	} {
+		router.GET(r.path+"/:id/attachments",
+			AuthMiddleware(authSvc, userRepo, licenseSvc),
+			RequirePermission(authzSvc, r.viewPerm),
+			attachmentH.List(r.entity),
+		)
+		router.POST(r.path+"/:id/attachments",
+			AuthMiddleware(authSvc, userRepo, licenseSvc),
+			RequirePermission(authzSvc, r.editPerm),
+			attachmentH.Upload(r.entity),
+		)
+		router.GET(r.path+"/:id/attachments/:attachmentId",
+			AuthMiddleware(authSvc, userRepo, licenseSvc),
+			RequirePermission(authzSvc, r.viewPerm),
+			attachmentH.Download(r.entity),
+		)
+		router.DELETE(r.path+"/:id/attachments/:attachmentId",
+			AuthMiddleware(authSvc, userRepo, licenseSvc),
+			RequirePermission(authzSvc, r.editPerm),
+			attachmentH.Delete(r.entity),
+		)
+	}
+
+	// 17c. Outbox inspection: dead events can be retried once the cause is fixed
+	router.GET("/outbox",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_outbox"),
+		outboxH.List,
+	)
+	router.POST("/outbox/:domain/:id/retry",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, "manage_outbox"),
+		outboxH.Retry,
+	)
+
+	// 17d. Access control: roles, their permissions and users' roles
+	router.GET("/permissions",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.ListPermissions,
+	)
+	router.GET("/roles",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.ListRoles,
+	)
+	router.POST("/roles",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.CreateRole,
+	)
+	router.GET("/roles/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.GetRole,
+	)
+	router.PUT("/roles/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.UpdateRole,
+	)
+	router.DELETE("/roles/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.DeleteRole,
+	)
+	router.GET("/roles/:id/permissions",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.RolePermissions,
+	)
+	router.PUT("/roles/:id/permissions",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.SetRolePermissions,
+	)
+	router.GET("/userroles",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.UserRoles,
+	)
+	router.POST("/userroles/bulk",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.BulkSetUserRoles,
+	)
+	router.GET("/access/audit",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageRoles),
+		accessH.Audit,
+	)
+
+	// 17e. Multi-factor authentication: self-service enrolment, and
+	// administrator reset and tenant policy
+	router.GET("/mfa", AuthMiddleware(authSvc, userRepo, licenseSvc), mfaH.Status)
+	router.POST("/mfa/enroll", AuthMiddleware(authSvc, userRepo, licenseSvc), mfaH.Enroll)
+	router.POST("/mfa/confirm", AuthMiddleware(authSvc, userRepo, licenseSvc), mfaH.Confirm)
+	router.POST("/mfa/recovery-codes", AuthMiddleware(authSvc, userRepo, licenseSvc), mfaH.RegenerateRecoveryCodes)
+	router.DELETE("/mfa", AuthMiddleware(authSvc, userRepo, licenseSvc), mfaH.Disable)
+	router.DELETE("/users/:id/mfa",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageMFA),
+		mfaH.Reset,
+	)
+	router.GET("/mfa/policy",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageMFA),
+		mfaH.GetPolicy,
+	)
+	router.PUT("/mfa/policy",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageMFA),
+		mfaH.SetPolicy,
+	)
+
+	// 17f. Tenants, managed from the platform tenant
+	router.GET("/tenants",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		tenantH.List,
+	)
+	router.POST("/tenants",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		tenantH.Create,
+	)
+	router.GET("/tenants/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		tenantH.Get,
+	)
+	router.POST("/tenants/:id/suspend",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		tenantH.Suspend,
+	)
+	router.POST("/tenants/:id/reactivate",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		tenantH.Reactivate,
+	)
+	router.GET("/tenants/:id/license",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		licenseH.Get,
+	)
+	router.PUT("/tenants/:id/license",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePlatformTenant(platformTenant),
+		RequirePermission(authzSvc, apiServices.PermManageTenants),
+		licenseH.Install,
+	)
+
+	// 17g. The tenant's own license; these answer even when it has lapsed
+	router.GET("/license",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		licenseH.Get,
+	)
+	router.PUT("/license",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageLicense),
+		licenseH.Install,
+	)
+
+	// 17h. API keys for integrations, sent in X-API-Key instead of a bearer token
+	router.GET("/api-keys",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageAPIKeys),
+		apiKeyH.List,
+	)
+	router.POST("/api-keys",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageAPIKeys),
+		apiKeyH.Create,
+	)
+	router.GET("/api-keys/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageAPIKeys),
+		apiKeyH.Get,
+	)
+	router.PUT("/api-keys/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageAPIKeys),
+		apiKeyH.Update,
+	)
+	router.DELETE("/api-keys/:id",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageAPIKeys),
+		apiKeyH.Delete,
+	)
+
+	// 17i. Single sign-on configuration
+	router.GET("/oidc/config",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageSSO),
+		oidcH.GetConfig,
+	)
+	router.PUT("/oidc/config",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageSSO),
+		oidcH.SetConfig,
+	)
+	router.DELETE("/oidc/config",
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequirePermission(authzSvc, apiServices.PermManageSSO),
+		oidcH.DeleteConfig,
+	)
 
 	// 18. Reporting routes
 	router.GET("/reports/commissions/beneficiary",
-		RequirePermission(userRepo, "view_commissions_report"),
+		RequireModule(licenseSvc, license.ModuleReports),
+		RequirePermission(authzSvc, "view_commissions_report"),
 		reportH.TotalCommissionByBeneficiary,
 	)
 
 	router.GET("/reports/installments/outstanding",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_installments_report"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleReports),
+		RequirePermission(authzSvc, "view_installments_report"),
 		reportH.OutstandingInstallmentsByPlan,
 	)
 
 	router.GET("/reports/sales/monthly",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_sales_report"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleReports),
+		RequirePermission(authzSvc, "view_sales_report"),
 		reportH.MonthlySalesVolume,
 	)
 
 	router.GET("/reports/lettings/rentroll",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_lettings_report"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleReports),
+		RequirePermission(authzSvc, "view_lettings_report"),
 		reportH.ActiveLettingsRentRoll,
 	)
 
 	router.GET("/reports/properties/top-payments",
-		AuthMiddleware(authSvc, userRepo),
-		RequirePermission(userRepo, "view_property_payments_report"),
+		AuthMiddleware(authSvc, userRepo, licenseSvc),
+		RequireModule(licenseSvc, license.ModuleReports),
+		RequirePermission(authzSvc, "view_property_payments_report"),
 		reportH.TopPropertiesByPaymentVolume,
 	)
 
@@ -422,6 +1112,18 @@ func main() {
 
 	router.GET("/export/properties.csv", ImportPropertiesCSV(db))
 
+	// Every permission a route can require exists in the catalog, so roles
+	// can be granted it.
+	if err := authzSvc.EnsurePermissions(context.Background(), routePermissions...); err != nil {
+		log.Fatalf("Failed to register permissions: %v", err)
+	}
+	if err := recordAccessSvc.Bootstrap(context.Background()); err != nil {
+		log.Fatalf("Failed to register record scopes: %v", err)
+	}
+	if err := tenantSvc.Bootstrap(context.Background(), platformTenant, platformAdmin, cfg.PlatformAdminPassword); err != nil {
+		log.Fatalf("Failed to create platform tenant: %v", err)
+	}
+
 	// 19. Start HTTP server with graceful shutdown
 	srv := &http.Server{
 		Addr:      ":8443",
@@ -436,11 +1138,23 @@ func main() {
 		}
 	}()
 
+	// Hourly installment reminders, when a notification channel is configured
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if len(notifiers) > 0 {
+		go reminderSvc.Start(jobsCtx, time.Hour)
+	}
+	// Overdue installment events and webhook delivery retries
+	go instSvc.Start(jobsCtx, time.Hour)
+	go webhookSvc.Start(jobsCtx, 15*time.Second)
+	go outboxSvc.Start(jobsCtx, 2*time.Second)
+
 	// 20. Graceful shutdown on SIGINT
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 	fmt.Println("Shutting down API server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -543,52 +1257,173 @@ func ExportPropertiesXLSX(db *sql.DB) http.HandlerFunc {
 	}
 }
 
-func AuthMiddleware(authSvc *apiServices.AuthService, userRepo apiRepos.UserRepo) gin.HandlerFunc {
+// AuthMiddleware authenticates the bearer token, or an API key sent in
+// X-API-Key, and checks the tenant's license. Without a usable license
+// only the /license routes answer, so an administrator can install one;
+// while it is close to or past expiry responses carry an
+// X-License-Warning header.
+func AuthMiddleware(authSvc *apiServices.AuthService, userRepo apiRepos.UserRepo, licenses *apiServices.LicenseService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		hdr := c.GetHeader("Authorization")
-		if !strings.HasPrefix(hdr, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+		var claims *apiServices.JWTClaims
+		var err error
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			claims, err = authSvc.AuthenticateAPIKey(c.Request.Context(), key, c.ClientIP())
+			if errors.Is(err, apiRepos.ErrInvalidAPIKey) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, apiRepos.ErrAPIKeyIPNotAllowed) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+		} else {
+			hdr := c.GetHeader("Authorization")
+			if !strings.HasPrefix(hdr, "Bearer ") {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+				return
+			}
+			claims, err = authSvc.Authenticate(c.Request.Context(), strings.TrimPrefix(hdr, "Bearer "))
+		}
+		if err == apiRepos.ErrTokenRevoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			return
+		}
+		if err == apiRepos.ErrTenantSuspended {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "tenant suspended"})
 			return
 		}
-		token := strings.TrimPrefix(hdr, "Bearer ")
-		claims, err := authSvc.ParseToken(token)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
-		// Check license expiry if you embedded LicenseExp in JWT (optional)
-		// if claims.LicenseExp < time.Now().Unix() {
-		//     c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "license expired"})
-		//     return
-		// }
+		warning, err := licenses.Check(c.Request.Context(), claims.TenantID)
+		switch {
+		case errors.Is(err, apiRepos.ErrNoLicense), errors.Is(err, apiRepos.ErrLicenseInvalid), errors.Is(err, apiRepos.ErrLicenseExpired):
+			if c.FullPath() != "/license" {
+				c.AbortWithStatusJSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+				return
+			}
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case warning != "":
+			c.Header("X-License-Warning", warning)
+		}
 
 		c.Set("currentUser", claims.UserID)
+		c.Set("currentUsername", claims.Subject)
 		c.Set("currentTenant", claims.TenantID)
 		c.Set("perms", claims.Permissions)
+		if claims.Purpose == apiServices.PurposeAPIKey {
+			c.Set("apiKeyPerms", claims.Permissions)
+		}
 		c.Next()
 	}
 }
 
-type Middleware struct {
-	UserRepo apiRepos.UserRepo
+// RequireModule lets the request through only if the tenant's license
+// includes module.
+func RequireModule(licenses *apiServices.LicenseService, module string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := licenses.CheckModule(c.Request.Context(), c.GetString("currentTenant"), module)
+		if errors.Is(err, apiRepos.ErrModuleNotLicensed) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
 }
 
-// RequirePermission checks that the logged‐in user's role is one of the allowed list.
-func RequirePermission(userRepo apiRepos.UserRepo, allowed ...string) gin.HandlerFunc {
-	isAllowed := func(role string) bool {
-		for _, r := range allowed {
-			if r == role {
-				return true
+// TenantHint notes the tenant a request names outside its body, for the
+// public routes that run before authentication: the X-Tenant-ID header,
+// else the first label of a host below baseDomain, else defaultTenant.
+func TenantHint(baseDomain, defaultTenant string) gin.HandlerFunc {
+	baseDomain = strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	return func(c *gin.Context) {
+		hint := strings.TrimSpace(c.GetHeader("X-Tenant-ID"))
+		if hint == "" && baseDomain != "" {
+			host := strings.ToLower(c.Request.Host)
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if sub, ok := strings.CutSuffix(host, "."+baseDomain); ok && !strings.Contains(sub, ".") {
+				hint = sub
 			}
 		}
-		return false
+		if hint == "" {
+			hint = defaultTenant
+		}
+		c.Set("tenantHint", strings.ToLower(hint))
+		c.Next()
 	}
+}
 
+// RequirePlatformTenant lets the request through only for users of the
+// platform tenant, so that tenant administrators cannot grant themselves
+// platform permissions.
+func RequirePlatformTenant(platformTenant string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("currentTenant") != platformTenant {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RecordScope puts the access policy of the logged-in user on the request
+// context, where the property, buyer, plan, sale and commission repos
+// read it to filter what they return.
+func RecordScope(svc *apiServices.RecordAccessService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var keyPerms []string
+		if _, isKey := c.Get("apiKeyPerms"); isKey {
+			keyPerms = c.GetStringSlice("apiKeyPerms")
+			if keyPerms == nil {
+				keyPerms = []string{}
+			}
+		}
+		policy, err := svc.Policy(c.Request.Context(), c.GetString("currentTenant"), c.GetInt64("currentUser"), keyPerms)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request = c.Request.WithContext(apiRepos.WithAccessPolicy(c.Request.Context(), policy))
+		c.Next()
+	}
+}
+
+// routePermissions collects the permission names used by RequirePermission
+// while the routes are registered.
+var routePermissions []string
+
+// RequirePermission lets the request through if the logged-in user
+// holds at least one of the allowed permissions, resolved through authz
+// rather than trusted from the token so that role changes apply to
+// tokens already issued. A request made with an API key also needs the
+// permission among the key's.
+func RequirePermission(authz *apiServices.AuthZService, allowed ...string) gin.HandlerFunc {
+	routePermissions = append(routePermissions, allowed...)
 	return func(c *gin.Context) {
 		userID := c.GetInt64("currentUser")
 		tenantID := c.GetString("currentTenant")
-		user, err := userRepo.GetByID(context.Background(), tenantID, userID)
-		if err != nil || user.Deleted || !isAllowed(user.Role) {
+		perms := allowed
+		if _, isKey := c.Get("apiKeyPerms"); isKey {
+			keyPerms := c.GetStringSlice("apiKeyPerms")
+			perms = slices.DeleteFunc(slices.Clone(allowed), func(p string) bool {
+				return !slices.Contains(keyPerms, p)
+			})
+		}
+		ok, err := authz.HasAny(c.Request.Context(), tenantID, userID, perms...)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			return
 		}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Access-control changes recorded in the audit log.
+const (
+	AuditRoleCreated        = "role.created"
+	AuditRoleUpdated        = "role.updated"
+	AuditRoleDeleted        = "role.deleted"
+	AuditRolePermissionsSet = "role.permissions_set"
+	AuditUserRolesSet       = "user.roles_set"
+)
+
+// AccessAudit records who changed which role or role assignment.
+// Detail is a JSON description of the change, such as the role IDs
+// before and after.
+type AccessAudit struct {
+	ID         int64     `db:"id" json:"id"`
+	TenantID   string    `db:"tenant_id" json:"tenantID"`
+	Actor      string    `db:"actor" json:"actor"`
+	Action     string    `db:"action" json:"action"`
+	TargetType string    `db:"target_type" json:"target_type"` // "role" or "user"
+	TargetID   int64     `db:"target_id" json:"target_id"`
+	Detail     string    `db:"detail" json:"detail"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
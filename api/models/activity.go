@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Activity types.
+const (
+	ActivityCall    = "call"
+	ActivityEmail   = "email"
+	ActivityViewing = "viewing"
+	ActivityNote    = "note"
+	ActivityTask    = "task"
+)
+
+// What an activity is about.
+const (
+	ActivitySubjectBuyer         = "buyer"
+	ActivitySubjectLettingTenant = "letting_tenant" // Lettings.TenantUserID
+)
+
+// Activity is one logged interaction with a buyer or a lettings tenant.
+type Activity struct {
+	ID           int64      `db:"id" json:"id"`
+	TenantID     string     `db:"tenant_id" json:"tenantID"`
+	SubjectType  string     `db:"subject_type" json:"subject_type"` // see ActivitySubject* constants
+	SubjectID    int64      `db:"subject_id" json:"subject_id"`     // Buyer.ID or User.ID
+	LettingID    int64      `db:"letting_id" json:"letting_id"`     // Lettings.ID for tenant activities, else 0
+	Type         string     `db:"type" json:"type"`                 // see Activity* type constants
+	OccurredAt   time.Time  `db:"occurred_at" json:"occurred_at"`
+	Author       string     `db:"author" json:"author"`
+	Body         string     `db:"body" json:"body"`
+	DueDate      *time.Time `db:"due_date" json:"due_date"`         // follow-up date, nil if none
+	CompletedAt  *time.Time `db:"completed_at" json:"completed_at"` // set when a follow-up is done
+	CreatedBy    string     `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy   string     `db:"modified_by" json:"modified_by"`
+	LastModified time.Time  `db:"last_modified" json:"last_modified"`
+	Deleted      bool       `db:"deleted" json:"deleted"`
+}
+
+// Timeline event kinds besides logged activities.
+const (
+	TimelineActivity           = "activity"
+	TimelinePlanCreated        = "plan_created"
+	TimelinePaymentReceived    = "payment_received"
+	TimelineInstallmentOverdue = "installment_overdue"
+	TimelineViewing            = "viewing"
+)
+
+// TimelineEntry is one item in a buyer's history.
+type TimelineEntry struct {
+	At            time.Time    `json:"at"`
+	Kind          string       `json:"kind"` // see Timeline* constants
+	Summary       string       `json:"summary"`
+	Activity      *Activity    `json:"activity,omitempty"`
+	Appointment   *Appointment `json:"appointment,omitempty"`
+	PlanID        int64        `json:"plan_id,omitempty"`
+	InstallmentID int64        `json:"installment_id,omitempty"`
+	PaymentID     int64        `json:"payment_id,omitempty"`
+	Amount        float64      `json:"amount,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// APIKey lets a script call the API as the user who created it, limited
+// to Permissions. Only the prefix is kept in clear; the secret is stored
+// hashed and shown once, when the key is created.
+type APIKey struct {
+	ID           int64      `db:"id" json:"id"`
+	TenantID     string     `db:"tenant_id" json:"tenant_id"`
+	UserID       int64      `db:"user_id" json:"user_id"`
+	Name         string     `db:"name" json:"name"`
+	Prefix       string     `db:"prefix" json:"prefix"`
+	SecretHash   string     `db:"secret_hash" json:"-"`
+	Permissions  []string   `db:"permissions" json:"permissions"`
+	AllowedIPs   []string   `db:"allowed_ips" json:"allowed_ips"` // IPs or CIDRs; empty allows any
+	ExpiresAt    *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt   *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP   string     `db:"last_used_ip" json:"last_used_ip,omitempty"`
+	CreatedBy    string     `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy   string     `db:"modified_by" json:"modified_by"`
+	LastModified time.Time  `db:"last_modified" json:"last_modified"`
+}
+
+// APIKeyRequest creates or updates an API key.
+type APIKeyRequest struct {
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	AllowedIPs  []string   `json:"allowed_ips"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// NewAPIKey is returned once, when a key is created; Key is the value to
+// send in the X-API-Key header.
+type NewAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Appointment statuses.
+const (
+	AppointmentScheduled = "scheduled"
+	AppointmentCompleted = "completed"
+	AppointmentCancelled = "cancelled"
+)
+
+// Appointment is a property viewing booked for a buyer, or for a prospect
+// who isn't a buyer yet, with an agent.
+type Appointment struct {
+	ID            int64     `db:"id" json:"id"`
+	TenantID      string    `db:"tenant_id" json:"tenantID"`
+	PropertyID    int64     `db:"property_id" json:"propertyID"`
+	BuyerID       int64     `db:"buyer_id" json:"buyerID"` // 0 for prospects
+	ProspectName  string    `db:"prospect_name" json:"prospect_name"`
+	ProspectPhone string    `db:"prospect_phone" json:"prospect_phone"`
+	ProspectEmail string    `db:"prospect_email" json:"prospect_email"`
+	AgentUserID   int64     `db:"agent_user_id" json:"agent_userID"`
+	StartsAt      time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt        time.Time `db:"ends_at" json:"ends_at"`
+	Status        string    `db:"status" json:"status"` // see Appointment* constants
+	Notes         string    `db:"notes" json:"notes"`
+	CreatedBy     string    `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	ModifiedBy    string    `db:"modified_by" json:"modified_by"`
+	LastModified  time.Time `db:"last_modified" json:"last_modified"`
+	Deleted       bool      `db:"deleted" json:"deleted"`
+}
+
+// AppointmentFilter narrows an appointment listing. Zero fields don't filter.
+// From/To select appointments overlapping [From, To).
+type AppointmentFilter struct {
+	From             time.Time
+	To               time.Time
+	AgentUserID      int64
+	PropertyID       int64
+	BuyerID          int64
+	IncludeCancelled bool
+}
+
+// TimeSlot is a free period returned by the availability search.
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Attachment is a file (photo, floor plan, deed, contract, ...) linked to a
+// property, sale, letting or installment plan. The bytes live in the
+// configured storage backend under StorageKey; this row holds the metadata.
+type Attachment struct {
+	ID           int64     `db:"id" json:"id"`
+	TenantID     string    `db:"tenant_id" json:"tenantID"`
+	EntityType   string    `db:"entity_type" json:"entity_type"` // "property", "sale", "letting", "plan"
+	EntityID     int64     `db:"entity_id" json:"entity_id"`
+	Category     string    `db:"category" json:"category"` // e.g. "photo", "floor_plan", "title_deed", "contract"
+	FileName     string    `db:"file_name" json:"file_name"`
+	MimeType     string    `db:"mime_type" json:"mime_type"`
+	SizeBytes    int64     `db:"size_bytes" json:"size_bytes"`
+	Checksum     string    `db:"checksum" json:"checksum"` // hex SHA-256 of the content
+	StorageKey   string    `db:"storage_key" json:"-"`
+	ThumbnailKey string    `db:"thumbnail_key" json:"-"` // empty when no thumbnail was generated
+	HasThumbnail bool      `db:"-" json:"has_thumbnail"`
+	UploadedBy   string    `db:"uploaded_by" json:"uploaded_by"`
+	CreatedBy    string    `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
+	LastModified time.Time `db:"last_modified" json:"last_modified"`
+	Deleted      bool      `db:"deleted" json:"deleted"`
+}
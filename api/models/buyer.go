@@ -10,6 +10,8 @@ type Buyer struct {
 	LastName     string    `db:"last_name" json:"last_name"`
 	Email        string    `db:"email" json:"email"`
 	Phone        string    `db:"phone" json:"phone"`
+	OwnerID      int64     `db:"owner_id" json:"owner_id"`
+	AssignedTo   int64     `db:"assigned_to" json:"assigned_to"`
 	CreatedBy    string    `db:"created_by" json:"created_by"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
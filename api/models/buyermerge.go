@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BuyerDuplicate is a pair of buyers that look like the same person.
+type BuyerDuplicate struct {
+	Buyer     Buyer    `json:"buyer"`
+	Duplicate Buyer    `json:"duplicate"`
+	Score     float64  `json:"score"`   // 0..1
+	Reasons   []string `json:"reasons"` // e.g. "email", "phone", "name"
+}
+
+// BuyerMerge records that DuplicateID was folded into SurvivorID.
+type BuyerMerge struct {
+	ID          int64  `db:"id" json:"id"`
+	TenantID    string `db:"tenant_id" json:"tenantID"`
+	SurvivorID  int64  `db:"survivor_id" json:"survivor_id"`
+	DuplicateID int64  `db:"duplicate_id" json:"duplicate_id"`
+	// DuplicateSnapshot is the duplicate Buyer as it was before the merge.
+	DuplicateSnapshot json.RawMessage `db:"duplicate_snapshot" json:"duplicate_snapshot"`
+	// Reassigned counts re-pointed rows per table, e.g. {"sales": 2}.
+	Reassigned map[string]int64 `db:"reassigned" json:"reassigned"`
+	Reason     string           `db:"reason" json:"reason"`
+	MergedBy   string           `db:"merged_by" json:"merged_by"`
+	MergedAt   time.Time        `db:"merged_at" json:"merged_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// CalendarFeedToken grants read access to one user's iCalendar feed. Only a
+// SHA-256 hash of the token is stored; the token itself is shown once.
+type CalendarFeedToken struct {
+	ID         int64      `db:"id" json:"id"`
+	TenantID   string     `db:"tenant_id" json:"tenantID"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Domain event types published to webhook subscribers.
+const (
+	EventPaymentReceived    = "payment.received"
+	EventInstallmentOverdue = "installment.overdue"
+	EventSaleCompleted      = "sale.completed"
+	EventLettingCreated     = "letting.created"
+	EventCommissionApproved = "commission.approved"
+)
+
+// EventTypes lists every event a webhook can subscribe to.
+var EventTypes = []string{
+	EventPaymentReceived,
+	EventInstallmentOverdue,
+	EventSaleCompleted,
+	EventLettingCreated,
+	EventCommissionApproved,
+}
+
+// DomainEvent is the envelope delivered to subscribers; Data holds the
+// affected record.
+type DomainEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TenantID   string    `json:"tenant_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Installment statuses.
+const (
+	InstallmentPending = "Pending"
+	InstallmentPaid    = "Paid"
+	InstallmentOverdue = "Overdue"
+)
+
 // Installment represents a single payment installment in a plan.
 type Installment struct {
 	ID             int64     `db:"id" json:"id"`
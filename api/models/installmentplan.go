@@ -14,6 +14,9 @@ type InstallmentPlan struct {
 	Frequency        string    `db:"frequency" json:"frequency"` // e.g. "Monthly"
 	FirstInstallment time.Time `db:"first_installment" json:"first_installment"`
 	InterestRate     float64   `db:"interest_rate" json:"interest_rate"`
+	SaleID           int64     `db:"sale_id" json:"sale_id"`         // FK → Sales.ID when created from a sale, else 0
+	OwnerID          int64     `db:"owner_id" json:"owner_id"`       // User.ID of the creator
+	AssignedTo       int64     `db:"assigned_to" json:"assigned_to"` // User.ID of the agent handling it, 0 if none
 	CreatedBy        string    `db:"created_by" json:"created_by"`
 	CreatedAt        time.Time `db:"created_at" json:"created_at"`
 	ModifiedBy       string    `db:"modified_by" json:"modified_by"`
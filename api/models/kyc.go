@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// KYC verification statuses.
+const (
+	KYCPending  = "pending"
+	KYCApproved = "approved"
+	KYCRejected = "rejected"
+)
+
+// Actions a KYC check guards; an override is granted for one of these.
+const (
+	KYCActionCreatePlan = "create_plan"
+	KYCActionCreateSale = "create_sale"
+)
+
+// BuyerKYC holds a buyer's identity and source-of-funds verification. There
+// is at most one record per buyer; resubmitting details resets it to pending.
+type BuyerKYC struct {
+	ID                       int64      `db:"id" json:"id"`
+	TenantID                 string     `db:"tenant_id" json:"tenantID"`
+	BuyerID                  int64      `db:"buyer_id" json:"buyerID"`
+	IDDocumentType           string     `db:"id_document_type" json:"id_document_type"` // e.g. "passport", "driving_licence"
+	IDDocumentNumber         string     `db:"id_document_number" json:"id_document_number"`
+	IDDocumentExpiry         time.Time  `db:"id_document_expiry" json:"id_document_expiry"`
+	IDDocumentAttachmentID   int64      `db:"id_document_attachment_id" json:"id_document_attachment_id"` // Attachment on the buyer, 0 = none
+	AddressProofType         string     `db:"address_proof_type" json:"address_proof_type"`               // e.g. "utility_bill", "bank_statement"
+	AddressProofAttachmentID int64      `db:"address_proof_attachment_id" json:"address_proof_attachment_id"`
+	SourceOfFunds            string     `db:"source_of_funds" json:"source_of_funds"`
+	Status                   string     `db:"status" json:"status"` // see KYC* status constants
+	VerifiedBy               string     `db:"verified_by" json:"verified_by"`
+	VerifiedAt               *time.Time `db:"verified_at" json:"verified_at"`
+	Notes                    string     `db:"notes" json:"notes"`
+	CreatedBy                string     `db:"created_by" json:"created_by"`
+	CreatedAt                time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy               string     `db:"modified_by" json:"modified_by"`
+	LastModified             time.Time  `db:"last_modified" json:"last_modified"`
+	Deleted                  bool       `db:"deleted" json:"deleted"`
+}
+
+// KYCOverride is an admin's one-off permission to proceed with a single
+// action for a buyer whose KYC isn't approved. Rows are never deleted so
+// they double as the audit trail.
+type KYCOverride struct {
+	ID        int64      `db:"id" json:"id"`
+	TenantID  string     `db:"tenant_id" json:"tenantID"`
+	BuyerID   int64      `db:"buyer_id" json:"buyerID"`
+	Action    string     `db:"action" json:"action"` // KYCActionCreatePlan or KYCActionCreateSale
+	Reason    string     `db:"reason" json:"reason"`
+	GrantedBy string     `db:"granted_by" json:"granted_by"`
+	GrantedAt time.Time  `db:"granted_at" json:"granted_at"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedBy    string     `db:"used_by" json:"used_by"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"` // nil until consumed
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TenantLicense is the signed license file installed for a tenant, kept
+// exactly as uploaded so the signature can be re-checked on load.
+type TenantLicense struct {
+	TenantID    string    `db:"tenant_id" json:"tenant_id"`
+	LicenseID   string    `db:"license_id" json:"license_id"`
+	Data        string    `db:"data" json:"-"`
+	InstalledBy string    `db:"installed_by" json:"installed_by"`
+	InstalledAt time.Time `db:"installed_at" json:"installed_at"`
+}
+
+// LicenseStatus is what a tenant sees of its license.
+type LicenseStatus struct {
+	Licensed  bool       `json:"licensed"`
+	LicenseID string     `json:"license_id,omitempty"`
+	Licensee  string     `json:"licensee,omitempty"`
+	Status    string     `json:"status"`
+	Seats     int        `json:"seats"`
+	SeatsUsed int        `json:"seats_used"`
+	Modules   []string   `json:"modules"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	GraceEnds *time.Time `json:"grace_ends,omitempty"`
+	Warning   string     `json:"warning,omitempty"`
+}
@@ -16,3 +16,29 @@ type LocationPricing struct {
 	LastModified  time.Time `db:"last_modified" json:"last_modified"`
 	Deleted       bool      `db:"deleted" json:"deleted"`
 }
+
+// PricingImportRowError reports why a single spreadsheet row was rejected.
+// Row is the 1-based line number in the uploaded file, header included.
+type PricingImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// PricingImportChange pairs an existing LocationPricing with the values an
+// import would overwrite it with.
+type PricingImportChange struct {
+	Row    int             `json:"row"`
+	Before LocationPricing `json:"before"`
+	After  LocationPricing `json:"after"`
+}
+
+// PricingImportResult is the diff produced by a bulk pricing import. On a
+// dry run (or when any row is invalid) nothing has been written.
+type PricingImportResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Committed bool                    `json:"committed"`
+	Inserts   []LocationPricing       `json:"inserts"`
+	Updates   []PricingImportChange   `json:"updates"`
+	Unchanged int                     `json:"unchanged"`
+	Errors    []PricingImportRowError `json:"errors"`
+}
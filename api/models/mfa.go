@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// MFASecret is a user's TOTP enrolment. It is pending until ConfirmedAt
+// is set by a first valid code. LastCounter is the last time step
+// accepted, so a code cannot be used twice.
+type MFASecret struct {
+	TenantID    string     `db:"tenant_id" json:"tenantID"`
+	UserID      int64      `db:"user_id" json:"user_id"`
+	Secret      string     `db:"secret" json:"-"`
+	ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmed_at,omitempty"`
+	LastCounter int64      `db:"last_counter" json:"-"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// MFAEnrolment is handed to the user once to set up an authenticator app.
+type MFAEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAPolicy makes MFA mandatory in a tenant for every user holding any of
+// RequiredPermissions, e.g. "delete_payments".
+type MFAPolicy struct {
+	TenantID            string    `db:"tenant_id" json:"tenantID"`
+	RequiredPermissions []string  `db:"required_permissions" json:"required_permissions"`
+	ModifiedBy          string    `db:"modified_by" json:"modified_by"`
+	LastModified        time.Time `db:"last_modified" json:"last_modified"`
+}
+
+// LoginResult is the outcome of a login step: either the session tokens,
+// or a short-lived MFA challenge token to present with a code.
+type LoginResult struct {
+	Tokens      *TokenPair `json:"-"`
+	MFARequired bool       `json:"mfa_required,omitempty"`
+	MFAToken    string     `json:"mfa_token,omitempty"`
+	// EnrolmentRequired means policy demands MFA but the user has none
+	// yet: they enrol with the challenge token before completing login.
+	EnrolmentRequired bool `json:"enrolment_required,omitempty"`
+	// RecoveryCodes are returned once, when an enrolment is confirmed.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Notification subjects.
+const (
+	NotifySubjectBuyer = "buyer"
+	NotifySubjectUser  = "user"
+)
+
+// Notification attempt outcomes.
+const (
+	NotifyAttemptSent   = "sent"
+	NotifyAttemptFailed = "failed"
+)
+
+// NotificationPreference is a buyer's or user's address on one channel
+// ("email", "sms", "webhook") and whether they have opted out of it.
+type NotificationPreference struct {
+	ID           int64      `db:"id" json:"id"`
+	TenantID     string     `db:"tenant_id" json:"tenantID"`
+	SubjectType  string     `db:"subject_type" json:"subject_type"`
+	SubjectID    int64      `db:"subject_id" json:"subject_id"`
+	Channel      string     `db:"channel" json:"channel"`
+	Address      string     `db:"address" json:"address"` // "" = the buyer's/user's email or phone
+	OptedOut     bool       `db:"opted_out" json:"opted_out"`
+	OptedOutAt   *time.Time `db:"opted_out_at" json:"opted_out_at,omitempty"`
+	CreatedBy    string     `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy   string     `db:"modified_by" json:"modified_by"`
+	LastModified time.Time  `db:"last_modified" json:"last_modified"`
+}
+
+// NotificationAttempt logs one delivery attempt on one channel.
+type NotificationAttempt struct {
+	ID          int64     `db:"id" json:"id"`
+	TenantID    string    `db:"tenant_id" json:"tenantID"`
+	SubjectType string    `db:"subject_type" json:"subject_type"`
+	SubjectID   int64     `db:"subject_id" json:"subject_id"`
+	Channel     string    `db:"channel" json:"channel"`
+	Recipient   string    `db:"recipient" json:"recipient"`
+	Subject     string    `db:"subject" json:"subject"`
+	Attempt     int       `db:"attempt" json:"attempt"`
+	Status      string    `db:"status" json:"status"`
+	Error       string    `db:"error" json:"error,omitempty"`
+	AttemptedAt time.Time `db:"attempted_at" json:"attempted_at"`
+}
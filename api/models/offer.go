@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Offer statuses.
+const (
+	OfferPending   = "pending"
+	OfferCountered = "countered"
+	OfferAccepted  = "accepted"
+	OfferRejected  = "rejected"
+	OfferWithdrawn = "withdrawn"
+	OfferCompleted = "completed"
+)
+
+// Conveyancing milestones an accepted offer moves through, in order. Reaching
+// OfferMilestoneCompletion creates the Sales record.
+const (
+	OfferMilestoneMemorandum = "memorandum_of_sale"
+	OfferMilestoneSearches   = "searches"
+	OfferMilestoneSurvey     = "survey"
+	OfferMilestoneMortgage   = "mortgage_offer"
+	OfferMilestoneExchange   = "exchange"
+	OfferMilestoneCompletion = "completion"
+)
+
+// OfferMilestones lists the conveyancing milestones in pipeline order.
+var OfferMilestones = []string{
+	OfferMilestoneMemorandum,
+	OfferMilestoneSearches,
+	OfferMilestoneSurvey,
+	OfferMilestoneMortgage,
+	OfferMilestoneExchange,
+	OfferMilestoneCompletion,
+}
+
+// Offer is a buyer's bid on a property, tracked from first offer through
+// conveyancing to completion.
+type Offer struct {
+	ID            int64      `db:"id" json:"id"`
+	TenantID      string     `db:"tenant_id" json:"tenantID"`
+	PropertyID    int64      `db:"property_id" json:"propertyID"`
+	BuyerID       int64      `db:"buyer_id" json:"buyerID"`
+	Amount        float64    `db:"amount" json:"amount"`
+	CounterAmount float64    `db:"counter_amount" json:"counter_amount"` // seller's counter, when Status is "countered"
+	Conditions    string     `db:"conditions" json:"conditions"`         // e.g. "subject to survey", "chain-free"
+	SaleType      string     `db:"sale_type" json:"saletype"`            // copied to the Sales record on completion
+	ExpiresAt     *time.Time `db:"expires_at" json:"expires_at"`         // nil = open-ended
+	Status        string     `db:"status" json:"status"`                 // see Offer* status constants
+	Stage         string     `db:"stage" json:"stage"`                   // latest milestone reached, "" before acceptance
+	SaleID        int64      `db:"sale_id" json:"saleID"`                // Sales.ID once completed
+	CreatedBy     string     `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy    string     `db:"modified_by" json:"modified_by"`
+	LastModified  time.Time  `db:"last_modified" json:"last_modified"`
+	Deleted       bool       `db:"deleted" json:"deleted"`
+
+	Milestones []OfferMilestone `db:"-" json:"milestones,omitempty"`
+}
+
+// OfferMilestone records when an accepted offer reached a conveyancing step.
+type OfferMilestone struct {
+	ID        int64     `db:"id" json:"id"`
+	TenantID  string    `db:"tenant_id" json:"tenantID"`
+	OfferID   int64     `db:"offer_id" json:"offerID"`
+	Milestone string    `db:"milestone" json:"milestone"`
+	ReachedAt time.Time `db:"reached_at" json:"reached_at"`
+	Notes     string    `db:"notes" json:"notes"`
+	CreatedBy string    `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
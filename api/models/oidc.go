@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// OIDCConfig is a tenant's OpenID Connect identity provider. Users log in
+// there and are matched, or created, by the claims the provider returns.
+type OIDCConfig struct {
+	TenantID     string `db:"tenant_id" json:"tenant_id"`
+	Enabled      bool   `db:"enabled" json:"enabled"`
+	Issuer       string `db:"issuer" json:"issuer"`
+	ClientID     string `db:"client_id" json:"client_id"`
+	ClientSecret string `db:"client_secret" json:"client_secret,omitempty"` // write-only
+	// Scopes beyond openid; default profile and email.
+	Scopes []string `db:"scopes" json:"scopes"`
+	// UsernameClaim names the claim used as username; default
+	// preferred_username, falling back to email.
+	UsernameClaim string `db:"username_claim" json:"username_claim"`
+	// If TenantClaim is set, the ID token must carry it with the value
+	// TenantClaimValue, so a shared provider cannot log other
+	// organisations' users into this tenant.
+	TenantClaim      string `db:"tenant_claim" json:"tenant_claim"`
+	TenantClaimValue string `db:"tenant_claim_value" json:"tenant_claim_value"`
+	// RoleClaim (e.g. groups) is mapped to tenant roles by RoleMappings,
+	// claim value to role name; users matching none get DefaultRole. With
+	// a RoleClaim the user's roles are set on every login.
+	RoleClaim    string            `db:"role_claim" json:"role_claim"`
+	RoleMappings map[string]string `db:"role_mappings" json:"role_mappings"`
+	DefaultRole  string            `db:"default_role" json:"default_role"`
+	// A first login is never linked to an existing user by username. With
+	// LinkVerifiedEmail it is linked to the one user with the same email,
+	// if the provider vouches for the email with email_verified; otherwise
+	// a user is created, unless the username is taken.
+	LinkVerifiedEmail bool      `db:"link_verified_email" json:"link_verified_email"`
+	ModifiedBy        string    `db:"modified_by" json:"modified_by"`
+	LastModified      time.Time `db:"last_modified" json:"last_modified"`
+}
+
+// OIDCLoginState is kept between sending a user to the provider and the
+// provider sending them back.
+type OIDCLoginState struct {
+	State        string    `db:"state" json:"-"`
+	TenantID     string    `db:"tenant_id" json:"tenant_id"`
+	Nonce        string    `db:"nonce" json:"-"`
+	CodeVerifier string    `db:"code_verifier" json:"-"`
+	RedirectURI  string    `db:"redirect_uri" json:"redirect_uri"`
+	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// OIDCIdentity links a provider's subject to a user.
+type OIDCIdentity struct {
+	TenantID  string    `db:"tenant_id" json:"tenant_id"`
+	Issuer    string    `db:"issuer" json:"issuer"`
+	Subject   string    `db:"subject" json:"subject"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event statuses. An event is dead once the dispatcher has used up
+// its retries or its consumer rejected it outright.
+const (
+	OutboxPending    = "pending"
+	OutboxDispatched = "dispatched"
+	OutboxDead       = "dead"
+)
+
+// Outbox topics: changes one domain database asks another to apply.
+const (
+	TopicPaymentApplied        = "payment.applied"
+	TopicPropertyStatusChanged = "property.status_changed"
+)
+
+// OutboxEvent is written in the same transaction as the change that
+// raised it and later handed to the consumer registered for its topic.
+// ID is unique across all domains so consumers can deduplicate on it.
+type OutboxEvent struct {
+	ID            string          `db:"id" json:"id"`
+	TenantID      string          `db:"tenant_id" json:"tenantID"`
+	Topic         string          `db:"topic" json:"topic"`
+	AggregateType string          `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   int64           `db:"aggregate_id" json:"aggregate_id"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	Status        string          `db:"status" json:"status"`
+	Attempts      int             `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string          `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+	DispatchedAt  *time.Time      `db:"dispatched_at" json:"dispatched_at,omitempty"`
+
+	// Domain names the database the event was read from; it is not stored.
+	Domain string `db:"-" json:"domain"`
+}
+
+// PaymentApplied moves an installment's paid amount by Delta: positive
+// for a new payment, negative for a deleted one.
+type PaymentApplied struct {
+	InstallmentID int64     `json:"installment_id"`
+	Delta         float64   `json:"delta"`
+	PaidDate      time.Time `json:"paid_date"`
+	ModifiedBy    string    `json:"modified_by"`
+}
+
+// PropertyStatusChanged sets a property's status after an offer moves.
+type PropertyStatusChanged struct {
+	PropertyID int64  `json:"property_id"`
+	Status     string `json:"status"`
+	ModifiedBy string `json:"modified_by"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token emailed to a user who forgot
+// their password. Only a SHA-256 hash is stored.
+type PasswordResetToken struct {
+	ID        int64      `db:"id" json:"id"`
+	TenantID  string     `db:"tenant_id" json:"tenantID"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+}
+
+// LoginFailure counts consecutive failed logins for one username from one
+// IP address. Once Failures reaches the lockout threshold, logins from
+// that address are refused until LockedUntil.
+type LoginFailure struct {
+	TenantID    string     `db:"tenant_id" json:"tenantID"`
+	UserName    string     `db:"username" json:"username"`
+	IP          string     `db:"ip" json:"ip"`
+	Failures    int        `db:"failures" json:"failures"`
+	LockedUntil *time.Time `db:"locked_until" json:"locked_until,omitempty"`
+	LastFailure time.Time  `db:"last_failure" json:"last_failure"`
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Property statuses.
+const (
+	PropertyAvailable  = "available"
+	PropertyUnderOffer = "under_offer"
+	PropertySold       = "sold"
+)
+
 // Property represents a real‐estate listing.
 type Property struct {
 	ID           int64     `db:"id" json:"id"`
@@ -10,7 +17,13 @@ type Property struct {
 	City         string    `db:"city" json:"city"`
 	ZIP          string    `db:"zip" json:"zip"`
 	ListingDate  time.Time `db:"listing_date" json:"listing_date"`
-	CreatedBy    string    `db:"created_by" json:"created_by"` // Username or userID who created
+	Status       string    `db:"status" json:"status"`           // "available", "under_offer", "sold"
+	Latitude     *float64  `db:"latitude" json:"latitude"`       // WGS84 degrees; nil until geocoded
+	Longitude    *float64  `db:"longitude" json:"longitude"`     // WGS84 degrees; nil until geocoded
+	GeoSource    string    `db:"geo_source" json:"geo_source"`   // "manual", "zip_centroid" or "" when unknown
+	OwnerID      int64     `db:"owner_id" json:"owner_id"`       // User.ID of the creator
+	AssignedTo   int64     `db:"assigned_to" json:"assigned_to"` // User.ID of the agent handling it, 0 if none
+	CreatedBy    string    `db:"created_by" json:"created_by"`   // Username or userID who created
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	ModifiedBy   string    `db:"modified_by" json:"modified_by"` // Username or userID who last modified
 	LastModified time.Time `db:"last_modified" json:"last_modified"`
@@ -21,3 +34,19 @@ type PropertyPaymentVolume struct {
 	PropertyID      int64   `json:"property_id"`
 	TotalPaidAmount float64 `json:"total_paid"`
 }
+
+// PropertySearchResult is a property matched by a radius search, with its
+// great-circle distance from the search point.
+type PropertySearchResult struct {
+	Property
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// PostalCentroid is a tenant-supplied ZIP/postcode centre point used to
+// geocode properties offline.
+type PostalCentroid struct {
+	TenantID   string  `db:"tenant_id" json:"tenantID"`
+	PostalCode string  `db:"postal_code" json:"postal_code"` // normalised: upper case, no spaces
+	Latitude   float64 `db:"latitude" json:"latitude"`
+	Longitude  float64 `db:"longitude" json:"longitude"`
+}
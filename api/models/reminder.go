@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Reminder kinds.
+const (
+	ReminderUpcoming = "upcoming"
+	ReminderOverdue  = "overdue"
+)
+
+// ReminderTemplate is a tenant's email template for one reminder kind.
+// Subject and Body are Go text/template strings; see ReminderData.
+type ReminderTemplate struct {
+	ID           int64     `db:"id" json:"id"`
+	TenantID     string    `db:"tenant_id" json:"tenantID"`
+	Kind         string    `db:"kind" json:"kind"`
+	Subject      string    `db:"subject" json:"subject"`
+	Body         string    `db:"body" json:"body"`
+	CreatedBy    string    `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
+	LastModified time.Time `db:"last_modified" json:"last_modified"`
+}
+
+// ReminderData holds the placeholders available to reminder templates,
+// e.g. {{.BuyerName}} or {{.DueDate}}.
+type ReminderData struct {
+	BuyerName         string
+	PlanID            int64
+	PropertyID        int64
+	InstallmentNumber int
+	NumInstallments   int
+	DueDate           string // YYYY-MM-DD
+	AmountDue         string
+	AmountPaid        string
+	Outstanding       string
+	LateFee           string
+	DaysUntilDue      int
+	DaysOverdue       int
+}
+
+// ReminderSend records a reminder delivered for an installment. There is
+// at most one per (tenant, installment, kind).
+type ReminderSend struct {
+	ID            int64     `db:"id" json:"id"`
+	TenantID      string    `db:"tenant_id" json:"tenantID"`
+	InstallmentID int64     `db:"installment_id" json:"installment_id"`
+	Kind          string    `db:"kind" json:"kind"`
+	Recipient     string    `db:"recipient" json:"recipient"`
+	SentAt        time.Time `db:"sent_at" json:"sent_at"`
+}
+
+// ReminderRunResult summarises one reminder pass.
+type ReminderRunResult struct {
+	Sent    int      `json:"sent"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
@@ -2,6 +2,7 @@ package models
 
 type Role struct {
 	ID          int64  `json:"id"`
+	TenantID    string `json:"tenantID"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
@@ -2,6 +2,9 @@ package models
 
 import "time"
 
+// SaleTypeInstallment marks a sale paid through an InstallmentPlan.
+const SaleTypeInstallment = "installment"
+
 type Sales struct {
 	ID           int64     `db:"id" json:"id"`
 	TenantID     string    `db:"tenant_id" json:"tenantID"`
@@ -10,6 +13,8 @@ type Sales struct {
 	SalePrice    float64   `db:"sale_price" json:"saleprice"`
 	SaleDate     time.Time `db:"sale_date" json:"saledate"`
 	SaleType     string    `db:"sale_type" json:"saletype"`
+	OwnerID      int64     `db:"owner_id" json:"owner_id"`
+	AssignedTo   int64     `db:"assigned_to" json:"assigned_to"`
 	CreatedBy    string    `db:"created_by" json:"created_by"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
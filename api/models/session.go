@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefreshToken is one link in a login session's chain of refresh tokens.
+// Each refresh consumes the token and issues the next one in the same
+// family; only a SHA-256 hash is stored.
+type RefreshToken struct {
+	ID        int64      `db:"id" json:"id"`
+	TenantID  string     `db:"tenant_id" json:"tenantID"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	FamilyID  string     `db:"family_id" json:"family_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// TokenPair is what a login or refresh hands back to the client.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Tenant statuses. A tenant is "provisioning" until its default roles and
+// admin user exist; users of a suspended tenant cannot log in or use
+// tokens already issued.
+const (
+	TenantProvisioning = "provisioning"
+	TenantActive       = "active"
+	TenantSuspended    = "suspended"
+)
+
+// Tenant is an agency using the system. ID is the slug users give at
+// login, also accepted as a subdomain.
+type Tenant struct {
+	ID              string     `db:"id" json:"id"`
+	Name            string     `db:"name" json:"name"`
+	Status          string     `db:"status" json:"status"`
+	SuspendedAt     *time.Time `db:"suspended_at" json:"suspended_at,omitempty"`
+	SuspendedReason string     `db:"suspended_reason" json:"suspended_reason,omitempty"`
+	CreatedBy       string     `db:"created_by" json:"created_by"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	ModifiedBy      string     `db:"modified_by" json:"modified_by"`
+	LastModified    time.Time  `db:"last_modified" json:"last_modified"`
+}
+
+// TenantProvisionRequest creates a tenant with its first administrator.
+type TenantProvisionRequest struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Admin struct {
+		UserName  string `json:"username"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+		Password  string `json:"password"`
+	} `json:"admin"`
+}
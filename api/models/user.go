@@ -13,6 +13,7 @@ type User struct {
 	Role         string    `db:"role" json:"role"`                   // e.g. "admin", "sales"
 	Email        string    `db:"email" json:"email"`                 // Contact email
 	Phone        string    `db:"phone" json:"phone"`                 // Contact phone number
+	Team         string    `db:"team" json:"team"`                   // Sales team; team leads see their team's records
 	CreatedBy    string    `db:"created_by" json:"created_by"`       // Who created this user
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`       // When this record was created
 	ModifiedBy   string    `db:"modified_by" json:"modified_by"`     // Who last modified
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses. A delivery is dead once it has used up its
+// retries; dead deliveries form the dead-letter log and can be replayed.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription sends a tenant's domain events to URL. Events lists
+// the event types wanted; empty means all.
+type WebhookSubscription struct {
+	ID           int64     `db:"id" json:"id"`
+	TenantID     string    `db:"tenant_id" json:"tenantID"`
+	URL          string    `db:"url" json:"url"`
+	Secret       string    `db:"secret" json:"-"` // HMAC key, shown once at creation
+	Events       []string  `db:"events" json:"events"`
+	Active       bool      `db:"active" json:"active"`
+	CreatedBy    string    `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
+	LastModified time.Time `db:"last_modified" json:"last_modified"`
+	Deleted      bool      `db:"deleted" json:"deleted"`
+}
+
+// Wants reports whether the subscription receives eventType.
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription.
+type WebhookDelivery struct {
+	ID             int64           `db:"id" json:"id"`
+	TenantID       string          `db:"tenant_id" json:"tenantID"`
+	SubscriptionID int64           `db:"subscription_id" json:"subscription_id"`
+	EventID        string          `db:"event_id" json:"event_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError      string          `db:"last_error" json:"last_error,omitempty"`
+	LastStatusCode int             `db:"last_status_code" json:"last_status_code,omitempty"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// AccessAuditRepo stores the audit log of role and permission changes.
+type AccessAuditRepo interface {
+	Create(ctx context.Context, a *models.AccessAudit) (int64, error)
+	// List returns the tenant's entries, newest first, optionally only
+	// those about one target.
+	List(ctx context.Context, tenantID, targetType string, targetID int64, limit int) ([]*models.AccessAudit, error)
+}
+
+func NewDBAccessAuditRepo(db *sql.DB, driver string) AccessAuditRepo {
+	switch driver {
+	case "postgres":
+		return &postgresAccessAuditRepo{db: db}
+	case "sqlite":
+		return &sqliteAccessAuditRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+const accessAuditColumns = `id, tenant_id, actor, action, target_type, target_id, detail, created_at`
+
+func scanAccessAudits(rows *sql.Rows) ([]*models.AccessAudit, error) {
+	defer rows.Close()
+	var out []*models.AccessAudit
+	for rows.Next() {
+		var a models.AccessAudit
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.Actor, &a.Action, &a.TargetType, &a.TargetID, &a.Detail, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,78 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AccessPolicy limits which of a tenant's properties, buyers, plans, sales
+// and commissions a request may see. A record belongs to the user who
+// created it (owner_id) and to the user it is assigned to (assigned_to, or
+// beneficiary_id for commissions); it is visible when either is one of
+// UserIDs.
+type AccessPolicy struct {
+	UserID  int64   // the acting user, who owns the records it creates
+	All     bool    // no restriction: admins
+	UserIDs []int64 // whose records are visible: the user, plus their team for team leads
+	Assign  bool    // may change who a record is assigned to
+}
+
+type accessPolicyKey struct{}
+
+// WithAccessPolicy returns ctx carrying p. The record repos filter their
+// reads by it; a context without a policy, as used by background jobs, sees
+// every record. The report summaries are tenant-wide and ignore it.
+func WithAccessPolicy(ctx context.Context, p *AccessPolicy) context.Context {
+	return context.WithValue(ctx, accessPolicyKey{}, p)
+}
+
+// AccessPolicyFrom returns the policy carried by ctx, or nil.
+func AccessPolicyFrom(ctx context.Context) *AccessPolicy {
+	p, _ := ctx.Value(accessPolicyKey{}).(*AccessPolicy)
+	return p
+}
+
+// Unscoped returns ctx without its access policy, for checks that must
+// see every record whoever asks, such as whether a sale already has a plan.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, accessPolicyKey{}, (*AccessPolicy)(nil))
+}
+
+// recordOwner is the owner to give a record created under ctx when the
+// caller did not set one.
+func recordOwner(ctx context.Context, owner int64) int64 {
+	if p := AccessPolicyFrom(ctx); owner == 0 && p != nil {
+		return p.UserID
+	}
+	return owner
+}
+
+// scopeFilter returns the condition, starting with AND, that restricts a
+// query to the records the policy in ctx may see, and its arguments. cols
+// are the columns holding the record's users. Placeholders are ? when next
+// is 0, otherwise $next, $next+1 and so on.
+func scopeFilter(ctx context.Context, next int, cols ...string) (string, []any) {
+	p := AccessPolicyFrom(ctx)
+	if p == nil || p.All {
+		return "", nil
+	}
+	if len(p.UserIDs) == 0 {
+		return " AND 1 = 0", nil
+	}
+	var conds []string
+	var args []any
+	for _, col := range cols {
+		marks := make([]string, len(p.UserIDs))
+		for i, id := range p.UserIDs {
+			marks[i] = "?"
+			if next > 0 {
+				marks[i] = fmt.Sprintf("$%d", next)
+				next++
+			}
+			args = append(args, id)
+		}
+		conds = append(conds, col+" IN ("+strings.Join(marks, ", ")+")")
+	}
+	return " AND (" + strings.Join(conds, " OR ") + ")", args
+}
@@ -0,0 +1,29 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// ActivityRepo stores CRM activities logged against buyers and lettings tenants.
+type ActivityRepo interface {
+	Create(ctx context.Context, a *models.Activity) (int64, error) // a.TenantID set
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.Activity, error)
+	// ListBySubject returns the subject's activities, oldest first.
+	ListBySubject(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.Activity, error)
+	Update(ctx context.Context, a *models.Activity) error // using a.TenantID, a.ID
+}
+
+// NewDBActivityRepo selects the concrete implementation based on driver.
+func NewDBActivityRepo(db *sql.DB, driver string) ActivityRepo {
+	switch driver {
+	case "postgres":
+		return &postgresActivityRepo{db: db}
+	case "sqlite":
+		return &sqliteActivityRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,66 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// APIKeyRepo stores API keys. Keys are found by prefix across all tenants,
+// since the key alone identifies its tenant.
+type APIKeyRepo interface {
+	Create(ctx context.Context, k *models.APIKey) (int64, error)
+	// GetByID returns ErrNotFound if there is no such key in the tenant.
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.APIKey, error)
+	// GetByPrefix returns ErrNotFound if no key has that prefix.
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	ListAll(ctx context.Context, tenantID string) ([]*models.APIKey, error)
+	// Update saves name, permissions, allowed IPs and expiry; ErrNotFound
+	// if missing.
+	Update(ctx context.Context, k *models.APIKey) error
+	// Delete revokes the key; ErrNotFound if missing.
+	Delete(ctx context.Context, tenantID string, id int64) error
+	// Touch records that the key was used at time at from ip.
+	Touch(ctx context.Context, id int64, at time.Time, ip string) error
+}
+
+// NewDBAPIKeyRepo selects the concrete implementation based on driver.
+func NewDBAPIKeyRepo(db *sql.DB, driver string) APIKeyRepo {
+	switch driver {
+	case "postgres":
+		return &postgresAPIKeyRepo{db: db}
+	case "sqlite":
+		return &sqliteAPIKeyRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var k models.APIKey
+	var perms, ips string
+	if err := row.Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.SecretHash,
+		&perms,
+		&ips,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.LastUsedIP,
+		&k.CreatedBy,
+		&k.CreatedAt,
+		&k.ModifiedBy,
+		&k.LastModified,
+	); err != nil {
+		return nil, err
+	}
+	k.Permissions = splitList(perms)
+	k.AllowedIPs = splitList(ips)
+	return &k, nil
+}
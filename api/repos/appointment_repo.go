@@ -0,0 +1,60 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// AppointmentRepo stores property viewing appointments.
+type AppointmentRepo interface {
+	Create(ctx context.Context, a *models.Appointment) (int64, error) // a.TenantID set
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.Appointment, error)
+	// List returns appointments matching f, ordered by start time.
+	List(ctx context.Context, tenantID string, f models.AppointmentFilter) ([]*models.Appointment, error)
+	Update(ctx context.Context, a *models.Appointment) error // using a.TenantID, a.ID
+}
+
+// NewDBAppointmentRepo selects the concrete implementation based on driver.
+func NewDBAppointmentRepo(db *sql.DB, driver string) AppointmentRepo {
+	switch driver {
+	case "postgres":
+		return &postgresAppointmentRepo{db: db}
+	case "sqlite":
+		return &sqliteAppointmentRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+// appointmentFilterSQL turns f into extra WHERE conditions using ?
+// placeholders, with their arguments.
+func appointmentFilterSQL(f models.AppointmentFilter) (string, []any) {
+	var where string
+	var args []any
+	if !f.From.IsZero() {
+		where += " AND ends_at > ?"
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		where += " AND starts_at < ?"
+		args = append(args, f.To.UTC())
+	}
+	if f.AgentUserID != 0 {
+		where += " AND agent_user_id = ?"
+		args = append(args, f.AgentUserID)
+	}
+	if f.PropertyID != 0 {
+		where += " AND property_id = ?"
+		args = append(args, f.PropertyID)
+	}
+	if f.BuyerID != 0 {
+		where += " AND buyer_id = ?"
+		args = append(args, f.BuyerID)
+	}
+	if !f.IncludeCancelled {
+		where += " AND status <> '" + models.AppointmentCancelled + "'"
+	}
+	return where, args
+}
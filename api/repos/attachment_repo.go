@@ -0,0 +1,28 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// AttachmentRepo stores attachment metadata; file content lives in storage.
+type AttachmentRepo interface {
+	Create(ctx context.Context, a *models.Attachment) (int64, error) // a.TenantID set
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.Attachment, error)
+	ListByEntity(ctx context.Context, tenantID, entityType string, entityID int64) ([]*models.Attachment, error)
+	Delete(ctx context.Context, tenantID, currentUser string, id int64) error
+}
+
+// NewDBAttachmentRepo selects the concrete implementation based on driver.
+func NewDBAttachmentRepo(db *sql.DB, driver string) AttachmentRepo {
+	switch driver {
+	case "postgres":
+		return &postgresAttachmentRepo{db: db}
+	case "sqlite":
+		return &sqliteAttachmentRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,145 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// BuyerMergeRepo folds a duplicate buyer into a surviving one and keeps the
+// merge history.
+type BuyerMergeRepo interface {
+	// Merge re-points every BuyerRef plus the buyer's own KYC records from
+	// m.DuplicateID to m.SurvivorID, soft-deletes the duplicate and stores m.
+	// m.Reassigned is filled with per-table row counts.
+	Merge(ctx context.Context, m *models.BuyerMerge) (int64, error)
+	ListMerges(ctx context.Context, tenantID string) ([]*models.BuyerMerge, error)
+}
+
+// BuyerRef names a column in a domain database that holds a buyer ID.
+type BuyerRef struct {
+	DB     *sql.DB
+	Driver string
+	Table  string
+	Column string
+	Where  string // optional extra condition, e.g. "entity_type = 'buyer'"
+	// UniqueWith lists the columns that are unique together with Column.
+	// The duplicate's rows that would clash with one of the survivor's
+	// are left behind, so the survivor's win.
+	UniqueWith []string
+}
+
+// NewDBBuyerMergeRepo selects the concrete implementation based on the
+// buyers database driver. refs lists the buyer references held in other
+// tables; they may live in any domain database.
+func NewDBBuyerMergeRepo(db *sql.DB, driver string, refs ...BuyerRef) BuyerMergeRepo {
+	switch driver {
+	case "postgres":
+		return &postgresBuyerMergeRepo{db: db, refs: refs}
+	case "sqlite":
+		return &sqliteBuyerMergeRepo{db: db, refs: refs}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+// mergeTxs holds one transaction per distinct database touched by a merge.
+// When every domain shares a database the merge is a single transaction;
+// otherwise each database commits on its own, references first and the
+// buyers database (duplicate removal and merge record) last, so a failure
+// part-way leaves the duplicate in place and the merge can simply be re-run.
+type mergeTxs struct {
+	order []*sql.DB
+	txs   map[*sql.DB]*sql.Tx
+}
+
+func (m *mergeTxs) get(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	if m.txs == nil {
+		m.txs = map[*sql.DB]*sql.Tx{}
+	}
+	if tx, ok := m.txs[db]; ok {
+		return tx, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.txs[db] = tx
+	m.order = append(m.order, db)
+	return tx, nil
+}
+
+func (m *mergeTxs) rollback() {
+	for _, tx := range m.txs {
+		tx.Rollback()
+	}
+}
+
+// commit commits every transaction, leaving last's until the end.
+func (m *mergeTxs) commit(last *sql.DB) error {
+	for _, db := range m.order {
+		if db == last {
+			continue
+		}
+		if err := m.txs[db].Commit(); err != nil {
+			return err
+		}
+		delete(m.txs, db)
+	}
+	if tx, ok := m.txs[last]; ok {
+		delete(m.txs, last)
+		return tx.Commit()
+	}
+	return nil
+}
+
+// reassignRefs re-points every ref from one buyer to another.
+func reassignRefs(ctx context.Context, txs *mergeTxs, refs []BuyerRef, m *models.BuyerMerge) error {
+	for _, ref := range refs {
+		tx, err := txs.get(ctx, ref.DB)
+		if err != nil {
+			return err
+		}
+		query := "UPDATE " + ref.Table + " SET " + ref.Column + " = ? WHERE tenant_id = ? AND " + ref.Column + " = ?"
+		args := []any{m.SurvivorID, m.TenantID, m.DuplicateID}
+		if ref.Where != "" {
+			query += " AND " + ref.Where
+		}
+		if len(ref.UniqueWith) > 0 {
+			query += " AND NOT EXISTS (SELECT 1 FROM " + ref.Table + " s WHERE s.tenant_id = " + ref.Table + ".tenant_id AND s." + ref.Column + " = ?"
+			for _, col := range ref.UniqueWith {
+				query += " AND s." + col + " = " + ref.Table + "." + col
+			}
+			query += ")"
+			args = append(args, m.SurvivorID)
+		}
+		res, err := tx.ExecContext(ctx, bindVars(ref.Driver, query), args...)
+		if err != nil {
+			return err
+		}
+		n, _ := res.RowsAffected()
+		m.Reassigned[ref.Table] += n
+	}
+	return nil
+}
+
+// bindVars rewrites ? placeholders as $1, $2, … for postgres.
+func bindVars(driver, query string) string {
+	if driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// mergeBuyers merges duplicate into survivor with refs and returns the
+// per-table counts of re-pointed rows.
+func mergeBuyers(t *testing.T, db *sql.DB, survivor, duplicate int64, refs ...BuyerRef) map[string]int64 {
+	t.Helper()
+	m := &models.BuyerMerge{
+		TenantID: testTenant, SurvivorID: survivor, DuplicateID: duplicate,
+		DuplicateSnapshot: []byte("{}"), MergedBy: "alice", MergedAt: time.Now().UTC(),
+	}
+	if _, err := NewDBBuyerMergeRepo(db, "sqlite", refs...).Merge(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	return m.Reassigned
+}
+
+func TestMergeReassignsActivities(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	survivor := createTestBuyer(t, db, "ada@example.com")
+	duplicate := createTestBuyer(t, db, "ada.lovelace@example.com")
+	activities := NewDBActivityRepo(db, "sqlite")
+	id, err := activities.Create(ctx, &models.Activity{
+		TenantID: testTenant, SubjectType: models.ActivitySubjectBuyer, SubjectID: duplicate,
+		Type: models.ActivityCall, OccurredAt: time.Now(), Author: "alice", CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mergeBuyers(t, db, survivor, duplicate,
+		BuyerRef{DB: db, Driver: "sqlite", Table: "activities", Column: "subject_id", Where: "subject_type = 'buyer'"})
+
+	if got["activities"] != 1 {
+		t.Errorf("reassigned %v, want 1 activity", got)
+	}
+	a, err := activities.GetByID(ctx, testTenant, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SubjectID != survivor {
+		t.Errorf("activity is on buyer %d, want the survivor %d", a.SubjectID, survivor)
+	}
+}
+
+func TestMergeReassignsAppointments(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	survivor := createTestBuyer(t, db, "ada@example.com")
+	duplicate := createTestBuyer(t, db, "ada.lovelace@example.com")
+	appointments := NewDBAppointmentRepo(db, "sqlite")
+	start := time.Now().Add(24 * time.Hour)
+	id, err := appointments.Create(ctx, &models.Appointment{
+		TenantID: testTenant, PropertyID: 1, BuyerID: duplicate, AgentUserID: 1, StartsAt: start, EndsAt: start.Add(time.Hour),
+		Status: models.AppointmentScheduled, CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mergeBuyers(t, db, survivor, duplicate,
+		BuyerRef{DB: db, Driver: "sqlite", Table: "appointments", Column: "buyer_id"})
+
+	if got["appointments"] != 1 {
+		t.Errorf("reassigned %v, want 1 appointment", got)
+	}
+	a, err := appointments.GetByID(ctx, testTenant, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.BuyerID != survivor {
+		t.Errorf("appointment is with buyer %d, want the survivor %d", a.BuyerID, survivor)
+	}
+}
+
+// Preferences are unique per channel: the duplicate's move over unless the
+// survivor already has one for the channel.
+func TestMergeReassignsNotificationPreferences(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	survivor := createTestBuyer(t, db, "ada@example.com")
+	duplicate := createTestBuyer(t, db, "ada.lovelace@example.com")
+	prefs := NewDBNotificationRepo(db, "sqlite")
+	for _, p := range []models.NotificationPreference{
+		{SubjectID: survivor, Channel: "email", Address: "survivor@example.com"},
+		{SubjectID: duplicate, Channel: "email", Address: "duplicate@example.com"},
+		{SubjectID: duplicate, Channel: "sms", Address: "+15550100", OptedOut: true},
+	} {
+		p.TenantID = testTenant
+		p.SubjectType = "buyer"
+		p.CreatedBy, p.ModifiedBy = "alice", "alice"
+		if err := prefs.SavePreference(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := mergeBuyers(t, db, survivor, duplicate, BuyerRef{
+		DB: db, Driver: "sqlite", Table: "notification_preferences", Column: "subject_id",
+		Where: "subject_type = 'buyer'", UniqueWith: []string{"subject_type", "channel"},
+	})
+
+	if got["notification_preferences"] != 1 {
+		t.Errorf("reassigned %v, want 1 notification preference", got)
+	}
+	list, err := prefs.ListPreferences(ctx, testTenant, "buyer", survivor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byChannel := map[string]*models.NotificationPreference{}
+	for _, p := range list {
+		byChannel[p.Channel] = p
+	}
+	if p := byChannel["email"]; p == nil || p.Address != "survivor@example.com" {
+		t.Errorf("email preference = %+v, want the survivor's", p)
+	}
+	if p := byChannel["sms"]; p == nil || !p.OptedOut {
+		t.Errorf("sms preference = %+v, want the duplicate's opt-out", p)
+	}
+}
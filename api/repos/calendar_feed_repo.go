@@ -0,0 +1,31 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// CalendarFeedRepo stores per-user calendar feed tokens.
+type CalendarFeedRepo interface {
+	Create(ctx context.Context, t *models.CalendarFeedToken) (int64, error)
+	// GetByHash returns the unrevoked token with the given hash in any tenant.
+	// Returns ErrNotFound if there is none.
+	GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeedToken, error)
+	// RevokeForUser revokes every active token of (tenantID, userID).
+	RevokeForUser(ctx context.Context, tenantID string, userID int64) error
+	Touch(ctx context.Context, id int64) error
+}
+
+// NewDBCalendarFeedRepo selects the concrete implementation based on driver.
+func NewDBCalendarFeedRepo(db *sql.DB, driver string) CalendarFeedRepo {
+	switch driver {
+	case "postgres":
+		return &postgresCalendarFeedRepo{db: db}
+	case "sqlite":
+		return &sqliteCalendarFeedRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -15,3 +15,52 @@ var ErrSignedString = errors.New("signed string error")
 var ErrParseWithClaims = errors.New("sparse with claims error")
 var ErrInvalidTokenClaims = errors.New("invalid token claims")
 var ErrCreateInstallmentPlanIDReq = errors.New("plan_id is required")
+var ErrUnsupportedImportFormat = errors.New("unsupported import format; expected csv or xlsx")
+var ErrImportRowsInvalid = errors.New("import contains invalid rows")
+var ErrAttachmentTooLarge = errors.New("attachment exceeds the maximum upload size")
+var ErrInvalidOfferTransition = errors.New("offer cannot move to that status or milestone from its current state")
+var ErrOfferExpired = errors.New("offer has expired")
+var ErrPropertyUnavailable = errors.New("property is not available for offers")
+var ErrOfferExpiresInPast = errors.New("offer expiry must be in the future")
+var ErrSaleNotInstallment = errors.New("sale is not an installment sale")
+var ErrSaleHasActivePlan = errors.New("sale already has an active installment plan")
+var ErrInvalidPlanTerms = errors.New("plan needs 1 to 600 installments, a first installment date and a down payment below the total price")
+var ErrKYCNotApproved = errors.New("buyer KYC has not been approved")
+var ErrKYCExpired = errors.New("buyer KYC identity document has expired")
+var ErrPaymentChanged = errors.New("payment was changed by another request; reload it and try again")
+var ErrKYCNotPending = errors.New("buyer KYC is not awaiting verification")
+var ErrAppointmentConflict = errors.New("appointment overlaps an existing booking")
+var ErrOutsideWorkingHours = errors.New("appointment is outside working hours")
+var ErrCommissionAlreadyApproved = errors.New("commission has already been approved")
+var ErrLastAdminPermission = errors.New("change would remove your own last role-management permission")
+var ErrRoleNameTaken = errors.New("a role with that name already exists")
+var ErrUnknownPermission = errors.New("unknown permission")
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+var ErrRefreshTokenReused = errors.New("refresh token was already used; the session has been revoked")
+var ErrTokenRevoked = errors.New("token has been revoked")
+var ErrMFAInvalidCode = errors.New("invalid or already used authentication code")
+var ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+var ErrMFANotEnrolled = errors.New("multi-factor authentication is not set up")
+var ErrMFARequired = errors.New("multi-factor authentication is required for this user by tenant policy")
+var ErrInvalidMFAToken = errors.New("invalid or expired MFA challenge token")
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+var ErrPasswordReused = errors.New("password was used recently; choose a different one")
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+var ErrAccountLocked = errors.New("too many failed logins; try again later")
+var ErrTenantExists = errors.New("a tenant with that ID already exists")
+var ErrTenantSuspended = errors.New("tenant is suspended")
+var ErrInvalidTenantID = errors.New("tenant ID must be 2-63 lowercase letters, digits or dashes, starting with a letter or digit")
+var ErrSuspendOwnTenant = errors.New("you cannot suspend your own tenant")
+var ErrNoLicense = errors.New("no license is installed for this tenant")
+var ErrLicenseExpired = errors.New("license has expired")
+var ErrLicenseInvalid = errors.New("license file is invalid or not signed by the vendor")
+var ErrLicenseTenantMismatch = errors.New("license was issued for a different tenant")
+var ErrModuleNotLicensed = errors.New("this module is not included in the tenant's license")
+var ErrSeatLimit = errors.New("the license's seat limit has been reached")
+var ErrInvalidAPIKey = errors.New("invalid or expired API key")
+var ErrAPIKeyIPNotAllowed = errors.New("API key is not allowed from this address")
+var ErrInvalidAPIKeyRequest = errors.New("an API key needs a name and at least one permission, and allowed IPs must be addresses or CIDR ranges")
+var ErrPermissionNotHeld = errors.New("you cannot grant a permission you do not hold")
+var ErrSSONotConfigured = errors.New("single sign-on is not set up for this tenant")
+var ErrInvalidSSOLogin = errors.New("single sign-on login failed or expired; start again")
+var ErrInvalidOIDCConfig = errors.New("an identity provider needs an issuer URL and client ID")
@@ -0,0 +1,62 @@
+package repos
+
+import (
+	"math"
+	"sort"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+const earthRadiusKm = 6371.0088
+
+// geoBox is a latitude/longitude rectangle enclosing a search circle. It lets
+// the SQL layer discard most rows with plain range comparisons (which can use
+// idx_properties_geo) before the exact haversine distance is computed.
+type geoBox struct {
+	minLat, maxLat float64
+	minLng, maxLng float64
+	allLng         bool // circle touches a pole or crosses the antimeridian
+}
+
+func boundingBox(lat, lng, radiusKm float64) geoBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	b := geoBox{minLat: lat - dLat, maxLat: lat + dLat}
+	if b.minLat <= -90 || b.maxLat >= 90 {
+		b.minLat, b.maxLat = math.Max(b.minLat, -90), math.Min(b.maxLat, 90)
+		b.allLng = true
+		return b
+	}
+	dLng := dLat / math.Cos(lat*math.Pi/180)
+	b.minLng, b.maxLng = lng-dLng, lng+dLng
+	if b.minLng < -180 || b.maxLng > 180 {
+		b.allLng = true
+	}
+	return b
+}
+
+// haversineKm is the great-circle distance between two WGS84 points.
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// filterByRadius keeps the candidates that really are inside the circle and
+// orders them nearest first.
+func filterByRadius(candidates []*models.Property, lat, lng, radiusKm float64) []models.PropertySearchResult {
+	out := make([]models.PropertySearchResult, 0, len(candidates))
+	for _, p := range candidates {
+		if p.Latitude == nil || p.Longitude == nil {
+			continue
+		}
+		d := haversineKm(lat, lng, *p.Latitude, *p.Longitude)
+		if d <= radiusKm {
+			out = append(out, models.PropertySearchResult{Property: *p, DistanceKm: d})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DistanceKm < out[j].DistanceKm })
+	return out
+}
@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
@@ -15,6 +16,13 @@ type InstallmentRepo interface {
 	ListByPlan(ctx context.Context, tenantID string, planID int64) ([]*models.Installment, error)
 	Update(ctx context.Context, inst *models.Installment) error // inst.TenantID and inst.ID must be set
 	Delete(ctx context.Context, tenantID string, id int64) error
+	// ListUnpaidDueBefore returns unpaid installments of every tenant due
+	// before the given time, for background jobs such as reminders.
+	ListUnpaidDueBefore(ctx context.Context, before time.Time) ([]*models.Installment, error)
+	// ApplyPayment adds delta to the installment's paid amount and settles
+	// its status, unless consumer has already processed eventID. It
+	// reports whether the change was applied.
+	ApplyPayment(ctx context.Context, consumer, eventID, tenantID string, installmentID int64, delta float64, paidDate time.Time, modifiedBy string) (bool, error)
 }
 
 // NewDBInstallmentRepo selects the concrete implementation based on driver.
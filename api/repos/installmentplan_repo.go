@@ -13,8 +13,13 @@ type InstallmentPlanRepo interface {
 	GetByID(ctx context.Context, tenantID string, id int64) (*models.InstallmentPlan, error)
 	ListAll(ctx context.Context, tenantID string) ([]*models.InstallmentPlan, error)
 	ListByPlan(ctx context.Context, tenantID string, planID int64) ([]*models.InstallmentPlan, error)
+	// ListByBuyer returns the live plans of buyerID.
+	ListByBuyer(ctx context.Context, tenantID string, buyerID int64) ([]*models.InstallmentPlan, error)
 	Update(ctx context.Context, p *models.InstallmentPlan) error // p.TenantID and p.ID must be set
 	Delete(ctx context.Context, tenantID string, id int64) error
+	// GetActiveBySale returns the live (non-deleted) plan created from saleID,
+	// or ErrNotFound.
+	GetActiveBySale(ctx context.Context, tenantID string, saleID int64) (*models.InstallmentPlan, error)
 	SummarizeByPlan(ctx context.Context, tenantID string) ([]models.PlanSummary, error)
 }
 
@@ -0,0 +1,43 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+func TestPlanRepoOneLivePlanPerSale(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewDBInstallmentPlanRepo(db, "sqlite")
+	create := func(saleID int64) (int64, error) {
+		return repo.Create(ctx, &models.InstallmentPlan{
+			TenantID: testTenant, PropertyID: 1, BuyerID: 1, SaleID: saleID, TotalPrice: 1000,
+			NumInstallments: 2, Frequency: "Monthly", FirstInstallment: time.Now(),
+			CreatedBy: "alice", ModifiedBy: "alice",
+		})
+	}
+
+	first, err := create(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create(5); !errors.Is(err, ErrSaleHasActivePlan) {
+		t.Fatalf("second plan for the sale: err = %v, want ErrSaleHasActivePlan", err)
+	}
+	if err := repo.Delete(ctx, testTenant, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create(5); err != nil {
+		t.Fatalf("plan after the first was deleted: %v", err)
+	}
+	// Plans not made from a sale are not constrained.
+	for i := 0; i < 2; i++ {
+		if _, err := create(0); err != nil {
+			t.Fatalf("plan without a sale: %v", err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// KYCRepo stores buyer KYC records and the overrides granted against them.
+type KYCRepo interface {
+	Create(ctx context.Context, k *models.BuyerKYC) (int64, error) // k.TenantID set
+	GetByBuyer(ctx context.Context, tenantID string, buyerID int64) (*models.BuyerKYC, error)
+	Update(ctx context.Context, k *models.BuyerKYC) error // using k.TenantID, k.ID
+	CreateOverride(ctx context.Context, o *models.KYCOverride) (int64, error)
+	ListOverrides(ctx context.Context, tenantID string, buyerID int64) ([]*models.KYCOverride, error)
+	// FindUsableOverride returns the oldest unused override for buyerID and
+	// action that hasn't expired at now, or ErrNotFound.
+	FindUsableOverride(ctx context.Context, tenantID string, buyerID int64, action string, now time.Time) (*models.KYCOverride, error)
+	// UseOverride marks an override consumed. It returns ErrNotFound if the
+	// override was already used, so each override is spent at most once.
+	UseOverride(ctx context.Context, tenantID string, id int64, usedBy string, usedAt time.Time) error
+}
+
+// NewDBKYCRepo selects the concrete implementation based on driver.
+func NewDBKYCRepo(db *sql.DB, driver string) KYCRepo {
+	switch driver {
+	case "postgres":
+		return &postgresKYCRepo{db: db}
+	case "sqlite":
+		return &sqliteKYCRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,28 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// LicenseRepo stores the license installed for each tenant.
+type LicenseRepo interface {
+	// Get returns ErrNotFound if the tenant has no license installed.
+	Get(ctx context.Context, tenantID string) (*models.TenantLicense, error)
+	// Save installs l, replacing any earlier license for the tenant.
+	Save(ctx context.Context, l *models.TenantLicense) error
+}
+
+// NewDBLicenseRepo selects the concrete implementation based on driver.
+func NewDBLicenseRepo(db *sql.DB, driver string) LicenseRepo {
+	switch driver {
+	case "postgres":
+		return &postgresLicenseRepo{db: db}
+	case "sqlite":
+		return &sqliteLicenseRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -14,6 +14,9 @@ type LocationPricingRepo interface {
 	ListAll(ctx context.Context, tenantID string) ([]*models.LocationPricing, error)
 	Update(ctx context.Context, lp *models.LocationPricing) error // use lp.TenantID
 	Delete(ctx context.Context, tenantID string, id int64) error
+	// ImportBatch inserts and updates the given rows in a single transaction,
+	// joining the one ctx carries; either every row is written or none are.
+	ImportBatch(ctx context.Context, inserts, updates []*models.LocationPricing) error
 }
 
 // NewDBLocationRepo selects the concrete implementation based on driver.
@@ -0,0 +1,59 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// MFARepo stores TOTP enrolments, hashed recovery codes and each tenant's
+// MFA policy.
+type MFARepo interface {
+	// GetTOTP returns the user's enrolment, confirmed or pending, or
+	// ErrNotFound.
+	GetTOTP(ctx context.Context, tenantID string, userID int64) (*models.MFASecret, error)
+	// SaveTOTP creates or replaces the user's enrolment.
+	SaveTOTP(ctx context.Context, m *models.MFASecret) error
+	// UseTOTPCounter records counter as the last accepted time step if it
+	// is later than the one stored, reporting whether it was; false means
+	// the code was already used.
+	UseTOTPCounter(ctx context.Context, tenantID string, userID, counter int64) (bool, error)
+	// DeleteTOTP removes the user's enrolment and recovery codes.
+	DeleteTOTP(ctx context.Context, tenantID string, userID int64) error
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores
+	// hashes in their place.
+	ReplaceRecoveryCodes(ctx context.Context, tenantID string, userID int64, hashes []string) error
+	// UseRecoveryCode marks the user's unused code with the given hash as
+	// used, reporting whether there was one.
+	UseRecoveryCode(ctx context.Context, tenantID string, userID int64, hash string, at time.Time) (bool, error)
+	// GetPolicy returns the tenant's policy, empty if none was saved.
+	GetPolicy(ctx context.Context, tenantID string) (*models.MFAPolicy, error)
+	SavePolicy(ctx context.Context, p *models.MFAPolicy) error
+}
+
+// NewDBMFARepo selects the concrete implementation based on driver.
+func NewDBMFARepo(db *sql.DB, driver string) MFARepo {
+	switch driver {
+	case "postgres":
+		return &postgresMFARepo{db: db}
+	case "sqlite":
+		return &sqliteMFARepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+// splitList parses a comma-joined list column such as
+// required_permissions.
+func splitList(s string) []string {
+	out := []string{}
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
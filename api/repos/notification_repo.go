@@ -0,0 +1,32 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// NotificationRepo stores channel preferences and the delivery attempt log.
+type NotificationRepo interface {
+	ListPreferences(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.NotificationPreference, error)
+	// SavePreference inserts or replaces the preference for
+	// (p.SubjectType, p.SubjectID, p.Channel).
+	SavePreference(ctx context.Context, p *models.NotificationPreference) error
+
+	LogAttempt(ctx context.Context, a *models.NotificationAttempt) (int64, error)
+	// ListAttempts returns the newest attempts first, at most limit rows.
+	ListAttempts(ctx context.Context, tenantID, subjectType string, subjectID int64, limit int) ([]*models.NotificationAttempt, error)
+}
+
+// NewDBNotificationRepo selects the concrete implementation based on driver.
+func NewDBNotificationRepo(db *sql.DB, driver string) NotificationRepo {
+	switch driver {
+	case "postgres":
+		return &postgresNotificationRepo{db: db}
+	case "sqlite":
+		return &sqliteNotificationRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,34 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// OfferRepo stores offers and their conveyancing milestones.
+type OfferRepo interface {
+	Create(ctx context.Context, o *models.Offer) (int64, error) // o.TenantID set
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.Offer, error)
+	// ListAll returns the tenant's offers; propertyID 0 means every property.
+	ListAll(ctx context.Context, tenantID string, propertyID int64) ([]*models.Offer, error)
+	Update(ctx context.Context, o *models.Offer) error // using o.TenantID, o.ID
+	// UpdateWithEvents saves o and queues evs in the outbox in one
+	// transaction.
+	UpdateWithEvents(ctx context.Context, o *models.Offer, evs ...*models.OutboxEvent) error
+	AddMilestone(ctx context.Context, m *models.OfferMilestone) (int64, error)
+	ListMilestones(ctx context.Context, tenantID string, offerID int64) ([]models.OfferMilestone, error)
+}
+
+// NewDBOfferRepo selects the concrete implementation based on driver.
+func NewDBOfferRepo(db *sql.DB, driver string) OfferRepo {
+	switch driver {
+	case "postgres":
+		return &postgresOfferRepo{db: db}
+	case "sqlite":
+		return &sqliteOfferRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,45 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// Accepting two offers for one property at once cannot both succeed.
+func TestOfferRepoOneAcceptedPerProperty(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewDBOfferRepo(db, "sqlite")
+	var offers []*models.Offer
+	for i := 0; i < 2; i++ {
+		o := &models.Offer{
+			TenantID: testTenant, PropertyID: 7, BuyerID: int64(i + 1), Amount: 100000, SaleType: "cash",
+			Status: models.OfferPending, CreatedBy: "alice", ModifiedBy: "alice",
+		}
+		id, err := repo.Create(ctx, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.ID = id
+		offers = append(offers, o)
+	}
+
+	offers[0].Status = models.OfferAccepted
+	if err := repo.Update(ctx, offers[0]); err != nil {
+		t.Fatal(err)
+	}
+	offers[1].Status = models.OfferAccepted
+	if err := repo.UpdateWithEvents(ctx, offers[1]); !errors.Is(err, ErrPropertyUnavailable) {
+		t.Fatalf("second acceptance: err = %v, want ErrPropertyUnavailable", err)
+	}
+	offers[0].Status = models.OfferWithdrawn
+	if err := repo.Update(ctx, offers[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateWithEvents(ctx, offers[1]); err != nil {
+		t.Fatalf("acceptance after the first was withdrawn: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// OIDCRepo stores tenants' identity provider settings, logins in
+// progress and the provider identities linked to users.
+type OIDCRepo interface {
+	// GetConfig returns ErrNotFound if the tenant has no provider.
+	GetConfig(ctx context.Context, tenantID string) (*models.OIDCConfig, error)
+	SaveConfig(ctx context.Context, c *models.OIDCConfig) error
+	// DeleteConfig returns ErrNotFound if the tenant has no provider.
+	DeleteConfig(ctx context.Context, tenantID string) error
+
+	CreateState(ctx context.Context, s *models.OIDCLoginState) error
+	// TakeState returns and deletes a login state, so each can be used
+	// once; ErrNotFound if it does not exist.
+	TakeState(ctx context.Context, state string) (*models.OIDCLoginState, error)
+	// DeleteExpiredStates removes logins that were never completed.
+	DeleteExpiredStates(ctx context.Context, now time.Time) error
+
+	// GetIdentity returns ErrNotFound if the subject is not linked.
+	GetIdentity(ctx context.Context, tenantID, issuer, subject string) (*models.OIDCIdentity, error)
+	// LinkIdentity is a no-op if the subject is already linked.
+	LinkIdentity(ctx context.Context, i *models.OIDCIdentity) error
+}
+
+// NewDBOIDCRepo selects the concrete implementation based on driver.
+func NewDBOIDCRepo(db *sql.DB, driver string) OIDCRepo {
+	switch driver {
+	case "postgres":
+		return &postgresOIDCRepo{db: db}
+	case "sqlite":
+		return &sqliteOIDCRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+func scanOIDCConfig(row interface{ Scan(...any) error }) (*models.OIDCConfig, error) {
+	var c models.OIDCConfig
+	var scopes, mappings string
+	if err := row.Scan(
+		&c.TenantID,
+		&c.Enabled,
+		&c.Issuer,
+		&c.ClientID,
+		&c.ClientSecret,
+		&scopes,
+		&c.UsernameClaim,
+		&c.TenantClaim,
+		&c.TenantClaimValue,
+		&c.RoleClaim,
+		&mappings,
+		&c.DefaultRole,
+		&c.LinkVerifiedEmail,
+		&c.ModifiedBy,
+		&c.LastModified,
+	); err != nil {
+		return nil, err
+	}
+	c.Scopes = splitList(scopes)
+	c.RoleMappings = map[string]string{}
+	if err := json.Unmarshal([]byte(mappings), &c.RoleMappings); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
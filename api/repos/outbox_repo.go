@@ -0,0 +1,77 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// OutboxRepo reads and updates the outbox of one domain database. Events
+// are written by the owning repo inside the transaction of the change that
+// raised them (see PaymentRepo.CreateWithEvents); this repo is what the
+// dispatcher drains.
+type OutboxRepo interface {
+	// ListDue returns pending events of every tenant whose next attempt is
+	// due, oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error)
+	// List returns the tenant's events, newest first, filtered by status
+	// when non-empty.
+	List(ctx context.Context, tenantID, status string, limit int) ([]*models.OutboxEvent, error)
+	Get(ctx context.Context, tenantID, id string) (*models.OutboxEvent, error)
+	// Update saves status, attempts, next_attempt_at, last_error and
+	// dispatched_at.
+	Update(ctx context.Context, ev *models.OutboxEvent) error
+}
+
+// NewDBOutboxRepo selects the concrete implementation based on driver.
+func NewDBOutboxRepo(db *sql.DB, driver string) OutboxRepo {
+	switch driver {
+	case "postgres":
+		return &postgresOutboxRepo{db: db}
+	case "sqlite":
+		return &sqliteOutboxRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+const outboxColumns = `
+	id, tenant_id, topic, aggregate_type, aggregate_id, payload, status, attempts, next_attempt_at,
+	last_error, created_at, dispatched_at`
+
+func scanOutboxEvents(rows *sql.Rows) ([]*models.OutboxEvent, error) {
+	var out []*models.OutboxEvent
+	for rows.Next() {
+		ev, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, rows.Err()
+}
+
+func scanOutboxEvent(row interface{ Scan(...any) error }) (*models.OutboxEvent, error) {
+	var ev models.OutboxEvent
+	var payload string
+	if err := row.Scan(
+		&ev.ID,
+		&ev.TenantID,
+		&ev.Topic,
+		&ev.AggregateType,
+		&ev.AggregateID,
+		&payload,
+		&ev.Status,
+		&ev.Attempts,
+		&ev.NextAttemptAt,
+		&ev.LastError,
+		&ev.CreatedAt,
+		&ev.DispatchedAt,
+	); err != nil {
+		return nil, err
+	}
+	ev.Payload = []byte(payload)
+	return &ev, nil
+}
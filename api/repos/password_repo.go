@@ -0,0 +1,51 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// PasswordRepo stores password reset tokens, password history and failed
+// login counters.
+type PasswordRepo interface {
+	CreateResetToken(ctx context.Context, t *models.PasswordResetToken) (int64, error)
+	// GetResetToken returns the token with the given hash in any tenant,
+	// or ErrNotFound.
+	GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// UseResetToken marks the token used if it still is unused, reporting
+	// whether it was.
+	UseResetToken(ctx context.Context, id int64, at time.Time) (bool, error)
+	// ExpireResetTokens marks every unused token of the user used.
+	ExpireResetTokens(ctx context.Context, tenantID string, userID int64, at time.Time) error
+
+	AddHistory(ctx context.Context, tenantID string, userID int64, passwordHash string, at time.Time) error
+	// ListHistory returns the user's last limit password hashes, newest
+	// first.
+	ListHistory(ctx context.Context, tenantID string, userID int64, limit int) ([]string, error)
+
+	// GetLoginFailure returns the failure counter for username from ip,
+	// or ErrNotFound.
+	GetLoginFailure(ctx context.Context, tenantID, username, ip string) (*models.LoginFailure, error)
+	// SaveLoginFailure creates or replaces the counter.
+	SaveLoginFailure(ctx context.Context, f *models.LoginFailure) error
+	// ClearLoginFailures drops the counters for username from ip, or from
+	// every address if ip is empty.
+	ClearLoginFailures(ctx context.Context, tenantID, username, ip string) error
+	// ListLockouts returns the counters locked at the given time.
+	ListLockouts(ctx context.Context, tenantID string, at time.Time) ([]*models.LoginFailure, error)
+}
+
+// NewDBPasswordRepo selects the concrete implementation based on driver.
+func NewDBPasswordRepo(db *sql.DB, driver string) PasswordRepo {
+	switch driver {
+	case "postgres":
+		return &postgresPasswordRepo{db: db}
+	case "sqlite":
+		return &sqlitePasswordRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
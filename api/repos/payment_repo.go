@@ -13,8 +13,19 @@ type PaymentRepo interface {
 	GetByID(ctx context.Context, tenantID string, id int64) (*models.Payment, error)
 	ListAll(ctx context.Context, tenantID string) ([]*models.Payment, error)
 	ListByInstallment(ctx context.Context, tenantID string, installmentID int64) ([]*models.Payment, error)
+	// ListByInstallments returns the live payments of any of installmentIDs
+	// in one query.
+	ListByInstallments(ctx context.Context, tenantID string, installmentIDs []int64) ([]*models.Payment, error)
 	Update(ctx context.Context, p *models.Payment) error
 	Delete(ctx context.Context, tenantID string, id int64) error
+
+	// CreateWithEvents and UpdateWithEvents commit the write together with
+	// outbox events for the installments database. UpdateWithEvents
+	// returns ErrPaymentChanged unless the stored payment is undeleted and
+	// still has prev's installment and amount, which evs were worked out
+	// from.
+	CreateWithEvents(ctx context.Context, p *models.Payment, evs ...*models.OutboxEvent) (int64, error)
+	UpdateWithEvents(ctx context.Context, p, prev *models.Payment, evs ...*models.OutboxEvent) error
 }
 
 // NewDBPaymentRepo selects the concrete implementation based on driver.
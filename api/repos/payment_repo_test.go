@@ -0,0 +1,42 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// Two requests that read the same payment cannot both apply their change.
+func TestPaymentUpdateWithEventsRefusesStaleRead(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewDBPaymentRepo(db, "sqlite")
+	id, err := repo.Create(ctx, &models.Payment{
+		TenantID: testTenant, InstallmentID: 1, AmountPaid: 100, PaymentDate: time.Now(), PaymentMethod: "card",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := repo.GetByID(ctx, testTenant, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := *read
+	deleted.Deleted = true
+	if err := repo.UpdateWithEvents(ctx, &deleted, read); err != nil {
+		t.Fatalf("first delete: %v", err)
+	}
+	if err := repo.UpdateWithEvents(ctx, &deleted, read); !errors.Is(err, ErrPaymentChanged) {
+		t.Fatalf("second delete: err = %v, want ErrPaymentChanged", err)
+	}
+	changed := *read
+	changed.AmountPaid = 150
+	if err := repo.UpdateWithEvents(ctx, &changed, read); !errors.Is(err, ErrPaymentChanged) {
+		t.Fatalf("update of a deleted payment: err = %v, want ErrPaymentChanged", err)
+	}
+}
@@ -0,0 +1,28 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// PostalCentroidRepo stores per-tenant ZIP/postcode centroids used for
+// offline geocoding.
+type PostalCentroidRepo interface {
+	GetByPostalCode(ctx context.Context, tenantID, postalCode string) (*models.PostalCentroid, error)
+	// UpsertBatch inserts or replaces the given centroids in one transaction.
+	UpsertBatch(ctx context.Context, cs []*models.PostalCentroid) error
+}
+
+// NewDBPostalCentroidRepo selects the concrete implementation based on driver.
+func NewDBPostalCentroidRepo(db *sql.DB, driver string) PostalCentroidRepo {
+	switch driver {
+	case "postgres":
+		return &postgresPostalCentroidRepo{db: db}
+	case "sqlite":
+		return &sqlitePostalCentroidRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
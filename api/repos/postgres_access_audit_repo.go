@@ -0,0 +1,37 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresAccessAuditRepo struct{ db *sql.DB }
+
+func (r *postgresAccessAuditRepo) Create(ctx context.Context, a *models.AccessAudit) (int64, error) {
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO access_audit (tenant_id, actor, action, target_type, target_id, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		a.TenantID, a.Actor, a.Action, a.TargetType, a.TargetID, a.Detail, a.CreatedAt,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresAccessAuditRepo) List(ctx context.Context, tenantID, targetType string, targetID int64, limit int) ([]*models.AccessAudit, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+accessAuditColumns+`
+		  FROM access_audit
+		 WHERE tenant_id = $1
+		   AND ($2::text = '' OR (target_type = $2 AND target_id = $3))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $4`,
+		tenantID, targetType, targetID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccessAudits(rows)
+}
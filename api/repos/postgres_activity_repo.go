@@ -0,0 +1,145 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresActivityRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresActivityRepo(db *sql.DB) ActivityRepo {
+	return &postgresActivityRepo{db: db}
+}
+
+const postgresActivityColumns = `
+	id, tenant_id, subject_type, subject_id, letting_id, type, occurred_at, author, body, due_date, completed_at,
+	created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *postgresActivityRepo) Create(ctx context.Context, a *models.Activity) (int64, error) {
+	if a.TenantID == "" || a.SubjectType == "" || a.SubjectID == 0 || a.Type == "" || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO activities (
+	  tenant_id, subject_type, subject_id, letting_id, type, occurred_at, author, body, due_date, completed_at,
+	  created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, FALSE)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		a.TenantID,
+		a.SubjectType,
+		a.SubjectID,
+		a.LettingID,
+		a.Type,
+		a.OccurredAt,
+		a.Author,
+		a.Body,
+		a.DueDate,
+		a.CompletedAt,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresActivityRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Activity, error) {
+	query := `SELECT` + postgresActivityColumns + `
+	FROM activities
+	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE;
+	`
+	a, err := scanPostgresActivity(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *postgresActivityRepo) ListBySubject(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.Activity, error) {
+	query := `SELECT` + postgresActivityColumns + `
+	FROM activities
+	WHERE tenant_id = $1 AND subject_type = $2 AND subject_id = $3 AND deleted = FALSE
+	ORDER BY occurred_at, id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Activity
+	for rows.Next() {
+		a, err := scanPostgresActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresActivityRepo) Update(ctx context.Context, a *models.Activity) error {
+	a.LastModified = time.Now().UTC()
+	query := `
+	UPDATE activities
+	SET type = $1, occurred_at = $2, body = $3, due_date = $4, completed_at = $5,
+	    modified_by = $6, last_modified = $7, deleted = $8
+	WHERE tenant_id = $9 AND id = $10 AND deleted = FALSE;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.Type,
+		a.OccurredAt,
+		a.Body,
+		a.DueDate,
+		a.CompletedAt,
+		a.ModifiedBy,
+		a.LastModified,
+		a.Deleted,
+		a.TenantID,
+		a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanPostgresActivity(row interface{ Scan(...any) error }) (*models.Activity, error) {
+	var a models.Activity
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.SubjectType,
+		&a.SubjectID,
+		&a.LettingID,
+		&a.Type,
+		&a.OccurredAt,
+		&a.Author,
+		&a.Body,
+		&a.DueDate,
+		&a.CompletedAt,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&a.Deleted,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
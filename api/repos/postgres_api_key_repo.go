@@ -0,0 +1,114 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresAPIKeyRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresAPIKeyRepo) Create(ctx context.Context, k *models.APIKey) (int64, error) {
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	INSERT INTO api_keys (tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, created_by, created_at, modified_by, last_modified)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING id;
+	`, k.TenantID, k.UserID, k.Name, k.Prefix, k.SecretHash, strings.Join(k.Permissions, ","), strings.Join(k.AllowedIPs, ","),
+		k.ExpiresAt, k.CreatedBy, k.CreatedAt, k.ModifiedBy, k.LastModified).Scan(&id)
+	return id, err
+}
+
+func (r *postgresAPIKeyRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.APIKey, error) {
+	k, err := scanAPIKey(conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE tenant_id = $1 AND id = $2;
+	`, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return k, err
+}
+
+func (r *postgresAPIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	k, err := scanAPIKey(conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE prefix = $1;
+	`, prefix))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return k, err
+}
+
+func (r *postgresAPIKeyRepo) ListAll(ctx context.Context, tenantID string) ([]*models.APIKey, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE tenant_id = $1
+	ORDER BY id;
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresAPIKeyRepo) Update(ctx context.Context, k *models.APIKey) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE api_keys
+	SET name = $1, permissions = $2, allowed_ips = $3, expires_at = $4, modified_by = $5, last_modified = $6
+	WHERE tenant_id = $7 AND id = $8;
+	`, k.Name, strings.Join(k.Permissions, ","), strings.Join(k.AllowedIPs, ","), k.ExpiresAt, k.ModifiedBy, k.LastModified,
+		k.TenantID, k.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresAPIKeyRepo) Delete(ctx context.Context, tenantID string, id int64) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM api_keys
+	WHERE tenant_id = $1 AND id = $2;
+	`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresAPIKeyRepo) Touch(ctx context.Context, id int64, at time.Time, ip string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE api_keys
+	SET last_used_at = $1, last_used_ip = $2
+	WHERE id = $3;
+	`, at, ip, id)
+	return err
+}
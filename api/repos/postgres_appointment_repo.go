@@ -0,0 +1,154 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresAppointmentRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresAppointmentRepo(db *sql.DB) AppointmentRepo {
+	return &postgresAppointmentRepo{db: db}
+}
+
+const postgresAppointmentColumns = `
+	id, tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id,
+	starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *postgresAppointmentRepo) Create(ctx context.Context, a *models.Appointment) (int64, error) {
+	if a.TenantID == "" || a.PropertyID == 0 || a.AgentUserID == 0 || a.StartsAt.IsZero() || a.EndsAt.IsZero() || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO appointments (
+	  tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id,
+	  starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, FALSE)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		a.TenantID,
+		a.PropertyID,
+		a.BuyerID,
+		a.ProspectName,
+		a.ProspectPhone,
+		a.ProspectEmail,
+		a.AgentUserID,
+		a.StartsAt,
+		a.EndsAt,
+		a.Status,
+		a.Notes,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresAppointmentRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Appointment, error) {
+	query := `SELECT` + postgresAppointmentColumns + `
+	FROM appointments
+	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE;
+	`
+	a, err := scanPostgresAppointment(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *postgresAppointmentRepo) List(ctx context.Context, tenantID string, f models.AppointmentFilter) ([]*models.Appointment, error) {
+	where, args := appointmentFilterSQL(f)
+	query := `SELECT` + postgresAppointmentColumns + `
+	FROM appointments
+	WHERE tenant_id = ? AND deleted = FALSE` + where + `
+	ORDER BY starts_at, id;
+	`
+	query = bindVars("postgres", query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Appointment
+	for rows.Next() {
+		a, err := scanPostgresAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresAppointmentRepo) Update(ctx context.Context, a *models.Appointment) error {
+	a.LastModified = time.Now().UTC()
+	query := `
+	UPDATE appointments
+	SET property_id = $1, buyer_id = $2, prospect_name = $3, prospect_phone = $4, prospect_email = $5, agent_user_id = $6,
+	    starts_at = $7, ends_at = $8, status = $9, notes = $10, modified_by = $11, last_modified = $12, deleted = $13
+	WHERE tenant_id = $14 AND id = $15 AND deleted = FALSE;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.PropertyID,
+		a.BuyerID,
+		a.ProspectName,
+		a.ProspectPhone,
+		a.ProspectEmail,
+		a.AgentUserID,
+		a.StartsAt,
+		a.EndsAt,
+		a.Status,
+		a.Notes,
+		a.ModifiedBy,
+		a.LastModified,
+		a.Deleted,
+		a.TenantID,
+		a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanPostgresAppointment(row interface{ Scan(...any) error }) (*models.Appointment, error) {
+	var a models.Appointment
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.PropertyID,
+		&a.BuyerID,
+		&a.ProspectName,
+		&a.ProspectPhone,
+		&a.ProspectEmail,
+		&a.AgentUserID,
+		&a.StartsAt,
+		&a.EndsAt,
+		&a.Status,
+		&a.Notes,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&a.Deleted,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,135 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresAttachmentRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresAttachmentRepo(db *sql.DB) AttachmentRepo {
+	return &postgresAttachmentRepo{db: db}
+}
+
+const postgresAttachmentColumns = `
+	id, tenant_id, entity_type, entity_id, category, file_name, mime_type, size_bytes, checksum,
+	storage_key, thumbnail_key, uploaded_by, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *postgresAttachmentRepo) Create(ctx context.Context, a *models.Attachment) (int64, error) {
+	if a.TenantID == "" || a.EntityType == "" || a.EntityID == 0 || a.StorageKey == "" || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO attachments (
+	  tenant_id, entity_type, entity_id, category, file_name, mime_type, size_bytes, checksum,
+	  storage_key, thumbnail_key, uploaded_by, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, FALSE)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		a.TenantID,
+		a.EntityType,
+		a.EntityID,
+		a.Category,
+		a.FileName,
+		a.MimeType,
+		a.SizeBytes,
+		a.Checksum,
+		a.StorageKey,
+		a.ThumbnailKey,
+		a.UploadedBy,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresAttachmentRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Attachment, error) {
+	query := `SELECT` + postgresAttachmentColumns + `
+	FROM attachments
+	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE;
+	`
+	a, err := scanPostgresAttachment(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *postgresAttachmentRepo) ListByEntity(ctx context.Context, tenantID, entityType string, entityID int64) ([]*models.Attachment, error) {
+	query := `SELECT` + postgresAttachmentColumns + `
+	FROM attachments
+	WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND deleted = FALSE
+	ORDER BY created_at;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Attachment
+	for rows.Next() {
+		a, err := scanPostgresAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresAttachmentRepo) Delete(ctx context.Context, tenantID, currentUser string, id int64) error {
+	query := `
+	UPDATE attachments
+	SET deleted = TRUE, modified_by = $1, last_modified = $2
+	WHERE tenant_id = $3 AND id = $4 AND deleted = FALSE;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, currentUser, time.Now().UTC(), tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanPostgresAttachment(row interface{ Scan(...any) error }) (*models.Attachment, error) {
+	var a models.Attachment
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.EntityType,
+		&a.EntityID,
+		&a.Category,
+		&a.FileName,
+		&a.MimeType,
+		&a.SizeBytes,
+		&a.Checksum,
+		&a.StorageKey,
+		&a.ThumbnailKey,
+		&a.UploadedBy,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&a.Deleted,
+	); err != nil {
+		return nil, err
+	}
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return &a, nil
+}
@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresBuyerMergeRepo struct {
+	db   *sql.DB
+	refs []BuyerRef
+}
+
+func NewPostgresBuyerMergeRepo(db *sql.DB, refs ...BuyerRef) BuyerMergeRepo {
+	return &postgresBuyerMergeRepo{db: db, refs: refs}
+}
+
+func (r *postgresBuyerMergeRepo) Merge(ctx context.Context, m *models.BuyerMerge) (int64, error) {
+	if m.TenantID == "" || m.SurvivorID == 0 || m.DuplicateID == 0 || m.MergedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	if m.Reassigned == nil {
+		m.Reassigned = map[string]int64{}
+	}
+	var txs mergeTxs
+	defer txs.rollback()
+
+	if err := reassignRefs(ctx, &txs, r.refs, m); err != nil {
+		return 0, err
+	}
+	tx, err := txs.get(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+
+	// A buyer has at most one KYC record; keep the survivor's if it has one.
+	res, err := tx.ExecContext(ctx, `
+	UPDATE buyer_kyc SET buyer_id = $1
+	WHERE tenant_id = $2 AND buyer_id = $3
+	  AND NOT EXISTS (SELECT 1 FROM buyer_kyc s WHERE s.tenant_id = $4 AND s.buyer_id = $5);
+	`, m.SurvivorID, m.TenantID, m.DuplicateID, m.TenantID, m.SurvivorID)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	m.Reassigned["buyer_kyc"] += n
+
+	res, err = tx.ExecContext(ctx, `
+	UPDATE kyc_overrides SET buyer_id = $1 WHERE tenant_id = $2 AND buyer_id = $3;
+	`, m.SurvivorID, m.TenantID, m.DuplicateID)
+	if err != nil {
+		return 0, err
+	}
+	n, _ = res.RowsAffected()
+	m.Reassigned["kyc_overrides"] += n
+
+	res, err = tx.ExecContext(ctx, `
+	UPDATE buyers SET deleted = TRUE, modified_by = $1, last_modified = $2
+	WHERE tenant_id = $3 AND id = $4 AND deleted = FALSE;
+	`, m.MergedBy, m.MergedAt, m.TenantID, m.DuplicateID)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrNotFound
+	}
+
+	reassigned, err := json.Marshal(m.Reassigned)
+	if err != nil {
+		return 0, err
+	}
+	row := tx.QueryRowContext(ctx, `
+	INSERT INTO buyer_merges (tenant_id, survivor_id, duplicate_id, duplicate_snapshot, reassigned, reason, merged_by, merged_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id;
+	`, m.TenantID, m.SurvivorID, m.DuplicateID, string(m.DuplicateSnapshot), string(reassigned), m.Reason, m.MergedBy, m.MergedAt)
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	if err := txs.commit(r.db); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *postgresBuyerMergeRepo) ListMerges(ctx context.Context, tenantID string) ([]*models.BuyerMerge, error) {
+	query := `
+	SELECT id, tenant_id, survivor_id, duplicate_id, duplicate_snapshot, reassigned, reason, merged_by, merged_at
+	FROM buyer_merges
+	WHERE tenant_id = $1
+	ORDER BY merged_at DESC, id DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.BuyerMerge
+	for rows.Next() {
+		m, err := scanBuyerMerge(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
@@ -25,17 +25,20 @@ func (r *postgresBuyerRepo) Create(ctx context.Context, b *models.Buyer) (int64,
 	now := time.Now().UTC()
 	b.CreatedAt = now
 	b.LastModified = now
+	b.OwnerID = recordOwner(ctx, b.OwnerID)
 	query := `
 	INSERT INTO buyers (
-	  tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		b.TenantID,
 		b.FirstName,
 		b.LastName,
 		b.Email,
 		b.Phone,
+		b.OwnerID,
+		b.AssignedTo,
 		b.CreatedBy,
 		b.CreatedAt,
 		b.ModifiedBy,
@@ -48,12 +51,13 @@ func (r *postgresBuyerRepo) Create(ctx context.Context, b *models.Buyer) (int64,
 }
 
 func (r *postgresBuyerRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Buyer, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM buyers
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 	var b models.Buyer
 	var deletedInt int
 	err := row.Scan(
@@ -63,6 +67,8 @@ func (r *postgresBuyerRepo) GetByID(ctx context.Context, tenantID string, id int
 		&b.LastName,
 		&b.Email,
 		&b.Phone,
+		&b.OwnerID,
+		&b.AssignedTo,
 		&b.CreatedBy,
 		&b.CreatedAt,
 		&b.ModifiedBy,
@@ -80,12 +86,13 @@ func (r *postgresBuyerRepo) GetByID(ctx context.Context, tenantID string, id int
 }
 
 func (r *postgresBuyerRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Buyer, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM buyers
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +108,8 @@ func (r *postgresBuyerRepo) ListAll(ctx context.Context, tenantID string) ([]*mo
 			&b.LastName,
 			&b.Email,
 			&b.Phone,
+			&b.OwnerID,
+			&b.AssignedTo,
 			&b.CreatedBy,
 			&b.CreatedAt,
 			&b.ModifiedBy,
@@ -130,11 +139,12 @@ func (r *postgresBuyerRepo) Update(ctx context.Context, b *models.Buyer) error {
 	SET first_name = ?, last_name = ?, email = ?, phone = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		b.FirstName,
 		b.LastName,
 		b.Email,
 		b.Phone,
+		b.AssignedTo,
 		b.ModifiedBy,
 		b.LastModified,
 		boolToInt(b.Deleted),
@@ -157,7 +167,7 @@ func (r *postgresBuyerRepo) Delete(ctx context.Context, tenantID string, id int6
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
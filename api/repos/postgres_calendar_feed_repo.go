@@ -0,0 +1,66 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresCalendarFeedRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresCalendarFeedRepo) Create(ctx context.Context, t *models.CalendarFeedToken) (int64, error) {
+	if t.TenantID == "" || t.UserID == 0 || t.TokenHash == "" {
+		return 0, errors.New("missing required fields or tenant info")
+	}
+	t.CreatedAt = time.Now().UTC()
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	INSERT INTO calendar_feed_tokens (tenant_id, user_id, token_hash, created_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id;
+	`, t.TenantID, t.UserID, t.TokenHash, t.CreatedAt).Scan(&id)
+	return id, err
+}
+
+func (r *postgresCalendarFeedRepo) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeedToken, error) {
+	var t models.CalendarFeedToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, token_hash, created_at, last_used_at, revoked_at
+	FROM calendar_feed_tokens
+	WHERE token_hash = $1 AND revoked_at IS NULL;
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.CreatedAt,
+		&t.LastUsedAt,
+		&t.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresCalendarFeedRepo) RevokeForUser(ctx context.Context, tenantID string, userID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE calendar_feed_tokens SET revoked_at = $1
+	WHERE tenant_id = $2 AND user_id = $3 AND revoked_at IS NULL;
+	`, time.Now().UTC(), tenantID, userID)
+	return err
+}
+
+func (r *postgresCalendarFeedRepo) Touch(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE calendar_feed_tokens SET last_used_at = $1 WHERE id = $2;`, time.Now().UTC(), id)
+	return err
+}
@@ -31,15 +31,16 @@ func (r *postgresCommissionRepo) Create(ctx context.Context, comm *models.Commis
 	now := time.Now().UTC()
 	comm.CreatedAt = now
 	comm.LastModified = now
+	comm.OwnerID = recordOwner(ctx, comm.OwnerID)
 
 	query := `
 	INSERT INTO commissions (
 	  tenant_id, transaction_type, transaction_id, beneficiary_id,
 	  commission_type, rate_or_amount, calculated_amount, memo,
-	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  owner_id, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		comm.TenantID,
 		comm.TransactionType,
 		comm.TransactionID,
@@ -48,6 +49,7 @@ func (r *postgresCommissionRepo) Create(ctx context.Context, comm *models.Commis
 		comm.RateOrAmount,
 		comm.CalculatedAmount,
 		comm.Memo,
+		comm.OwnerID,
 		comm.CreatedBy,
 		comm.CreatedAt,
 		comm.ModifiedBy,
@@ -60,14 +62,15 @@ func (r *postgresCommissionRepo) Create(ctx context.Context, comm *models.Commis
 }
 
 func (r *postgresCommissionRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Commission, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
 	SELECT id, tenant_id, transaction_type, transaction_id, beneficiary_id,
-	       commission_type, rate_or_amount, calculated_amount, memo,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       commission_type, rate_or_amount, calculated_amount, memo, approved_by, approved_at,
+	       owner_id, created_by, created_at, modified_by, last_modified, deleted
 	FROM commissions
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var comm models.Commission
 	var deletedInt int
@@ -81,6 +84,9 @@ func (r *postgresCommissionRepo) GetByID(ctx context.Context, tenantID string, i
 		&comm.RateOrAmount,
 		&comm.CalculatedAmount,
 		&comm.Memo,
+		&comm.ApprovedBy,
+		&comm.ApprovedAt,
+		&comm.OwnerID,
 		&comm.CreatedBy,
 		&comm.CreatedAt,
 		&comm.ModifiedBy,
@@ -98,14 +104,15 @@ func (r *postgresCommissionRepo) GetByID(ctx context.Context, tenantID string, i
 }
 
 func (r *postgresCommissionRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Commission, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
 	SELECT id, tenant_id, transaction_type, transaction_id, beneficiary_id,
-	       commission_type, rate_or_amount, calculated_amount, memo,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       commission_type, rate_or_amount, calculated_amount, memo, approved_by, approved_at,
+	       owner_id, created_by, created_at, modified_by, last_modified, deleted
 	FROM commissions
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +132,9 @@ func (r *postgresCommissionRepo) ListAll(ctx context.Context, tenantID string) (
 			&comm.RateOrAmount,
 			&comm.CalculatedAmount,
 			&comm.Memo,
+			&comm.ApprovedBy,
+			&comm.ApprovedAt,
+			&comm.OwnerID,
 			&comm.CreatedBy,
 			&comm.CreatedAt,
 			&comm.ModifiedBy,
@@ -154,10 +164,10 @@ func (r *postgresCommissionRepo) Update(ctx context.Context, comm *models.Commis
 	UPDATE commissions
 	SET transaction_type = ?, transaction_id = ?, beneficiary_id = ?,
 	    commission_type = ?, rate_or_amount = ?, calculated_amount = ?, memo = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    approved_by = ?, approved_at = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		comm.TransactionType,
 		comm.TransactionID,
 		comm.BeneficiaryID,
@@ -165,6 +175,8 @@ func (r *postgresCommissionRepo) Update(ctx context.Context, comm *models.Commis
 		comm.RateOrAmount,
 		comm.CalculatedAmount,
 		comm.Memo,
+		comm.ApprovedBy,
+		comm.ApprovedAt,
 		comm.ModifiedBy,
 		comm.LastModified,
 		boolToInt(comm.Deleted),
@@ -187,7 +199,7 @@ func (r *postgresCommissionRepo) Delete(ctx context.Context, tenantID string, id
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -204,7 +216,7 @@ func (r *postgresCommissionRepo) TotalCommissionByBeneficiary(ctx context.Contex
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY beneficiary_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +240,7 @@ func (r *postgresCommissionRepo) GetCommissionDetailsForBeneficiary(
 	beneficiaryID int64,
 ) ([]*models.Commission, error) {
 
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
         SELECT 
           id,
@@ -239,6 +252,9 @@ func (r *postgresCommissionRepo) GetCommissionDetailsForBeneficiary(
           rate_or_amount,
           calculated_amount,
           memo,
+          approved_by,
+          approved_at,
+          owner_id,
           created_by,
           created_at,
           modified_by,
@@ -247,9 +263,9 @@ func (r *postgresCommissionRepo) GetCommissionDetailsForBeneficiary(
         FROM commissions
         WHERE tenant_id = ? 
           AND beneficiary_id = ? 
-          AND deleted = 0;
+          AND deleted = 0` + scope + `;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID, beneficiaryID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, beneficiaryID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +284,9 @@ func (r *postgresCommissionRepo) GetCommissionDetailsForBeneficiary(
 			&c.RateOrAmount,
 			&c.CalculatedAmount,
 			&c.Memo,
+			&c.ApprovedBy,
+			&c.ApprovedAt,
+			&c.OwnerID,
 			&c.CreatedBy,
 			&c.CreatedAt,
 			&c.ModifiedBy,
@@ -32,7 +32,7 @@ func (r *postgresInstallmentRepo) Create(ctx context.Context, inst *models.Insta
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		inst.TenantID,
 		inst.PlanID,
 		inst.SequenceNumber,
@@ -60,7 +60,7 @@ func (r *postgresInstallmentRepo) GetByID(ctx context.Context, tenantID string,
 	FROM installments
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var inst models.Installment
 	var deletedInt int
@@ -98,7 +98,7 @@ func (r *postgresInstallmentRepo) ListAll(ctx context.Context, tenantID string)
 	FROM installments
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +140,7 @@ func (r *postgresInstallmentRepo) ListByPlan(ctx context.Context, tenantID strin
 	FROM installments
 	WHERE tenant_id = ? AND plan_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID, planID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, planID)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +192,7 @@ func (r *postgresInstallmentRepo) Update(ctx context.Context, inst *models.Insta
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		inst.PlanID,
 		inst.SequenceNumber,
 		inst.DueDate,
@@ -223,7 +223,7 @@ func (r *postgresInstallmentRepo) Delete(ctx context.Context, tenantID string, i
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -231,3 +231,84 @@ func (r *postgresInstallmentRepo) Delete(ctx context.Context, tenantID string, i
 	)
 	return err
 }
+
+func (r *postgresInstallmentRepo) ListUnpaidDueBefore(ctx context.Context, before time.Time) ([]*models.Installment, error) {
+	query := `
+	SELECT id, tenant_id, plan_id, sequence_number, due_date, amount_due, amount_paid, status, late_fee, paid_date,
+	       created_by, created_at, modified_by, last_modified, deleted
+	FROM installments
+	WHERE due_date < ? AND status <> ? AND amount_paid < amount_due AND deleted = 0
+	ORDER BY tenant_id, due_date;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, before, models.InstallmentPaid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.Installment
+	for rows.Next() {
+		var inst models.Installment
+		var deletedInt int
+		if err := rows.Scan(
+			&inst.ID,
+			&inst.TenantID,
+			&inst.PlanID,
+			&inst.SequenceNumber,
+			&inst.DueDate,
+			&inst.AmountDue,
+			&inst.AmountPaid,
+			&inst.Status,
+			&inst.LateFee,
+			&inst.PaidDate,
+			&inst.CreatedBy,
+			&inst.CreatedAt,
+			&inst.ModifiedBy,
+			&inst.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		inst.Deleted = deletedInt != 0
+		out = append(out, &inst)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresInstallmentRepo) ApplyPayment(ctx context.Context, consumer, eventID, tenantID string, installmentID int64, delta float64, paidDate time.Time, modifiedBy string) (bool, error) {
+	var applied bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		claimed, err := postgresClaimEvent(ctx, tx, consumer, eventID)
+		if err != nil || !claimed {
+			return err
+		}
+		query := `
+		UPDATE installments
+		SET amount_paid = amount_paid + ?,
+		    status = CASE WHEN amount_paid + ? >= amount_due THEN ?
+		                  WHEN status = ? THEN ? ELSE status END,
+		    paid_date = CASE WHEN amount_paid + ? >= amount_due AND status <> ? THEN ? ELSE paid_date END,
+		    modified_by = ?, last_modified = ?
+		WHERE tenant_id = ? AND id = ? AND deleted = 0;
+		`
+		res, err := tx.ExecContext(ctx, query,
+			delta,
+			delta, models.InstallmentPaid,
+			models.InstallmentPaid, models.InstallmentPending,
+			delta, models.InstallmentPaid, paidDate,
+			modifiedBy,
+			time.Now().UTC(),
+			tenantID,
+			installmentID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
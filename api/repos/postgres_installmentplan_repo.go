@@ -6,7 +6,7 @@ import (
 	"errors"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
 
@@ -25,14 +25,15 @@ func (r *postgresInstallmentPlanRepo) Create(ctx context.Context, p *models.Inst
 	now := time.Now().UTC()
 	p.CreatedAt = now
 	p.LastModified = now
+	p.OwnerID = recordOwner(ctx, p.OwnerID)
 
 	query := `
 	INSERT INTO installment_plans (
-	  tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	  owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		p.TenantID,
 		p.PropertyID,
 		p.BuyerID,
@@ -42,25 +43,29 @@ func (r *postgresInstallmentPlanRepo) Create(ctx context.Context, p *models.Inst
 		p.Frequency,
 		p.FirstInstallment,
 		p.InterestRate,
+		p.SaleID,
+		p.OwnerID,
+		p.AssignedTo,
 		p.CreatedBy,
 		p.CreatedAt,
 		p.ModifiedBy,
 		p.LastModified,
 	)
 	if err != nil {
-		return 0, err
+		return 0, postgresPlanSaleConflict(err)
 	}
 	return res.LastInsertId()
 }
 
 func (r *postgresInstallmentPlanRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var p models.InstallmentPlan
 	var deletedInt int
@@ -75,6 +80,9 @@ func (r *postgresInstallmentPlanRepo) GetByID(ctx context.Context, tenantID stri
 		&p.Frequency,
 		&p.FirstInstallment,
 		&p.InterestRate,
+		&p.SaleID,
+		&p.OwnerID,
+		&p.AssignedTo,
 		&p.CreatedBy,
 		&p.CreatedAt,
 		&p.ModifiedBy,
@@ -92,13 +100,14 @@ func (r *postgresInstallmentPlanRepo) GetByID(ctx context.Context, tenantID stri
 }
 
 func (r *postgresInstallmentPlanRepo) ListAll(ctx context.Context, tenantID string) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +128,55 @@ func (r *postgresInstallmentPlanRepo) ListAll(ctx context.Context, tenantID stri
 			&p.Frequency,
 			&p.FirstInstallment,
 			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		p.Deleted = deletedInt != 0
+		out = append(out, &p)
+	}
+	return out, nil
+}
+
+func (r *postgresInstallmentPlanRepo) ListByBuyer(ctx context.Context, tenantID string, buyerID int64) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
+	query := `
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	FROM installment_plans
+	WHERE tenant_id = ? AND buyer_id = ? AND deleted = 0` + scope + `;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, buyerID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.InstallmentPlan
+	for rows.Next() {
+		var p models.InstallmentPlan
+		var deletedInt int
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.PropertyID,
+			&p.BuyerID,
+			&p.TotalPrice,
+			&p.DownPayment,
+			&p.NumInstallments,
+			&p.Frequency,
+			&p.FirstInstallment,
+			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
 			&p.CreatedBy,
 			&p.CreatedAt,
 			&p.ModifiedBy,
@@ -134,13 +192,14 @@ func (r *postgresInstallmentPlanRepo) ListAll(ctx context.Context, tenantID stri
 }
 
 func (r *postgresInstallmentPlanRepo) ListByPlan(ctx context.Context, tenantID string, planID int64) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID, planID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, planID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +220,9 @@ func (r *postgresInstallmentPlanRepo) ListByPlan(ctx context.Context, tenantID s
 			&p.Frequency,
 			&p.FirstInstallment,
 			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
 			&p.CreatedBy,
 			&p.CreatedAt,
 			&p.ModifiedBy,
@@ -189,10 +251,10 @@ func (r *postgresInstallmentPlanRepo) Update(ctx context.Context, p *models.Inst
 	query := `
 	UPDATE installment_plans
 	SET property_id = ?, buyer_id = ?, total_price = ?, down_payment = ?, num_installments = ?, frequency = ?, first_installment = ?, interest_rate = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    sale_id = ?, assigned_to = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		p.PropertyID,
 		p.BuyerID,
 		p.TotalPrice,
@@ -201,13 +263,15 @@ func (r *postgresInstallmentPlanRepo) Update(ctx context.Context, p *models.Inst
 		p.Frequency,
 		p.FirstInstallment,
 		p.InterestRate,
+		p.SaleID,
+		p.AssignedTo,
 		p.ModifiedBy,
 		p.LastModified,
 		boolToInt(p.Deleted),
 		p.TenantID,
 		p.ID,
 	)
-	return err
+	return postgresPlanSaleConflict(err)
 }
 
 func (r *postgresInstallmentPlanRepo) Delete(ctx context.Context, tenantID string, id int64) error {
@@ -223,7 +287,7 @@ func (r *postgresInstallmentPlanRepo) Delete(ctx context.Context, tenantID strin
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -239,6 +303,25 @@ func boolToInt(b bool) int {
 	return 0
 }
 
+func (r *postgresInstallmentPlanRepo) GetActiveBySale(ctx context.Context, tenantID string, saleID int64) (*models.InstallmentPlan, error) {
+	query := `
+	SELECT id
+	FROM installment_plans
+	WHERE tenant_id = ? AND sale_id = ? AND deleted = 0
+	ORDER BY id DESC
+	LIMIT 1;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, saleID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, tenantID, id)
+}
+
 // SummarizeByPlan computes “amount_due − amount_paid” grouped by each plan.
 func (r *postgresInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenantID string) ([]models.PlanSummary, error) {
 	query := `
@@ -248,7 +331,7 @@ func (r *postgresInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenan
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY plan_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -264,3 +347,13 @@ func (r *postgresInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenan
 	}
 	return out, nil
 }
+
+// postgresPlanSaleConflict maps a second live plan for a sale, refused by
+// idx_installmentplans_active_sale, to ErrSaleHasActivePlan.
+func postgresPlanSaleConflict(err error) error {
+	var pe *pq.Error
+	if errors.As(err, &pe) && pe.Code == "23505" && pe.Constraint == "idx_installmentplans_active_sale" {
+		return ErrSaleHasActivePlan
+	}
+	return err
+}
@@ -39,7 +39,7 @@ func (r *postgresIntroductionsRepo) Create(ctx context.Context, intro *models.In
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		intro.TenantID,
 		intro.IntroducerID,
 		intro.IntroducedParty,
@@ -66,7 +66,7 @@ func (r *postgresIntroductionsRepo) GetByID(ctx context.Context, tenantID string
 	FROM introductions
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var intro models.Introductions
 	var deletedInt int
@@ -103,7 +103,7 @@ func (r *postgresIntroductionsRepo) ListAll(ctx context.Context, tenantID string
 	FROM introductions
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +154,7 @@ func (r *postgresIntroductionsRepo) Update(ctx context.Context, intro *models.In
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		intro.IntroducerID,
 		intro.IntroducedParty,
 		intro.PropertyID,
@@ -184,7 +184,7 @@ func (r *postgresIntroductionsRepo) Delete(ctx context.Context, tenantID string,
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -0,0 +1,200 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresKYCRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresKYCRepo(db *sql.DB) KYCRepo {
+	return &postgresKYCRepo{db: db}
+}
+
+func (r *postgresKYCRepo) Create(ctx context.Context, k *models.BuyerKYC) (int64, error) {
+	if k.TenantID == "" || k.BuyerID == 0 || k.IDDocumentType == "" || k.IDDocumentNumber == "" || k.CreatedBy == "" || k.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	k.CreatedAt = now
+	k.LastModified = now
+	query := `
+	INSERT INTO buyer_kyc (
+	  tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id,
+	  address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes,
+	  created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, FALSE)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		k.TenantID,
+		k.BuyerID,
+		k.IDDocumentType,
+		k.IDDocumentNumber,
+		k.IDDocumentExpiry,
+		k.IDDocumentAttachmentID,
+		k.AddressProofType,
+		k.AddressProofAttachmentID,
+		k.SourceOfFunds,
+		k.Status,
+		k.VerifiedBy,
+		k.VerifiedAt,
+		k.Notes,
+		k.CreatedBy,
+		k.CreatedAt,
+		k.ModifiedBy,
+		k.LastModified,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresKYCRepo) GetByBuyer(ctx context.Context, tenantID string, buyerID int64) (*models.BuyerKYC, error) {
+	query := `
+	SELECT id, tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id,
+	       address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes,
+	       created_by, created_at, modified_by, last_modified, deleted
+	FROM buyer_kyc
+	WHERE tenant_id = $1 AND buyer_id = $2 AND deleted = FALSE;
+	`
+	var k models.BuyerKYC
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, buyerID).Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.BuyerID,
+		&k.IDDocumentType,
+		&k.IDDocumentNumber,
+		&k.IDDocumentExpiry,
+		&k.IDDocumentAttachmentID,
+		&k.AddressProofType,
+		&k.AddressProofAttachmentID,
+		&k.SourceOfFunds,
+		&k.Status,
+		&k.VerifiedBy,
+		&k.VerifiedAt,
+		&k.Notes,
+		&k.CreatedBy,
+		&k.CreatedAt,
+		&k.ModifiedBy,
+		&k.LastModified,
+		&k.Deleted,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *postgresKYCRepo) Update(ctx context.Context, k *models.BuyerKYC) error {
+	k.LastModified = time.Now().UTC()
+	query := `
+	UPDATE buyer_kyc
+	SET id_document_type = $1, id_document_number = $2, id_document_expiry = $3, id_document_attachment_id = $4,
+	    address_proof_type = $5, address_proof_attachment_id = $6, source_of_funds = $7, status = $8,
+	    verified_by = $9, verified_at = $10, notes = $11, modified_by = $12, last_modified = $13, deleted = $14
+	WHERE tenant_id = $15 AND id = $16 AND deleted = FALSE;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		k.IDDocumentType,
+		k.IDDocumentNumber,
+		k.IDDocumentExpiry,
+		k.IDDocumentAttachmentID,
+		k.AddressProofType,
+		k.AddressProofAttachmentID,
+		k.SourceOfFunds,
+		k.Status,
+		k.VerifiedBy,
+		k.VerifiedAt,
+		k.Notes,
+		k.ModifiedBy,
+		k.LastModified,
+		k.Deleted,
+		k.TenantID,
+		k.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresKYCRepo) CreateOverride(ctx context.Context, o *models.KYCOverride) (int64, error) {
+	if o.TenantID == "" || o.BuyerID == 0 || o.Action == "" || o.Reason == "" || o.GrantedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	query := `
+	INSERT INTO kyc_overrides (tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, '', NULL)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, o.TenantID, o.BuyerID, o.Action, o.Reason, o.GrantedBy, o.GrantedAt, o.ExpiresAt).Scan(&id)
+	return id, err
+}
+
+const postgresKYCOverrideColumns = `id, tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at`
+
+func (r *postgresKYCRepo) ListOverrides(ctx context.Context, tenantID string, buyerID int64) ([]*models.KYCOverride, error) {
+	query := `SELECT ` + postgresKYCOverrideColumns + `
+	FROM kyc_overrides
+	WHERE tenant_id = $1 AND buyer_id = $2
+	ORDER BY granted_at DESC, id DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.KYCOverride
+	for rows.Next() {
+		o, err := scanKYCOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresKYCRepo) FindUsableOverride(ctx context.Context, tenantID string, buyerID int64, action string, now time.Time) (*models.KYCOverride, error) {
+	query := `SELECT ` + postgresKYCOverrideColumns + `
+	FROM kyc_overrides
+	WHERE tenant_id = $1 AND buyer_id = $2 AND action = $3 AND used_at IS NULL AND expires_at > $4
+	ORDER BY granted_at, id
+	LIMIT 1;
+	`
+	o, err := scanKYCOverride(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, buyerID, action, now))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return o, err
+}
+
+func (r *postgresKYCRepo) UseOverride(ctx context.Context, tenantID string, id int64, usedBy string, usedAt time.Time) error {
+	query := `
+	UPDATE kyc_overrides
+	SET used_by = $1, used_at = $2
+	WHERE tenant_id = $3 AND id = $4 AND used_at IS NULL;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, usedBy, usedAt, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
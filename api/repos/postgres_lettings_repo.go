@@ -40,7 +40,7 @@ func (r *postgresLettingsRepo) Create(ctx context.Context, lt *models.Lettings)
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		lt.TenantID,
 		lt.PropertyID,
 		lt.TenantUserID,
@@ -68,7 +68,7 @@ func (r *postgresLettingsRepo) GetByID(ctx context.Context, tenantID string, id
 	FROM lettings
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var lt models.Lettings
 	var deletedInt int
@@ -106,7 +106,7 @@ func (r *postgresLettingsRepo) ListAll(ctx context.Context, tenantID string) ([]
 	FROM lettings
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +158,7 @@ func (r *postgresLettingsRepo) Update(ctx context.Context, lt *models.Lettings)
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lt.PropertyID,
 		lt.TenantUserID,
 		lt.RentAmount,
@@ -189,7 +189,7 @@ func (r *postgresLettingsRepo) Delete(ctx context.Context, lt *models.Lettings)
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lt.ModifiedBy,
 		time.Now().UTC(),
 		lt.TenantUserID,
@@ -209,7 +209,7 @@ func (r *postgresLettingsRepo) SummarizeRentRoll(ctx context.Context, tenantID s
            AND (end_date IS NULL OR DATE(end_date) > DATE('now'))
          GROUP BY property_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
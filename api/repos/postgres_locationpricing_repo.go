@@ -31,7 +31,7 @@ func (r *postgresLocationPricingRepo) Create(ctx context.Context, lp *models.Loc
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		lp.TenantID,
 		lp.ZipCode,
 		lp.City,
@@ -54,7 +54,7 @@ func (r *postgresLocationPricingRepo) GetByID(ctx context.Context, tenantID stri
 	FROM location_pricing
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 	var lp models.LocationPricing
 	var deletedInt int
 	err := row.Scan(
@@ -86,7 +86,7 @@ func (r *postgresLocationPricingRepo) ListAll(ctx context.Context, tenantID stri
 	FROM location_pricing
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +131,7 @@ func (r *postgresLocationPricingRepo) Update(ctx context.Context, lp *models.Loc
 	SET zip_code = ?, city = ?, price_per_sqft = ?, effective_date = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lp.ZipCode,
 		lp.City,
 		lp.PricePerSqFt,
@@ -158,7 +158,7 @@ func (r *postgresLocationPricingRepo) Delete(ctx context.Context, tenantID strin
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -166,3 +166,57 @@ func (r *postgresLocationPricingRepo) Delete(ctx context.Context, tenantID strin
 	)
 	return err
 }
+
+func (r *postgresLocationPricingRepo) ImportBatch(ctx context.Context, inserts, updates []*models.LocationPricing) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		insertQuery := `
+		INSERT INTO location_pricing (
+		  tenant_id, zip_code, city, price_per_sqft, effective_date,
+		  created_by, created_at, modified_by, last_modified, deleted
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE)
+		RETURNING id;
+		`
+		for _, lp := range inserts {
+			lp.CreatedAt = now
+			lp.LastModified = now
+			if err := tx.QueryRowContext(ctx, insertQuery,
+				lp.TenantID,
+				lp.ZipCode,
+				lp.City,
+				lp.PricePerSqFt,
+				lp.EffectiveDate,
+				lp.CreatedBy,
+				lp.CreatedAt,
+				lp.ModifiedBy,
+				lp.LastModified,
+			).Scan(&lp.ID); err != nil {
+				return err
+			}
+		}
+
+		updateQuery := `
+		UPDATE location_pricing
+		SET city = $1, price_per_sqft = $2, modified_by = $3, last_modified = $4
+		WHERE tenant_id = $5 AND id = $6 AND deleted = FALSE;
+		`
+		for _, lp := range updates {
+			lp.LastModified = now
+			res, err := tx.ExecContext(ctx, updateQuery,
+				lp.City,
+				lp.PricePerSqFt,
+				lp.ModifiedBy,
+				lp.LastModified,
+				lp.TenantID,
+				lp.ID,
+			)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresMFARepo struct {
+	db *sql.DB
+}
+
+func (r *postgresMFARepo) GetTOTP(ctx context.Context, tenantID string, userID int64) (*models.MFASecret, error) {
+	var m models.MFASecret
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT tenant_id, user_id, secret, confirmed_at, last_counter, created_at
+	FROM mfa_secrets
+	WHERE tenant_id = $1 AND user_id = $2;
+	`, tenantID, userID).Scan(
+		&m.TenantID,
+		&m.UserID,
+		&m.Secret,
+		&m.ConfirmedAt,
+		&m.LastCounter,
+		&m.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *postgresMFARepo) SaveTOTP(ctx context.Context, m *models.MFASecret) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO mfa_secrets (tenant_id, user_id, secret, confirmed_at, last_counter, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (tenant_id, user_id) DO UPDATE SET
+	  secret = excluded.secret,
+	  confirmed_at = excluded.confirmed_at,
+	  last_counter = excluded.last_counter,
+	  created_at = excluded.created_at;
+	`, m.TenantID, m.UserID, m.Secret, m.ConfirmedAt, m.LastCounter, m.CreatedAt)
+	return err
+}
+
+func (r *postgresMFARepo) UseTOTPCounter(ctx context.Context, tenantID string, userID, counter int64) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE mfa_secrets SET last_counter = $1
+	WHERE tenant_id = $2 AND user_id = $3 AND last_counter < $4;
+	`, counter, tenantID, userID, counter)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *postgresMFARepo) DeleteTOTP(ctx context.Context, tenantID string, userID int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+		DELETE FROM mfa_recovery_codes WHERE tenant_id = $1 AND user_id = $2;
+		`, tenantID, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+		DELETE FROM mfa_secrets WHERE tenant_id = $1 AND user_id = $2;
+		`, tenantID, userID)
+		return err
+	})
+}
+
+func (r *postgresMFARepo) ReplaceRecoveryCodes(ctx context.Context, tenantID string, userID int64, hashes []string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+		DELETE FROM mfa_recovery_codes WHERE tenant_id = $1 AND user_id = $2;
+		`, tenantID, userID); err != nil {
+			return err
+		}
+		for _, h := range hashes {
+			if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (tenant_id, user_id, code_hash) VALUES ($1, $2, $3);
+			`, tenantID, userID, h); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresMFARepo) UseRecoveryCode(ctx context.Context, tenantID string, userID int64, hash string, at time.Time) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE mfa_recovery_codes SET used_at = $1
+	WHERE tenant_id = $2 AND user_id = $3 AND code_hash = $4 AND used_at IS NULL;
+	`, at, tenantID, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresMFARepo) GetPolicy(ctx context.Context, tenantID string) (*models.MFAPolicy, error) {
+	p := models.MFAPolicy{TenantID: tenantID, RequiredPermissions: []string{}}
+	var perms string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT required_permissions, modified_by, last_modified
+	FROM mfa_policies
+	WHERE tenant_id = $1;
+	`, tenantID).Scan(&perms, &p.ModifiedBy, &p.LastModified)
+	if err == sql.ErrNoRows {
+		return &p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.RequiredPermissions = splitList(perms)
+	return &p, nil
+}
+
+func (r *postgresMFARepo) SavePolicy(ctx context.Context, p *models.MFAPolicy) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO mfa_policies (tenant_id, required_permissions, modified_by, last_modified)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (tenant_id) DO UPDATE SET
+	  required_permissions = excluded.required_permissions,
+	  modified_by = excluded.modified_by,
+	  last_modified = excluded.last_modified;
+	`, p.TenantID, strings.Join(p.RequiredPermissions, ","), p.ModifiedBy, p.LastModified)
+	return err
+}
@@ -0,0 +1,127 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresNotificationRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresNotificationRepo) ListPreferences(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.NotificationPreference, error) {
+	query := `
+	SELECT id, tenant_id, subject_type, subject_id, channel, address, opted_out, opted_out_at,
+	       created_by, created_at, modified_by, last_modified
+	FROM notification_preferences
+	WHERE tenant_id = $1 AND subject_type = $2 AND subject_id = $3
+	ORDER BY channel;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.NotificationPreference
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.SubjectType,
+			&p.SubjectID,
+			&p.Channel,
+			&p.Address,
+			&p.OptedOut,
+			&p.OptedOutAt,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &p)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresNotificationRepo) SavePreference(ctx context.Context, p *models.NotificationPreference) error {
+	if p.TenantID == "" || p.SubjectType == "" || p.SubjectID == 0 || p.Channel == "" || p.ModifiedBy == "" {
+		return errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	p.LastModified = now
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+		p.CreatedBy = p.ModifiedBy
+	}
+	query := `
+	INSERT INTO notification_preferences (
+	  tenant_id, subject_type, subject_id, channel, address, opted_out, opted_out_at,
+	  created_by, created_at, modified_by, last_modified
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (tenant_id, subject_type, subject_id, channel) DO UPDATE
+	SET address = EXCLUDED.address, opted_out = EXCLUDED.opted_out, opted_out_at = EXCLUDED.opted_out_at,
+	    modified_by = EXCLUDED.modified_by, last_modified = EXCLUDED.last_modified;
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		p.TenantID,
+		p.SubjectType,
+		p.SubjectID,
+		p.Channel,
+		p.Address,
+		p.OptedOut,
+		p.OptedOutAt,
+		p.CreatedBy,
+		p.CreatedAt,
+		p.ModifiedBy,
+		p.LastModified,
+	)
+	return err
+}
+
+func (r *postgresNotificationRepo) LogAttempt(ctx context.Context, a *models.NotificationAttempt) (int64, error) {
+	a.AttemptedAt = time.Now().UTC()
+	query := `
+	INSERT INTO notification_attempts (
+	  tenant_id, subject_type, subject_id, channel, recipient, subject, attempt, status, error, attempted_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		a.TenantID,
+		a.SubjectType,
+		a.SubjectID,
+		a.Channel,
+		a.Recipient,
+		a.Subject,
+		a.Attempt,
+		a.Status,
+		a.Error,
+		a.AttemptedAt,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresNotificationRepo) ListAttempts(ctx context.Context, tenantID, subjectType string, subjectID int64, limit int) ([]*models.NotificationAttempt, error) {
+	query := `
+	SELECT id, tenant_id, subject_type, subject_id, channel, recipient, subject, attempt, status, error, attempted_at
+	FROM notification_attempts
+	WHERE tenant_id = $1 AND ($2 = '' OR subject_type = $2) AND ($3 = 0 OR subject_id = $3)
+	ORDER BY attempted_at DESC, id DESC
+	LIMIT $4;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotificationAttempts(rows)
+}
@@ -0,0 +1,213 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresOfferRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresOfferRepo(db *sql.DB) OfferRepo {
+	return &postgresOfferRepo{db: db}
+}
+
+const postgresOfferColumns = `
+	id, tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type,
+	expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *postgresOfferRepo) Create(ctx context.Context, o *models.Offer) (int64, error) {
+	if o.TenantID == "" || o.PropertyID == 0 || o.BuyerID == 0 || o.Amount <= 0 || o.Status == "" || o.CreatedBy == "" || o.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	o.CreatedAt = now
+	o.LastModified = now
+	query := `
+	INSERT INTO offers (
+	  tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type,
+	  expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, FALSE)
+	RETURNING id;
+	`
+	args := []any{
+		o.TenantID,
+		o.PropertyID,
+		o.BuyerID,
+		o.Amount,
+		o.CounterAmount,
+		o.Conditions,
+		o.SaleType,
+		o.ExpiresAt,
+		o.Status,
+		o.Stage,
+		o.SaleID,
+		o.CreatedBy,
+		o.CreatedAt,
+		o.ModifiedBy,
+		o.LastModified,
+	}
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&id)
+	return id, err
+}
+
+func (r *postgresOfferRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Offer, error) {
+	query := `SELECT` + postgresOfferColumns + `
+	FROM offers
+	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE;
+	`
+	o, err := scanPostgresOffer(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return o, err
+}
+
+func (r *postgresOfferRepo) ListAll(ctx context.Context, tenantID string, propertyID int64) ([]*models.Offer, error) {
+	query := `SELECT` + postgresOfferColumns + `
+	FROM offers
+	WHERE tenant_id = $1 AND deleted = FALSE AND ($2 = 0 OR property_id = $2)
+	ORDER BY created_at DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Offer
+	for rows.Next() {
+		o, err := scanPostgresOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresOfferRepo) Update(ctx context.Context, o *models.Offer) error {
+	return r.update(ctx, conn(ctx, r.db), o)
+}
+
+func (r *postgresOfferRepo) UpdateWithEvents(ctx context.Context, o *models.Offer, evs ...*models.OutboxEvent) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := r.update(ctx, tx, o); err != nil {
+			return err
+		}
+		return postgresEnqueueOutbox(ctx, tx, evs)
+	})
+}
+
+func (r *postgresOfferRepo) update(ctx context.Context, db dbConn, o *models.Offer) error {
+	o.LastModified = time.Now().UTC()
+	query := `
+	UPDATE offers
+	SET amount = $1, counter_amount = $2, conditions = $3, sale_type = $4, expires_at = $5,
+	    status = $6, stage = $7, sale_id = $8, modified_by = $9, last_modified = $10, deleted = $11
+	WHERE tenant_id = $12 AND id = $13 AND deleted = FALSE;
+	`
+	res, err := db.ExecContext(ctx, query,
+		o.Amount,
+		o.CounterAmount,
+		o.Conditions,
+		o.SaleType,
+		o.ExpiresAt,
+		o.Status,
+		o.Stage,
+		o.SaleID,
+		o.ModifiedBy,
+		o.LastModified,
+		o.Deleted,
+		o.TenantID,
+		o.ID,
+	)
+	if err != nil {
+		return postgresOfferAcceptConflict(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// postgresOfferAcceptConflict maps a second accepted offer for a property,
+// refused by idx_offers_accepted_property, to ErrPropertyUnavailable.
+func postgresOfferAcceptConflict(err error) error {
+	var pe *pq.Error
+	if errors.As(err, &pe) && pe.Code == "23505" && pe.Constraint == "idx_offers_accepted_property" {
+		return ErrPropertyUnavailable
+	}
+	return err
+}
+
+func (r *postgresOfferRepo) AddMilestone(ctx context.Context, m *models.OfferMilestone) (int64, error) {
+	if m.TenantID == "" || m.OfferID == 0 || m.Milestone == "" || m.CreatedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	m.CreatedAt = time.Now().UTC()
+	query := `
+	INSERT INTO offer_milestones (tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id;
+	`
+	args := []any{m.TenantID, m.OfferID, m.Milestone, m.ReachedAt, m.Notes, m.CreatedBy, m.CreatedAt}
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&id)
+	return id, err
+}
+
+func (r *postgresOfferRepo) ListMilestones(ctx context.Context, tenantID string, offerID int64) ([]models.OfferMilestone, error) {
+	query := `
+	SELECT id, tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at
+	FROM offer_milestones
+	WHERE tenant_id = $1 AND offer_id = $2
+	ORDER BY reached_at, id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.OfferMilestone
+	for rows.Next() {
+		var m models.OfferMilestone
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.OfferID, &m.Milestone, &m.ReachedAt, &m.Notes, &m.CreatedBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func scanPostgresOffer(row interface{ Scan(...any) error }) (*models.Offer, error) {
+	var o models.Offer
+	if err := row.Scan(
+		&o.ID,
+		&o.TenantID,
+		&o.PropertyID,
+		&o.BuyerID,
+		&o.Amount,
+		&o.CounterAmount,
+		&o.Conditions,
+		&o.SaleType,
+		&o.ExpiresAt,
+		&o.Status,
+		&o.Stage,
+		&o.SaleID,
+		&o.CreatedBy,
+		&o.CreatedAt,
+		&o.ModifiedBy,
+		&o.LastModified,
+		&o.Deleted,
+	); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
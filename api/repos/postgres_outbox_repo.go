@@ -0,0 +1,124 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresOutboxRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE status = $1 AND next_attempt_at <= $2
+	ORDER BY next_attempt_at, created_at
+	LIMIT $3;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.OutboxPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+func (r *postgresOutboxRepo) List(ctx context.Context, tenantID, status string, limit int) ([]*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE tenant_id = $1 AND ($2::text = '' OR status = $2)
+	ORDER BY created_at DESC
+	LIMIT $3;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+func (r *postgresOutboxRepo) Get(ctx context.Context, tenantID, id string) (*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE tenant_id = $1 AND id = $2;
+	`
+	ev, err := scanOutboxEvent(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return ev, err
+}
+
+func (r *postgresOutboxRepo) Update(ctx context.Context, ev *models.OutboxEvent) error {
+	query := `
+	UPDATE outbox_events
+	SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, dispatched_at = $5
+	WHERE id = $6;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		ev.Status,
+		ev.Attempts,
+		ev.NextAttemptAt,
+		ev.LastError,
+		ev.DispatchedAt,
+		ev.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// postgresEnqueueOutbox writes evs to the outbox within tx.
+func postgresEnqueueOutbox(ctx context.Context, tx *sql.Tx, evs []*models.OutboxEvent) error {
+	query := `
+	INSERT INTO outbox_events (
+	  id, tenant_id, topic, aggregate_type, aggregate_id, payload, status, attempts, next_attempt_at, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9);
+	`
+	now := time.Now().UTC()
+	for _, ev := range evs {
+		ev.Status = models.OutboxPending
+		ev.CreatedAt = now
+		ev.NextAttemptAt = now
+		if _, err := tx.ExecContext(ctx, query,
+			ev.ID,
+			ev.TenantID,
+			ev.Topic,
+			ev.AggregateType,
+			ev.AggregateID,
+			string(ev.Payload),
+			ev.Status,
+			ev.NextAttemptAt,
+			ev.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postgresClaimEvent records that consumer has processed eventID within tx.
+// It returns false if it already had, in which case the caller must not
+// apply the event again.
+func postgresClaimEvent(ctx context.Context, tx *sql.Tx, consumer, eventID string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO processed_events (consumer, event_id, processed_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (consumer, event_id) DO NOTHING;
+	`, consumer, eventID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
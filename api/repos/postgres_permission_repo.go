@@ -13,39 +13,41 @@ func NewPostgresPermissionRepo(db *sql.DB) PermissionRepo {
 	return &postgresPermissionRepo{db: db}
 }
 
-func (r *postgresPermissionRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
+func (r *postgresPermissionRepo) Create(ctx context.Context, m *models.Permission) (int64, error) {
 	var id int64
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO roles (name, description) VALUES ($1,$2) RETURNING id`,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO permissions (name, description) VALUES ($1,$2) RETURNING id`,
 		m.Name, m.Description,
 	).Scan(&id)
 	return id, err
 }
 
-func (r *postgresPermissionRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *postgresPermissionRepo) ListAll(ctx context.Context) ([]*models.Permission, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id,name,COALESCE(description,'') FROM permissions ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []*models.Role
+	var out []*models.Permission
 	for rows.Next() {
-		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		var m models.Permission
+		if err := rows.Scan(&m.ID, &m.Name, &m.Description); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
-func (r *postgresPermissionRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=$1,description=$2 WHERE id=$3`,
+func (r *postgresPermissionRepo) Update(ctx context.Context, m *models.Permission) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE permissions SET name=$1,description=$2 WHERE id=$3`,
 		m.Name, m.Description, m.ID,
 	)
 	return err
 }
 
 func (r *postgresPermissionRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=$1`, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM permissions WHERE id=$1`, id)
 	return err
 }
@@ -0,0 +1,63 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresPostalCentroidRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresPostalCentroidRepo(db *sql.DB) PostalCentroidRepo {
+	return &postgresPostalCentroidRepo{db: db}
+}
+
+func (r *postgresPostalCentroidRepo) GetByPostalCode(ctx context.Context, tenantID, postalCode string) (*models.PostalCentroid, error) {
+	query := `
+	SELECT tenant_id, postal_code, latitude, longitude
+	FROM postal_centroids
+	WHERE tenant_id = $1 AND postal_code = $2;
+	`
+	var c models.PostalCentroid
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, postalCode).Scan(
+		&c.TenantID,
+		&c.PostalCode,
+		&c.Latitude,
+		&c.Longitude,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *postgresPostalCentroidRepo) UpsertBatch(ctx context.Context, cs []*models.PostalCentroid) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO postal_centroids (tenant_id, postal_code, latitude, longitude)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (tenant_id, postal_code)
+	DO UPDATE SET latitude = excluded.latitude, longitude = excluded.longitude;
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, c := range cs {
+		if _, err := stmt.ExecContext(ctx, c.TenantID, c.PostalCode, c.Latitude, c.Longitude); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
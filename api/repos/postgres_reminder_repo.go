@@ -0,0 +1,118 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresReminderRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresReminderRepo) GetTemplate(ctx context.Context, tenantID, kind string) (*models.ReminderTemplate, error) {
+	query := `
+	SELECT id, tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified
+	FROM reminder_templates
+	WHERE tenant_id = $1 AND kind = $2;
+	`
+	t, err := scanReminderTemplate(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, kind))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+func (r *postgresReminderRepo) ListTemplates(ctx context.Context, tenantID string) ([]*models.ReminderTemplate, error) {
+	query := `
+	SELECT id, tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified
+	FROM reminder_templates
+	WHERE tenant_id = $1
+	ORDER BY kind;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.ReminderTemplate
+	for rows.Next() {
+		t, err := scanReminderTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresReminderRepo) SaveTemplate(ctx context.Context, t *models.ReminderTemplate) error {
+	if t.TenantID == "" || t.Kind == "" || t.ModifiedBy == "" {
+		return errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	t.LastModified = now
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+		t.CreatedBy = t.ModifiedBy
+	}
+	query := `
+	INSERT INTO reminder_templates (tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (tenant_id, kind) DO UPDATE
+	SET subject = excluded.subject, body = excluded.body,
+	    modified_by = excluded.modified_by, last_modified = excluded.last_modified;
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		t.TenantID,
+		t.Kind,
+		t.Subject,
+		t.Body,
+		t.CreatedBy,
+		t.CreatedAt,
+		t.ModifiedBy,
+		t.LastModified,
+	)
+	return err
+}
+
+func (r *postgresReminderRepo) Claim(ctx context.Context, s *models.ReminderSend) (bool, error) {
+	s.SentAt = time.Now().UTC()
+	query := `
+	INSERT INTO reminder_sends (tenant_id, installment_id, kind, recipient, sent_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (tenant_id, installment_id, kind) DO NOTHING
+	RETURNING id;
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, s.TenantID, s.InstallmentID, s.Kind, s.Recipient, s.SentAt).Scan(&s.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *postgresReminderRepo) Release(ctx context.Context, tenantID string, installmentID int64, kind string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM reminder_sends WHERE tenant_id = $1 AND installment_id = $2 AND kind = $3;
+	`, tenantID, installmentID, kind)
+	return err
+}
+
+func (r *postgresReminderRepo) ListSends(ctx context.Context, tenantID string, installmentID int64) ([]*models.ReminderSend, error) {
+	query := `
+	SELECT id, tenant_id, installment_id, kind, recipient, sent_at
+	FROM reminder_sends
+	WHERE tenant_id = $1 AND ($2 = 0 OR installment_id = $2)
+	ORDER BY sent_at DESC, id DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, installmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReminderSends(rows)
+}
@@ -13,39 +13,70 @@ func NewPostgresRolePermissionRepo(db *sql.DB) RolePermissionRepo {
 	return &postgresRolePermissionRepo{db: db}
 }
 
-func (r *postgresRolePermissionRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
-	var id int64
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO roles (name, description) VALUES ($1,$2) RETURNING id`,
-		m.Name, m.Description,
-	).Scan(&id)
-	return id, err
+func (r *postgresRolePermissionRepo) Add(ctx context.Context, m *models.RolePermission) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, m.RoleID, m.PermissionID)
+	return err
+}
+
+func (r *postgresRolePermissionRepo) Remove(ctx context.Context, roleID, permissionID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM role_permissions WHERE role_id=$1 AND permission_id=$2`,
+		roleID, permissionID,
+	)
+	return err
+}
+
+func (r *postgresRolePermissionRepo) Replace(ctx context.Context, roleID int64, permissionIDs []int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=$1`, roleID); err != nil {
+			return err
+		}
+		for _, id := range permissionIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, roleID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresRolePermissionRepo) DeleteByRole(ctx context.Context, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=$1`, roleID)
+	return err
 }
 
-func (r *postgresRolePermissionRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *postgresRolePermissionRepo) ListByRole(ctx context.Context, roleID int64) ([]int64, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT permission_id FROM role_permissions WHERE role_id=$1 ORDER BY permission_id`, roleID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []*models.Role
+	var out []int64
 	for rows.Next() {
-		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
-		out = append(out, &m)
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
-func (r *postgresRolePermissionRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=$1,description=$2 WHERE id=$3`,
-		m.Name, m.Description, m.ID,
-	)
-	return err
-}
-
-func (r *postgresRolePermissionRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=$1`, id)
-	return err
+func (r *postgresRolePermissionRepo) ListAll(ctx context.Context) ([]*models.RolePermission, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT role_id,permission_id FROM role_permissions ORDER BY role_id,permission_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.RolePermission
+	for rows.Next() {
+		var m models.RolePermission
+		if err := rows.Scan(&m.RoleID, &m.PermissionID); err != nil {
+			return nil, err
+		}
+		out = append(out, &m)
+	}
+	return out, rows.Err()
 }
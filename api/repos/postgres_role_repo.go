@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
@@ -15,15 +16,17 @@ func NewPostgresRoleRepo(db *sql.DB) RoleRepo {
 
 func (r *postgresRoleRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
 	var id int64
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO roles (name, description) VALUES ($1,$2) RETURNING id`,
-		m.Name, m.Description,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO roles (tenant_id, name, description) VALUES ($1,$2,$3) RETURNING id`,
+		m.TenantID, m.Name, m.Description,
 	).Scan(&id)
 	return id, err
 }
 
-func (r *postgresRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *postgresRoleRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Role, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT id,tenant_id,name,COALESCE(description,'') FROM roles WHERE tenant_id=$1 ORDER BY name`, tenantID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -31,21 +34,49 @@ func (r *postgresRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error)
 	var out []*models.Role
 	for rows.Next() {
 		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.Name, &m.Description); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
+	return out, rows.Err()
+}
+
+func (r *postgresRoleRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Role, error) {
+	var m models.Role
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id,tenant_id,name,COALESCE(description,'') FROM roles WHERE tenant_id=$1 AND id=$2`, tenantID, id,
+	).Scan(&m.ID, &m.TenantID, &m.Name, &m.Description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 func (r *postgresRoleRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=$1,description=$2 WHERE id=$3`,
-		m.Name, m.Description, m.ID,
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE roles SET name=$1,description=$2 WHERE tenant_id=$3 AND id=$4`,
+		m.Name, m.Description, m.TenantID, m.ID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (r *postgresRoleRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=$1`, id)
-	return err
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM roles WHERE tenant_id=$1 AND id=$2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
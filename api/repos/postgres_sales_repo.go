@@ -30,20 +30,22 @@ func (r *postgresSalesRepo) Create(ctx context.Context, s *models.Sales) (int64,
 	now := time.Now().UTC()
 	s.CreatedAt = now
 	s.LastModified = now
+	s.OwnerID = recordOwner(ctx, s.OwnerID)
 
 	query := `
     INSERT INTO sales (
       tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-      created_by, created_at, modified_by, last_modified, deleted
+      owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
     ) VALUES (
       $1,$2,$3,$4,$5,$6,
-      $7,$8,$9,$10,FALSE
+      $7,$8,$9,$10,$11,$12,FALSE
     ) RETURNING id
     `
 	var newID int64
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		s.TenantID, s.PropertyID, s.BuyerID,
 		s.SalePrice, s.SaleDate, s.SaleType,
+		s.OwnerID, s.AssignedTo,
 		s.CreatedBy, s.CreatedAt, s.ModifiedBy, s.LastModified,
 	).Scan(&newID)
 	if err != nil {
@@ -53,13 +55,14 @@ func (r *postgresSalesRepo) Create(ctx context.Context, s *models.Sales) (int64,
 }
 
 func (r *postgresSalesRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Sales, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
 	SELECT id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM sales
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var s models.Sales
 	var deletedInt int
@@ -71,6 +74,8 @@ func (r *postgresSalesRepo) GetByID(ctx context.Context, tenantID string, id int
 		&s.SalePrice,
 		&s.SaleDate,
 		&s.SaleType,
+		&s.OwnerID,
+		&s.AssignedTo,
 		&s.CreatedBy,
 		&s.CreatedAt,
 		&s.ModifiedBy,
@@ -88,13 +93,14 @@ func (r *postgresSalesRepo) GetByID(ctx context.Context, tenantID string, id int
 }
 
 func (r *postgresSalesRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Sales, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
 	SELECT id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM sales
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +118,8 @@ func (r *postgresSalesRepo) ListAll(ctx context.Context, tenantID string) ([]*mo
 			&s.SalePrice,
 			&s.SaleDate,
 			&s.SaleType,
+			&s.OwnerID,
+			&s.AssignedTo,
 			&s.CreatedBy,
 			&s.CreatedAt,
 			&s.ModifiedBy,
@@ -140,15 +148,16 @@ func (r *postgresSalesRepo) Update(ctx context.Context, s *models.Sales) error {
 	query := `
 	UPDATE sales
 	SET property_id = ?, buyer_id = ?, sale_price = ?, sale_date = ?, sale_type = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    assigned_to = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		s.PropertyID,
 		s.BuyerID,
 		s.SalePrice,
 		s.SaleDate,
 		s.SaleType,
+		s.AssignedTo,
 		s.ModifiedBy,
 		s.LastModified,
 		boolToInt(s.Deleted),
@@ -171,7 +180,7 @@ func (r *postgresSalesRepo) Delete(ctx context.Context, tenantID string, id int6
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -187,7 +196,7 @@ func (r *sqliteCommissionRepo) SummarizeByBeneficiary(ctx context.Context, tenan
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY beneficiary_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +223,7 @@ func (r *postgresSalesRepo) SummarizeByMonth(ctx context.Context, tenantID strin
          GROUP BY month
          ORDER BY month DESC;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,97 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresSessionRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresSessionRepo) CreateRefreshToken(ctx context.Context, t *models.RefreshToken) (int64, error) {
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	INSERT INTO refresh_tokens (tenant_id, user_id, family_id, token_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`, t.TenantID, t.UserID, t.FamilyID, t.TokenHash, t.CreatedAt, t.ExpiresAt).Scan(&id)
+	return id, err
+}
+
+func (r *postgresSessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, family_id, token_hash, created_at, expires_at, used_at, revoked_at
+	FROM refresh_tokens
+	WHERE token_hash = $1;
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresSessionRepo) UseRefreshToken(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE refresh_tokens SET used_at = $1
+	WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL;
+	`, at, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *postgresSessionRepo) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE refresh_tokens SET revoked_at = $1
+	WHERE family_id = $2 AND revoked_at IS NULL;
+	`, at, familyID)
+	return err
+}
+
+func (r *postgresSessionRepo) RevokeUser(ctx context.Context, tenantID string, userID int64, at time.Time, by string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $1
+		WHERE tenant_id = $2 AND user_id = $3 AND revoked_at IS NULL;
+		`, at, tenantID, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO session_revocations (tenant_id, user_id, revoked_at, revoked_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET revoked_at = excluded.revoked_at, revoked_by = excluded.revoked_by;
+		`, tenantID, userID, at, by)
+		return err
+	})
+}
+
+func (r *postgresSessionRepo) RevokedAt(ctx context.Context, tenantID string, userID int64) (time.Time, error) {
+	var at time.Time
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT revoked_at FROM session_revocations WHERE tenant_id = $1 AND user_id = $2;
+	`, tenantID, userID).Scan(&at)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return at, err
+}
@@ -0,0 +1,103 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresTenantRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresTenantRepo) Create(ctx context.Context, t *models.Tenant) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO tenants (id, name, status, suspended_at, suspended_reason, created_by, created_at, modified_by, last_modified)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (id) DO NOTHING;
+	`, t.ID, t.Name, t.Status, t.SuspendedAt, t.SuspendedReason, t.CreatedBy, t.CreatedAt, t.ModifiedBy, t.LastModified)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTenantExists
+	}
+	return nil
+}
+
+func (r *postgresTenantRepo) GetByID(ctx context.Context, id string) (*models.Tenant, error) {
+	var t models.Tenant
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, name, status, suspended_at, suspended_reason, created_by, created_at, modified_by, last_modified
+	FROM tenants
+	WHERE id = $1;
+	`, id).Scan(
+		&t.ID,
+		&t.Name,
+		&t.Status,
+		&t.SuspendedAt,
+		&t.SuspendedReason,
+		&t.CreatedBy,
+		&t.CreatedAt,
+		&t.ModifiedBy,
+		&t.LastModified,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresTenantRepo) ListAll(ctx context.Context) ([]*models.Tenant, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+	SELECT id, name, status, suspended_at, suspended_reason, created_by, created_at, modified_by, last_modified
+	FROM tenants
+	ORDER BY id;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Tenant
+	for rows.Next() {
+		var t models.Tenant
+		if err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Status,
+			&t.SuspendedAt,
+			&t.SuspendedReason,
+			&t.CreatedBy,
+			&t.CreatedAt,
+			&t.ModifiedBy,
+			&t.LastModified,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &t)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresTenantRepo) Update(ctx context.Context, t *models.Tenant) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE tenants
+	SET name = $1, status = $2, suspended_at = $3, suspended_reason = $4, modified_by = $5, last_modified = $6
+	WHERE id = $7;
+	`, t.Name, t.Status, t.SuspendedAt, t.SuspendedReason, t.ModifiedBy, t.LastModified, t.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
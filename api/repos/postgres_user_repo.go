@@ -23,6 +23,13 @@ func NewPostgresUserRepo(db *sql.DB) UserRepo {
 
 // Create inserts a new user and returns its generated ID.
 func (r *postgresUserRepo) Create(ctx context.Context, u *models.User) (int64, error) {
+	return r.CreateWithinSeats(ctx, u, 0)
+}
+
+// CreateWithinSeats inserts the user only while the tenant's count of
+// users is under seats. A transaction-scoped advisory lock on the tenant
+// serialises the count and insert against other seat-limited creations.
+func (r *postgresUserRepo) CreateWithinSeats(ctx context.Context, u *models.User, seats int) (int64, error) {
 	if u.TenantID == "" || u.UserName == "" || u.PasswordHash == "" ||
 		u.FirstName == "" || u.LastName == "" || u.Role == "" ||
 		u.Email == "" || u.CreatedBy == "" || u.ModifiedBy == "" {
@@ -36,31 +43,43 @@ func (r *postgresUserRepo) Create(ctx context.Context, u *models.User) (int64, e
 	query := `
 	INSERT INTO users (
 		tenant_id, username, password_hash,
-		first_name, last_name, role, email, phone,
+		first_name, last_name, role, email, phone, team,
 		created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (
+	) SELECT
 		$1,$2,$3,
-		$4,$5,$6,$7,$8,
-		$9,$10,$11,$12, FALSE
-	)
+		$4,$5,$6,$7,$8,$9,
+		$10,$11,$12,$13, FALSE
+	WHERE $14::int = 0 OR (SELECT COUNT(*) FROM users WHERE tenant_id = $1 AND deleted = FALSE) < $14::int
 	RETURNING id` // boolean column 'deleted'
 
 	var newID int64
-	err := r.db.QueryRowContext(
-		ctx, query,
-		u.TenantID,
-		u.UserName,
-		u.PasswordHash,
-		u.FirstName,
-		u.LastName,
-		u.Role,
-		u.Email,
-		u.Phone,
-		u.CreatedBy,
-		u.CreatedAt,
-		u.ModifiedBy,
-		u.LastModified,
-	).Scan(&newID)
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if seats > 0 {
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('users:' || $1))`, u.TenantID); err != nil {
+				return err
+			}
+		}
+		return tx.QueryRowContext(
+			ctx, query,
+			u.TenantID,
+			u.UserName,
+			u.PasswordHash,
+			u.FirstName,
+			u.LastName,
+			u.Role,
+			u.Email,
+			u.Phone,
+			u.Team,
+			u.CreatedBy,
+			u.CreatedAt,
+			u.ModifiedBy,
+			u.LastModified,
+			seats,
+		).Scan(&newID)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrSeatLimit
+	}
 	if err != nil {
 		return 0, fmt.Errorf("Create user: %w", err)
 	}
@@ -71,12 +90,12 @@ func (r *postgresUserRepo) Create(ctx context.Context, u *models.User) (int64, e
 func (r *postgresUserRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.User, error) {
 	query := `
 	SELECT id, tenant_id, username, password_hash,
-		first_name, last_name, role, email, phone,
+		first_name, last_name, role, email, phone, team,
 		created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE` + `;`
 
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 	var u models.User
 	var deleted bool
 	err := row.Scan(
@@ -89,6 +108,7 @@ func (r *postgresUserRepo) GetByID(ctx context.Context, tenantID string, id int6
 		&u.Role,
 		&u.Email,
 		&u.Phone,
+		&u.Team,
 		&u.CreatedBy,
 		&u.CreatedAt,
 		&u.ModifiedBy,
@@ -109,12 +129,12 @@ func (r *postgresUserRepo) GetByID(ctx context.Context, tenantID string, id int6
 func (r *postgresUserRepo) GetByUsername(ctx context.Context, tenantID, username string) (*models.User, error) {
 	query := `
 	SELECT id, tenant_id, username, password_hash,
-		first_name, last_name, role, email, phone,
+		first_name, last_name, role, email, phone, team,
 		created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = $1 AND username = $2 AND deleted = FALSE` + `;`
 
-	row := r.db.QueryRowContext(ctx, query, tenantID, username)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, username)
 	var u models.User
 	var deleted bool
 	err := row.Scan(
@@ -127,6 +147,7 @@ func (r *postgresUserRepo) GetByUsername(ctx context.Context, tenantID, username
 		&u.Role,
 		&u.Email,
 		&u.Phone,
+		&u.Team,
 		&u.CreatedBy,
 		&u.CreatedAt,
 		&u.ModifiedBy,
@@ -147,12 +168,12 @@ func (r *postgresUserRepo) GetByUsername(ctx context.Context, tenantID, username
 func (r *postgresUserRepo) ListAll(ctx context.Context, tenantID string) ([]*models.User, error) {
 	query := `
 	SELECT id, tenant_id, username, password_hash,
-		first_name, last_name, role, email, phone,
+		first_name, last_name, role, email, phone, team,
 		created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = $1 AND deleted = FALSE` + `;`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("ListAll users: %w", err)
 	}
@@ -172,6 +193,7 @@ func (r *postgresUserRepo) ListAll(ctx context.Context, tenantID string) ([]*mod
 			&u.Role,
 			&u.Email,
 			&u.Phone,
+			&u.Team,
 			&u.CreatedBy,
 			&u.CreatedAt,
 			&u.ModifiedBy,
@@ -202,12 +224,12 @@ func (r *postgresUserRepo) Update(ctx context.Context, u *models.User) error {
 	UPDATE users SET
 		username = $1, password_hash = $2,
 		first_name = $3, last_name = $4, role = $5,
-		email = $6, phone = $7,
-		modified_by = $8, last_modified = $9,
-		deleted = $10
-	WHERE tenant_id = $11 AND id = $12` + `;`
+		email = $6, phone = $7, team = $8,
+		modified_by = $9, last_modified = $10,
+		deleted = $11
+	WHERE tenant_id = $12 AND id = $13` + `;`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		u.UserName,
 		u.PasswordHash,
 		u.FirstName,
@@ -215,6 +237,7 @@ func (r *postgresUserRepo) Update(ctx context.Context, u *models.User) error {
 		u.Role,
 		u.Email,
 		u.Phone,
+		u.Team,
 		u.ModifiedBy,
 		u.LastModified,
 		u.Deleted,
@@ -240,7 +263,7 @@ func (r *postgresUserRepo) Delete(ctx context.Context, tenantID string, id int64
 		last_modified = $2
 	WHERE tenant_id = $3 AND id = $4` + `;`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -259,7 +282,7 @@ func (r *postgresUserRepo) ListPermissionsForUser(ctx context.Context, tenantID
 	JOIN users u ON u.id = ur.user_id
 	WHERE u.tenant_id = $1 AND u.id = $2 AND u.deleted = FALSE` + `;`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("ListPermissions: %w", err)
 	}
@@ -9,43 +9,74 @@ import (
 
 type postgresUserRoleRepo struct{ db *sql.DB }
 
-func NewPostgresUserRoleRepo(db *sql.DB) RoleRepo {
+func NewPostgresUserRoleRepo(db *sql.DB) UserRoleRepo {
 	return &postgresUserRoleRepo{db: db}
 }
 
-func (r *postgresUserRoleRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
-	var id int64
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO roles (name, description) VALUES ($1,$2) RETURNING id`,
-		m.Name, m.Description,
-	).Scan(&id)
-	return id, err
+func (r *postgresUserRoleRepo) Add(ctx context.Context, m *models.UserRole) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT INTO user_roles (user_id, role_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, m.UserID, m.RoleID)
+	return err
+}
+
+func (r *postgresUserRoleRepo) Remove(ctx context.Context, userID, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM user_roles WHERE user_id=$1 AND role_id=$2`,
+		userID, roleID,
+	)
+	return err
+}
+
+func (r *postgresUserRoleRepo) Replace(ctx context.Context, userID int64, roleIDs []int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id=$1`, userID); err != nil {
+			return err
+		}
+		for _, id := range roleIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO user_roles (user_id, role_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, userID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresUserRoleRepo) DeleteByRole(ctx context.Context, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_roles WHERE role_id=$1`, roleID)
+	return err
 }
 
-func (r *postgresUserRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *postgresUserRoleRepo) ListRoles(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT role_id FROM user_roles WHERE user_id=$1 ORDER BY role_id`, userID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []*models.Role
+	var out []int64
 	for rows.Next() {
-		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
-		out = append(out, &m)
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
-func (r *postgresUserRoleRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=$1,description=$2 WHERE id=$3`,
-		m.Name, m.Description, m.ID,
-	)
-	return err
-}
-
-func (r *postgresUserRoleRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=$1`, id)
-	return err
+func (r *postgresUserRoleRepo) ListAll(ctx context.Context) ([]*models.UserRole, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT user_id,role_id FROM user_roles ORDER BY user_id,role_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.UserRole
+	for rows.Next() {
+		var m models.UserRole
+		if err := rows.Scan(&m.UserID, &m.RoleID); err != nil {
+			return nil, err
+		}
+		out = append(out, &m)
+	}
+	return out, rows.Err()
 }
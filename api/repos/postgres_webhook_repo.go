@@ -0,0 +1,225 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type postgresWebhookRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresWebhookRepo) CreateSubscription(ctx context.Context, s *models.WebhookSubscription) (int64, error) {
+	if s.TenantID == "" || s.URL == "" || s.Secret == "" || s.CreatedBy == "" || s.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	s.CreatedAt = now
+	s.LastModified = now
+	query := `
+	INSERT INTO webhook_subscriptions (
+	  tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE)
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		s.TenantID,
+		s.URL,
+		s.Secret,
+		joinEvents(s.Events),
+		s.Active,
+		s.CreatedBy,
+		s.CreatedAt,
+		s.ModifiedBy,
+		s.LastModified,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *postgresWebhookRepo) GetSubscription(ctx context.Context, tenantID string, id int64) (*models.WebhookSubscription, error) {
+	query := `
+	SELECT id, tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	FROM webhook_subscriptions
+	WHERE tenant_id = $1 AND id = $2 AND deleted = FALSE;
+	`
+	s, err := scanPostgresWebhookSubscription(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return s, err
+}
+
+func (r *postgresWebhookRepo) ListSubscriptions(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error) {
+	query := `
+	SELECT id, tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	FROM webhook_subscriptions
+	WHERE tenant_id = $1 AND deleted = FALSE
+	ORDER BY id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.WebhookSubscription
+	for rows.Next() {
+		s, err := scanPostgresWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *postgresWebhookRepo) UpdateSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	s.LastModified = time.Now().UTC()
+	query := `
+	UPDATE webhook_subscriptions
+	SET url = $1, events = $2, active = $3, modified_by = $4, last_modified = $5, deleted = $6
+	WHERE tenant_id = $7 AND id = $8 AND deleted = FALSE;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		s.URL,
+		joinEvents(s.Events),
+		s.Active,
+		s.ModifiedBy,
+		s.LastModified,
+		s.Deleted,
+		s.TenantID,
+		s.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresWebhookRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) (int64, error) {
+	d.CreatedAt = time.Now().UTC()
+	query := `
+	INSERT INTO webhook_deliveries (
+	  tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	  last_error, last_status_code, delivered_at, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	ON CONFLICT (subscription_id, event_id) DO NOTHING
+	RETURNING id;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		d.TenantID,
+		d.SubscriptionID,
+		d.EventID,
+		d.EventType,
+		string(d.Payload),
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastError,
+		d.LastStatusCode,
+		d.DeliveredAt,
+		d.CreatedAt,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *postgresWebhookRepo) GetDelivery(ctx context.Context, tenantID string, id int64) (*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE tenant_id = $1 AND id = $2;
+	`
+	d, err := scanWebhookDelivery(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return d, err
+}
+
+func (r *postgresWebhookRepo) ListDeliveries(ctx context.Context, tenantID, status string, subscriptionID int64, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE tenant_id = $1 AND ($2 = '' OR status = $2) AND ($3 = 0 OR subscription_id = $3)
+	ORDER BY id DESC
+	LIMIT $4;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, status, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *postgresWebhookRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE status = $1 AND next_attempt_at <= $2
+	ORDER BY next_attempt_at, id
+	LIMIT $3;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.DeliveryPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *postgresWebhookRepo) UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, last_status_code = $5, delivered_at = $6
+	WHERE tenant_id = $7 AND id = $8;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastError,
+		d.LastStatusCode,
+		d.DeliveredAt,
+		d.TenantID,
+		d.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanPostgresWebhookSubscription(row interface{ Scan(...any) error }) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	var events string
+	if err := row.Scan(
+		&s.ID,
+		&s.TenantID,
+		&s.URL,
+		&s.Secret,
+		&events,
+		&s.Active,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.ModifiedBy,
+		&s.LastModified,
+		&s.Deleted,
+	); err != nil {
+		return nil, err
+	}
+	s.Events = splitEvents(events)
+	return &s, nil
+}
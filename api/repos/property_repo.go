@@ -7,7 +7,8 @@ import (
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
 
-// PropertyRepo defines CRUD for Property
+// PropertyRepo defines CRUD for Property. Reads, and so updates and
+// deletes, are limited by the AccessPolicy in ctx.
 type PropertyRepo interface {
 	Create(ctx context.Context, p *models.Property) (int64, error)
 	GetByID(ctx context.Context, tenantID string, id int64) (*models.Property, error)
@@ -15,6 +16,12 @@ type PropertyRepo interface {
 	Update(ctx context.Context, p *models.Property) error
 	Delete(ctx context.Context, tenantID string, id int64) error
 	SummarizeTopProperties(ctx context.Context, tenantID string) ([]models.PropertyPaymentVolume, error)
+	// SearchByRadius returns geocoded properties within radiusKm of (lat, lng),
+	// nearest first. A non-empty status limits it to properties in that status.
+	SearchByRadius(ctx context.Context, tenantID string, lat, lng, radiusKm float64, status string) ([]models.PropertySearchResult, error)
+	// ApplyStatus sets the property's status unless consumer has already
+	// processed eventID. It reports whether the change was applied.
+	ApplyStatus(ctx context.Context, consumer, eventID, tenantID string, propertyID int64, status, modifiedBy string) (bool, error)
 }
 
 // PropertyPaymentVolume holds a property_id and total paid so far.
@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// ReminderRepo stores reminder templates and the log of sent reminders.
+type ReminderRepo interface {
+	// GetTemplate returns ErrNotFound if the tenant hasn't customised kind.
+	GetTemplate(ctx context.Context, tenantID, kind string) (*models.ReminderTemplate, error)
+	ListTemplates(ctx context.Context, tenantID string) ([]*models.ReminderTemplate, error)
+	// SaveTemplate inserts or replaces the tenant's template for t.Kind.
+	SaveTemplate(ctx context.Context, t *models.ReminderTemplate) error
+
+	// Claim records s unless a send for the same installment and kind
+	// already exists, in which case it returns false.
+	Claim(ctx context.Context, s *models.ReminderSend) (bool, error)
+	// Release removes a claim whose delivery failed so it is retried.
+	Release(ctx context.Context, tenantID string, installmentID int64, kind string) error
+	ListSends(ctx context.Context, tenantID string, installmentID int64) ([]*models.ReminderSend, error)
+}
+
+// NewDBReminderRepo selects the concrete implementation based on driver.
+func NewDBReminderRepo(db *sql.DB, driver string) ReminderRepo {
+	switch driver {
+	case "postgres":
+		return &postgresReminderRepo{db: db}
+	case "sqlite":
+		return &sqliteReminderRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
@@ -0,0 +1,43 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/migrate"
+)
+
+const testTenant = "acme"
+
+// newTestDB returns an in-memory sqlite database with every migration
+// applied, as used in single-database mode.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	for _, dir := range []string{"../../migrations/sqlite", "../../migrations/single/sqlite"} {
+		if err := migrate.MigrateSQL(db, dir); err != nil {
+			t.Fatalf("%s: %v", dir, err)
+		}
+	}
+	return db
+}
+
+func createTestBuyer(t *testing.T, db *sql.DB, email string) int64 {
+	t.Helper()
+	id, err := NewDBBuyerRepo(db, "sqlite").Create(context.Background(), &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: email,
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
@@ -9,13 +9,13 @@ import (
 
 // RolePermissionRepo defines role–permission assignments.
 type RolePermissionRepo interface {
-	Add(ctx context.Context, rp *models.RolePermission) error
+	Add(ctx context.Context, rp *models.RolePermission) error // no-op if already granted
+	Remove(ctx context.Context, roleID, permissionID int64) error
+	// Replace sets the role's permissions to exactly permissionIDs.
+	Replace(ctx context.Context, roleID int64, permissionIDs []int64) error
+	DeleteByRole(ctx context.Context, roleID int64) error
 	ListByRole(ctx context.Context, roleID int64) ([]int64 /*permissionIDs*/, error)
-	Create(ctx context.Context, r *models.RolePermission) (int64, error)
 	ListAll(ctx context.Context) ([]*models.RolePermission, error)
-	GetByID(ctx context.Context, tenantID string, id int64) (*models.RolePermission, error)
-	Update(ctx context.Context, b *models.RolePermission) error // using b.TenantID,b.ID
-	Delete(ctx context.Context, tenantID string, id int64) error
 }
 
 func NewDBRolePermissionRepo(db *sql.DB, driver string) RolePermissionRepo {
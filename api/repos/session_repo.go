@@ -0,0 +1,40 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// SessionRepo stores refresh tokens and per-user session revocations.
+type SessionRepo interface {
+	CreateRefreshToken(ctx context.Context, t *models.RefreshToken) (int64, error)
+	// GetRefreshToken returns the token with the given hash in any tenant,
+	// used or not. Returns ErrNotFound if there is none.
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// UseRefreshToken marks the token used if it is still unused and
+	// unrevoked, reporting whether it was; false means it was spent
+	// concurrently.
+	UseRefreshToken(ctx context.Context, id int64, at time.Time) (bool, error)
+	// RevokeFamily revokes every token descended from the same login.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeUser revokes all the user's refresh tokens and records at as
+	// the time before which their access tokens are refused.
+	RevokeUser(ctx context.Context, tenantID string, userID int64, at time.Time, by string) error
+	// RevokedAt returns the user's last revocation time, or the zero time.
+	RevokedAt(ctx context.Context, tenantID string, userID int64) (time.Time, error)
+}
+
+// NewDBSessionRepo selects the concrete implementation based on driver.
+func NewDBSessionRepo(db *sql.DB, driver string) SessionRepo {
+	switch driver {
+	case "postgres":
+		return &postgresSessionRepo{db: db}
+	case "sqlite":
+		return &sqliteSessionRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
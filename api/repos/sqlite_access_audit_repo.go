@@ -0,0 +1,38 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteAccessAuditRepo struct{ db *sql.DB }
+
+func (r *sqliteAccessAuditRepo) Create(ctx context.Context, a *models.AccessAudit) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO access_audit (tenant_id, actor, action, target_type, target_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.TenantID, a.Actor, a.Action, a.TargetType, a.TargetID, a.Detail, a.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteAccessAuditRepo) List(ctx context.Context, tenantID, targetType string, targetID int64, limit int) ([]*models.AccessAudit, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+accessAuditColumns+`
+		  FROM access_audit
+		 WHERE tenant_id = ?
+		   AND (? = '' OR (target_type = ? AND target_id = ?))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT ?`,
+		tenantID, targetType, targetType, targetID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccessAudits(rows)
+}
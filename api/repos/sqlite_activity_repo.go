@@ -0,0 +1,148 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteActivityRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteActivityRepo(db *sql.DB) ActivityRepo {
+	return &sqliteActivityRepo{db: db}
+}
+
+const sqliteActivityColumns = `
+	id, tenant_id, subject_type, subject_id, letting_id, type, occurred_at, author, body, due_date, completed_at,
+	created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *sqliteActivityRepo) Create(ctx context.Context, a *models.Activity) (int64, error) {
+	if a.TenantID == "" || a.SubjectType == "" || a.SubjectID == 0 || a.Type == "" || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO activities (
+	  tenant_id, subject_type, subject_id, letting_id, type, occurred_at, author, body, due_date, completed_at,
+	  created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.TenantID,
+		a.SubjectType,
+		a.SubjectID,
+		a.LettingID,
+		a.Type,
+		a.OccurredAt,
+		a.Author,
+		a.Body,
+		a.DueDate,
+		a.CompletedAt,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteActivityRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Activity, error) {
+	query := `SELECT` + sqliteActivityColumns + `
+	FROM activities
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	a, err := scanSQLiteActivity(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *sqliteActivityRepo) ListBySubject(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.Activity, error) {
+	query := `SELECT` + sqliteActivityColumns + `
+	FROM activities
+	WHERE tenant_id = ? AND subject_type = ? AND subject_id = ? AND deleted = 0
+	ORDER BY occurred_at, id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Activity
+	for rows.Next() {
+		a, err := scanSQLiteActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteActivityRepo) Update(ctx context.Context, a *models.Activity) error {
+	a.LastModified = time.Now().UTC()
+	query := `
+	UPDATE activities
+	SET type = ?, occurred_at = ?, body = ?, due_date = ?, completed_at = ?,
+	    modified_by = ?, last_modified = ?, deleted = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.Type,
+		a.OccurredAt,
+		a.Body,
+		a.DueDate,
+		a.CompletedAt,
+		a.ModifiedBy,
+		a.LastModified,
+		boolToInt(a.Deleted),
+		a.TenantID,
+		a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanSQLiteActivity(row interface{ Scan(...any) error }) (*models.Activity, error) {
+	var a models.Activity
+	var deletedInt int
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.SubjectType,
+		&a.SubjectID,
+		&a.LettingID,
+		&a.Type,
+		&a.OccurredAt,
+		&a.Author,
+		&a.Body,
+		&a.DueDate,
+		&a.CompletedAt,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&deletedInt,
+	); err != nil {
+		return nil, err
+	}
+	a.Deleted = deletedInt != 0
+	return &a, nil
+}
@@ -0,0 +1,115 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteAPIKeyRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteAPIKeyRepo) Create(ctx context.Context, k *models.APIKey) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO api_keys (tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, created_by, created_at, modified_by, last_modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`, k.TenantID, k.UserID, k.Name, k.Prefix, k.SecretHash, strings.Join(k.Permissions, ","), strings.Join(k.AllowedIPs, ","),
+		k.ExpiresAt, k.CreatedBy, k.CreatedAt, k.ModifiedBy, k.LastModified)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteAPIKeyRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.APIKey, error) {
+	k, err := scanAPIKey(conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE tenant_id = ? AND id = ?;
+	`, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return k, err
+}
+
+func (r *sqliteAPIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	k, err := scanAPIKey(conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE prefix = ?;
+	`, prefix))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return k, err
+}
+
+func (r *sqliteAPIKeyRepo) ListAll(ctx context.Context, tenantID string) ([]*models.APIKey, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+	SELECT id, tenant_id, user_id, name, prefix, secret_hash, permissions, allowed_ips, expires_at, last_used_at, last_used_ip, created_by, created_at, modified_by, last_modified
+	FROM api_keys
+	WHERE tenant_id = ?
+	ORDER BY id;
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteAPIKeyRepo) Update(ctx context.Context, k *models.APIKey) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE api_keys
+	SET name = ?, permissions = ?, allowed_ips = ?, expires_at = ?, modified_by = ?, last_modified = ?
+	WHERE tenant_id = ? AND id = ?;
+	`, k.Name, strings.Join(k.Permissions, ","), strings.Join(k.AllowedIPs, ","), k.ExpiresAt, k.ModifiedBy, k.LastModified,
+		k.TenantID, k.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteAPIKeyRepo) Delete(ctx context.Context, tenantID string, id int64) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM api_keys
+	WHERE tenant_id = ? AND id = ?;
+	`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteAPIKeyRepo) Touch(ctx context.Context, id int64, at time.Time, ip string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE api_keys
+	SET last_used_at = ?, last_used_ip = ?
+	WHERE id = ?;
+	`, at, ip, id)
+	return err
+}
@@ -0,0 +1,156 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteAppointmentRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteAppointmentRepo(db *sql.DB) AppointmentRepo {
+	return &sqliteAppointmentRepo{db: db}
+}
+
+const sqliteAppointmentColumns = `
+	id, tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id,
+	starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *sqliteAppointmentRepo) Create(ctx context.Context, a *models.Appointment) (int64, error) {
+	if a.TenantID == "" || a.PropertyID == 0 || a.AgentUserID == 0 || a.StartsAt.IsZero() || a.EndsAt.IsZero() || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO appointments (
+	  tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id,
+	  starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.TenantID,
+		a.PropertyID,
+		a.BuyerID,
+		a.ProspectName,
+		a.ProspectPhone,
+		a.ProspectEmail,
+		a.AgentUserID,
+		a.StartsAt,
+		a.EndsAt,
+		a.Status,
+		a.Notes,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteAppointmentRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Appointment, error) {
+	query := `SELECT` + sqliteAppointmentColumns + `
+	FROM appointments
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	a, err := scanSQLiteAppointment(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *sqliteAppointmentRepo) List(ctx context.Context, tenantID string, f models.AppointmentFilter) ([]*models.Appointment, error) {
+	where, args := appointmentFilterSQL(f)
+	query := `SELECT` + sqliteAppointmentColumns + `
+	FROM appointments
+	WHERE tenant_id = ? AND deleted = 0` + where + `
+	ORDER BY starts_at, id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Appointment
+	for rows.Next() {
+		a, err := scanSQLiteAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteAppointmentRepo) Update(ctx context.Context, a *models.Appointment) error {
+	a.LastModified = time.Now().UTC()
+	query := `
+	UPDATE appointments
+	SET property_id = ?, buyer_id = ?, prospect_name = ?, prospect_phone = ?, prospect_email = ?, agent_user_id = ?,
+	    starts_at = ?, ends_at = ?, status = ?, notes = ?, modified_by = ?, last_modified = ?, deleted = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.PropertyID,
+		a.BuyerID,
+		a.ProspectName,
+		a.ProspectPhone,
+		a.ProspectEmail,
+		a.AgentUserID,
+		a.StartsAt,
+		a.EndsAt,
+		a.Status,
+		a.Notes,
+		a.ModifiedBy,
+		a.LastModified,
+		boolToInt(a.Deleted),
+		a.TenantID,
+		a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanSQLiteAppointment(row interface{ Scan(...any) error }) (*models.Appointment, error) {
+	var a models.Appointment
+	var deletedInt int
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.PropertyID,
+		&a.BuyerID,
+		&a.ProspectName,
+		&a.ProspectPhone,
+		&a.ProspectEmail,
+		&a.AgentUserID,
+		&a.StartsAt,
+		&a.EndsAt,
+		&a.Status,
+		&a.Notes,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&deletedInt,
+	); err != nil {
+		return nil, err
+	}
+	a.Deleted = deletedInt != 0
+	return &a, nil
+}
@@ -0,0 +1,138 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteAttachmentRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteAttachmentRepo(db *sql.DB) AttachmentRepo {
+	return &sqliteAttachmentRepo{db: db}
+}
+
+const sqliteAttachmentColumns = `
+	id, tenant_id, entity_type, entity_id, category, file_name, mime_type, size_bytes, checksum,
+	storage_key, thumbnail_key, uploaded_by, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *sqliteAttachmentRepo) Create(ctx context.Context, a *models.Attachment) (int64, error) {
+	if a.TenantID == "" || a.EntityType == "" || a.EntityID == 0 || a.StorageKey == "" || a.CreatedBy == "" || a.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	query := `
+	INSERT INTO attachments (
+	  tenant_id, entity_type, entity_id, category, file_name, mime_type, size_bytes, checksum,
+	  storage_key, thumbnail_key, uploaded_by, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.TenantID,
+		a.EntityType,
+		a.EntityID,
+		a.Category,
+		a.FileName,
+		a.MimeType,
+		a.SizeBytes,
+		a.Checksum,
+		a.StorageKey,
+		a.ThumbnailKey,
+		a.UploadedBy,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.ModifiedBy,
+		a.LastModified,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteAttachmentRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Attachment, error) {
+	query := `SELECT` + sqliteAttachmentColumns + `
+	FROM attachments
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	a, err := scanSQLiteAttachment(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *sqliteAttachmentRepo) ListByEntity(ctx context.Context, tenantID, entityType string, entityID int64) ([]*models.Attachment, error) {
+	query := `SELECT` + sqliteAttachmentColumns + `
+	FROM attachments
+	WHERE tenant_id = ? AND entity_type = ? AND entity_id = ? AND deleted = 0
+	ORDER BY created_at;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Attachment
+	for rows.Next() {
+		a, err := scanSQLiteAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteAttachmentRepo) Delete(ctx context.Context, tenantID, currentUser string, id int64) error {
+	query := `
+	UPDATE attachments
+	SET deleted = 1, modified_by = ?, last_modified = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, currentUser, time.Now().UTC(), tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanSQLiteAttachment(row interface{ Scan(...any) error }) (*models.Attachment, error) {
+	var a models.Attachment
+	var deletedInt int
+	if err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.EntityType,
+		&a.EntityID,
+		&a.Category,
+		&a.FileName,
+		&a.MimeType,
+		&a.SizeBytes,
+		&a.Checksum,
+		&a.StorageKey,
+		&a.ThumbnailKey,
+		&a.UploadedBy,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.ModifiedBy,
+		&a.LastModified,
+		&deletedInt,
+	); err != nil {
+		return nil, err
+	}
+	a.Deleted = deletedInt != 0
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return &a, nil
+}
@@ -28,6 +28,8 @@ func NewSQLiteBuyerRepo(dbPath string) (BuyerRepo, error) {
 	  last_name TEXT NOT NULL,
 	  email TEXT NOT NULL,
 	  phone TEXT,
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
 	  created_by TEXT NOT NULL,
 	  created_at DATETIME NOT NULL,
 	  modified_by TEXT NOT NULL,
@@ -49,17 +51,20 @@ func (r *sqliteBuyerRepo) Create(ctx context.Context, b *models.Buyer) (int64, e
 	now := time.Now().UTC()
 	b.CreatedAt = now
 	b.LastModified = now
+	b.OwnerID = recordOwner(ctx, b.OwnerID)
 	query := `
 	INSERT INTO buyers (
-	  tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		b.TenantID,
 		b.FirstName,
 		b.LastName,
 		b.Email,
 		b.Phone,
+		b.OwnerID,
+		b.AssignedTo,
 		b.CreatedBy,
 		b.CreatedAt,
 		b.ModifiedBy,
@@ -72,12 +77,13 @@ func (r *sqliteBuyerRepo) Create(ctx context.Context, b *models.Buyer) (int64, e
 }
 
 func (r *sqliteBuyerRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Buyer, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM buyers
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 	var b models.Buyer
 	var deletedInt int
 	err := row.Scan(
@@ -87,6 +93,8 @@ func (r *sqliteBuyerRepo) GetByID(ctx context.Context, tenantID string, id int64
 		&b.LastName,
 		&b.Email,
 		&b.Phone,
+		&b.OwnerID,
+		&b.AssignedTo,
 		&b.CreatedBy,
 		&b.CreatedAt,
 		&b.ModifiedBy,
@@ -104,12 +112,13 @@ func (r *sqliteBuyerRepo) GetByID(ctx context.Context, tenantID string, id int64
 }
 
 func (r *sqliteBuyerRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Buyer, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, first_name, last_name, email, phone, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, first_name, last_name, email, phone, owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM buyers
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +134,8 @@ func (r *sqliteBuyerRepo) ListAll(ctx context.Context, tenantID string) ([]*mode
 			&b.LastName,
 			&b.Email,
 			&b.Phone,
+			&b.OwnerID,
+			&b.AssignedTo,
 			&b.CreatedBy,
 			&b.CreatedAt,
 			&b.ModifiedBy,
@@ -154,11 +165,12 @@ func (r *sqliteBuyerRepo) Update(ctx context.Context, b *models.Buyer) error {
 	SET first_name = ?, last_name = ?, email = ?, phone = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		b.FirstName,
 		b.LastName,
 		b.Email,
 		b.Phone,
+		b.AssignedTo,
 		b.ModifiedBy,
 		b.LastModified,
 		boolToInt(b.Deleted),
@@ -181,7 +193,7 @@ func (r *sqliteBuyerRepo) Delete(ctx context.Context, tenantID string, id int64)
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
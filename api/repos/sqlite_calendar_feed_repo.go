@@ -0,0 +1,67 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteCalendarFeedRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteCalendarFeedRepo) Create(ctx context.Context, t *models.CalendarFeedToken) (int64, error) {
+	if t.TenantID == "" || t.UserID == 0 || t.TokenHash == "" {
+		return 0, errors.New("missing required fields or tenant info")
+	}
+	t.CreatedAt = time.Now().UTC()
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO calendar_feed_tokens (tenant_id, user_id, token_hash, created_at)
+	VALUES (?, ?, ?, ?);
+	`, t.TenantID, t.UserID, t.TokenHash, t.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteCalendarFeedRepo) GetByHash(ctx context.Context, tokenHash string) (*models.CalendarFeedToken, error) {
+	var t models.CalendarFeedToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, token_hash, created_at, last_used_at, revoked_at
+	FROM calendar_feed_tokens
+	WHERE token_hash = ? AND revoked_at IS NULL;
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.CreatedAt,
+		&t.LastUsedAt,
+		&t.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *sqliteCalendarFeedRepo) RevokeForUser(ctx context.Context, tenantID string, userID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE calendar_feed_tokens SET revoked_at = ?
+	WHERE tenant_id = ? AND user_id = ? AND revoked_at IS NULL;
+	`, time.Now().UTC(), tenantID, userID)
+	return err
+}
+
+func (r *sqliteCalendarFeedRepo) Touch(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE calendar_feed_tokens SET last_used_at = ? WHERE id = ?;`, time.Now().UTC(), id)
+	return err
+}
@@ -31,6 +31,9 @@ func NewSQLiteCommissionRepo(dbPath string) (CommissionRepo, error) {
 	  rate_or_amount    REAL    NOT NULL,
 	  calculated_amount REAL    NOT NULL,
 	  memo              TEXT,
+	  approved_by       TEXT    NOT NULL DEFAULT '',
+	  approved_at       DATETIME,
+	  owner_id          INTEGER NOT NULL DEFAULT 0,
 	  created_by        TEXT    NOT NULL,
 	  created_at        DATETIME NOT NULL,
 	  modified_by       TEXT    NOT NULL,
@@ -60,15 +63,16 @@ func (r *sqliteCommissionRepo) Create(ctx context.Context, comm *models.Commissi
 	now := time.Now().UTC()
 	comm.CreatedAt = now
 	comm.LastModified = now
+	comm.OwnerID = recordOwner(ctx, comm.OwnerID)
 
 	query := `
 	INSERT INTO commissions (
 	  tenant_id, transaction_type, transaction_id, beneficiary_id,
 	  commission_type, rate_or_amount, calculated_amount, memo,
-	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  owner_id, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		comm.TenantID,
 		comm.TransactionType,
 		comm.TransactionID,
@@ -77,6 +81,7 @@ func (r *sqliteCommissionRepo) Create(ctx context.Context, comm *models.Commissi
 		comm.RateOrAmount,
 		comm.CalculatedAmount,
 		comm.Memo,
+		comm.OwnerID,
 		comm.CreatedBy,
 		comm.CreatedAt,
 		comm.ModifiedBy,
@@ -89,14 +94,15 @@ func (r *sqliteCommissionRepo) Create(ctx context.Context, comm *models.Commissi
 }
 
 func (r *sqliteCommissionRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Commission, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
 	SELECT id, tenant_id, transaction_type, transaction_id, beneficiary_id,
-	       commission_type, rate_or_amount, calculated_amount, memo,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       commission_type, rate_or_amount, calculated_amount, memo, approved_by, approved_at,
+	       owner_id, created_by, created_at, modified_by, last_modified, deleted
 	FROM commissions
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var comm models.Commission
 	var deletedInt int
@@ -110,6 +116,9 @@ func (r *sqliteCommissionRepo) GetByID(ctx context.Context, tenantID string, id
 		&comm.RateOrAmount,
 		&comm.CalculatedAmount,
 		&comm.Memo,
+		&comm.ApprovedBy,
+		&comm.ApprovedAt,
+		&comm.OwnerID,
 		&comm.CreatedBy,
 		&comm.CreatedAt,
 		&comm.ModifiedBy,
@@ -127,14 +136,15 @@ func (r *sqliteCommissionRepo) GetByID(ctx context.Context, tenantID string, id
 }
 
 func (r *sqliteCommissionRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Commission, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
 	SELECT id, tenant_id, transaction_type, transaction_id, beneficiary_id,
-	       commission_type, rate_or_amount, calculated_amount, memo,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       commission_type, rate_or_amount, calculated_amount, memo, approved_by, approved_at,
+	       owner_id, created_by, created_at, modified_by, last_modified, deleted
 	FROM commissions
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -154,6 +164,9 @@ func (r *sqliteCommissionRepo) ListAll(ctx context.Context, tenantID string) ([]
 			&comm.RateOrAmount,
 			&comm.CalculatedAmount,
 			&comm.Memo,
+			&comm.ApprovedBy,
+			&comm.ApprovedAt,
+			&comm.OwnerID,
 			&comm.CreatedBy,
 			&comm.CreatedAt,
 			&comm.ModifiedBy,
@@ -183,10 +196,10 @@ func (r *sqliteCommissionRepo) Update(ctx context.Context, comm *models.Commissi
 	UPDATE commissions
 	SET transaction_type = ?, transaction_id = ?, beneficiary_id = ?,
 	    commission_type = ?, rate_or_amount = ?, calculated_amount = ?, memo = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    approved_by = ?, approved_at = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		comm.TransactionType,
 		comm.TransactionID,
 		comm.BeneficiaryID,
@@ -194,6 +207,8 @@ func (r *sqliteCommissionRepo) Update(ctx context.Context, comm *models.Commissi
 		comm.RateOrAmount,
 		comm.CalculatedAmount,
 		comm.Memo,
+		comm.ApprovedBy,
+		comm.ApprovedAt,
 		comm.ModifiedBy,
 		comm.LastModified,
 		boolToInt(comm.Deleted),
@@ -216,7 +231,7 @@ func (r *sqliteCommissionRepo) Delete(ctx context.Context, tenantID string, id i
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -233,7 +248,7 @@ func (r *sqliteCommissionRepo) TotalCommissionByBeneficiary(ctx context.Context,
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY beneficiary_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -257,6 +272,7 @@ func (r *sqliteCommissionRepo) GetCommissionDetailsForBeneficiary(
 	beneficiaryID int64,
 ) ([]*models.Commission, error) {
 
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "beneficiary_id")
 	query := `
         SELECT 
           id,
@@ -268,6 +284,9 @@ func (r *sqliteCommissionRepo) GetCommissionDetailsForBeneficiary(
           rate_or_amount,
           calculated_amount,
           memo,
+          approved_by,
+          approved_at,
+          owner_id,
           created_by,
           created_at,
           modified_by,
@@ -276,9 +295,9 @@ func (r *sqliteCommissionRepo) GetCommissionDetailsForBeneficiary(
         FROM commissions
         WHERE tenant_id = ? 
           AND beneficiary_id = ? 
-          AND deleted = 0;
+          AND deleted = 0` + scope + `;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID, beneficiaryID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, beneficiaryID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -297,6 +316,9 @@ func (r *sqliteCommissionRepo) GetCommissionDetailsForBeneficiary(
 			&c.RateOrAmount,
 			&c.CalculatedAmount,
 			&c.Memo,
+			&c.ApprovedBy,
+			&c.ApprovedAt,
+			&c.OwnerID,
 			&c.CreatedBy,
 			&c.CreatedAt,
 			&c.ModifiedBy,
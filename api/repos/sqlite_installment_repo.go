@@ -62,7 +62,7 @@ func (r *sqliteInstallmentRepo) Create(ctx context.Context, inst *models.Install
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		inst.TenantID,
 		inst.PlanID,
 		inst.SequenceNumber,
@@ -90,7 +90,7 @@ func (r *sqliteInstallmentRepo) GetByID(ctx context.Context, tenantID string, id
 	FROM installments
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var inst models.Installment
 	var deletedInt int
@@ -128,7 +128,7 @@ func (r *sqliteInstallmentRepo) ListAll(ctx context.Context, tenantID string) ([
 	FROM installments
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +170,7 @@ func (r *sqliteInstallmentRepo) ListByPlan(ctx context.Context, tenantID string,
 	FROM installments
 	WHERE tenant_id = ? AND plan_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID, planID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, planID)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +222,7 @@ func (r *sqliteInstallmentRepo) Update(ctx context.Context, inst *models.Install
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		inst.PlanID,
 		inst.SequenceNumber,
 		inst.DueDate,
@@ -253,7 +253,7 @@ func (r *sqliteInstallmentRepo) Delete(ctx context.Context, tenantID string, id
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -261,3 +261,84 @@ func (r *sqliteInstallmentRepo) Delete(ctx context.Context, tenantID string, id
 	)
 	return err
 }
+
+func (r *sqliteInstallmentRepo) ListUnpaidDueBefore(ctx context.Context, before time.Time) ([]*models.Installment, error) {
+	query := `
+	SELECT id, tenant_id, plan_id, sequence_number, due_date, amount_due, amount_paid, status, late_fee, paid_date,
+	       created_by, created_at, modified_by, last_modified, deleted
+	FROM installments
+	WHERE due_date < ? AND status <> ? AND amount_paid < amount_due AND deleted = 0
+	ORDER BY tenant_id, due_date;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, before, models.InstallmentPaid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.Installment
+	for rows.Next() {
+		var inst models.Installment
+		var deletedInt int
+		if err := rows.Scan(
+			&inst.ID,
+			&inst.TenantID,
+			&inst.PlanID,
+			&inst.SequenceNumber,
+			&inst.DueDate,
+			&inst.AmountDue,
+			&inst.AmountPaid,
+			&inst.Status,
+			&inst.LateFee,
+			&inst.PaidDate,
+			&inst.CreatedBy,
+			&inst.CreatedAt,
+			&inst.ModifiedBy,
+			&inst.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		inst.Deleted = deletedInt != 0
+		out = append(out, &inst)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteInstallmentRepo) ApplyPayment(ctx context.Context, consumer, eventID, tenantID string, installmentID int64, delta float64, paidDate time.Time, modifiedBy string) (bool, error) {
+	var applied bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		claimed, err := sqliteClaimEvent(ctx, tx, consumer, eventID)
+		if err != nil || !claimed {
+			return err
+		}
+		query := `
+		UPDATE installments
+		SET amount_paid = amount_paid + ?,
+		    status = CASE WHEN amount_paid + ? >= amount_due THEN ?
+		                  WHEN status = ? THEN ? ELSE status END,
+		    paid_date = CASE WHEN amount_paid + ? >= amount_due AND status <> ? THEN ? ELSE paid_date END,
+		    modified_by = ?, last_modified = ?
+		WHERE tenant_id = ? AND id = ? AND deleted = 0;
+		`
+		res, err := tx.ExecContext(ctx, query,
+			delta,
+			delta, models.InstallmentPaid,
+			models.InstallmentPaid, models.InstallmentPending,
+			delta, models.InstallmentPaid, paidDate,
+			modifiedBy,
+			time.Now().UTC(),
+			tenantID,
+			installmentID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
@@ -8,7 +8,7 @@ import (
 	"errors"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
 
@@ -33,6 +33,9 @@ func NewSQLiteInstallmentPlanRepo(dbPath string) (InstallmentPlanRepo, error) {
 	  frequency TEXT NOT NULL,
 	  first_installment DATETIME NOT NULL,
 	  interest_rate REAL NOT NULL,
+	  sale_id INTEGER NOT NULL DEFAULT 0,
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
 	  created_by TEXT NOT NULL,
 	  created_at DATETIME NOT NULL,
 	  modified_by TEXT NOT NULL,
@@ -41,6 +44,7 @@ func NewSQLiteInstallmentPlanRepo(dbPath string) (InstallmentPlanRepo, error) {
 	);
 	CREATE INDEX IF NOT EXISTS idx_installmentplans_tenant ON installment_plans(tenant_id);
 	CREATE INDEX IF NOT EXISTS idx_installmentplans_property ON installment_plans(property_id);
+	CREATE INDEX IF NOT EXISTS idx_installmentplans_sale ON installment_plans(tenant_id, sale_id);
 	`
 	if _, err := db.Exec(schema); err != nil {
 		return nil, err
@@ -55,14 +59,15 @@ func (r *sqliteInstallmentPlanRepo) Create(ctx context.Context, p *models.Instal
 	now := time.Now().UTC()
 	p.CreatedAt = now
 	p.LastModified = now
+	p.OwnerID = recordOwner(ctx, p.OwnerID)
 
 	query := `
 	INSERT INTO installment_plans (
-	  tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	  owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		p.TenantID,
 		p.PropertyID,
 		p.BuyerID,
@@ -72,25 +77,29 @@ func (r *sqliteInstallmentPlanRepo) Create(ctx context.Context, p *models.Instal
 		p.Frequency,
 		p.FirstInstallment,
 		p.InterestRate,
+		p.SaleID,
+		p.OwnerID,
+		p.AssignedTo,
 		p.CreatedBy,
 		p.CreatedAt,
 		p.ModifiedBy,
 		p.LastModified,
 	)
 	if err != nil {
-		return 0, err
+		return 0, sqlitePlanSaleConflict(err)
 	}
 	return res.LastInsertId()
 }
 
 func (r *sqliteInstallmentPlanRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var p models.InstallmentPlan
 	var deletedInt int
@@ -105,6 +114,9 @@ func (r *sqliteInstallmentPlanRepo) GetByID(ctx context.Context, tenantID string
 		&p.Frequency,
 		&p.FirstInstallment,
 		&p.InterestRate,
+		&p.SaleID,
+		&p.OwnerID,
+		&p.AssignedTo,
 		&p.CreatedBy,
 		&p.CreatedAt,
 		&p.ModifiedBy,
@@ -122,13 +134,14 @@ func (r *sqliteInstallmentPlanRepo) GetByID(ctx context.Context, tenantID string
 }
 
 func (r *sqliteInstallmentPlanRepo) ListAll(ctx context.Context, tenantID string) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +162,55 @@ func (r *sqliteInstallmentPlanRepo) ListAll(ctx context.Context, tenantID string
 			&p.Frequency,
 			&p.FirstInstallment,
 			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		p.Deleted = deletedInt != 0
+		out = append(out, &p)
+	}
+	return out, nil
+}
+
+func (r *sqliteInstallmentPlanRepo) ListByBuyer(ctx context.Context, tenantID string, buyerID int64) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
+	query := `
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	FROM installment_plans
+	WHERE tenant_id = ? AND buyer_id = ? AND deleted = 0` + scope + `;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, buyerID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.InstallmentPlan
+	for rows.Next() {
+		var p models.InstallmentPlan
+		var deletedInt int
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.PropertyID,
+			&p.BuyerID,
+			&p.TotalPrice,
+			&p.DownPayment,
+			&p.NumInstallments,
+			&p.Frequency,
+			&p.FirstInstallment,
+			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
 			&p.CreatedBy,
 			&p.CreatedAt,
 			&p.ModifiedBy,
@@ -164,13 +226,14 @@ func (r *sqliteInstallmentPlanRepo) ListAll(ctx context.Context, tenantID string
 }
 
 func (r *sqliteInstallmentPlanRepo) ListByPlan(ctx context.Context, tenantID string, planID int64) ([]*models.InstallmentPlan, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate,
-	       created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, sale_id,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM installment_plans
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID, planID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID, planID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +254,9 @@ func (r *sqliteInstallmentPlanRepo) ListByPlan(ctx context.Context, tenantID str
 			&p.Frequency,
 			&p.FirstInstallment,
 			&p.InterestRate,
+			&p.SaleID,
+			&p.OwnerID,
+			&p.AssignedTo,
 			&p.CreatedBy,
 			&p.CreatedAt,
 			&p.ModifiedBy,
@@ -219,10 +285,10 @@ func (r *sqliteInstallmentPlanRepo) Update(ctx context.Context, p *models.Instal
 	query := `
 	UPDATE installment_plans
 	SET property_id = ?, buyer_id = ?, total_price = ?, down_payment = ?, num_installments = ?, frequency = ?, first_installment = ?, interest_rate = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    sale_id = ?, assigned_to = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		p.PropertyID,
 		p.BuyerID,
 		p.TotalPrice,
@@ -231,13 +297,15 @@ func (r *sqliteInstallmentPlanRepo) Update(ctx context.Context, p *models.Instal
 		p.Frequency,
 		p.FirstInstallment,
 		p.InterestRate,
+		p.SaleID,
+		p.AssignedTo,
 		p.ModifiedBy,
 		p.LastModified,
 		boolToInt(p.Deleted),
 		p.TenantID,
 		p.ID,
 	)
-	return err
+	return sqlitePlanSaleConflict(err)
 }
 
 func (r *sqliteInstallmentPlanRepo) Delete(ctx context.Context, tenantID string, id int64) error {
@@ -253,7 +321,7 @@ func (r *sqliteInstallmentPlanRepo) Delete(ctx context.Context, tenantID string,
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -262,6 +330,25 @@ func (r *sqliteInstallmentPlanRepo) Delete(ctx context.Context, tenantID string,
 	return err
 }
 
+func (r *sqliteInstallmentPlanRepo) GetActiveBySale(ctx context.Context, tenantID string, saleID int64) (*models.InstallmentPlan, error) {
+	query := `
+	SELECT id
+	FROM installment_plans
+	WHERE tenant_id = ? AND sale_id = ? AND deleted = 0
+	ORDER BY id DESC
+	LIMIT 1;
+	`
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, saleID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, tenantID, id)
+}
+
 // SummarizeByPlan computes “amount_due − amount_paid” grouped by each plan.
 func (r *sqliteInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenantID string) ([]models.PlanSummary, error) {
 	query := `
@@ -271,7 +358,7 @@ func (r *sqliteInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenantI
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY plan_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -287,3 +374,13 @@ func (r *sqliteInstallmentPlanRepo) SummarizeByPlan(ctx context.Context, tenantI
 	}
 	return out, nil
 }
+
+// sqlitePlanSaleConflict maps a second live plan for a sale, refused by
+// idx_installmentplans_active_sale, to ErrSaleHasActivePlan.
+func sqlitePlanSaleConflict(err error) error {
+	var se sqlite3.Error
+	if errors.As(err, &se) && se.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return ErrSaleHasActivePlan
+	}
+	return err
+}
@@ -68,7 +68,7 @@ func (r *sqliteIntroductionsRepo) Create(ctx context.Context, intro *models.Intr
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		intro.TenantID,
 		intro.IntroducerID,
 		intro.IntroducedParty,
@@ -95,7 +95,7 @@ func (r *sqliteIntroductionsRepo) GetByID(ctx context.Context, tenantID string,
 	FROM introductions
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var intro models.Introductions
 	var deletedInt int
@@ -132,7 +132,7 @@ func (r *sqliteIntroductionsRepo) ListAll(ctx context.Context, tenantID string)
 	FROM introductions
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +183,7 @@ func (r *sqliteIntroductionsRepo) Update(ctx context.Context, intro *models.Intr
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		intro.IntroducerID,
 		intro.IntroducedParty,
 		intro.PropertyID,
@@ -213,7 +213,7 @@ func (r *sqliteIntroductionsRepo) Delete(ctx context.Context, tenantID string, i
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
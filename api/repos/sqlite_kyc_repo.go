@@ -0,0 +1,224 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteKYCRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteKYCRepo(db *sql.DB) KYCRepo {
+	return &sqliteKYCRepo{db: db}
+}
+
+func (r *sqliteKYCRepo) Create(ctx context.Context, k *models.BuyerKYC) (int64, error) {
+	if k.TenantID == "" || k.BuyerID == 0 || k.IDDocumentType == "" || k.IDDocumentNumber == "" || k.CreatedBy == "" || k.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	k.CreatedAt = now
+	k.LastModified = now
+	query := `
+	INSERT INTO buyer_kyc (
+	  tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id,
+	  address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes,
+	  created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		k.TenantID,
+		k.BuyerID,
+		k.IDDocumentType,
+		k.IDDocumentNumber,
+		k.IDDocumentExpiry,
+		k.IDDocumentAttachmentID,
+		k.AddressProofType,
+		k.AddressProofAttachmentID,
+		k.SourceOfFunds,
+		k.Status,
+		k.VerifiedBy,
+		k.VerifiedAt,
+		k.Notes,
+		k.CreatedBy,
+		k.CreatedAt,
+		k.ModifiedBy,
+		k.LastModified,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteKYCRepo) GetByBuyer(ctx context.Context, tenantID string, buyerID int64) (*models.BuyerKYC, error) {
+	query := `
+	SELECT id, tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id,
+	       address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes,
+	       created_by, created_at, modified_by, last_modified, deleted
+	FROM buyer_kyc
+	WHERE tenant_id = ? AND buyer_id = ? AND deleted = 0;
+	`
+	var k models.BuyerKYC
+	var deletedInt int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, buyerID).Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.BuyerID,
+		&k.IDDocumentType,
+		&k.IDDocumentNumber,
+		&k.IDDocumentExpiry,
+		&k.IDDocumentAttachmentID,
+		&k.AddressProofType,
+		&k.AddressProofAttachmentID,
+		&k.SourceOfFunds,
+		&k.Status,
+		&k.VerifiedBy,
+		&k.VerifiedAt,
+		&k.Notes,
+		&k.CreatedBy,
+		&k.CreatedAt,
+		&k.ModifiedBy,
+		&k.LastModified,
+		&deletedInt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	k.Deleted = deletedInt != 0
+	return &k, nil
+}
+
+func (r *sqliteKYCRepo) Update(ctx context.Context, k *models.BuyerKYC) error {
+	k.LastModified = time.Now().UTC()
+	query := `
+	UPDATE buyer_kyc
+	SET id_document_type = ?, id_document_number = ?, id_document_expiry = ?, id_document_attachment_id = ?,
+	    address_proof_type = ?, address_proof_attachment_id = ?, source_of_funds = ?, status = ?,
+	    verified_by = ?, verified_at = ?, notes = ?, modified_by = ?, last_modified = ?, deleted = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		k.IDDocumentType,
+		k.IDDocumentNumber,
+		k.IDDocumentExpiry,
+		k.IDDocumentAttachmentID,
+		k.AddressProofType,
+		k.AddressProofAttachmentID,
+		k.SourceOfFunds,
+		k.Status,
+		k.VerifiedBy,
+		k.VerifiedAt,
+		k.Notes,
+		k.ModifiedBy,
+		k.LastModified,
+		boolToInt(k.Deleted),
+		k.TenantID,
+		k.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteKYCRepo) CreateOverride(ctx context.Context, o *models.KYCOverride) (int64, error) {
+	if o.TenantID == "" || o.BuyerID == 0 || o.Action == "" || o.Reason == "" || o.GrantedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	query := `
+	INSERT INTO kyc_overrides (tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, '', NULL);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, o.TenantID, o.BuyerID, o.Action, o.Reason, o.GrantedBy, o.GrantedAt, o.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+const sqliteKYCOverrideColumns = `id, tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at`
+
+func (r *sqliteKYCRepo) ListOverrides(ctx context.Context, tenantID string, buyerID int64) ([]*models.KYCOverride, error) {
+	query := `SELECT ` + sqliteKYCOverrideColumns + `
+	FROM kyc_overrides
+	WHERE tenant_id = ? AND buyer_id = ?
+	ORDER BY granted_at DESC, id DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.KYCOverride
+	for rows.Next() {
+		o, err := scanKYCOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteKYCRepo) FindUsableOverride(ctx context.Context, tenantID string, buyerID int64, action string, now time.Time) (*models.KYCOverride, error) {
+	query := `SELECT ` + sqliteKYCOverrideColumns + `
+	FROM kyc_overrides
+	WHERE tenant_id = ? AND buyer_id = ? AND action = ? AND used_at IS NULL AND expires_at > ?
+	ORDER BY granted_at, id
+	LIMIT 1;
+	`
+	o, err := scanKYCOverride(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, buyerID, action, now))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return o, err
+}
+
+func (r *sqliteKYCRepo) UseOverride(ctx context.Context, tenantID string, id int64, usedBy string, usedAt time.Time) error {
+	query := `
+	UPDATE kyc_overrides
+	SET used_by = ?, used_at = ?
+	WHERE tenant_id = ? AND id = ? AND used_at IS NULL;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, usedBy, usedAt, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanKYCOverride is shared by both drivers; the column set is identical.
+func scanKYCOverride(row interface{ Scan(...any) error }) (*models.KYCOverride, error) {
+	var o models.KYCOverride
+	if err := row.Scan(
+		&o.ID,
+		&o.TenantID,
+		&o.BuyerID,
+		&o.Action,
+		&o.Reason,
+		&o.GrantedBy,
+		&o.GrantedAt,
+		&o.ExpiresAt,
+		&o.UsedBy,
+		&o.UsedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
@@ -69,7 +69,7 @@ func (r *sqliteLettingsRepo) Create(ctx context.Context, lt *models.Lettings) (i
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		lt.TenantID,
 		lt.PropertyID,
 		lt.TenantUserID,
@@ -97,7 +97,7 @@ func (r *sqliteLettingsRepo) GetByID(ctx context.Context, tenantID string, id in
 	FROM lettings
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var lt models.Lettings
 	var deletedInt int
@@ -135,7 +135,7 @@ func (r *sqliteLettingsRepo) ListAll(ctx context.Context, tenantID string) ([]*m
 	FROM lettings
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +187,7 @@ func (r *sqliteLettingsRepo) Update(ctx context.Context, lt *models.Lettings) er
 	    modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lt.PropertyID,
 		lt.TenantUserID,
 		lt.RentAmount,
@@ -218,7 +218,7 @@ func (r *sqliteLettingsRepo) Delete(ctx context.Context, lt *models.Lettings) er
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lt.ModifiedBy,
 		time.Now().UTC(),
 		lt.TenantUserID,
@@ -238,7 +238,7 @@ func (r *sqliteLettingsRepo) SummarizeRentRoll(ctx context.Context, tenantID str
            AND (end_date IS NULL OR DATE(end_date) > DATE('now'))
          GROUP BY property_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
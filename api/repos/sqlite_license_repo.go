@@ -0,0 +1,41 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteLicenseRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteLicenseRepo) Get(ctx context.Context, tenantID string) (*models.TenantLicense, error) {
+	var l models.TenantLicense
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT tenant_id, license_id, data, installed_by, installed_at
+	FROM tenant_licenses
+	WHERE tenant_id = ?;
+	`, tenantID).Scan(&l.TenantID, &l.LicenseID, &l.Data, &l.InstalledBy, &l.InstalledAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *sqliteLicenseRepo) Save(ctx context.Context, l *models.TenantLicense) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO tenant_licenses (tenant_id, license_id, data, installed_by, installed_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id) DO UPDATE SET
+	  license_id = excluded.license_id,
+	  data = excluded.data,
+	  installed_by = excluded.installed_by,
+	  installed_at = excluded.installed_at;
+	`, l.TenantID, l.LicenseID, l.Data, l.InstalledBy, l.InstalledAt)
+	return err
+}
@@ -55,7 +55,7 @@ func (r *sqliteLocationPricingRepo) Create(ctx context.Context, lp *models.Locat
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		lp.TenantID,
 		lp.ZipCode,
 		lp.City,
@@ -78,7 +78,7 @@ func (r *sqliteLocationPricingRepo) GetByID(ctx context.Context, tenantID string
 	FROM location_pricing
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 	var lp models.LocationPricing
 	var deletedInt int
 	err := row.Scan(
@@ -110,7 +110,7 @@ func (r *sqliteLocationPricingRepo) ListAll(ctx context.Context, tenantID string
 	FROM location_pricing
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +155,7 @@ func (r *sqliteLocationPricingRepo) Update(ctx context.Context, lp *models.Locat
 	SET zip_code = ?, city = ?, price_per_sqft = ?, effective_date = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		lp.ZipCode,
 		lp.City,
 		lp.PricePerSqFt,
@@ -182,7 +182,7 @@ func (r *sqliteLocationPricingRepo) Delete(ctx context.Context, tenantID string,
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -190,3 +190,60 @@ func (r *sqliteLocationPricingRepo) Delete(ctx context.Context, tenantID string,
 	)
 	return err
 }
+
+func (r *sqliteLocationPricingRepo) ImportBatch(ctx context.Context, inserts, updates []*models.LocationPricing) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		insertQuery := `
+		INSERT INTO location_pricing (
+		  tenant_id, zip_code, city, price_per_sqft, effective_date,
+		  created_by, created_at, modified_by, last_modified, deleted
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+		`
+		for _, lp := range inserts {
+			lp.CreatedAt = now
+			lp.LastModified = now
+			res, err := tx.ExecContext(ctx, insertQuery,
+				lp.TenantID,
+				lp.ZipCode,
+				lp.City,
+				lp.PricePerSqFt,
+				lp.EffectiveDate,
+				lp.CreatedBy,
+				lp.CreatedAt,
+				lp.ModifiedBy,
+				lp.LastModified,
+			)
+			if err != nil {
+				return err
+			}
+			if lp.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		}
+
+		updateQuery := `
+		UPDATE location_pricing
+		SET city = ?, price_per_sqft = ?, modified_by = ?, last_modified = ?
+		WHERE tenant_id = ? AND id = ? AND deleted = 0;
+		`
+		for _, lp := range updates {
+			lp.LastModified = now
+			res, err := tx.ExecContext(ctx, updateQuery,
+				lp.City,
+				lp.PricePerSqFt,
+				lp.ModifiedBy,
+				lp.LastModified,
+				lp.TenantID,
+				lp.ID,
+			)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,154 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteNotificationRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteNotificationRepo) ListPreferences(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.NotificationPreference, error) {
+	query := `
+	SELECT id, tenant_id, subject_type, subject_id, channel, address, opted_out, opted_out_at,
+	       created_by, created_at, modified_by, last_modified
+	FROM notification_preferences
+	WHERE tenant_id = ? AND subject_type = ? AND subject_id = ?
+	ORDER BY channel;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.NotificationPreference
+	for rows.Next() {
+		var p models.NotificationPreference
+		var optedOut int
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.SubjectType,
+			&p.SubjectID,
+			&p.Channel,
+			&p.Address,
+			&optedOut,
+			&p.OptedOutAt,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+		); err != nil {
+			return nil, err
+		}
+		p.OptedOut = optedOut != 0
+		out = append(out, &p)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteNotificationRepo) SavePreference(ctx context.Context, p *models.NotificationPreference) error {
+	if p.TenantID == "" || p.SubjectType == "" || p.SubjectID == 0 || p.Channel == "" || p.ModifiedBy == "" {
+		return errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	p.LastModified = now
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+		p.CreatedBy = p.ModifiedBy
+	}
+	query := `
+	INSERT INTO notification_preferences (
+	  tenant_id, subject_type, subject_id, channel, address, opted_out, opted_out_at,
+	  created_by, created_at, modified_by, last_modified
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id, subject_type, subject_id, channel) DO UPDATE
+	SET address = excluded.address, opted_out = excluded.opted_out, opted_out_at = excluded.opted_out_at,
+	    modified_by = excluded.modified_by, last_modified = excluded.last_modified;
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		p.TenantID,
+		p.SubjectType,
+		p.SubjectID,
+		p.Channel,
+		p.Address,
+		boolToInt(p.OptedOut),
+		p.OptedOutAt,
+		p.CreatedBy,
+		p.CreatedAt,
+		p.ModifiedBy,
+		p.LastModified,
+	)
+	return err
+}
+
+func (r *sqliteNotificationRepo) LogAttempt(ctx context.Context, a *models.NotificationAttempt) (int64, error) {
+	a.AttemptedAt = time.Now().UTC()
+	query := `
+	INSERT INTO notification_attempts (
+	  tenant_id, subject_type, subject_id, channel, recipient, subject, attempt, status, error, attempted_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.TenantID,
+		a.SubjectType,
+		a.SubjectID,
+		a.Channel,
+		a.Recipient,
+		a.Subject,
+		a.Attempt,
+		a.Status,
+		a.Error,
+		a.AttemptedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteNotificationRepo) ListAttempts(ctx context.Context, tenantID, subjectType string, subjectID int64, limit int) ([]*models.NotificationAttempt, error) {
+	query := `
+	SELECT id, tenant_id, subject_type, subject_id, channel, recipient, subject, attempt, status, error, attempted_at
+	FROM notification_attempts
+	WHERE tenant_id = ? AND (? = '' OR subject_type = ?) AND (? = 0 OR subject_id = ?)
+	ORDER BY attempted_at DESC, id DESC
+	LIMIT ?;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, subjectType, subjectType, subjectID, subjectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotificationAttempts(rows)
+}
+
+func scanNotificationAttempts(rows *sql.Rows) ([]*models.NotificationAttempt, error) {
+	var out []*models.NotificationAttempt
+	for rows.Next() {
+		var a models.NotificationAttempt
+		if err := rows.Scan(
+			&a.ID,
+			&a.TenantID,
+			&a.SubjectType,
+			&a.SubjectID,
+			&a.Channel,
+			&a.Recipient,
+			&a.Subject,
+			&a.Attempt,
+			&a.Status,
+			&a.Error,
+			&a.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
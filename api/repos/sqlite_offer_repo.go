@@ -0,0 +1,217 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteOfferRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteOfferRepo(db *sql.DB) OfferRepo {
+	return &sqliteOfferRepo{db: db}
+}
+
+const sqliteOfferColumns = `
+	id, tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type,
+	expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted`
+
+func (r *sqliteOfferRepo) Create(ctx context.Context, o *models.Offer) (int64, error) {
+	if o.TenantID == "" || o.PropertyID == 0 || o.BuyerID == 0 || o.Amount <= 0 || o.Status == "" || o.CreatedBy == "" || o.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	o.CreatedAt = now
+	o.LastModified = now
+	query := `
+	INSERT INTO offers (
+	  tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type,
+	  expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	args := []any{
+		o.TenantID,
+		o.PropertyID,
+		o.BuyerID,
+		o.Amount,
+		o.CounterAmount,
+		o.Conditions,
+		o.SaleType,
+		o.ExpiresAt,
+		o.Status,
+		o.Stage,
+		o.SaleID,
+		o.CreatedBy,
+		o.CreatedAt,
+		o.ModifiedBy,
+		o.LastModified,
+	}
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteOfferRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Offer, error) {
+	query := `SELECT` + sqliteOfferColumns + `
+	FROM offers
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	o, err := scanSQLiteOffer(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return o, err
+}
+
+func (r *sqliteOfferRepo) ListAll(ctx context.Context, tenantID string, propertyID int64) ([]*models.Offer, error) {
+	query := `SELECT` + sqliteOfferColumns + `
+	FROM offers
+	WHERE tenant_id = ? AND deleted = 0 AND (? = 0 OR property_id = ?)
+	ORDER BY created_at DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, propertyID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Offer
+	for rows.Next() {
+		o, err := scanSQLiteOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteOfferRepo) Update(ctx context.Context, o *models.Offer) error {
+	return r.update(ctx, conn(ctx, r.db), o)
+}
+
+func (r *sqliteOfferRepo) UpdateWithEvents(ctx context.Context, o *models.Offer, evs ...*models.OutboxEvent) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := r.update(ctx, tx, o); err != nil {
+			return err
+		}
+		return sqliteEnqueueOutbox(ctx, tx, evs)
+	})
+}
+
+func (r *sqliteOfferRepo) update(ctx context.Context, db dbConn, o *models.Offer) error {
+	o.LastModified = time.Now().UTC()
+	query := `
+	UPDATE offers
+	SET amount = ?, counter_amount = ?, conditions = ?, sale_type = ?, expires_at = ?,
+	    status = ?, stage = ?, sale_id = ?, modified_by = ?, last_modified = ?, deleted = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := db.ExecContext(ctx, query,
+		o.Amount,
+		o.CounterAmount,
+		o.Conditions,
+		o.SaleType,
+		o.ExpiresAt,
+		o.Status,
+		o.Stage,
+		o.SaleID,
+		o.ModifiedBy,
+		o.LastModified,
+		boolToInt(o.Deleted),
+		o.TenantID,
+		o.ID,
+	)
+	if err != nil {
+		return sqliteOfferAcceptConflict(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// sqliteOfferAcceptConflict maps a second accepted offer for a property,
+// refused by idx_offers_accepted_property, to ErrPropertyUnavailable.
+func sqliteOfferAcceptConflict(err error) error {
+	var se sqlite3.Error
+	if errors.As(err, &se) && se.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return ErrPropertyUnavailable
+	}
+	return err
+}
+
+func (r *sqliteOfferRepo) AddMilestone(ctx context.Context, m *models.OfferMilestone) (int64, error) {
+	if m.TenantID == "" || m.OfferID == 0 || m.Milestone == "" || m.CreatedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	m.CreatedAt = time.Now().UTC()
+	query := `
+	INSERT INTO offer_milestones (tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?);
+	`
+	args := []any{m.TenantID, m.OfferID, m.Milestone, m.ReachedAt, m.Notes, m.CreatedBy, m.CreatedAt}
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteOfferRepo) ListMilestones(ctx context.Context, tenantID string, offerID int64) ([]models.OfferMilestone, error) {
+	query := `
+	SELECT id, tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at
+	FROM offer_milestones
+	WHERE tenant_id = ? AND offer_id = ?
+	ORDER BY reached_at, id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.OfferMilestone
+	for rows.Next() {
+		var m models.OfferMilestone
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.OfferID, &m.Milestone, &m.ReachedAt, &m.Notes, &m.CreatedBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func scanSQLiteOffer(row interface{ Scan(...any) error }) (*models.Offer, error) {
+	var o models.Offer
+	var deletedInt int
+	if err := row.Scan(
+		&o.ID,
+		&o.TenantID,
+		&o.PropertyID,
+		&o.BuyerID,
+		&o.Amount,
+		&o.CounterAmount,
+		&o.Conditions,
+		&o.SaleType,
+		&o.ExpiresAt,
+		&o.Status,
+		&o.Stage,
+		&o.SaleID,
+		&o.CreatedBy,
+		&o.CreatedAt,
+		&o.ModifiedBy,
+		&o.LastModified,
+		&deletedInt,
+	); err != nil {
+		return nil, err
+	}
+	o.Deleted = deletedInt != 0
+	return &o, nil
+}
@@ -0,0 +1,146 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteOIDCRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteOIDCRepo) GetConfig(ctx context.Context, tenantID string) (*models.OIDCConfig, error) {
+	c, err := scanOIDCConfig(conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT tenant_id, enabled, issuer, client_id, client_secret, scopes, username_claim, tenant_claim, tenant_claim_value, role_claim, role_mappings, default_role, link_verified_email, modified_by, last_modified
+	FROM oidc_configs
+	WHERE tenant_id = ?;
+	`, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return c, err
+}
+
+func (r *sqliteOIDCRepo) SaveConfig(ctx context.Context, c *models.OIDCConfig) error {
+	mappings, err := json.Marshal(c.RoleMappings)
+	if err != nil {
+		return err
+	}
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO oidc_configs (tenant_id, enabled, issuer, client_id, client_secret, scopes, username_claim, tenant_claim, tenant_claim_value, role_claim, role_mappings, default_role, link_verified_email, modified_by, last_modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id) DO UPDATE SET
+	  enabled = excluded.enabled,
+	  issuer = excluded.issuer,
+	  client_id = excluded.client_id,
+	  client_secret = excluded.client_secret,
+	  scopes = excluded.scopes,
+	  username_claim = excluded.username_claim,
+	  tenant_claim = excluded.tenant_claim,
+	  tenant_claim_value = excluded.tenant_claim_value,
+	  role_claim = excluded.role_claim,
+	  role_mappings = excluded.role_mappings,
+	  default_role = excluded.default_role,
+	  link_verified_email = excluded.link_verified_email,
+	  modified_by = excluded.modified_by,
+	  last_modified = excluded.last_modified;
+	`, c.TenantID, c.Enabled, c.Issuer, c.ClientID, c.ClientSecret, strings.Join(c.Scopes, ","), c.UsernameClaim,
+		c.TenantClaim, c.TenantClaimValue, c.RoleClaim, string(mappings), c.DefaultRole, c.LinkVerifiedEmail, c.ModifiedBy, c.LastModified)
+	return err
+}
+
+func (r *sqliteOIDCRepo) DeleteConfig(ctx context.Context, tenantID string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM oidc_configs
+	WHERE tenant_id = ?;
+	`, tenantID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteOIDCRepo) CreateState(ctx context.Context, s *models.OIDCLoginState) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO oidc_login_states (state, tenant_id, nonce, code_verifier, redirect_uri, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?);
+	`, s.State, s.TenantID, s.Nonce, s.CodeVerifier, s.RedirectURI, s.ExpiresAt)
+	return err
+}
+
+func (r *sqliteOIDCRepo) TakeState(ctx context.Context, state string) (*models.OIDCLoginState, error) {
+	var s models.OIDCLoginState
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+		SELECT state, tenant_id, nonce, code_verifier, redirect_uri, expires_at
+		FROM oidc_login_states
+		WHERE state = ?;
+		`, state).Scan(&s.State, &s.TenantID, &s.Nonce, &s.CodeVerifier, &s.RedirectURI, &s.ExpiresAt)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state = ?;
+		`, state)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sqliteOIDCRepo) DeleteExpiredStates(ctx context.Context, now time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM oidc_login_states
+	WHERE expires_at < ?;
+	`, now)
+	return err
+}
+
+func (r *sqliteOIDCRepo) GetIdentity(ctx context.Context, tenantID, issuer, subject string) (*models.OIDCIdentity, error) {
+	var i models.OIDCIdentity
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT tenant_id, issuer, subject, user_id, created_at
+	FROM oidc_identities
+	WHERE tenant_id = ? AND issuer = ? AND subject = ?;
+	`, tenantID, issuer, subject).Scan(&i.TenantID, &i.Issuer, &i.Subject, &i.UserID, &i.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *sqliteOIDCRepo) LinkIdentity(ctx context.Context, i *models.OIDCIdentity) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO oidc_identities (tenant_id, issuer, subject, user_id, created_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id, issuer, subject) DO NOTHING;
+	`, i.TenantID, i.Issuer, i.Subject, i.UserID, i.CreatedAt)
+	return err
+}
@@ -0,0 +1,124 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteOutboxRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY next_attempt_at, created_at
+	LIMIT ?;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.OutboxPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+func (r *sqliteOutboxRepo) List(ctx context.Context, tenantID, status string, limit int) ([]*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE tenant_id = ? AND (? = '' OR status = ?)
+	ORDER BY created_at DESC
+	LIMIT ?;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+func (r *sqliteOutboxRepo) Get(ctx context.Context, tenantID, id string) (*models.OutboxEvent, error) {
+	query := `SELECT` + outboxColumns + `
+	FROM outbox_events
+	WHERE tenant_id = ? AND id = ?;
+	`
+	ev, err := scanOutboxEvent(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return ev, err
+}
+
+func (r *sqliteOutboxRepo) Update(ctx context.Context, ev *models.OutboxEvent) error {
+	query := `
+	UPDATE outbox_events
+	SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, dispatched_at = ?
+	WHERE id = ?;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		ev.Status,
+		ev.Attempts,
+		ev.NextAttemptAt,
+		ev.LastError,
+		ev.DispatchedAt,
+		ev.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// sqliteEnqueueOutbox writes evs to the outbox within tx.
+func sqliteEnqueueOutbox(ctx context.Context, tx *sql.Tx, evs []*models.OutboxEvent) error {
+	query := `
+	INSERT INTO outbox_events (
+	  id, tenant_id, topic, aggregate_type, aggregate_id, payload, status, attempts, next_attempt_at, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, ?);
+	`
+	now := time.Now().UTC()
+	for _, ev := range evs {
+		ev.Status = models.OutboxPending
+		ev.CreatedAt = now
+		ev.NextAttemptAt = now
+		if _, err := tx.ExecContext(ctx, query,
+			ev.ID,
+			ev.TenantID,
+			ev.Topic,
+			ev.AggregateType,
+			ev.AggregateID,
+			string(ev.Payload),
+			ev.Status,
+			ev.NextAttemptAt,
+			ev.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteClaimEvent records that consumer has processed eventID within tx.
+// It returns false if it already had, in which case the caller must not
+// apply the event again.
+func sqliteClaimEvent(ctx context.Context, tx *sql.Tx, consumer, eventID string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO processed_events (consumer, event_id, processed_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT (consumer, event_id) DO NOTHING;
+	`, consumer, eventID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,163 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqlitePasswordRepo struct {
+	db *sql.DB
+}
+
+func (r *sqlitePasswordRepo) CreateResetToken(ctx context.Context, t *models.PasswordResetToken) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO password_reset_tokens (tenant_id, user_id, token_hash, created_at, expires_at)
+	VALUES (?, ?, ?, ?, ?);
+	`, t.TenantID, t.UserID, t.TokenHash, t.CreatedAt, t.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqlitePasswordRepo) GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, token_hash, created_at, expires_at, used_at
+	FROM password_reset_tokens
+	WHERE token_hash = ?;
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&t.UsedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *sqlitePasswordRepo) UseResetToken(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE password_reset_tokens SET used_at = ?
+	WHERE id = ? AND used_at IS NULL;
+	`, at, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *sqlitePasswordRepo) ExpireResetTokens(ctx context.Context, tenantID string, userID int64, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE password_reset_tokens SET used_at = ?
+	WHERE tenant_id = ? AND user_id = ? AND used_at IS NULL;
+	`, at, tenantID, userID)
+	return err
+}
+
+func (r *sqlitePasswordRepo) AddHistory(ctx context.Context, tenantID string, userID int64, passwordHash string, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO password_history (tenant_id, user_id, password_hash, created_at)
+	VALUES (?, ?, ?, ?);
+	`, tenantID, userID, passwordHash, at)
+	return err
+}
+
+func (r *sqlitePasswordRepo) ListHistory(ctx context.Context, tenantID string, userID int64, limit int) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+	SELECT password_hash FROM password_history
+	WHERE tenant_id = ? AND user_id = ?
+	ORDER BY id DESC
+	LIMIT ?;
+	`, tenantID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqlitePasswordRepo) GetLoginFailure(ctx context.Context, tenantID, username, ip string) (*models.LoginFailure, error) {
+	var f models.LoginFailure
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT tenant_id, username, ip, failures, locked_until, last_failure
+	FROM login_failures
+	WHERE tenant_id = ? AND username = ? AND ip = ?;
+	`, tenantID, username, ip).Scan(
+		&f.TenantID,
+		&f.UserName,
+		&f.IP,
+		&f.Failures,
+		&f.LockedUntil,
+		&f.LastFailure,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *sqlitePasswordRepo) SaveLoginFailure(ctx context.Context, f *models.LoginFailure) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO login_failures (tenant_id, username, ip, failures, locked_until, last_failure)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id, username, ip) DO UPDATE SET
+	  failures = excluded.failures,
+	  locked_until = excluded.locked_until,
+	  last_failure = excluded.last_failure;
+	`, f.TenantID, f.UserName, f.IP, f.Failures, f.LockedUntil, f.LastFailure)
+	return err
+}
+
+func (r *sqlitePasswordRepo) ClearLoginFailures(ctx context.Context, tenantID, username, ip string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM login_failures
+	WHERE tenant_id = ? AND username = ? AND (? = '' OR ip = ?);
+	`, tenantID, username, ip, ip)
+	return err
+}
+
+func (r *sqlitePasswordRepo) ListLockouts(ctx context.Context, tenantID string, at time.Time) ([]*models.LoginFailure, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+	SELECT tenant_id, username, ip, failures, locked_until, last_failure
+	FROM login_failures
+	WHERE tenant_id = ? AND locked_until > ?
+	ORDER BY locked_until DESC;
+	`, tenantID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.LoginFailure
+	for rows.Next() {
+		var f models.LoginFailure
+		if err := rows.Scan(&f.TenantID, &f.UserName, &f.IP, &f.Failures, &f.LockedUntil, &f.LastFailure); err != nil {
+			return nil, err
+		}
+		out = append(out, &f)
+	}
+	return out, rows.Err()
+}
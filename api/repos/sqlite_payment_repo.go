@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -44,6 +45,27 @@ func NewSQLitePaymentRepo(dbPath string) (PaymentRepo, error) {
 }
 
 func (r *sqlitePaymentRepo) Create(ctx context.Context, p *models.Payment) (int64, error) {
+	return r.insert(ctx, conn(ctx, r.db), p)
+}
+
+// CreateWithEvents inserts p and queues evs in the outbox in one
+// transaction. Each event's AggregateID is set to the new payment's ID.
+func (r *sqlitePaymentRepo) CreateWithEvents(ctx context.Context, p *models.Payment, evs ...*models.OutboxEvent) (int64, error) {
+	var id int64
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		if id, err = r.insert(ctx, tx, p); err != nil {
+			return err
+		}
+		for _, ev := range evs {
+			ev.AggregateID = id
+		}
+		return sqliteEnqueueOutbox(ctx, tx, evs)
+	})
+	return id, err
+}
+
+func (r *sqlitePaymentRepo) insert(ctx context.Context, db dbConn, p *models.Payment) (int64, error) {
 	if p.TenantID == "" || p.InstallmentID == 0 || p.AmountPaid <= 0 || p.PaymentMethod == "" || p.CreatedBy == "" || p.ModifiedBy == "" {
 		return 0, errors.New("missing required fields or tenant/audit info")
 	}
@@ -56,7 +78,7 @@ func (r *sqlitePaymentRepo) Create(ctx context.Context, p *models.Payment) (int6
 	  created_by, created_at, modified_by, last_modified, deleted
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := db.ExecContext(ctx, query,
 		p.TenantID,
 		p.InstallmentID,
 		p.AmountPaid,
@@ -81,7 +103,7 @@ func (r *sqlitePaymentRepo) GetByID(ctx context.Context, tenantID string, id int
 	FROM payments
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 	var p models.Payment
 	var deletedInt int
 	err := row.Scan(
@@ -115,7 +137,7 @@ func (r *sqlitePaymentRepo) ListAll(ctx context.Context, tenantID string) ([]*mo
 	FROM payments
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -151,9 +173,55 @@ func (r *sqlitePaymentRepo) ListByInstallment(ctx context.Context, tenantID stri
 	SELECT id, tenant_id, installment_id, amount_paid, payment_date, payment_method, transaction_ref,
 	       created_by, created_at, modified_by, last_modified, deleted
 	FROM payments
-	WHERE tenant_id = ? installmentID = ? AND deleted = 0;
+	WHERE tenant_id = ? AND installment_id = ? AND deleted = 0;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, installmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Payment
+	for rows.Next() {
+		var p models.Payment
+		var deletedInt int
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.InstallmentID,
+			&p.AmountPaid,
+			&p.PaymentDate,
+			&p.PaymentMethod,
+			&p.TransactionRef,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		p.Deleted = deletedInt != 0
+		out = append(out, &p)
+	}
+	return out, nil
+}
+
+func (r *sqlitePaymentRepo) ListByInstallments(ctx context.Context, tenantID string, installmentIDs []int64) ([]*models.Payment, error) {
+	if len(installmentIDs) == 0 {
+		return nil, nil
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(installmentIDs)), ", ")
+	args := []any{tenantID}
+	for _, id := range installmentIDs {
+		args = append(args, id)
+	}
+	query := `
+	SELECT id, tenant_id, installment_id, amount_paid, payment_date, payment_method, transaction_ref,
+	       created_by, created_at, modified_by, last_modified, deleted
+	FROM payments
+	WHERE tenant_id = ? AND installment_id IN (` + marks + `) AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID, installmentID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -192,15 +260,33 @@ func (r *sqlitePaymentRepo) Update(ctx context.Context, p *models.Payment) error
 	if existing.Deleted {
 		return ErrNotFound
 	}
+	return r.update(ctx, conn(ctx, r.db), p, nil)
+}
+
+// UpdateWithEvents saves p and queues evs in the outbox in one
+// transaction, provided the payment still has prev's installment and
+// amount.
+func (r *sqlitePaymentRepo) UpdateWithEvents(ctx context.Context, p, prev *models.Payment, evs ...*models.OutboxEvent) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := r.update(ctx, tx, p, prev); err != nil {
+			return err
+		}
+		return sqliteEnqueueOutbox(ctx, tx, evs)
+	})
+}
+
+// update saves p. With prev set, it only does so if the stored payment is
+// undeleted and still has prev's installment and amount, and returns
+// ErrPaymentChanged otherwise.
+func (r *sqlitePaymentRepo) update(ctx context.Context, db dbConn, p, prev *models.Payment) error {
 	now := time.Now().UTC()
 	p.LastModified = now
 	query := `
 	UPDATE payments
 	SET installment_id = ?, amount_paid = ?, payment_date = ?, payment_method = ?, transaction_ref = ?,
 	    modified_by = ?, last_modified = ?, deleted = ?
-	WHERE tenant_id = ? AND id = ?;
-	`
-	_, err = r.db.ExecContext(ctx, query,
+	WHERE tenant_id = ? AND id = ?`
+	args := []any{
 		p.InstallmentID,
 		p.AmountPaid,
 		p.PaymentDate,
@@ -211,8 +297,21 @@ func (r *sqlitePaymentRepo) Update(ctx context.Context, p *models.Payment) error
 		boolToInt(p.Deleted),
 		p.TenantID,
 		p.ID,
-	)
-	return err
+	}
+	if prev != nil {
+		query += ` AND deleted = 0 AND installment_id = ? AND amount_paid = ?`
+		args = append(args, prev.InstallmentID, prev.AmountPaid)
+	}
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil || prev == nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPaymentChanged
+	}
+	return nil
 }
 
 func (r *sqlitePaymentRepo) Delete(ctx context.Context, tenantID string, id int64) error {
@@ -228,7 +327,7 @@ func (r *sqlitePaymentRepo) Delete(ctx context.Context, tenantID string, id int6
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -13,39 +13,43 @@ func NewSQLitePermissionRepo(db *sql.DB) PermissionRepo {
 	return &sqlitePermissionRepo{db: db}
 }
 
-func (r *sqlitePermissionRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
-	query := `INSERT INTO roles (name, description) VALUES (?,?)`
-	res, err := r.db.ExecContext(ctx, query, m.Name, m.Description)
+func (r *sqlitePermissionRepo) Create(ctx context.Context, m *models.Permission) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO permissions (name, description) VALUES (?,?)`,
+		m.Name, m.Description,
+	)
 	if err != nil {
 		return 0, err
 	}
 	return res.LastInsertId()
 }
 
-func (r *sqlitePermissionRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *sqlitePermissionRepo) ListAll(ctx context.Context) ([]*models.Permission, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id,name,COALESCE(description,'') FROM permissions ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []*models.Role
+	var out []*models.Permission
 	for rows.Next() {
-		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		var m models.Permission
+		if err := rows.Scan(&m.ID, &m.Name, &m.Description); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
-func (r *sqlitePermissionRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=?,description=? WHERE id=?`,
+func (r *sqlitePermissionRepo) Update(ctx context.Context, m *models.Permission) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE permissions SET name=?,description=? WHERE id=?`,
 		m.Name, m.Description, m.ID,
 	)
 	return err
 }
 
 func (r *sqlitePermissionRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=?`, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM permissions WHERE id=?`, id)
 	return err
 }
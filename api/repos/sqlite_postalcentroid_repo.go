@@ -0,0 +1,63 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqlitePostalCentroidRepo struct {
+	db *sql.DB
+}
+
+func NewSQLitePostalCentroidRepo(db *sql.DB) PostalCentroidRepo {
+	return &sqlitePostalCentroidRepo{db: db}
+}
+
+func (r *sqlitePostalCentroidRepo) GetByPostalCode(ctx context.Context, tenantID, postalCode string) (*models.PostalCentroid, error) {
+	query := `
+	SELECT tenant_id, postal_code, latitude, longitude
+	FROM postal_centroids
+	WHERE tenant_id = ? AND postal_code = ?;
+	`
+	var c models.PostalCentroid
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, postalCode).Scan(
+		&c.TenantID,
+		&c.PostalCode,
+		&c.Latitude,
+		&c.Longitude,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *sqlitePostalCentroidRepo) UpsertBatch(ctx context.Context, cs []*models.PostalCentroid) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO postal_centroids (tenant_id, postal_code, latitude, longitude)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (tenant_id, postal_code)
+	DO UPDATE SET latitude = excluded.latitude, longitude = excluded.longitude;
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, c := range cs {
+		if _, err := stmt.ExecContext(ctx, c.TenantID, c.PostalCode, c.Latitude, c.Longitude); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -28,6 +28,12 @@ func NewSQLitePropertyRepo(dbPath string) (PropertyRepo, error) {
 	  city TEXT NOT NULL,
 	  zip TEXT NOT NULL,
 	  listing_date DATETIME NOT NULL,
+	  status TEXT NOT NULL DEFAULT 'available',
+	  latitude REAL,
+	  longitude REAL,
+	  geo_source TEXT NOT NULL DEFAULT '',
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
 	  created_by TEXT NOT NULL,
 	  created_at DATETIME NOT NULL,
 	  modified_by TEXT NOT NULL,
@@ -35,6 +41,7 @@ func NewSQLitePropertyRepo(dbPath string) (PropertyRepo, error) {
 	  deleted INTEGER NOT NULL DEFAULT 0
 	);
 	CREATE INDEX IF NOT EXISTS idx_properties_tenant ON properties(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_properties_geo ON properties(tenant_id, latitude, longitude);
 	`
 	if _, err := db.Exec(schema); err != nil {
 		return nil, err
@@ -49,17 +56,25 @@ func (r *sqlitePropertyRepo) Create(ctx context.Context, p *models.Property) (in
 	now := time.Now().UTC()
 	p.CreatedAt = now
 	p.LastModified = now
+	p.OwnerID = recordOwner(ctx, p.OwnerID)
 	query := `
 	INSERT INTO properties (
-	  tenant_id, address, city, zip, listing_date, created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  tenant_id, address, city, zip, listing_date, status, latitude, longitude, geo_source,
+	  owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		p.TenantID,
 		p.Address,
 		p.City,
 		p.ZIP,
 		p.ListingDate,
+		p.Status,
+		p.Latitude,
+		p.Longitude,
+		p.GeoSource,
+		p.OwnerID,
+		p.AssignedTo,
 		p.CreatedBy,
 		p.CreatedAt,
 		p.ModifiedBy,
@@ -72,12 +87,14 @@ func (r *sqlitePropertyRepo) Create(ctx context.Context, p *models.Property) (in
 }
 
 func (r *sqlitePropertyRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Property, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, address, city, zip, listing_date, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, address, city, zip, listing_date, status, latitude, longitude, geo_source,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM properties
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 	var p models.Property
 	var deletedInt int
 	err := row.Scan(
@@ -87,6 +104,12 @@ func (r *sqlitePropertyRepo) GetByID(ctx context.Context, tenantID string, id in
 		&p.City,
 		&p.ZIP,
 		&p.ListingDate,
+		&p.Status,
+		&p.Latitude,
+		&p.Longitude,
+		&p.GeoSource,
+		&p.OwnerID,
+		&p.AssignedTo,
 		&p.CreatedBy,
 		&p.CreatedAt,
 		&p.ModifiedBy,
@@ -104,12 +127,14 @@ func (r *sqlitePropertyRepo) GetByID(ctx context.Context, tenantID string, id in
 }
 
 func (r *sqlitePropertyRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Property, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
-	SELECT id, tenant_id, address, city, zip, listing_date, created_by, created_at, modified_by, last_modified, deleted
+	SELECT id, tenant_id, address, city, zip, listing_date, status, latitude, longitude, geo_source,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM properties
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +150,12 @@ func (r *sqlitePropertyRepo) ListAll(ctx context.Context, tenantID string) ([]*m
 			&p.City,
 			&p.ZIP,
 			&p.ListingDate,
+			&p.Status,
+			&p.Latitude,
+			&p.Longitude,
+			&p.GeoSource,
+			&p.OwnerID,
+			&p.AssignedTo,
 			&p.CreatedBy,
 			&p.CreatedAt,
 			&p.ModifiedBy,
@@ -151,14 +182,20 @@ func (r *sqlitePropertyRepo) Update(ctx context.Context, p *models.Property) err
 	p.LastModified = now
 	query := `
 	UPDATE properties
-	SET address = ?, city = ?, zip = ?, listing_date = ?, modified_by = ?, last_modified = ?, deleted = ?
+	SET address = ?, city = ?, zip = ?, listing_date = ?, status = ?, latitude = ?, longitude = ?, geo_source = ?,
+	    assigned_to = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		p.Address,
 		p.City,
 		p.ZIP,
 		p.ListingDate,
+		p.Status,
+		p.Latitude,
+		p.Longitude,
+		p.GeoSource,
+		p.AssignedTo,
 		p.ModifiedBy,
 		p.LastModified,
 		boolToInt(p.Deleted),
@@ -181,7 +218,7 @@ func (r *sqlitePropertyRepo) Delete(ctx context.Context, tenantID string, id int
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -190,22 +227,24 @@ func (r *sqlitePropertyRepo) Delete(ctx context.Context, tenantID string, id int
 	return err
 }
 
-// SummarizeTopProperties sums all payments by joining installments → payments → properties.
+// SummarizeTopProperties sums all payments by joining payments → installments →
+// plans → properties, so it needs every domain in one database (DB_MODE=single).
 func (r *sqlitePropertyRepo) SummarizeTopProperties(ctx context.Context, tenantID string) ([]models.PropertyPaymentVolume, error) {
 	query := `
         SELECT p.id            AS property_id,
-               SUM(pay.amount) AS total_paid
+               SUM(pay.amount_paid) AS total_paid
           FROM payments AS pay
-          JOIN installments AS inst ON inst.id = pay.installment_id
-          JOIN properties AS p    ON p.id   = inst.property_id
-         WHERE pay.tenant_id = ? 
+          JOIN installments AS inst     ON inst.id = pay.installment_id
+          JOIN installment_plans AS pl  ON pl.id   = inst.plan_id
+          JOIN properties AS p          ON p.id    = pl.property_id
+         WHERE pay.tenant_id = ?
            AND pay.deleted   = 0
            AND inst.deleted  = 0
            AND p.deleted     = 0
          GROUP BY p.id
          ORDER BY total_paid DESC;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -221,3 +260,92 @@ func (r *sqlitePropertyRepo) SummarizeTopProperties(ctx context.Context, tenantI
 	}
 	return out, nil
 }
+
+// SearchByRadius prefilters on a bounding box in SQL, then applies the exact
+// haversine distance in Go.
+func (r *sqlitePropertyRepo) SearchByRadius(ctx context.Context, tenantID string, lat, lng, radiusKm float64, status string) ([]models.PropertySearchResult, error) {
+	box := boundingBox(lat, lng, radiusKm)
+	query := `
+	SELECT id, tenant_id, address, city, zip, listing_date, status, latitude, longitude, geo_source,
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	FROM properties
+	WHERE tenant_id = ? AND deleted = 0
+	  AND latitude BETWEEN ? AND ?
+	`
+	args := []any{tenantID, box.minLat, box.maxLat}
+	if !box.allLng {
+		query += ` AND longitude BETWEEN ? AND ?`
+		args = append(args, box.minLng, box.maxLng)
+	} else {
+		query += ` AND longitude IS NOT NULL`
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
+	query += scope
+	args = append(args, scopeArgs...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var candidates []*models.Property
+	for rows.Next() {
+		var p models.Property
+		var deletedInt int
+		if err := rows.Scan(
+			&p.ID,
+			&p.TenantID,
+			&p.Address,
+			&p.City,
+			&p.ZIP,
+			&p.ListingDate,
+			&p.Status,
+			&p.Latitude,
+			&p.Longitude,
+			&p.GeoSource,
+			&p.OwnerID,
+			&p.AssignedTo,
+			&p.CreatedBy,
+			&p.CreatedAt,
+			&p.ModifiedBy,
+			&p.LastModified,
+			&deletedInt,
+		); err != nil {
+			return nil, err
+		}
+		p.Deleted = deletedInt != 0
+		candidates = append(candidates, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return filterByRadius(candidates, lat, lng, radiusKm), nil
+}
+
+func (r *sqlitePropertyRepo) ApplyStatus(ctx context.Context, consumer, eventID, tenantID string, propertyID int64, status, modifiedBy string) (bool, error) {
+	var applied bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		claimed, err := sqliteClaimEvent(ctx, tx, consumer, eventID)
+		if err != nil || !claimed {
+			return err
+		}
+		query := `
+		UPDATE properties
+		SET status = ?, modified_by = ?, last_modified = ?
+		WHERE tenant_id = ? AND id = ? AND deleted = 0;
+		`
+		res, err := tx.ExecContext(ctx, query, status, modifiedBy, time.Now().UTC(), tenantID, propertyID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteReminderRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteReminderRepo) GetTemplate(ctx context.Context, tenantID, kind string) (*models.ReminderTemplate, error) {
+	query := `
+	SELECT id, tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified
+	FROM reminder_templates
+	WHERE tenant_id = ? AND kind = ?;
+	`
+	t, err := scanReminderTemplate(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, kind))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+func (r *sqliteReminderRepo) ListTemplates(ctx context.Context, tenantID string) ([]*models.ReminderTemplate, error) {
+	query := `
+	SELECT id, tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified
+	FROM reminder_templates
+	WHERE tenant_id = ?
+	ORDER BY kind;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.ReminderTemplate
+	for rows.Next() {
+		t, err := scanReminderTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteReminderRepo) SaveTemplate(ctx context.Context, t *models.ReminderTemplate) error {
+	if t.TenantID == "" || t.Kind == "" || t.ModifiedBy == "" {
+		return errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	t.LastModified = now
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+		t.CreatedBy = t.ModifiedBy
+	}
+	query := `
+	INSERT INTO reminder_templates (tenant_id, kind, subject, body, created_by, created_at, modified_by, last_modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id, kind) DO UPDATE
+	SET subject = excluded.subject, body = excluded.body,
+	    modified_by = excluded.modified_by, last_modified = excluded.last_modified;
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		t.TenantID,
+		t.Kind,
+		t.Subject,
+		t.Body,
+		t.CreatedBy,
+		t.CreatedAt,
+		t.ModifiedBy,
+		t.LastModified,
+	)
+	return err
+}
+
+func (r *sqliteReminderRepo) Claim(ctx context.Context, s *models.ReminderSend) (bool, error) {
+	s.SentAt = time.Now().UTC()
+	query := `
+	INSERT INTO reminder_sends (tenant_id, installment_id, kind, recipient, sent_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (tenant_id, installment_id, kind) DO NOTHING;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, s.TenantID, s.InstallmentID, s.Kind, s.Recipient, s.SentAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	s.ID, _ = res.LastInsertId()
+	return true, nil
+}
+
+func (r *sqliteReminderRepo) Release(ctx context.Context, tenantID string, installmentID int64, kind string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	DELETE FROM reminder_sends WHERE tenant_id = ? AND installment_id = ? AND kind = ?;
+	`, tenantID, installmentID, kind)
+	return err
+}
+
+func (r *sqliteReminderRepo) ListSends(ctx context.Context, tenantID string, installmentID int64) ([]*models.ReminderSend, error) {
+	query := `
+	SELECT id, tenant_id, installment_id, kind, recipient, sent_at
+	FROM reminder_sends
+	WHERE tenant_id = ? AND (? = 0 OR installment_id = ?)
+	ORDER BY sent_at DESC, id DESC;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, installmentID, installmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReminderSends(rows)
+}
+
+func scanReminderTemplate(row interface{ Scan(...any) error }) (*models.ReminderTemplate, error) {
+	var t models.ReminderTemplate
+	if err := row.Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.Kind,
+		&t.Subject,
+		&t.Body,
+		&t.CreatedBy,
+		&t.CreatedAt,
+		&t.ModifiedBy,
+		&t.LastModified,
+	); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanReminderSends(rows *sql.Rows) ([]*models.ReminderSend, error) {
+	var out []*models.ReminderSend
+	for rows.Next() {
+		var s models.ReminderSend
+		if err := rows.Scan(&s.ID, &s.TenantID, &s.InstallmentID, &s.Kind, &s.Recipient, &s.SentAt); err != nil {
+			return nil, err
+		}
+		out = append(out, &s)
+	}
+	return out, rows.Err()
+}
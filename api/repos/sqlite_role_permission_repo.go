@@ -9,21 +9,63 @@ import (
 
 type sqliteRolePermissionRepo struct{ db *sql.DB }
 
-func NewSQliteRolePermissionRepo(db *sql.DB) RolePermissionRepo {
+func NewSQLiteRolePermissionRepo(db *sql.DB) RolePermissionRepo {
 	return &sqliteRolePermissionRepo{db: db}
 }
 
-func (r *sqliteRolePermissionRepo) Create(ctx context.Context, m *models.RolePermission) (int64, error) {
-	query := `INSERT INTO roles (name, description) VALUES (?,?)`
-	res, err := r.db.ExecContext(ctx, query, m.Name, m.Description)
+func (r *sqliteRolePermissionRepo) Add(ctx context.Context, m *models.RolePermission) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT OR IGNORE INTO role_permissions (role_id, permission_id) VALUES (?,?)`, m.RoleID, m.PermissionID)
+	return err
+}
+
+func (r *sqliteRolePermissionRepo) Remove(ctx context.Context, roleID, permissionID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM role_permissions WHERE role_id=? AND permission_id=?`,
+		roleID, permissionID,
+	)
+	return err
+}
+
+func (r *sqliteRolePermissionRepo) Replace(ctx context.Context, roleID int64, permissionIDs []int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=?`, roleID); err != nil {
+			return err
+		}
+		for _, id := range permissionIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO role_permissions (role_id, permission_id) VALUES (?,?)`, roleID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqliteRolePermissionRepo) DeleteByRole(ctx context.Context, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=?`, roleID)
+	return err
+}
+
+func (r *sqliteRolePermissionRepo) ListByRole(ctx context.Context, roleID int64) ([]int64, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT permission_id FROM role_permissions WHERE role_id=? ORDER BY permission_id`, roleID,
+	)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
 	}
-	return res.LastInsertId()
+	return out, rows.Err()
 }
 
 func (r *sqliteRolePermissionRepo) ListAll(ctx context.Context) ([]*models.RolePermission, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT role_id,permission_id FROM role_permissions ORDER BY role_id,permission_id`)
 	if err != nil {
 		return nil, err
 	}
@@ -31,21 +73,10 @@ func (r *sqliteRolePermissionRepo) ListAll(ctx context.Context) ([]*models.RoleP
 	var out []*models.RolePermission
 	for rows.Next() {
 		var m models.RolePermission
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		if err := rows.Scan(&m.RoleID, &m.PermissionID); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
-}
-
-func (r *sqliteRolePermissionRepo) Update(ctx context.Context, m *models.RolePermission) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=?,description=? WHERE id=?`,
-		m.Name, m.Description, m.ID,
-	)
-	return err
-}
-
-func (r *sqliteRolePermissionRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=?`, id)
-	return err
+	return out, rows.Err()
 }
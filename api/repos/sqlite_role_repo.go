@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 )
@@ -14,16 +15,20 @@ func NewSQLiteRoleRepo(db *sql.DB) RoleRepo {
 }
 
 func (r *sqliteRoleRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
-	query := `INSERT INTO roles (name, description) VALUES (?,?)`
-	res, err := r.db.ExecContext(ctx, query, m.Name, m.Description)
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO roles (tenant_id, name, description) VALUES (?,?,?)`,
+		m.TenantID, m.Name, m.Description,
+	)
 	if err != nil {
 		return 0, err
 	}
 	return res.LastInsertId()
 }
 
-func (r *sqliteRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *sqliteRoleRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Role, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT id,tenant_id,name,COALESCE(description,'') FROM roles WHERE tenant_id=? ORDER BY name`, tenantID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -31,21 +36,49 @@ func (r *sqliteRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
 	var out []*models.Role
 	for rows.Next() {
 		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.Name, &m.Description); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
+	return out, rows.Err()
+}
+
+func (r *sqliteRoleRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Role, error) {
+	var m models.Role
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id,tenant_id,name,COALESCE(description,'') FROM roles WHERE tenant_id=? AND id=?`, tenantID, id,
+	).Scan(&m.ID, &m.TenantID, &m.Name, &m.Description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 func (r *sqliteRoleRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=?,description=? WHERE id=?`,
-		m.Name, m.Description, m.ID,
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE roles SET name=?,description=? WHERE tenant_id=? AND id=?`,
+		m.Name, m.Description, m.TenantID, m.ID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (r *sqliteRoleRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=?`, id)
-	return err
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM roles WHERE tenant_id=? AND id=?`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
@@ -28,6 +28,8 @@ func NewSQLiteSalesRepo(dbPath string) (SalesRepo, error) {
 	  sale_price    REAL    NOT NULL,
 	  sale_date     DATETIME NOT NULL,
 	  sale_type     TEXT    NOT NULL,
+	  owner_id      INTEGER NOT NULL DEFAULT 0,
+	  assigned_to   INTEGER NOT NULL DEFAULT 0,
 	  created_by    TEXT    NOT NULL,
 	  created_at    DATETIME NOT NULL,
 	  modified_by   TEXT    NOT NULL,
@@ -53,20 +55,23 @@ func (r *sqliteSalesRepo) Create(ctx context.Context, s *models.Sales) (int64, e
 	now := time.Now().UTC()
 	s.CreatedAt = now
 	s.LastModified = now
+	s.OwnerID = recordOwner(ctx, s.OwnerID)
 
 	query := `
 	INSERT INTO sales (
 	  tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	  owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		s.TenantID,
 		s.PropertyID,
 		s.BuyerID,
 		s.SalePrice,
 		s.SaleDate,
 		s.SaleType,
+		s.OwnerID,
+		s.AssignedTo,
 		s.CreatedBy,
 		s.CreatedAt,
 		s.ModifiedBy,
@@ -79,13 +84,14 @@ func (r *sqliteSalesRepo) Create(ctx context.Context, s *models.Sales) (int64, e
 }
 
 func (r *sqliteSalesRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Sales, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
 	SELECT id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM sales
-	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND id = ? AND deleted = 0` + scope + `;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{tenantID, id}, scopeArgs...)...)
 
 	var s models.Sales
 	var deletedInt int
@@ -97,6 +103,8 @@ func (r *sqliteSalesRepo) GetByID(ctx context.Context, tenantID string, id int64
 		&s.SalePrice,
 		&s.SaleDate,
 		&s.SaleType,
+		&s.OwnerID,
+		&s.AssignedTo,
 		&s.CreatedBy,
 		&s.CreatedAt,
 		&s.ModifiedBy,
@@ -114,13 +122,14 @@ func (r *sqliteSalesRepo) GetByID(ctx context.Context, tenantID string, id int64
 }
 
 func (r *sqliteSalesRepo) ListAll(ctx context.Context, tenantID string) ([]*models.Sales, error) {
+	scope, scopeArgs := scopeFilter(ctx, 0, "owner_id", "assigned_to")
 	query := `
 	SELECT id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type,
-	       created_by, created_at, modified_by, last_modified, deleted
+	       owner_id, assigned_to, created_by, created_at, modified_by, last_modified, deleted
 	FROM sales
-	WHERE tenant_id = ? AND deleted = 0;
+	WHERE tenant_id = ? AND deleted = 0` + scope + `;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append([]any{tenantID}, scopeArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +147,8 @@ func (r *sqliteSalesRepo) ListAll(ctx context.Context, tenantID string) ([]*mode
 			&s.SalePrice,
 			&s.SaleDate,
 			&s.SaleType,
+			&s.OwnerID,
+			&s.AssignedTo,
 			&s.CreatedBy,
 			&s.CreatedAt,
 			&s.ModifiedBy,
@@ -166,15 +177,16 @@ func (r *sqliteSalesRepo) Update(ctx context.Context, s *models.Sales) error {
 	query := `
 	UPDATE sales
 	SET property_id = ?, buyer_id = ?, sale_price = ?, sale_date = ?, sale_type = ?,
-	    modified_by = ?, last_modified = ?, deleted = ?
+	    assigned_to = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		s.PropertyID,
 		s.BuyerID,
 		s.SalePrice,
 		s.SaleDate,
 		s.SaleType,
+		s.AssignedTo,
 		s.ModifiedBy,
 		s.LastModified,
 		boolToInt(s.Deleted),
@@ -197,7 +209,7 @@ func (r *sqliteSalesRepo) Delete(ctx context.Context, tenantID string, id int64)
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -213,7 +225,7 @@ func (r *sqliteCommissionRepo) SQLiteSummarizeByBeneficiary(ctx context.Context,
          WHERE tenant_id = ? AND deleted = 0
          GROUP BY beneficiary_id;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +252,7 @@ func (r *sqliteSalesRepo) SummarizeByMonth(ctx context.Context, tenantID string)
          GROUP BY month
          ORDER BY month DESC;
     `
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
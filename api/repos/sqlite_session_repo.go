@@ -0,0 +1,98 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteSessionRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteSessionRepo) CreateRefreshToken(ctx context.Context, t *models.RefreshToken) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	INSERT INTO refresh_tokens (tenant_id, user_id, family_id, token_hash, created_at, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?);
+	`, t.TenantID, t.UserID, t.FamilyID, t.TokenHash, t.CreatedAt, t.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteSessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT id, tenant_id, user_id, family_id, token_hash, created_at, expires_at, used_at, revoked_at
+	FROM refresh_tokens
+	WHERE token_hash = ?;
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *sqliteSessionRepo) UseRefreshToken(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE refresh_tokens SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL;
+	`, at, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *sqliteSessionRepo) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+	UPDATE refresh_tokens SET revoked_at = ?
+	WHERE family_id = ? AND revoked_at IS NULL;
+	`, at, familyID)
+	return err
+}
+
+func (r *sqliteSessionRepo) RevokeUser(ctx context.Context, tenantID string, userID int64, at time.Time, by string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = ?
+		WHERE tenant_id = ? AND user_id = ? AND revoked_at IS NULL;
+		`, at, tenantID, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO session_revocations (tenant_id, user_id, revoked_at, revoked_by)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET revoked_at = excluded.revoked_at, revoked_by = excluded.revoked_by;
+		`, tenantID, userID, at, by)
+		return err
+	})
+}
+
+func (r *sqliteSessionRepo) RevokedAt(ctx context.Context, tenantID string, userID int64) (time.Time, error) {
+	var at time.Time
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+	SELECT revoked_at FROM session_revocations WHERE tenant_id = ? AND user_id = ?;
+	`, tenantID, userID).Scan(&at)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return at, err
+}
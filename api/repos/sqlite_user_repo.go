@@ -19,6 +19,13 @@ func NewSQLiteUserRepo(db *sql.DB) UserRepo {
 }
 
 func (r *sqliteUserRepo) Create(ctx context.Context, u *models.User) (int64, error) {
+	return r.CreateWithinSeats(ctx, u, 0)
+}
+
+// CreateWithinSeats inserts the user only while the tenant's count of
+// users is under seats. SQLite runs the single statement under its write
+// lock, so the count it checks cannot change before the row is added.
+func (r *sqliteUserRepo) CreateWithinSeats(ctx context.Context, u *models.User, seats int) (int64, error) {
 	if u.TenantID == "" ||
 		u.UserName == "" ||
 		u.PasswordHash == "" ||
@@ -36,11 +43,12 @@ func (r *sqliteUserRepo) Create(ctx context.Context, u *models.User) (int64, err
 
 	query := `
 	INSERT INTO users (
-	  tenant_id, username, password_hash, first_name, last_name, role, email, phone,
+	  tenant_id, username, password_hash, first_name, last_name, role, email, phone, team,
 	  created_by, created_at, modified_by, last_modified, deleted
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	) SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0
+	WHERE ? = 0 OR (SELECT COUNT(*) FROM users WHERE tenant_id = ? AND deleted = 0) < ?;
 	`
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
 		u.TenantID,
 		u.UserName,
 		u.PasswordHash,
@@ -49,25 +57,32 @@ func (r *sqliteUserRepo) Create(ctx context.Context, u *models.User) (int64, err
 		u.Role,
 		u.Email,
 		u.Phone,
+		u.Team,
 		u.CreatedBy,
 		u.CreatedAt,
 		u.ModifiedBy,
 		u.LastModified,
+		seats, u.TenantID, seats,
 	)
 	if err != nil {
 		return 0, err
 	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, ErrSeatLimit
+	}
 	return res.LastInsertId()
 }
 
 func (r *sqliteUserRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.User, error) {
 	query := `
-	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone,
+	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone, team,
 	       created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = ? AND id = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id)
 
 	var u models.User
 	var deletedInt int
@@ -81,6 +96,7 @@ func (r *sqliteUserRepo) GetByID(ctx context.Context, tenantID string, id int64)
 		&u.Role,
 		&u.Email,
 		&u.Phone,
+		&u.Team,
 		&u.CreatedBy,
 		&u.CreatedAt,
 		&u.ModifiedBy,
@@ -99,12 +115,12 @@ func (r *sqliteUserRepo) GetByID(ctx context.Context, tenantID string, id int64)
 
 func (r *sqliteUserRepo) GetByUsername(ctx context.Context, tenantID, username string) (*models.User, error) {
 	query := `
-	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone,
+	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone, team,
 	       created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = ? AND username = ? AND deleted = 0;
 	`
-	row := r.db.QueryRowContext(ctx, query, tenantID, username)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, username)
 
 	var u models.User
 	var deletedInt int
@@ -118,6 +134,7 @@ func (r *sqliteUserRepo) GetByUsername(ctx context.Context, tenantID, username s
 		&u.Role,
 		&u.Email,
 		&u.Phone,
+		&u.Team,
 		&u.CreatedBy,
 		&u.CreatedAt,
 		&u.ModifiedBy,
@@ -136,12 +153,12 @@ func (r *sqliteUserRepo) GetByUsername(ctx context.Context, tenantID, username s
 
 func (r *sqliteUserRepo) ListAll(ctx context.Context, tenantID string) ([]*models.User, error) {
 	query := `
-	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone,
+	SELECT id, tenant_id, username, password_hash, first_name, last_name, role, email, phone, team,
 	       created_by, created_at, modified_by, last_modified, deleted
 	FROM users
 	WHERE tenant_id = ? AND deleted = 0;
 	`
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +178,7 @@ func (r *sqliteUserRepo) ListAll(ctx context.Context, tenantID string) ([]*model
 			&u.Role,
 			&u.Email,
 			&u.Phone,
+			&u.Team,
 			&u.CreatedBy,
 			&u.CreatedAt,
 			&u.ModifiedBy,
@@ -188,10 +206,10 @@ func (r *sqliteUserRepo) Update(ctx context.Context, u *models.User) error {
 
 	query := `
 	UPDATE users
-	SET username = ?, password_hash = ?, first_name = ?, last_name = ?, role = ?, email = ?, phone = ?, modified_by = ?, last_modified = ?, deleted = ?
+	SET username = ?, password_hash = ?, first_name = ?, last_name = ?, role = ?, email = ?, phone = ?, team = ?, modified_by = ?, last_modified = ?, deleted = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		u.UserName,
 		u.PasswordHash,
 		u.FirstName,
@@ -199,6 +217,7 @@ func (r *sqliteUserRepo) Update(ctx context.Context, u *models.User) error {
 		u.Role,
 		u.Email,
 		u.Phone,
+		u.Team,
 		u.ModifiedBy,
 		u.LastModified,
 		boolToInt(u.Deleted),
@@ -221,7 +240,7 @@ func (r *sqliteUserRepo) Delete(ctx context.Context, tenantID string, id int64)
 	SET deleted = 1, modified_by = ?, last_modified = ?
 	WHERE tenant_id = ? AND id = ?;
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		existing.ModifiedBy,
 		time.Now().UTC(),
 		tenantID,
@@ -239,7 +258,7 @@ func (r *sqliteUserRepo) ListPermissionsForUser(ctx context.Context, tenantID st
           JOIN users AS u ON u.id = ur.user_id
          WHERE u.id = ? AND u.tenant_id = ? AND u.deleted = 0;
     `
-	rows, err := r.db.QueryContext(ctx, query, userID, tenantID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, tenantID)
 	if err != nil {
 		return nil, err
 	}
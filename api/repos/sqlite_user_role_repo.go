@@ -13,39 +13,70 @@ func NewSQLiteUserRoleRepo(db *sql.DB) UserRoleRepo {
 	return &sqliteUserRoleRepo{db: db}
 }
 
-func (r *sqliteUserRoleRepo) Create(ctx context.Context, m *models.Role) (int64, error) {
-	query := `INSERT INTO roles (name, description) VALUES (?,?)`
-	res, err := r.db.ExecContext(ctx, query, m.Name, m.Description)
+func (r *sqliteUserRoleRepo) Add(ctx context.Context, m *models.UserRole) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `INSERT OR IGNORE INTO user_roles (user_id, role_id) VALUES (?,?)`, m.UserID, m.RoleID)
+	return err
+}
+
+func (r *sqliteUserRoleRepo) Remove(ctx context.Context, userID, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM user_roles WHERE user_id=? AND role_id=?`,
+		userID, roleID,
+	)
+	return err
+}
+
+func (r *sqliteUserRoleRepo) Replace(ctx context.Context, userID int64, roleIDs []int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id=?`, userID); err != nil {
+			return err
+		}
+		for _, id := range roleIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO user_roles (user_id, role_id) VALUES (?,?)`, userID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqliteUserRoleRepo) DeleteByRole(ctx context.Context, roleID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_roles WHERE role_id=?`, roleID)
+	return err
+}
+
+func (r *sqliteUserRoleRepo) ListRoles(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT role_id FROM user_roles WHERE user_id=? ORDER BY role_id`, userID,
+	)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
 	}
-	return res.LastInsertId()
+	return out, rows.Err()
 }
 
-func (r *sqliteUserRoleRepo) ListAll(ctx context.Context) ([]*models.Role, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id,name,description FROM roles`)
+func (r *sqliteUserRoleRepo) ListAll(ctx context.Context) ([]*models.UserRole, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT user_id,role_id FROM user_roles ORDER BY user_id,role_id`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []*models.Role
+	var out []*models.UserRole
 	for rows.Next() {
-		var m models.Role
-		rows.Scan(&m.ID, &m.Name, &m.Description)
+		var m models.UserRole
+		if err := rows.Scan(&m.UserID, &m.RoleID); err != nil {
+			return nil, err
+		}
 		out = append(out, &m)
 	}
-	return out, nil
-}
-
-func (r *sqliteUserRoleRepo) Update(ctx context.Context, m *models.Role) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE roles SET name=?,description=? WHERE id=?`,
-		m.Name, m.Description, m.ID,
-	)
-	return err
-}
-
-func (r *sqliteUserRoleRepo) Delete(ctx context.Context, tenantID string, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id=?`, id)
-	return err
+	return out, rows.Err()
 }
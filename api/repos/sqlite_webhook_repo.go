@@ -0,0 +1,230 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+type sqliteWebhookRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteWebhookRepo) CreateSubscription(ctx context.Context, s *models.WebhookSubscription) (int64, error) {
+	if s.TenantID == "" || s.URL == "" || s.Secret == "" || s.CreatedBy == "" || s.ModifiedBy == "" {
+		return 0, errors.New("missing required fields or tenant/audit info")
+	}
+	now := time.Now().UTC()
+	s.CreatedAt = now
+	s.LastModified = now
+	query := `
+	INSERT INTO webhook_subscriptions (
+	  tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0);
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		s.TenantID,
+		s.URL,
+		s.Secret,
+		joinEvents(s.Events),
+		boolToInt(s.Active),
+		s.CreatedBy,
+		s.CreatedAt,
+		s.ModifiedBy,
+		s.LastModified,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteWebhookRepo) GetSubscription(ctx context.Context, tenantID string, id int64) (*models.WebhookSubscription, error) {
+	query := `
+	SELECT id, tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	FROM webhook_subscriptions
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	s, err := scanSQLiteWebhookSubscription(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return s, err
+}
+
+func (r *sqliteWebhookRepo) ListSubscriptions(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error) {
+	query := `
+	SELECT id, tenant_id, url, secret, events, active, created_by, created_at, modified_by, last_modified, deleted
+	FROM webhook_subscriptions
+	WHERE tenant_id = ? AND deleted = 0
+	ORDER BY id;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.WebhookSubscription
+	for rows.Next() {
+		s, err := scanSQLiteWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteWebhookRepo) UpdateSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	s.LastModified = time.Now().UTC()
+	query := `
+	UPDATE webhook_subscriptions
+	SET url = ?, events = ?, active = ?, modified_by = ?, last_modified = ?, deleted = ?
+	WHERE tenant_id = ? AND id = ? AND deleted = 0;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		s.URL,
+		joinEvents(s.Events),
+		boolToInt(s.Active),
+		s.ModifiedBy,
+		s.LastModified,
+		boolToInt(s.Deleted),
+		s.TenantID,
+		s.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqliteWebhookRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) (int64, error) {
+	d.CreatedAt = time.Now().UTC()
+	query := `
+	INSERT INTO webhook_deliveries (
+	  tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	  last_error, last_status_code, delivered_at, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (subscription_id, event_id) DO NOTHING;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		d.TenantID,
+		d.SubscriptionID,
+		d.EventID,
+		d.EventType,
+		string(d.Payload),
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastError,
+		d.LastStatusCode,
+		d.DeliveredAt,
+		d.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, nil
+	}
+	return res.LastInsertId()
+}
+
+func (r *sqliteWebhookRepo) GetDelivery(ctx context.Context, tenantID string, id int64) (*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE tenant_id = ? AND id = ?;
+	`
+	d, err := scanWebhookDelivery(conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return d, err
+}
+
+func (r *sqliteWebhookRepo) ListDeliveries(ctx context.Context, tenantID, status string, subscriptionID int64, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE tenant_id = ? AND (? = '' OR status = ?) AND (? = 0 OR subscription_id = ?)
+	ORDER BY id DESC
+	LIMIT ?;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, tenantID, status, status, subscriptionID, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *sqliteWebhookRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY next_attempt_at, id
+	LIMIT ?;
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.DeliveryPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *sqliteWebhookRepo) UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, last_status_code = ?, delivered_at = ?
+	WHERE tenant_id = ? AND id = ?;
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastError,
+		d.LastStatusCode,
+		d.DeliveredAt,
+		d.TenantID,
+		d.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanSQLiteWebhookSubscription(row interface{ Scan(...any) error }) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	var events string
+	var active, deleted int
+	if err := row.Scan(
+		&s.ID,
+		&s.TenantID,
+		&s.URL,
+		&s.Secret,
+		&events,
+		&active,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.ModifiedBy,
+		&s.LastModified,
+		&deleted,
+	); err != nil {
+		return nil, err
+	}
+	s.Events = splitEvents(events)
+	s.Active = active != 0
+	s.Deleted = deleted != 0
+	return &s, nil
+}
@@ -0,0 +1,32 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// TenantRepo stores tenants. Unlike the other repos it is not scoped by
+// tenant.
+type TenantRepo interface {
+	// Create adds a tenant; ErrTenantExists if the ID is taken.
+	Create(ctx context.Context, t *models.Tenant) error
+	// GetByID returns ErrNotFound if there is no such tenant.
+	GetByID(ctx context.Context, id string) (*models.Tenant, error)
+	ListAll(ctx context.Context) ([]*models.Tenant, error)
+	// Update saves name, status and suspension; ErrNotFound if missing.
+	Update(ctx context.Context, t *models.Tenant) error
+}
+
+// NewDBTenantRepo selects the concrete implementation based on driver.
+func NewDBTenantRepo(db *sql.DB, driver string) TenantRepo {
+	switch driver {
+	case "postgres":
+		return &postgresTenantRepo{db: db}
+	case "sqlite":
+		return &sqliteTenantRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
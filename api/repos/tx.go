@@ -0,0 +1,79 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbConn is what the repos query through: the database itself, or the
+// transaction a unit of work has open on it.
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+type ctxTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// Transactor runs units of work on one database.
+type Transactor interface {
+	// InTx runs fn in a single transaction. Repos built on the same
+	// database join it when called with the context fn receives; repos on
+	// any other database are unaffected. A nested InTx joins the outer
+	// transaction. When every domain shares one database this makes a
+	// unit of work spanning domains atomic.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return dbTransactor{db: db}
+}
+
+type dbTransactor struct {
+	db *sql.DB
+}
+
+func (t dbTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ct, ok := ctx.Value(txKey{}).(*ctxTx); ok && ct.db == t.db {
+		return fn(ctx)
+	}
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, txKey{}, &ctxTx{db: t.db, tx: tx})); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn returns the transaction ctx carries for db, or db itself.
+func conn(ctx context.Context, db *sql.DB) dbConn {
+	if ct, ok := ctx.Value(txKey{}).(*ctxTx); ok && ct.db == db {
+		return ct.tx
+	}
+	return db
+}
+
+// withTx runs fn in the transaction ctx carries for db, or else in a new
+// one of its own.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if ct, ok := ctx.Value(txKey{}).(*ctxTx); ok && ct.db == db {
+		return fn(ct.tx)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -12,6 +12,11 @@ type UserRepo interface {
 	// Create a new User. Returns the newly‐assigned ID.
 	Create(ctx context.Context, u *models.User) (int64, error)
 
+	// CreateWithinSeats is Create, refused with ErrSeatLimit if the tenant
+	// already has seats users. The count and the insert are one atomic
+	// step, so concurrent creations cannot overshoot. seats 0 is no limit.
+	CreateWithinSeats(ctx context.Context, u *models.User, seats int) (int64, error)
+
 	// GetByID returns a single User by its ID, within the given tenant.
 	// Returns ErrNotFound if no such (tenantID, id) record exists or is marked deleted.
 	GetByID(ctx context.Context, tenantID string, id int64) (*models.User, error)
@@ -9,13 +9,13 @@ import (
 
 // UserRoleRepo defines user–role assignments.
 type UserRoleRepo interface {
-	Add(ctx context.Context, ur *models.UserRole) error
+	Add(ctx context.Context, ur *models.UserRole) error // no-op if already assigned
+	Remove(ctx context.Context, userID, roleID int64) error
+	// Replace sets the user's roles to exactly roleIDs.
+	Replace(ctx context.Context, userID int64, roleIDs []int64) error
+	DeleteByRole(ctx context.Context, roleID int64) error
 	ListRoles(ctx context.Context, userID int64) ([]int64 /*roleIDs*/, error)
-	Create(ctx context.Context, r *models.UserRole) (int64, error)
 	ListAll(ctx context.Context) ([]*models.UserRole, error)
-	GetByID(ctx context.Context, tenantID string, id int64) (*models.UserRole, error)
-	Update(ctx context.Context, b *models.UserRole) error // using b.TenantID,b.ID
-	Delete(ctx context.Context, tenantID string, id int64) error
 }
 
 func NewDBUserRoleRepo(db *sql.DB, driver string) UserRoleRepo {
@@ -0,0 +1,92 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// WebhookRepo stores webhook subscriptions and their delivery queue.
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, s *models.WebhookSubscription) (int64, error)
+	GetSubscription(ctx context.Context, tenantID string, id int64) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, s *models.WebhookSubscription) error // using s.TenantID, s.ID
+
+	// CreateDelivery queues d; a second delivery of the same event to the
+	// same subscription is ignored and returns 0.
+	CreateDelivery(ctx context.Context, d *models.WebhookDelivery) (int64, error)
+	GetDelivery(ctx context.Context, tenantID string, id int64) (*models.WebhookDelivery, error)
+	// ListDeliveries filters by status and subscription when non-empty/non-zero.
+	ListDeliveries(ctx context.Context, tenantID, status string, subscriptionID int64, limit int) ([]*models.WebhookDelivery, error)
+	// ListDue returns pending deliveries of every tenant whose next attempt
+	// is due, oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error
+}
+
+// NewDBWebhookRepo selects the concrete implementation based on driver.
+func NewDBWebhookRepo(db *sql.DB, driver string) WebhookRepo {
+	switch driver {
+	case "postgres":
+		return &postgresWebhookRepo{db: db}
+	case "sqlite":
+		return &sqliteWebhookRepo{db: db}
+	default:
+		panic("unsupported driver: " + driver)
+	}
+}
+
+const webhookDeliveryColumns = `
+	id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_error, last_status_code, delivered_at, created_at`
+
+func joinEvents(events []string) string {
+	return strings.Join(events, ",")
+}
+
+func splitEvents(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload string
+	if err := row.Scan(
+		&d.ID,
+		&d.TenantID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastError,
+		&d.LastStatusCode,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Payload = []byte(payload)
+	return &d, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	var out []*models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// PermManageRoles is the permission that guards role and permission
+// management. AccessService refuses any change that would take it away
+// from the user making the change.
+const PermManageRoles = "manage_roles"
+
+// AccessService manages a tenant's roles, the permissions granted to them
+// and the roles held by its users. Every change is written to the access
+// audit log in the same transaction and invalidates the affected cached
+// permissions. With per-domain databases, a change to grants or
+// assignments commits separately, after its audit row has been written
+// in the transaction, so a failed change leaves no audit row.
+type AccessService struct {
+	roleRepo     repos.RoleRepo
+	permRepo     repos.PermissionRepo
+	rolePermRepo repos.RolePermissionRepo
+	userRoleRepo repos.UserRoleRepo
+	userRepo     repos.UserRepo
+	auditRepo    repos.AccessAuditRepo
+	authz        *AuthZService
+	tx           repos.Transactor // over the roles and audit database
+}
+
+func NewAccessService(
+	rr repos.RoleRepo,
+	pr repos.PermissionRepo,
+	rpr repos.RolePermissionRepo,
+	urr repos.UserRoleRepo,
+	ur repos.UserRepo,
+	ar repos.AccessAuditRepo,
+	authz *AuthZService,
+	tx repos.Transactor,
+) *AccessService {
+	return &AccessService{
+		roleRepo:     rr,
+		permRepo:     pr,
+		rolePermRepo: rpr,
+		userRoleRepo: urr,
+		userRepo:     ur,
+		auditRepo:    ar,
+		authz:        authz,
+		tx:           tx,
+	}
+}
+
+// ListPermissions returns the permission catalog.
+func (s *AccessService) ListPermissions(ctx context.Context) ([]*models.Permission, error) {
+	return s.permRepo.ListAll(ctx)
+}
+
+func (s *AccessService) ListRoles(ctx context.Context, tenantID string) ([]*models.Role, error) {
+	return s.roleRepo.ListAll(ctx, tenantID)
+}
+
+func (s *AccessService) GetRole(ctx context.Context, tenantID string, id int64) (*models.Role, error) {
+	return s.roleRepo.GetByID(ctx, tenantID, id)
+}
+
+func (s *AccessService) CreateRole(ctx context.Context, tenantID, actor string, r models.Role) (int64, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return 0, errors.New("name is required")
+	}
+	if err := s.checkRoleName(ctx, tenantID, 0, r.Name); err != nil {
+		return 0, err
+	}
+	return s.createRole(ctx, tenantID, actor, r, nil)
+}
+
+// SeedRole creates a role with the permissions permIDs on behalf of the
+// system, such as a tenant's default roles.
+func (s *AccessService) SeedRole(ctx context.Context, tenantID, actor string, r models.Role, permIDs []int64) (int64, error) {
+	return s.createRole(ctx, tenantID, actor, r, uniqueIDs(permIDs))
+}
+
+func (s *AccessService) createRole(ctx context.Context, tenantID, actor string, r models.Role, permIDs []int64) (int64, error) {
+	r.TenantID = tenantID
+	var id int64
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.roleRepo.Create(ctx, &r); err != nil {
+			return err
+		}
+		r.ID = id
+		detail := any(r)
+		if permIDs != nil {
+			detail = map[string]any{"role": r, "permission_ids": permIDs}
+		}
+		if err := s.audit(ctx, tenantID, actor, models.AuditRoleCreated, "role", id, detail); err != nil {
+			return err
+		}
+		if permIDs == nil {
+			return nil
+		}
+		return s.rolePermRepo.Replace(ctx, id, permIDs)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if permIDs != nil {
+		s.authz.InvalidateAll()
+	}
+	return id, nil
+}
+
+func (s *AccessService) UpdateRole(ctx context.Context, tenantID, actor string, id int64, r models.Role) error {
+	existing, err := s.roleRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if err := s.checkRoleName(ctx, tenantID, id, r.Name); err != nil {
+		return err
+	}
+	r.ID = id
+	r.TenantID = tenantID
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.audit(ctx, tenantID, actor, models.AuditRoleUpdated, "role", id, map[string]any{"before": existing, "after": r}); err != nil {
+			return err
+		}
+		return s.roleRepo.Update(ctx, &r)
+	})
+}
+
+// DeleteRole removes the role along with its grants and assignments.
+func (s *AccessService) DeleteRole(ctx context.Context, tenantID, actor string, actorID, id int64) error {
+	role, err := s.roleRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	actorRoles, err := s.userRoleRepo.ListRoles(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	remaining := slices.DeleteFunc(slices.Clone(actorRoles), func(r int64) bool { return r == id })
+	if err := s.guardAdmin(ctx, actorRoles, remaining, nil); err != nil {
+		return err
+	}
+	permIDs, err := s.rolePermRepo.ListByRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.audit(ctx, tenantID, actor, models.AuditRoleDeleted, "role", id, map[string]any{"role": role, "permission_ids": permIDs}); err != nil {
+			return err
+		}
+		if err := s.userRoleRepo.DeleteByRole(ctx, id); err != nil {
+			return err
+		}
+		if err := s.rolePermRepo.DeleteByRole(ctx, id); err != nil {
+			return err
+		}
+		return s.roleRepo.Delete(ctx, tenantID, id)
+	})
+	s.authz.InvalidateAll()
+	return err
+}
+
+// RolePermissions returns the IDs of the permissions granted to the role.
+func (s *AccessService) RolePermissions(ctx context.Context, tenantID string, roleID int64) ([]int64, error) {
+	if _, err := s.roleRepo.GetByID(ctx, tenantID, roleID); err != nil {
+		return nil, err
+	}
+	ids, err := s.rolePermRepo.ListByRole(ctx, roleID)
+	if ids == nil && err == nil {
+		ids = []int64{}
+	}
+	return ids, err
+}
+
+// SetRolePermissions replaces the permissions granted to the role.
+func (s *AccessService) SetRolePermissions(ctx context.Context, tenantID, actor string, actorID, roleID int64, permIDs []int64) error {
+	if _, err := s.roleRepo.GetByID(ctx, tenantID, roleID); err != nil {
+		return err
+	}
+	permIDs = uniqueIDs(permIDs)
+	all, err := s.permRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range permIDs {
+		if !slices.ContainsFunc(all, func(p *models.Permission) bool { return p.ID == id }) {
+			return fmt.Errorf("%w: %d", repos.ErrUnknownPermission, id)
+		}
+	}
+	actorRoles, err := s.userRoleRepo.ListRoles(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.guardAdmin(ctx, actorRoles, actorRoles, map[int64][]int64{roleID: permIDs}); err != nil {
+		return err
+	}
+	before, err := s.rolePermRepo.ListByRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.audit(ctx, tenantID, actor, models.AuditRolePermissionsSet, "role", roleID, map[string]any{"before": before, "after": permIDs}); err != nil {
+			return err
+		}
+		return s.rolePermRepo.Replace(ctx, roleID, permIDs)
+	})
+	s.authz.InvalidateAll()
+	return err
+}
+
+// UserRoles returns the user's role assignments.
+func (s *AccessService) UserRoles(ctx context.Context, tenantID string, userID int64) ([]models.UserRole, error) {
+	if err := s.checkUser(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	ids, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.UserRole, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, models.UserRole{UserID: userID, RoleID: id})
+	}
+	return out, nil
+}
+
+// SetUserRoles replaces the roles held by the user. Every role must belong
+// to the tenant.
+func (s *AccessService) SetUserRoles(ctx context.Context, tenantID, actor string, actorID, userID int64, roleIDs []int64) error {
+	if err := s.checkUser(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	roleIDs = uniqueIDs(roleIDs)
+	for _, id := range roleIDs {
+		if _, err := s.roleRepo.GetByID(ctx, tenantID, id); err != nil {
+			return err
+		}
+	}
+	before, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if userID == actorID {
+		if err := s.guardAdmin(ctx, before, roleIDs, nil); err != nil {
+			return err
+		}
+	}
+	return s.replaceUserRoles(ctx, tenantID, actor, userID, before, roleIDs)
+}
+
+// AssignRole adds a role to those the user holds, on behalf of the system,
+// such as a new tenant's first administrator.
+func (s *AccessService) AssignRole(ctx context.Context, tenantID, actor string, userID, roleID int64) error {
+	before, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil || slices.Contains(before, roleID) {
+		return err
+	}
+	return s.replaceUserRoles(ctx, tenantID, actor, userID, before, uniqueIDs(append(slices.Clone(before), roleID)))
+}
+
+// SyncUserRoles sets the user's roles to roleIDs on behalf of the system,
+// such as from an identity provider's claims. Nothing is written or
+// audited if the roles are unchanged.
+func (s *AccessService) SyncUserRoles(ctx context.Context, tenantID, actor string, userID int64, roleIDs []int64) error {
+	before, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	roleIDs = uniqueIDs(roleIDs)
+	if slices.Equal(uniqueIDs(before), roleIDs) {
+		return nil
+	}
+	return s.replaceUserRoles(ctx, tenantID, actor, userID, before, roleIDs)
+}
+
+// RemoveUserRoles takes every role away from a user who has been deleted.
+func (s *AccessService) RemoveUserRoles(ctx context.Context, tenantID, actor string, userID int64) error {
+	before, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil || len(before) == 0 {
+		return err
+	}
+	return s.replaceUserRoles(ctx, tenantID, actor, userID, before, []int64{})
+}
+
+// replaceUserRoles sets the user's roles from before to after and audits
+// the change.
+func (s *AccessService) replaceUserRoles(ctx context.Context, tenantID, actor string, userID int64, before, after []int64) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.audit(ctx, tenantID, actor, models.AuditUserRolesSet, "user", userID, map[string]any{"before": before, "after": after}); err != nil {
+			return err
+		}
+		return s.userRoleRepo.Replace(ctx, userID, after)
+	})
+	s.authz.Invalidate(userID)
+	return err
+}
+
+// AuditLog lists access changes, newest first, optionally only those
+// about one role or user.
+func (s *AccessService) AuditLog(ctx context.Context, tenantID, targetType string, targetID int64, limit int) ([]*models.AccessAudit, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.auditRepo.List(ctx, tenantID, targetType, targetID, limit)
+}
+
+// guardAdmin refuses a change that moves the acting user from roles
+// before to roles after (with override applied to role grants) if it
+// would cost them PermManageRoles.
+func (s *AccessService) guardAdmin(ctx context.Context, before, after []int64, override map[int64][]int64) error {
+	had, err := s.authz.PermissionsOfRoles(ctx, before, nil)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(had, PermManageRoles) {
+		return nil
+	}
+	has, err := s.authz.PermissionsOfRoles(ctx, after, override)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(has, PermManageRoles) {
+		return repos.ErrLastAdminPermission
+	}
+	return nil
+}
+
+func (s *AccessService) checkRoleName(ctx context.Context, tenantID string, id int64, name string) error {
+	roles, err := s.roleRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.ID != id && strings.EqualFold(r.Name, name) {
+			return repos.ErrRoleNameTaken
+		}
+	}
+	return nil
+}
+
+func (s *AccessService) checkUser(ctx context.Context, tenantID string, userID int64) error {
+	u, err := s.userRepo.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if u.Deleted {
+		return repos.ErrNotFound
+	}
+	return nil
+}
+
+func (s *AccessService) audit(ctx context.Context, tenantID, actor, action, targetType string, targetID int64, detail any) error {
+	b, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	_, err = s.auditRepo.Create(ctx, &models.AccessAudit{
+		TenantID:   tenantID,
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     string(b),
+		CreatedAt:  time.Now().UTC(),
+	})
+	return err
+}
+
+// uniqueIDs sorts ids and drops duplicates.
+func uniqueIDs(ids []int64) []int64 {
+	out := append([]int64{}, ids...)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// failingAudit is an audit repo whose writes fail.
+type failingAudit struct {
+	repos.AccessAuditRepo
+}
+
+var errAuditWrite = errors.New("audit write failed")
+
+func (failingAudit) Create(context.Context, *models.AccessAudit) (int64, error) {
+	return 0, errAuditWrite
+}
+
+type accessTest struct {
+	db           *sql.DB
+	access       *AccessService
+	tenants      *TenantService
+	auditRepo    repos.AccessAuditRepo
+	roleRepo     repos.RoleRepo
+	userRoleRepo repos.UserRoleRepo
+	userRepo     repos.UserRepo
+}
+
+func newAccessTest(t *testing.T, audit func(repos.AccessAuditRepo) repos.AccessAuditRepo) *accessTest {
+	db := newTestDB(t)
+	a := &accessTest{
+		db:           db,
+		auditRepo:    repos.NewDBAccessAuditRepo(db, "sqlite"),
+		roleRepo:     repos.NewDBRoleRepo(db, "sqlite"),
+		userRoleRepo: repos.NewDBUserRoleRepo(db, "sqlite"),
+		userRepo:     repos.NewDBUserRepo(db, "sqlite"),
+	}
+	permRepo := repos.NewDBPermissionRepo(db, "sqlite")
+	rolePermRepo := repos.NewDBRolePermissionRepo(db, "sqlite")
+	authz := NewAuthZService(permRepo, rolePermRepo, a.userRoleRepo)
+	auditRepo := a.auditRepo
+	if audit != nil {
+		auditRepo = audit(auditRepo)
+	}
+	a.access = NewAccessService(a.roleRepo, permRepo, rolePermRepo, a.userRoleRepo, a.userRepo, auditRepo, authz, repos.NewTransactor(db))
+	passwords := NewPasswordService(repos.NewDBPasswordRepo(db, "sqlite"), a.userRepo, repos.NewDBSessionRepo(db, "sqlite"), nil, "", PasswordPolicy{}, LockoutPolicy{})
+	a.tenants = NewTenantService(repos.NewDBTenantRepo(db, "sqlite"), a.userRepo, a.roleRepo, permRepo, a.access, authz, passwords)
+	return a
+}
+
+// A role change whose audit row cannot be written does not happen.
+func TestAccessChangeNeedsItsAudit(t *testing.T) {
+	a := newAccessTest(t, func(r repos.AccessAuditRepo) repos.AccessAuditRepo { return failingAudit{r} })
+	ctx := context.Background()
+	now := time.Now().UTC()
+	userID, err := a.userRepo.Create(ctx, &models.User{
+		TenantID: testTenant, UserName: "bob", PasswordHash: "x", FirstName: "Bob", LastName: "Jones",
+		Email: "bob@example.com", Role: "agent", CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleID, err := a.roleRepo.Create(ctx, &models.Role{TenantID: testTenant, Name: "agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.access.SetUserRoles(ctx, testTenant, "admin", 0, userID, []int64{roleID}); !errors.Is(err, errAuditWrite) {
+		t.Fatalf("SetUserRoles: err = %v, want the audit failure", err)
+	}
+	if roles, err := a.userRoleRepo.ListRoles(ctx, userID); err != nil || len(roles) != 0 {
+		t.Errorf("roles = %v (err %v), want none", roles, err)
+	}
+	if _, err := a.access.CreateRole(ctx, testTenant, "admin", models.Role{Name: "viewer"}); !errors.Is(err, errAuditWrite) {
+		t.Fatalf("CreateRole: err = %v, want the audit failure", err)
+	}
+	if roles, err := a.roleRepo.ListAll(ctx, testTenant); err != nil || len(roles) != 1 {
+		t.Errorf("roles in the tenant = %d (err %v), want only agent", len(roles), err)
+	}
+}
+
+// Provisioning a tenant audits its default roles and its admin's role.
+func TestProvisionAuditsRoles(t *testing.T) {
+	a := newAccessTest(t, nil)
+	ctx := context.Background()
+	req := models.TenantProvisionRequest{ID: "globex"}
+	req.Admin.UserName = "hank"
+	req.Admin.FirstName = "Hank"
+	req.Admin.LastName = "Scorpio"
+	req.Admin.Email = "hank@example.com"
+	req.Admin.Password = "volcano lair 42"
+	if _, err := a.tenants.Provision(ctx, "platform-admin", req); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := a.auditRepo.List(ctx, "globex", "", 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles, err := a.roleRepo.ListAll(ctx, "globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := map[string]int{}
+	for _, e := range entries {
+		if e.Actor != "platform-admin" {
+			t.Errorf("%s audited as %q", e.Action, e.Actor)
+		}
+		count[e.Action]++
+	}
+	if count[models.AuditRoleCreated] != len(roles) || count[models.AuditUserRolesSet] != 1 {
+		t.Errorf("audit = %v, want %d %s and one %s", count, len(roles), models.AuditRoleCreated, models.AuditUserRolesSet)
+	}
+}
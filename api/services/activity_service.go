@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// ActivityService logs CRM activities (calls, emails, viewings, notes and
+// follow-up tasks) and assembles buyer timelines.
+type ActivityService struct {
+	repo         repos.ActivityRepo
+	buyerRepo    repos.BuyerRepo
+	lettingsRepo repos.LettingsRepo
+	planRepo     repos.InstallmentPlanRepo
+	instRepo     repos.InstallmentRepo
+	payRepo      repos.PaymentRepo
+	apptRepo     repos.AppointmentRepo
+}
+
+func NewActivityService(
+	r repos.ActivityRepo,
+	br repos.BuyerRepo,
+	lr repos.LettingsRepo,
+	pr repos.InstallmentPlanRepo,
+	ir repos.InstallmentRepo,
+	payr repos.PaymentRepo,
+	ar repos.AppointmentRepo,
+) *ActivityService {
+	return &ActivityService{
+		repo:         r,
+		buyerRepo:    br,
+		lettingsRepo: lr,
+		planRepo:     pr,
+		instRepo:     ir,
+		payRepo:      payr,
+		apptRepo:     ar,
+	}
+}
+
+// CreateActivity logs an activity. Buyer activities need an existing buyer;
+// lettings tenant activities need LettingID to name a letting whose tenant
+// user is SubjectID. OccurredAt defaults to now.
+func (s *ActivityService) CreateActivity(ctx context.Context, tenantID, currentUser string, a models.Activity) (int64, error) {
+	switch a.Type {
+	case models.ActivityCall, models.ActivityEmail, models.ActivityViewing, models.ActivityNote, models.ActivityTask:
+	default:
+		return 0, errors.New("type must be one of call, email, viewing, note, task")
+	}
+	if a.Type == models.ActivityTask && a.DueDate == nil {
+		return 0, errors.New("tasks need a due date")
+	}
+	if err := s.checkSubject(ctx, tenantID, a.SubjectType, a.SubjectID, a.LettingID); err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	if a.OccurredAt.IsZero() {
+		a.OccurredAt = now
+	}
+	a.ID = 0
+	a.TenantID = tenantID
+	a.Author = currentUser
+	a.CompletedAt = nil
+	a.CreatedBy = currentUser
+	a.ModifiedBy = currentUser
+	a.Deleted = false
+	return s.repo.Create(ctx, &a)
+}
+
+// ListActivities returns the activities for one buyer or lettings tenant.
+func (s *ActivityService) ListActivities(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]models.Activity, error) {
+	rows, err := s.repo.ListBySubject(ctx, tenantID, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Activity, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+// CompleteActivity marks a follow-up done.
+func (s *ActivityService) CompleteActivity(ctx context.Context, tenantID, currentUser string, id int64) error {
+	a, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if a.DueDate == nil {
+		return errors.New("activity has no follow-up to complete")
+	}
+	now := time.Now().UTC()
+	a.CompletedAt = &now
+	a.ModifiedBy = currentUser
+	return s.repo.Update(ctx, a)
+}
+
+func (s *ActivityService) DeleteActivity(ctx context.Context, tenantID, currentUser string, id int64) error {
+	a, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	a.Deleted = true
+	a.ModifiedBy = currentUser
+	return s.repo.Update(ctx, a)
+}
+
+// BuyerTimeline merges the buyer's logged activities and viewing
+// appointments with system events from their installment plans (plan
+// created, payment received, installment overdue), oldest first.
+func (s *ActivityService) BuyerTimeline(ctx context.Context, tenantID string, buyerID int64) ([]models.TimelineEntry, error) {
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return nil, err
+	}
+	var out []models.TimelineEntry
+
+	acts, err := s.repo.ListBySubject(ctx, tenantID, models.ActivitySubjectBuyer, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range acts {
+		out = append(out, models.TimelineEntry{
+			At:       a.OccurredAt,
+			Kind:     models.TimelineActivity,
+			Summary:  a.Type,
+			Activity: a,
+		})
+	}
+
+	appts, err := s.apptRepo.List(ctx, tenantID, models.AppointmentFilter{BuyerID: buyerID, IncludeCancelled: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range appts {
+		out = append(out, models.TimelineEntry{
+			At:          a.StartsAt,
+			Kind:        models.TimelineViewing,
+			Summary:     fmt.Sprintf("Viewing of property #%d (%s)", a.PropertyID, a.Status),
+			Appointment: a,
+		})
+	}
+
+	plans, err := s.planRepo.ListByBuyer(ctx, tenantID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	// Payments are fetched for all the buyer's installments at once.
+	byID := map[int64]*models.Installment{}
+	var instIDs []int64
+	for _, p := range plans {
+		out = append(out, models.TimelineEntry{
+			At:      p.CreatedAt,
+			Kind:    models.TimelinePlanCreated,
+			Summary: fmt.Sprintf("Plan created: %d installments, %.2f total", p.NumInstallments, p.TotalPrice),
+			PlanID:  p.ID,
+			Amount:  p.TotalPrice,
+		})
+		insts, err := s.instRepo.ListByPlan(ctx, tenantID, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range insts {
+			if inst.Deleted {
+				continue
+			}
+			byID[inst.ID] = inst
+			instIDs = append(instIDs, inst.ID)
+			if installmentOverdue(inst, now) {
+				out = append(out, models.TimelineEntry{
+					At:            inst.DueDate,
+					Kind:          models.TimelineInstallmentOverdue,
+					Summary:       fmt.Sprintf("Installment %d overdue: %.2f outstanding", inst.SequenceNumber, inst.AmountDue-inst.AmountPaid),
+					PlanID:        p.ID,
+					InstallmentID: inst.ID,
+					Amount:        inst.AmountDue - inst.AmountPaid,
+				})
+			}
+		}
+	}
+	pays, err := s.payRepo.ListByInstallments(ctx, tenantID, instIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, pay := range pays {
+		inst, ok := byID[pay.InstallmentID]
+		if !ok || pay.Deleted {
+			continue
+		}
+		out = append(out, models.TimelineEntry{
+			At:            pay.PaymentDate,
+			Kind:          models.TimelinePaymentReceived,
+			Summary:       fmt.Sprintf("Payment received for installment %d: %.2f", inst.SequenceNumber, pay.AmountPaid),
+			PlanID:        inst.PlanID,
+			InstallmentID: inst.ID,
+			PaymentID:     pay.ID,
+			Amount:        pay.AmountPaid,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// installmentOverdue reports whether inst is marked overdue, or is past its
+// due date and not fully paid.
+func installmentOverdue(inst *models.Installment, now time.Time) bool {
+	if inst.Status == models.InstallmentOverdue {
+		return true
+	}
+	return inst.Status != models.InstallmentPaid && inst.AmountPaid < inst.AmountDue && inst.DueDate.Before(now)
+}
+
+func (s *ActivityService) checkSubject(ctx context.Context, tenantID, subjectType string, subjectID, lettingID int64) error {
+	if subjectID == 0 {
+		return errors.New("subject_id is required")
+	}
+	switch subjectType {
+	case models.ActivitySubjectBuyer:
+		_, err := s.buyerRepo.GetByID(ctx, tenantID, subjectID)
+		return err
+	case models.ActivitySubjectLettingTenant:
+		if lettingID == 0 {
+			return errors.New("letting_id is required for lettings tenant activities")
+		}
+		l, err := s.lettingsRepo.GetByID(ctx, tenantID, lettingID)
+		if err != nil {
+			return err
+		}
+		if l.TenantUserID != subjectID {
+			return errors.New("subject is not the tenant on this letting")
+		}
+		return nil
+	}
+	return errors.New("subject_type must be buyer or letting_tenant")
+}
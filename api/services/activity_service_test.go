@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// The timeline lists the buyer's own plans and the payments against them,
+// and nothing of another buyer's.
+func TestBuyerTimelineListsPlansAndPayments(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	planRepo := repos.NewDBInstallmentPlanRepo(db, "sqlite")
+	instRepo := repos.NewDBInstallmentRepo(db, "sqlite")
+	payRepo := repos.NewDBPaymentRepo(db, "sqlite")
+	svc := NewActivityService(repos.NewDBActivityRepo(db, "sqlite"), buyerRepo, repos.NewDBLettingsRepo(db, "sqlite"),
+		planRepo, instRepo, payRepo, repos.NewDBAppointmentRepo(db, "sqlite"))
+
+	var buyers []int64
+	for _, email := range []string{"ada@example.com", "bob@example.com"} {
+		id, err := buyerRepo.Create(ctx, &models.Buyer{
+			TenantID: testTenant, FirstName: "Test", LastName: "Buyer", Email: email,
+			CreatedBy: "alice", ModifiedBy: "alice",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		buyers = append(buyers, id)
+	}
+	var payments []int64
+	for _, buyerID := range buyers {
+		planID, err := planRepo.Create(ctx, &models.InstallmentPlan{
+			TenantID: testTenant, PropertyID: 1, BuyerID: buyerID, TotalPrice: 1000,
+			NumInstallments: 2, Frequency: "Monthly", FirstInstallment: time.Now(),
+			CreatedBy: "alice", ModifiedBy: "alice",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for seq := 1; seq <= 2; seq++ {
+			instID, err := instRepo.Create(ctx, &models.Installment{
+				TenantID: testTenant, PlanID: planID, SequenceNumber: seq, DueDate: time.Now().AddDate(0, seq, 0),
+				AmountDue: 500, Status: models.InstallmentPending, CreatedBy: "alice", ModifiedBy: "alice",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			payID, err := payRepo.Create(ctx, &models.Payment{
+				TenantID: testTenant, InstallmentID: instID, AmountPaid: 100, PaymentDate: time.Now(),
+				PaymentMethod: "cash", CreatedBy: "alice", ModifiedBy: "alice",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buyerID == buyers[0] {
+				payments = append(payments, payID)
+			}
+		}
+	}
+
+	entries, err := svc.BuyerTimeline(ctx, testTenant, buyers[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plans int
+	var got []int64
+	for _, e := range entries {
+		switch e.Kind {
+		case models.TimelinePlanCreated:
+			plans++
+		case models.TimelinePaymentReceived:
+			got = append(got, e.PaymentID)
+		}
+	}
+	if plans != 1 {
+		t.Errorf("plan entries = %d, want 1", plans)
+	}
+	if len(got) != len(payments) {
+		t.Fatalf("payment entries = %v, want %v", got, payments)
+	}
+	for _, id := range payments {
+		found := false
+		for _, g := range got {
+			found = found || g == id
+		}
+		if !found {
+			t.Errorf("payment %d missing from timeline %v", id, got)
+		}
+	}
+}
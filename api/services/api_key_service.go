@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// PermManageAPIKeys allows creating, changing and revoking the tenant's
+// API keys.
+const PermManageAPIKeys = "manage_api_keys"
+
+// apiKeyPrefix starts every key so leaked keys are easy to recognise.
+const apiKeyPrefix = "rik_"
+
+// apiKeyTouchInterval limits how often a busy key's last-used time is
+// written.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService manages API keys for scripts and integrations. A key
+// acts as the user who created it, limited to the permissions it was
+// given; it is rik_<prefix>_<secret>, of which only the prefix and a hash
+// of the whole key are stored.
+type APIKeyService struct {
+	repo     repos.APIKeyRepo
+	userRepo repos.UserRepo
+	authz    *AuthZService
+	tenants  *TenantService
+}
+
+func NewAPIKeyService(r repos.APIKeyRepo, ur repos.UserRepo, authz *AuthZService, tenants *TenantService) *APIKeyService {
+	return &APIKeyService{repo: r, userRepo: ur, authz: authz, tenants: tenants}
+}
+
+func (s *APIKeyService) List(ctx context.Context, tenantID string) ([]*models.APIKey, error) {
+	list, err := s.repo.ListAll(ctx, tenantID)
+	if list == nil && err == nil {
+		list = []*models.APIKey{}
+	}
+	return list, err
+}
+
+func (s *APIKeyService) Get(ctx context.Context, tenantID string, id int64) (*models.APIKey, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// Create issues a key owned by userID. The key itself is only returned
+// here.
+func (s *APIKeyService) Create(ctx context.Context, tenantID string, userID int64, currentUser string, req models.APIKeyRequest) (*models.NewAPIKey, error) {
+	if err := s.validate(ctx, tenantID, userID, &req); err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + randomHex(6)
+	key := prefix + "_" + randomHex(32)
+	now := time.Now().UTC()
+	k := &models.APIKey{
+		TenantID:     tenantID,
+		UserID:       userID,
+		Name:         req.Name,
+		Prefix:       prefix,
+		SecretHash:   hashSessionToken(key),
+		Permissions:  req.Permissions,
+		AllowedIPs:   req.AllowedIPs,
+		ExpiresAt:    req.ExpiresAt,
+		CreatedBy:    currentUser,
+		CreatedAt:    now,
+		ModifiedBy:   currentUser,
+		LastModified: now,
+	}
+	id, err := s.repo.Create(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+	k.ID = id
+	return &models.NewAPIKey{APIKey: k, Key: key}, nil
+}
+
+// Update changes a key's name, permissions, allowed IPs and expiry. The
+// key value and owner stay the same.
+func (s *APIKeyService) Update(ctx context.Context, tenantID string, userID int64, currentUser string, id int64, req models.APIKeyRequest) (*models.APIKey, error) {
+	k, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, tenantID, userID, &req); err != nil {
+		return nil, err
+	}
+	k.Name = req.Name
+	k.Permissions = req.Permissions
+	k.AllowedIPs = req.AllowedIPs
+	k.ExpiresAt = req.ExpiresAt
+	k.ModifiedBy = currentUser
+	k.LastModified = time.Now().UTC()
+	if err := s.repo.Update(ctx, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Delete revokes a key at once.
+func (s *APIKeyService) Delete(ctx context.Context, tenantID string, id int64) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Authenticate checks a key presented from ip and returns it with the
+// user it acts as. Unknown, expired and orphaned keys are all
+// ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(ctx context.Context, key, ip string) (*models.APIKey, *models.User, error) {
+	i := strings.LastIndex(key, "_")
+	if !strings.HasPrefix(key, apiKeyPrefix) || i <= len(apiKeyPrefix) {
+		return nil, nil, repos.ErrInvalidAPIKey
+	}
+	k, err := s.repo.GetByPrefix(ctx, key[:i])
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, nil, repos.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSessionToken(key)), []byte(k.SecretHash)) != 1 {
+		return nil, nil, repos.ErrInvalidAPIKey
+	}
+	now := time.Now().UTC()
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return nil, nil, repos.ErrInvalidAPIKey
+	}
+	if !ipAllowed(k.AllowedIPs, ip) {
+		return nil, nil, repos.ErrAPIKeyIPNotAllowed
+	}
+	if err := s.tenants.CheckActive(ctx, k.TenantID); errors.Is(err, repos.ErrNotFound) {
+		return nil, nil, repos.ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, k.TenantID, k.UserID)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, nil, repos.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval || k.LastUsedIP != ip {
+		if err := s.repo.Touch(ctx, k.ID, now, ip); err != nil {
+			return nil, nil, err
+		}
+	}
+	return k, user, nil
+}
+
+// validate normalises req and checks that the key only gets permissions
+// the user granting them holds.
+func (s *APIKeyService) validate(ctx context.Context, tenantID string, userID int64, req *models.APIKeyRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Permissions) == 0 {
+		return repos.ErrInvalidAPIKeyRequest
+	}
+	for _, entry := range req.AllowedIPs {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			return repos.ErrInvalidAPIKeyRequest
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return repos.ErrInvalidAPIKeyRequest
+	}
+	if req.AllowedIPs == nil {
+		req.AllowedIPs = []string{}
+	}
+	held, err := s.authz.Permissions(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	for _, p := range req.Permissions {
+		if !slices.Contains(held, p) {
+			return repos.ErrPermissionNotHeld
+		}
+	}
+	return nil
+}
+
+// ipAllowed reports whether ip matches one of allowed, which holds
+// addresses and CIDR ranges. An empty list allows every address.
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if _, n, err := net.ParseCIDR(entry); err == nil {
+			if n.Contains(addr) {
+				return true
+			}
+		} else if a := net.ParseIP(entry); a != nil && a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+type apiKeyTest struct {
+	*accessTest
+	keys    *APIKeyService
+	keyRepo repos.APIKeyRepo
+	adminID int64
+}
+
+// newAPIKeyTest provisions testTenant, whose admin holds view_properties.
+func newAPIKeyTest(t *testing.T) *apiKeyTest {
+	t.Helper()
+	a := &apiKeyTest{accessTest: newAccessTest(t, nil)}
+	ctx := context.Background()
+	if err := a.access.authz.EnsurePermissions(ctx, "view_properties"); err != nil {
+		t.Fatal(err)
+	}
+	req := models.TenantProvisionRequest{ID: testTenant, Name: "Acme"}
+	req.Admin.UserName = "alice"
+	req.Admin.FirstName = "Alice"
+	req.Admin.LastName = "Admin"
+	req.Admin.Email = "alice@example.com"
+	req.Admin.Password = "correct horse battery"
+	if _, err := a.tenants.Provision(ctx, "platform-admin", req); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := a.userRepo.GetByUsername(ctx, testTenant, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.adminID = admin.ID
+	a.keyRepo = repos.NewDBAPIKeyRepo(a.db, "sqlite")
+	a.keys = NewAPIKeyService(a.keyRepo, a.userRepo, a.access.authz, a.tenants)
+	return a
+}
+
+func (a *apiKeyTest) create(t *testing.T, req models.APIKeyRequest) *models.NewAPIKey {
+	t.Helper()
+	if req.Name == "" {
+		req.Name = "nightly export"
+	}
+	if req.Permissions == nil {
+		req.Permissions = []string{"view_properties"}
+	}
+	k, err := a.keys.Create(context.Background(), testTenant, a.adminID, "alice", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// Only a hash of the key is stored, and only the exact key matches it.
+func TestAPIKeyLookupByHash(t *testing.T) {
+	a := newAPIKeyTest(t)
+	ctx := context.Background()
+	k := a.create(t, models.APIKeyRequest{})
+
+	stored, err := a.keyRepo.GetByID(ctx, testTenant, k.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SecretHash == k.Key || strings.Contains(stored.SecretHash, k.Key[len(k.Prefix)+1:]) {
+		t.Fatal("the key's secret is stored in the clear")
+	}
+	if !strings.HasPrefix(k.Key, k.Prefix+"_") || !strings.HasPrefix(k.Prefix, apiKeyPrefix) {
+		t.Fatalf("key %q does not start with its prefix %q", k.Key, k.Prefix)
+	}
+
+	got, user, err := a.keys.Authenticate(ctx, k.Key, "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != k.ID || user.ID != a.adminID {
+		t.Fatalf("Authenticate = key %d user %d, want key %d user %d", got.ID, user.ID, k.ID, a.adminID)
+	}
+
+	secret := k.Key[len(k.Prefix)+1:]
+	flipped := "0"
+	if secret[0] == '0' {
+		flipped = "1"
+	}
+	for _, bad := range []string{
+		k.Prefix + "_" + flipped + secret[1:],
+		k.Prefix + "_",
+		k.Prefix,
+		strings.TrimPrefix(k.Key, apiKeyPrefix),
+		apiKeyPrefix + "000000000000_" + secret,
+		"",
+	} {
+		if _, _, err := a.keys.Authenticate(ctx, bad, "203.0.113.7"); !errors.Is(err, repos.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q): err = %v, want ErrInvalidAPIKey", bad, err)
+		}
+	}
+}
+
+func TestAPIKeyRevocationAndExpiry(t *testing.T) {
+	a := newAPIKeyTest(t)
+	ctx := context.Background()
+	k := a.create(t, models.APIKeyRequest{})
+	if err := a.keys.Delete(ctx, testTenant, k.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.keys.Authenticate(ctx, k.Key, "203.0.113.7"); !errors.Is(err, repos.ErrInvalidAPIKey) {
+		t.Errorf("revoked key: err = %v, want ErrInvalidAPIKey", err)
+	}
+
+	soon := time.Now().Add(time.Hour)
+	k = a.create(t, models.APIKeyRequest{ExpiresAt: &soon})
+	stored, err := a.keyRepo.GetByID(ctx, testTenant, k.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Minute)
+	stored.ExpiresAt = &past
+	if err := a.keyRepo.Update(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.keys.Authenticate(ctx, k.Key, "203.0.113.7"); !errors.Is(err, repos.ErrInvalidAPIKey) {
+		t.Errorf("expired key: err = %v, want ErrInvalidAPIKey", err)
+	}
+
+	if _, err := a.keys.Create(ctx, testTenant, a.adminID, "alice", models.APIKeyRequest{
+		Name: "backdated", Permissions: []string{"view_properties"}, ExpiresAt: &past,
+	}); !errors.Is(err, repos.ErrInvalidAPIKeyRequest) {
+		t.Errorf("creating an expired key: err = %v, want ErrInvalidAPIKeyRequest", err)
+	}
+}
+
+func TestAPIKeyIPAllowlist(t *testing.T) {
+	a := newAPIKeyTest(t)
+	ctx := context.Background()
+	k := a.create(t, models.APIKeyRequest{AllowedIPs: []string{"198.51.100.0/24", "2001:db8::1"}})
+
+	for ip, want := range map[string]error{
+		"198.51.100.23": nil,
+		"2001:db8::1":   nil,
+		"198.51.101.23": repos.ErrAPIKeyIPNotAllowed,
+		"2001:db8::2":   repos.ErrAPIKeyIPNotAllowed,
+		"":              repos.ErrAPIKeyIPNotAllowed,
+		"not-an-ip":     repos.ErrAPIKeyIPNotAllowed,
+	} {
+		if _, _, err := a.keys.Authenticate(ctx, k.Key, ip); !errors.Is(err, want) {
+			t.Errorf("from %q: err = %v, want %v", ip, err, want)
+		}
+	}
+
+	if _, err := a.keys.Create(ctx, testTenant, a.adminID, "alice", models.APIKeyRequest{
+		Name: "bad list", Permissions: []string{"view_properties"}, AllowedIPs: []string{"10.0.0.0/33"},
+	}); !errors.Is(err, repos.ErrInvalidAPIKeyRequest) {
+		t.Errorf("invalid allowlist entry: err = %v, want ErrInvalidAPIKeyRequest", err)
+	}
+}
+
+// A key stops working when its owner is deleted, and cannot be given
+// permissions its owner lacks.
+func TestAPIKeyOwner(t *testing.T) {
+	a := newAPIKeyTest(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	bobID, err := a.userRepo.Create(ctx, &models.User{
+		TenantID: testTenant, UserName: "bob", PasswordHash: "x", FirstName: "Bob", LastName: "Jones",
+		Email: "bob@example.com", Role: "agent", CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.keys.Create(ctx, testTenant, bobID, "bob", models.APIKeyRequest{
+		Name: "escalate", Permissions: []string{"view_properties"},
+	}); !errors.Is(err, repos.ErrPermissionNotHeld) {
+		t.Fatalf("key with a permission its owner lacks: err = %v, want ErrPermissionNotHeld", err)
+	}
+
+	k := a.create(t, models.APIKeyRequest{})
+	if err := a.userRepo.Delete(ctx, testTenant, a.adminID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.keys.Authenticate(ctx, k.Key, "203.0.113.7"); !errors.Is(err, repos.ErrInvalidAPIKey) {
+		t.Errorf("key of a deleted user: err = %v, want ErrInvalidAPIKey", err)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/ical"
+)
+
+// Limits on availability searches.
+const (
+	MaxAvailabilityRange   = 31 * 24 * time.Hour
+	DefaultViewingDuration = 30 * time.Minute
+)
+
+// AppointmentService books property viewings, keeping agents and properties
+// free of double bookings and inside working hours.
+type AppointmentService struct {
+	repo         repos.AppointmentRepo
+	propertyRepo repos.PropertyRepo
+	buyerRepo    repos.BuyerRepo
+	userRepo     repos.UserRepo
+	hours        WorkingHours
+}
+
+func NewAppointmentService(
+	r repos.AppointmentRepo,
+	pr repos.PropertyRepo,
+	br repos.BuyerRepo,
+	ur repos.UserRepo,
+	hours WorkingHours,
+) *AppointmentService {
+	return &AppointmentService{repo: r, propertyRepo: pr, buyerRepo: br, userRepo: ur, hours: hours}
+}
+
+// CreateAppointment books a viewing. Times are truncated to the minute.
+func (s *AppointmentService) CreateAppointment(ctx context.Context, tenantID, currentUser string, a models.Appointment) (int64, error) {
+	a.ID = 0
+	a.TenantID = tenantID
+	if err := s.validate(ctx, &a); err != nil {
+		return 0, err
+	}
+	a.Status = models.AppointmentScheduled
+	a.CreatedBy = currentUser
+	a.ModifiedBy = currentUser
+	a.Deleted = false
+	return s.repo.Create(ctx, &a)
+}
+
+// UpdateAppointment reschedules or reassigns a scheduled viewing.
+func (s *AppointmentService) UpdateAppointment(ctx context.Context, tenantID, currentUser string, id int64, a models.Appointment) error {
+	existing, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if existing.Status != models.AppointmentScheduled {
+		return errors.New("only scheduled appointments can be changed")
+	}
+	a.ID = id
+	a.TenantID = tenantID
+	if err := s.validate(ctx, &a); err != nil {
+		return err
+	}
+	a.Status = existing.Status
+	a.CreatedBy = existing.CreatedBy
+	a.CreatedAt = existing.CreatedAt
+	a.ModifiedBy = currentUser
+	return s.repo.Update(ctx, &a)
+}
+
+// SetStatus marks a viewing completed or cancelled.
+func (s *AppointmentService) SetStatus(ctx context.Context, tenantID, currentUser string, id int64, status string) error {
+	if status != models.AppointmentCompleted && status != models.AppointmentCancelled {
+		return errors.New("status must be completed or cancelled")
+	}
+	a, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if a.Status != models.AppointmentScheduled {
+		return errors.New("appointment is already " + a.Status)
+	}
+	a.Status = status
+	a.ModifiedBy = currentUser
+	return s.repo.Update(ctx, a)
+}
+
+func (s *AppointmentService) ListAppointments(ctx context.Context, tenantID string, f models.AppointmentFilter) ([]models.Appointment, error) {
+	rows, err := s.repo.List(ctx, tenantID, f)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Appointment, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+// Availability returns free slots of the given duration in [from, to) for
+// an agent, a property, or both together. Slots are laid end to end from
+// the start of each working period.
+func (s *AppointmentService) Availability(ctx context.Context, tenantID string, agentUserID, propertyID int64, from, to time.Time, duration time.Duration) ([]models.TimeSlot, error) {
+	if agentUserID == 0 && propertyID == 0 {
+		return nil, errors.New("agent_id or property_id is required")
+	}
+	if duration <= 0 {
+		duration = DefaultViewingDuration
+	}
+	if !to.After(from) || to.Sub(from) > MaxAvailabilityRange {
+		return nil, fmt.Errorf("range must be positive and at most %d days", int(MaxAvailabilityRange.Hours()/24))
+	}
+
+	var busy []*models.Appointment
+	if agentUserID != 0 {
+		rows, err := s.repo.List(ctx, tenantID, models.AppointmentFilter{From: from, To: to, AgentUserID: agentUserID})
+		if err != nil {
+			return nil, err
+		}
+		busy = append(busy, rows...)
+	}
+	if propertyID != 0 {
+		rows, err := s.repo.List(ctx, tenantID, models.AppointmentFilter{From: from, To: to, PropertyID: propertyID})
+		if err != nil {
+			return nil, err
+		}
+		busy = append(busy, rows...)
+	}
+
+	var out []models.TimeSlot
+	for _, win := range s.hours.Windows(from, to) {
+		for start := win.Start; !start.Add(duration).After(win.End); start = start.Add(duration) {
+			end := start.Add(duration)
+			if !overlapsAny(busy, start, end, 0) {
+				out = append(out, models.TimeSlot{Start: start, End: end})
+			}
+		}
+	}
+	return out, nil
+}
+
+// ExportICS writes the appointments matching f as an iCalendar file.
+func (s *AppointmentService) ExportICS(ctx context.Context, tenantID string, f models.AppointmentFilter, w io.Writer) error {
+	f.IncludeCancelled = true
+	rows, err := s.repo.List(ctx, tenantID, f)
+	if err != nil {
+		return err
+	}
+	cal := &ical.Calendar{ProdID: ICalProdID, Name: "Viewings"}
+	props := map[int64]*models.Property{}
+	for _, a := range rows {
+		cal.Events = append(cal.Events, s.appointmentEvent(ctx, tenantID, a, props))
+	}
+	return cal.Write(w)
+}
+
+// ICalProdID identifies this application in exported calendars.
+const ICalProdID = "-//Realtor Installment Assistant//EN"
+
+func (s *AppointmentService) appointmentEvent(ctx context.Context, tenantID string, a *models.Appointment, props map[int64]*models.Property) ical.Event {
+	p, ok := props[a.PropertyID]
+	if !ok {
+		p, _ = s.propertyRepo.GetByID(ctx, tenantID, a.PropertyID)
+		props[a.PropertyID] = p
+	}
+	summary := fmt.Sprintf("Viewing: property #%d", a.PropertyID)
+	var location string
+	if p != nil {
+		summary = "Viewing: " + p.Address
+		location = strings.Join([]string{p.Address, p.City, p.ZIP}, ", ")
+	}
+	who := a.ProspectName
+	if a.BuyerID != 0 {
+		who = fmt.Sprintf("buyer #%d", a.BuyerID)
+	}
+	status := "CONFIRMED"
+	if a.Status == models.AppointmentCancelled {
+		status = "CANCELLED"
+	}
+	return ical.Event{
+		UID:         fmt.Sprintf("appointment-%d-%s@realtorinstall", a.ID, tenantID),
+		Summary:     summary,
+		Description: strings.TrimSpace("With " + who + "\n" + a.Notes),
+		Location:    location,
+		Start:       a.StartsAt,
+		End:         a.EndsAt,
+		Updated:     a.LastModified,
+		Status:      status,
+	}
+}
+
+// validate normalises a's times and checks references, working hours and
+// conflicts with other bookings for the same agent or property.
+func (s *AppointmentService) validate(ctx context.Context, a *models.Appointment) error {
+	a.StartsAt = a.StartsAt.UTC().Truncate(time.Minute)
+	a.EndsAt = a.EndsAt.UTC().Truncate(time.Minute)
+	if a.PropertyID == 0 || a.AgentUserID == 0 || a.StartsAt.IsZero() {
+		return errors.New("property, agent and start time are required")
+	}
+	if !a.EndsAt.After(a.StartsAt) {
+		return errors.New("end time must be after start time")
+	}
+	if a.BuyerID == 0 && strings.TrimSpace(a.ProspectName) == "" {
+		return errors.New("a buyer or a prospect name is required")
+	}
+	if _, err := s.propertyRepo.GetByID(ctx, a.TenantID, a.PropertyID); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.GetByID(ctx, a.TenantID, a.AgentUserID); err != nil {
+		return err
+	}
+	if a.BuyerID != 0 {
+		if _, err := s.buyerRepo.GetByID(ctx, a.TenantID, a.BuyerID); err != nil {
+			return err
+		}
+	}
+	if !s.hours.Contains(a.StartsAt, a.EndsAt) {
+		return repos.ErrOutsideWorkingHours
+	}
+
+	for _, f := range []struct {
+		what   string
+		filter models.AppointmentFilter
+	}{
+		{"agent", models.AppointmentFilter{From: a.StartsAt, To: a.EndsAt, AgentUserID: a.AgentUserID}},
+		{"property", models.AppointmentFilter{From: a.StartsAt, To: a.EndsAt, PropertyID: a.PropertyID}},
+	} {
+		rows, err := s.repo.List(ctx, a.TenantID, f.filter)
+		if err != nil {
+			return err
+		}
+		for _, other := range rows {
+			if other.ID != a.ID {
+				return fmt.Errorf("%w: %s is booked %s–%s (appointment %d)", repos.ErrAppointmentConflict,
+					f.what, other.StartsAt.Format(time.RFC3339), other.EndsAt.Format(time.RFC3339), other.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// overlapsAny reports whether [start, end) overlaps any appointment other
+// than skipID.
+func overlapsAny(as []*models.Appointment, start, end time.Time, skipID int64) bool {
+	for _, a := range as {
+		if a.ID != skipID && a.StartsAt.Before(end) && a.EndsAt.After(start) {
+			return true
+		}
+	}
+	return false
+}
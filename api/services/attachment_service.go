@@ -0,0 +1,292 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/storage"
+)
+
+// MaxAttachmentSize caps a single upload.
+const MaxAttachmentSize = 25 << 20
+
+// thumbnailSize is the longest edge, in pixels, of generated thumbnails.
+const thumbnailSize = 320
+
+// maxThumbnailPixels caps the decoded size of an image we will thumbnail. A
+// small compressed file can declare huge dimensions, and decoding it would
+// allocate width*height*4 bytes or more.
+const maxThumbnailPixels = 40_000_000
+
+// Attachment entity types. Each maps to the repo used to check that the
+// parent record exists in the caller's tenant.
+const (
+	AttachmentEntityProperty = "property"
+	AttachmentEntitySale     = "sale"
+	AttachmentEntityLetting  = "letting"
+	AttachmentEntityPlan     = "plan"
+	AttachmentEntityBuyer    = "buyer"
+)
+
+type AttachmentService struct {
+	repo         repos.AttachmentRepo
+	store        storage.Storage
+	propertyRepo repos.PropertyRepo
+	salesRepo    repos.SalesRepo
+	lettingsRepo repos.LettingsRepo
+	planRepo     repos.InstallmentPlanRepo
+	buyerRepo    repos.BuyerRepo
+}
+
+func NewAttachmentService(
+	r repos.AttachmentRepo,
+	store storage.Storage,
+	pr repos.PropertyRepo,
+	sr repos.SalesRepo,
+	lr repos.LettingsRepo,
+	ipr repos.InstallmentPlanRepo,
+	br repos.BuyerRepo,
+) *AttachmentService {
+	return &AttachmentService{
+		repo:         r,
+		store:        store,
+		propertyRepo: pr,
+		salesRepo:    sr,
+		lettingsRepo: lr,
+		planRepo:     ipr,
+		buyerRepo:    br,
+	}
+}
+
+// checkEntity verifies the record an attachment hangs off exists.
+func (s *AttachmentService) checkEntity(ctx context.Context, tenantID, entityType string, entityID int64) error {
+	var err error
+	switch entityType {
+	case AttachmentEntityProperty:
+		_, err = s.propertyRepo.GetByID(ctx, tenantID, entityID)
+	case AttachmentEntitySale:
+		_, err = s.salesRepo.GetByID(ctx, tenantID, entityID)
+	case AttachmentEntityLetting:
+		_, err = s.lettingsRepo.GetByID(ctx, tenantID, entityID)
+	case AttachmentEntityPlan:
+		_, err = s.planRepo.GetByID(ctx, tenantID, entityID)
+	case AttachmentEntityBuyer:
+		_, err = s.buyerRepo.GetByID(ctx, tenantID, entityID)
+	default:
+		return fmt.Errorf("invalid attachment entity type %q", entityType)
+	}
+	return err
+}
+
+// Upload stores the file, generates a thumbnail for images and records the
+// metadata. If the metadata cannot be saved the stored objects are removed.
+func (s *AttachmentService) Upload(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	entityType string,
+	entityID int64,
+	category string,
+	fileName string,
+	r io.Reader,
+) (*models.Attachment, error) {
+	if err := s.checkEntity(ctx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("attachment is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, repos.ErrAttachmentTooLarge
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	mimeType := http.DetectContentType(data)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+
+	a := &models.Attachment{
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Category:   category,
+		FileName:   path.Base(fileName),
+		MimeType:   mimeType,
+		SizeBytes:  int64(len(data)),
+		Checksum:   checksum,
+		StorageKey: fmt.Sprintf("%s/%s/%d/%s", tenantID, entityType, entityID, checksum),
+		UploadedBy: currentUser,
+		CreatedBy:  currentUser,
+		ModifiedBy: currentUser,
+	}
+	if err := s.store.Put(ctx, a.StorageKey, bytes.NewReader(data), a.SizeBytes, a.MimeType); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(mimeType, "image/") {
+		if thumb, err := makeThumbnail(data); err == nil {
+			a.ThumbnailKey = a.StorageKey + ".thumb.jpg"
+			if err := s.store.Put(ctx, a.ThumbnailKey, bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+				a.ThumbnailKey = ""
+			}
+		}
+	}
+	a.HasThumbnail = a.ThumbnailKey != ""
+
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.LastModified = now
+	id, err := s.repo.Create(ctx, a)
+	if err != nil {
+		// Content is addressed by checksum, so another live attachment on the
+		// same record may share these keys; only clean up if none does.
+		if others, lerr := s.repo.ListByEntity(ctx, tenantID, entityType, entityID); lerr == nil && !sharesKey(others, a.StorageKey) {
+			s.store.Delete(ctx, a.StorageKey)
+			if a.ThumbnailKey != "" {
+				s.store.Delete(ctx, a.ThumbnailKey)
+			}
+		}
+		return nil, err
+	}
+	a.ID = id
+	return a, nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, tenantID, entityType string, entityID int64) ([]models.Attachment, error) {
+	if err := s.checkEntity(ctx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	as, err := s.repo.ListByEntity(ctx, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Attachment, 0, len(as))
+	for _, a := range as {
+		out = append(out, *a)
+	}
+	return out, nil
+}
+
+// getForEntity loads an attachment and checks it belongs to the given record
+// and that the caller may see that record, so files cannot be fetched by
+// guessing IDs.
+func (s *AttachmentService) getForEntity(ctx context.Context, tenantID, entityType string, entityID, id int64) (*models.Attachment, error) {
+	if err := s.checkEntity(ctx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	a, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.EntityType != entityType || a.EntityID != entityID {
+		return nil, repos.ErrNotFound
+	}
+	return a, nil
+}
+
+// Download opens the attachment content, or its thumbnail when thumb is set.
+// The caller must close the returned reader.
+func (s *AttachmentService) Download(ctx context.Context, tenantID, entityType string, entityID, id int64, thumb bool) (*models.Attachment, io.ReadCloser, error) {
+	a, err := s.getForEntity(ctx, tenantID, entityType, entityID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := a.StorageKey
+	if thumb {
+		if a.ThumbnailKey == "" {
+			return nil, nil, repos.ErrNotFound
+		}
+		key = a.ThumbnailKey
+	}
+	rc, err := s.store.Get(ctx, key)
+	if err == storage.ErrNotFound {
+		return nil, nil, repos.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, rc, nil
+}
+
+// DeleteAttachment soft-deletes the metadata. Stored content is kept so the
+// record can be restored and because other attachments may share it.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, tenantID, currentUser, entityType string, entityID, id int64) error {
+	if _, err := s.getForEntity(ctx, tenantID, entityType, entityID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, tenantID, currentUser, id)
+}
+
+// makeThumbnail scales an image so its longest edge is thumbnailSize and
+// re-encodes it as JPEG. Images larger than maxThumbnailPixels are refused
+// before they are decoded.
+func makeThumbnail(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("empty image")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxThumbnailPixels {
+		return nil, errors.New("image too large to thumbnail")
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("empty image")
+	}
+	tw, th := thumbnailSize, thumbnailSize
+	if w >= h {
+		th = max(1, h*thumbnailSize/w)
+	} else {
+		tw = max(1, w*thumbnailSize/h)
+	}
+	if w <= tw && h <= th {
+		tw, th = w, h
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sharesKey(as []*models.Attachment, key string) bool {
+	for _, a := range as {
+		if a.StorageKey == key {
+			return true
+		}
+	}
+	return false
+}
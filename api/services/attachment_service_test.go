@@ -0,0 +1,79 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/storage"
+)
+
+// pngOfSize returns a 1x1 PNG whose header claims w x h pixels.
+func pngOfSize(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// The IHDR chunk follows the 8-byte signature: length, type, then
+	// width and height, with its CRC after the 13 data bytes.
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestMakeThumbnailRefusesHugeImages(t *testing.T) {
+	if _, err := makeThumbnail(pngOfSize(t, 1, 1)); err != nil {
+		t.Fatalf("small image: %v", err)
+	}
+	if _, err := makeThumbnail(pngOfSize(t, 50000, 50000)); err == nil {
+		t.Fatal("thumbnailed a 50000x50000 image")
+	}
+}
+
+// An agent cannot fetch or delete the files of a buyer they cannot see.
+func TestAttachmentsFollowRecordScope(t *testing.T) {
+	db := newTestDB(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	svc := NewAttachmentService(repos.NewDBAttachmentRepo(db, "sqlite"), store, repos.NewDBPropertyRepo(db, "sqlite"),
+		repos.NewDBSalesRepo(db, "sqlite"), repos.NewDBLettingsRepo(db, "sqlite"), repos.NewDBInstallmentPlanRepo(db, "sqlite"), buyerRepo)
+
+	owner := asUser(1)
+	buyerID, err := buyerRepo.Create(owner, &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := svc.Upload(owner, testTenant, "alice", AttachmentEntityBuyer, buyerID, "id", "passport.txt", strings.NewReader("passport scan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := asUser(2)
+	if _, _, err := svc.Download(other, testTenant, AttachmentEntityBuyer, buyerID, a.ID, false); !errors.Is(err, repos.ErrNotFound) {
+		t.Errorf("download by another agent: err = %v, want ErrNotFound", err)
+	}
+	if err := svc.DeleteAttachment(other, testTenant, "bob", AttachmentEntityBuyer, buyerID, a.ID); !errors.Is(err, repos.ErrNotFound) {
+		t.Errorf("delete by another agent: err = %v, want ErrNotFound", err)
+	}
+	_, rc, err := svc.Download(owner, testTenant, AttachmentEntityBuyer, buyerID, a.ID, false)
+	if err != nil {
+		t.Fatalf("download by the owner: %v", err)
+	}
+	rc.Close()
+}
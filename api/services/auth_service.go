@@ -2,7 +2,11 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -17,20 +21,85 @@ type JWTClaims struct {
 	UserID      int64    `json:"user_id"`
 	TenantID    string   `json:"tenant_id"`
 	Permissions []string `json:"perms"`
+	// Purpose is empty for access tokens. An MFA challenge token carries
+	// "mfa" and is only accepted by the second login step.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// RefreshTokenTTL is how long a session can go unused before its refresh
+// token expires and the user must log in again.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// MFAChallengeTTL is how long a user has to present their second factor
+// after the password step of login.
+const MFAChallengeTTL = 5 * time.Minute
+
+// MaxMFAAttempts is how many wrong codes an MFA challenge token survives.
+// Wrong codes also count towards the login lockout.
+const MaxMFAAttempts = 5
+
+const purposeMFA = "mfa"
+
+// PurposeAPIKey marks the claims of a request authenticated with an API
+// key rather than a token; their Permissions are the key's.
+const PurposeAPIKey = "api_key"
+
+// AuthService issues short-lived access tokens (JWTs) together with
+// refresh tokens. A refresh token is single use: refreshing returns the
+// next one in the same family, and presenting a spent token again revokes
+// the whole family, since it means the token was copied.
+//
+// Users with MFA enabled, or required by tenant policy, log in in two
+// steps: the password yields a short-lived challenge token, which is
+// exchanged for the session together with a TOTP or recovery code.
 type AuthService struct {
 	userRepo  repos.UserRepo
+	authz     *AuthZService
+	sessions  repos.SessionRepo
+	mfa       *MFAService
+	passwords *PasswordService
+	tenants   *TenantService
+	licenses  *LicenseService
+	apiKeys   *APIKeyService
 	jwtSecret []byte
 	ttl       time.Duration
+
+	mu         sync.Mutex
+	challenges map[string]challengeAttempts // by challenge token ID
+}
+
+// challengeAttempts counts the wrong codes presented with one MFA
+// challenge token until it expires.
+type challengeAttempts struct {
+	failures int
+	expires  time.Time
 }
 
-func NewAuthService(userRepo repos.UserRepo, jwtSecret string, tokenTTL time.Duration) *AuthService {
+func NewAuthService(
+	userRepo repos.UserRepo,
+	authz *AuthZService,
+	sessions repos.SessionRepo,
+	mfa *MFAService,
+	passwords *PasswordService,
+	tenants *TenantService,
+	licenses *LicenseService,
+	apiKeys *APIKeyService,
+	jwtSecret string,
+	accessTTL time.Duration,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: []byte(jwtSecret),
-		ttl:       tokenTTL,
+		userRepo:   userRepo,
+		authz:      authz,
+		sessions:   sessions,
+		mfa:        mfa,
+		passwords:  passwords,
+		tenants:    tenants,
+		licenses:   licenses,
+		apiKeys:    apiKeys,
+		jwtSecret:  []byte(jwtSecret),
+		ttl:        accessTTL,
+		challenges: map[string]challengeAttempts{},
 	}
 }
 
@@ -49,12 +118,16 @@ func (s *AuthService) Register(
 	if existing, _ := s.userRepo.GetByUsername(ctx, tenantID, u.UserName); existing != nil {
 		return 0, repos.ErrUserAlreadyExists
 	}
+	seats, err := s.licenses.Seats(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(rawPassword), bcrypt.DefaultCost)
+	hash, err := s.passwords.Hash(ctx, tenantID, 0, u.UserName, rawPassword)
 	if err != nil {
 		return 0, err
 	}
-	u.PasswordHash = string(hash)
+	u.PasswordHash = hash
 	now := time.Now().UTC()
 	u.TenantID = tenantID
 	u.CreatedAt = now
@@ -63,54 +136,338 @@ func (s *AuthService) Register(
 	u.ModifiedBy = currentUser
 	u.Deleted = false
 
-	return s.userRepo.Create(ctx, &u)
+	id, err := s.userRepo.CreateWithinSeats(ctx, &u, seats)
+	if err != nil {
+		return 0, err
+	}
+	return id, s.passwords.Remember(ctx, tenantID, id, hash)
 }
 
-// Login authenticates username/password, loads permissions, issues JWT.
+// Login authenticates username/password in tenantID. It starts a new
+// session, or, if the user needs a second factor, returns an MFA
+// challenge instead; failures are then only cleared once CompleteMFA
+// succeeds. Repeated failures for a username from the client's ip lock
+// that address out with a *LockedError; users of a suspended tenant get
+// ErrTenantSuspended.
 func (s *AuthService) Login(
 	ctx context.Context,
 	tenantID string,
 	username string,
 	password string,
-) (string, error) {
+	ip string,
+) (*models.LoginResult, error) {
+	if err := s.tenants.CheckActive(ctx, tenantID); errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.passwords.CheckLockout(ctx, tenantID, username, ip); err != nil {
+		return nil, err
+	}
 	user, err := s.userRepo.GetByUsername(ctx, tenantID, username)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		if err := s.passwords.RecordFailure(ctx, tenantID, username, ip); err != nil {
+			return nil, err
+		}
+		return nil, repos.ErrInvalidCredentials
+	}
+	enabled, required, err := s.mfa.Status(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		if err := s.passwords.RecordSuccess(ctx, tenantID, username, ip); err != nil {
+			return nil, err
+		}
+		pair, err := s.issue(ctx, user, "fam_"+randomHex(16))
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{Tokens: pair}, nil
+	}
+	challenge, err := s.sign(user, purposeMFA, nil, MFAChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{MFARequired: true, MFAToken: challenge, EnrolmentRequired: !enabled}, nil
+}
+
+// StartSession issues tokens for a user who has been authenticated
+// elsewhere, by their identity provider.
+func (s *AuthService) StartSession(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	if err := s.tenants.CheckActive(ctx, user.TenantID); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user, "fam_"+randomHex(16))
+}
+
+// BeginChallengeEnrolment starts TOTP enrolment for a user whom policy
+// requires to use MFA but who has not set it up, authorised by their MFA
+// challenge token.
+func (s *AuthService) BeginChallengeEnrolment(ctx context.Context, mfaToken string) (*models.MFAEnrolment, error) {
+	_, user, err := s.challengeUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfa.BeginEnrolment(ctx, user.TenantID, user.ID, user.UserName)
+}
+
+// CompleteMFA finishes a login with the challenge token and either a TOTP
+// code or a recovery code, presented from ip. If the user's enrolment is
+// still pending, the code confirms it and the new recovery codes are
+// returned too. A wrong code counts as a failed login; the challenge token
+// is refused after MaxMFAAttempts of them, and once it has been used.
+func (s *AuthService) CompleteMFA(ctx context.Context, mfaToken, code, recoveryCode, ip string) (*models.LoginResult, error) {
+	claims, user, err := s.challengeUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.passwords.CheckLockout(ctx, user.TenantID, user.UserName, ip); err != nil {
+		return nil, err
+	}
+	res := &models.LoginResult{}
+	enabled, err := s.mfa.Enabled(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !enabled:
+		res.RecoveryCodes, err = s.mfa.ConfirmEnrolment(ctx, user.TenantID, user.ID, code)
+	case recoveryCode != "":
+		err = s.mfa.RedeemRecoveryCode(ctx, user.TenantID, user.ID, recoveryCode)
+	default:
+		err = s.mfa.Verify(ctx, user.TenantID, user.ID, code)
+	}
+	if errors.Is(err, repos.ErrMFAInvalidCode) {
+		s.countChallenge(claims, 1)
+		if ferr := s.passwords.RecordFailure(ctx, user.TenantID, user.UserName, ip); ferr != nil {
+			return nil, ferr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.countChallenge(claims, MaxMFAAttempts)
+	if err := s.passwords.RecordSuccess(ctx, user.TenantID, user.UserName, ip); err != nil {
+		return nil, err
+	}
+	if res.Tokens, err = s.issue(ctx, user, "fam_"+randomHex(16)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// challengeUser returns the claims of an MFA challenge token that has
+// attempts left, and the user it was issued to.
+func (s *AuthService) challengeUser(ctx context.Context, mfaToken string) (*JWTClaims, *models.User, error) {
+	claims, err := s.parse(mfaToken, purposeMFA)
+	if err != nil || claims.ID == "" {
+		return nil, nil, repos.ErrInvalidMFAToken
+	}
+	s.mu.Lock()
+	spent := s.challenges[claims.ID].failures >= MaxMFAAttempts
+	s.mu.Unlock()
+	if spent {
+		return nil, nil, repos.ErrInvalidMFAToken
+	}
+	if err := s.tenants.CheckActive(ctx, claims.TenantID); err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, claims.TenantID, claims.UserID)
+	if err != nil || user.Deleted {
+		return nil, nil, repos.ErrInvalidMFAToken
+	}
+	return claims, user, nil
+}
+
+// countChallenge uses up n of the challenge token's attempts, forgetting
+// tokens that have expired.
+func (s *AuthService) countChallenge(claims *JWTClaims, n int) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, a := range s.challenges {
+		if now.After(a.expires) {
+			delete(s.challenges, id)
+		}
+	}
+	a := s.challenges[claims.ID]
+	a.failures += n
+	if claims.ExpiresAt != nil {
+		a.expires = claims.ExpiresAt.Time
+	}
+	s.challenges[claims.ID] = a
+}
+
+// Refresh spends a refresh token and returns a new access token and the
+// next refresh token of the session.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	t, err := s.sessions.GetRefreshToken(ctx, hashSessionToken(refreshToken))
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if t.RevokedAt != nil || !now.Before(t.ExpiresAt) {
+		return nil, repos.ErrInvalidRefreshToken
+	}
+	if t.UsedAt != nil {
+		return nil, s.reused(ctx, t, now)
+	}
+	ok, err := s.sessions.UseRefreshToken(ctx, t.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Spent by a concurrent refresh with the same token.
+		return nil, s.reused(ctx, t, now)
+	}
+	user, err := s.userRepo.GetByID(ctx, t.TenantID, t.UserID)
+	if err != nil || user.Deleted {
+		return nil, repos.ErrInvalidRefreshToken
+	}
+	if err := s.tenants.CheckActive(ctx, t.TenantID); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user, t.FamilyID)
+}
+
+// reused revokes the family of a refresh token that was presented twice.
+func (s *AuthService) reused(ctx context.Context, t *models.RefreshToken, now time.Time) error {
+	if err := s.sessions.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+		return err
+	}
+	log.Printf("auth: refresh token reuse for user %d in tenant %s; session %s revoked", t.UserID, t.TenantID, t.FamilyID)
+	return repos.ErrRefreshTokenReused
+}
+
+// Logout ends the session the refresh token belongs to. Its access token
+// stays valid until it expires.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	t, err := s.sessions.GetRefreshToken(ctx, hashSessionToken(refreshToken))
+	if errors.Is(err, repos.ErrNotFound) {
+		return repos.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	return s.sessions.RevokeFamily(ctx, t.FamilyID, time.Now().UTC())
+}
+
+// RevokeAllSessions ends every session of the user at once: refresh tokens
+// are revoked and access tokens issued until now are refused.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, tenantID, currentUser string, userID int64) error {
+	if _, err := s.userRepo.GetByID(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUser(ctx, tenantID, userID, time.Now().UTC(), currentUser)
+}
+
+// issue signs an access token for user and adds a refresh token to family.
+func (s *AuthService) issue(ctx context.Context, user *models.User, family string) (*models.TokenPair, error) {
+	// Effective permissions are informational for clients; RequirePermission
+	// resolves them afresh per request.
+	perms, err := s.authz.Permissions(ctx, user.TenantID, user.ID)
 	if err != nil {
-		return "", repos.ErrInvalidCredentials
+		return nil, err
 	}
-	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
-		return "", repos.ErrInvalidCredentials
+	signed, err := s.sign(user, "", perms, s.ttl)
+	if err != nil {
+		return nil, err
 	}
 
-	// 1) Load this user’s permissions from userRepo
-	perms, err := s.userRepo.ListPermissionsForUser(ctx, tenantID, user.ID)
+	now := time.Now().UTC()
+	refresh := "rt_" + randomHex(32)
+	_, err = s.sessions.CreateRefreshToken(ctx, &models.RefreshToken{
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		FamilyID:  family,
+		TokenHash: hashSessionToken(refresh),
+		CreatedAt: now,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return &models.TokenPair{AccessToken: signed, RefreshToken: refresh, ExpiresIn: int64(s.ttl / time.Second)}, nil
+}
 
+// Authenticate parses an access token and refuses it if the user's
+// sessions were revoked after it was issued or their tenant is suspended.
+func (s *AuthService) Authenticate(ctx context.Context, tokenStr string) (*JWTClaims, error) {
+	claims, err := s.ParseToken(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tenants.CheckActive(ctx, claims.TenantID); errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrInvalidTokenClaims
+	} else if err != nil {
+		return nil, err
+	}
+	revokedAt, err := s.sessions.RevokedAt(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// iat has one-second resolution, so a token from the second of the
+	// revocation is refused too.
+	if !revokedAt.IsZero() && (claims.IssuedAt == nil || !claims.IssuedAt.After(revokedAt.Truncate(time.Second))) {
+		return nil, repos.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// AuthenticateAPIKey checks an API key presented from ip and returns
+// claims for the user it acts as, with Permissions limited to the key's.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key, ip string) (*JWTClaims, error) {
+	k, user, err := s.apiKeys.Authenticate(ctx, key, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &JWTClaims{
+		UserID:           user.ID,
+		TenantID:         user.TenantID,
+		Permissions:      k.Permissions,
+		Purpose:          PurposeAPIKey,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.UserName, ID: k.Prefix},
+	}, nil
+}
+
+// sign creates a JWT for user with the given purpose and lifetime.
+func (s *AuthService) sign(user *models.User, purpose string, perms []string, ttl time.Duration) (string, error) {
 	now := time.Now().UTC()
 	claims := JWTClaims{
 		UserID:      user.ID,
 		TenantID:    user.TenantID,
-		Permissions: perms, // e.g. ["create_sale","delete_user","view_commissions"]
+		Permissions: perms,
+		Purpose:     purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			Issuer:    "realtor-installment-app",
-			Subject:   username,
+			Subject:   user.UserName,
+			ID:        randomHex(16),
 		},
 	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(s.jwtSecret)
-	if err != nil {
-		return "", err
-	}
-	return signed, nil
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-// ParseToken verifies a JWT string and returns its claims.
+// ParseToken verifies an access token and returns its claims. MFA
+// challenge tokens are refused.
 func (s *AuthService) ParseToken(tokenStr string) (*JWTClaims, error) {
+	return s.parse(tokenStr, "")
+}
+
+// parse verifies a JWT string issued for purpose and returns its claims.
+func (s *AuthService) parse(tokenStr, purpose string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return s.jwtSecret, nil
 	})
@@ -118,7 +475,7 @@ func (s *AuthService) ParseToken(tokenStr string) (*JWTClaims, error) {
 		return nil, err
 	}
 	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.Purpose != purpose {
 		return nil, repos.ErrInvalidTokenClaims
 	}
 	return claims, nil
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testIP = "192.0.2.1"
+
+// newMFALoginTest returns an AuthService locking out after threshold
+// failures, with alice's password "correct horse" and a confirmed TOTP
+// enrolment whose secret is returned.
+func newMFALoginTest(t *testing.T, threshold int) (*AuthService, string) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	userRepo := repos.NewDBUserRepo(db, "sqlite")
+	roleRepo := repos.NewDBRoleRepo(db, "sqlite")
+	permRepo := repos.NewDBPermissionRepo(db, "sqlite")
+	rolePermRepo := repos.NewDBRolePermissionRepo(db, "sqlite")
+	userRoleRepo := repos.NewDBUserRoleRepo(db, "sqlite")
+	authz := NewAuthZService(permRepo, rolePermRepo, userRoleRepo)
+	access := NewAccessService(roleRepo, permRepo, rolePermRepo, userRoleRepo, userRepo, repos.NewDBAccessAuditRepo(db, "sqlite"), authz, repos.NewTransactor(db))
+	tenants := NewTenantService(repos.NewDBTenantRepo(db, "sqlite"), userRepo, roleRepo, permRepo, access, authz, nil)
+	licenses := NewLicenseService(repos.NewDBLicenseRepo(db, "sqlite"), userRepo, nil, 0, "")
+	sessions := repos.NewDBSessionRepo(db, "sqlite")
+	mfaRepo := repos.NewDBMFARepo(db, "sqlite")
+	passwords := NewPasswordService(repos.NewDBPasswordRepo(db, "sqlite"), userRepo, sessions, nil, "",
+		PasswordPolicy{}, LockoutPolicy{Threshold: threshold})
+	auth := NewAuthService(userRepo, authz, sessions, NewMFAService(mfaRepo, authz, "Test"), passwords, tenants, licenses, nil, "secret", time.Minute)
+
+	if err := repos.NewDBTenantRepo(db, "sqlite").Create(ctx, &models.Tenant{
+		ID: testTenant, Name: "Acme", Status: models.TenantActive,
+		CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID, err := userRepo.Create(ctx, &models.User{
+		TenantID: testTenant, UserName: "alice", PasswordHash: string(hash), FirstName: "Alice", LastName: "Smith",
+		Email: "alice@example.com", Role: "admin", CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfaRepo.SaveTOTP(ctx, &models.MFASecret{
+		TenantID: testTenant, UserID: userID, Secret: secret, ConfirmedAt: &now, CreatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return auth, secret
+}
+
+func challenge(t *testing.T, auth *AuthService) string {
+	t.Helper()
+	res, err := auth.Login(context.Background(), testTenant, "alice", "correct horse", testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.MFARequired {
+		t.Fatal("login did not ask for a second factor")
+	}
+	return res.MFAToken
+}
+
+// wrongCode is a well-formed code from long ago.
+func wrongCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestMFAChallengeRefusedAfterMaxAttempts(t *testing.T) {
+	auth, secret := newMFALoginTest(t, 100)
+	ctx := context.Background()
+	token := challenge(t, auth)
+	for i := 0; i < MaxMFAAttempts; i++ {
+		if _, err := auth.CompleteMFA(ctx, token, wrongCode(t, secret), "", testIP); !errors.Is(err, repos.ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d: err = %v, want ErrMFAInvalidCode", i+1, err)
+		}
+	}
+	good, err := totp.Code(secret, totp.Counter(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.CompleteMFA(ctx, token, good, "", testIP); !errors.Is(err, repos.ErrInvalidMFAToken) {
+		t.Fatalf("good code on a spent challenge: err = %v, want ErrInvalidMFAToken", err)
+	}
+
+	token = challenge(t, auth)
+	res, err := auth.CompleteMFA(ctx, token, good, "", testIP)
+	if err != nil || res.Tokens == nil {
+		t.Fatalf("fresh challenge: res = %+v, err = %v", res, err)
+	}
+	if _, err := auth.CompleteMFA(ctx, token, good, "", testIP); !errors.Is(err, repos.ErrInvalidMFAToken) {
+		t.Fatalf("reused challenge: err = %v, want ErrInvalidMFAToken", err)
+	}
+}
+
+// Wrong codes count towards the lockout, and a good password alone does
+// not clear earlier failures.
+func TestMFAFailuresCountTowardsLockout(t *testing.T) {
+	auth, secret := newMFALoginTest(t, 3)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := auth.Login(ctx, testTenant, "alice", "wrong", testIP); !errors.Is(err, repos.ErrInvalidCredentials) {
+			t.Fatalf("bad password: err = %v", err)
+		}
+	}
+	token := challenge(t, auth)
+	if _, err := auth.CompleteMFA(ctx, token, wrongCode(t, secret), "", testIP); !errors.Is(err, repos.ErrMFAInvalidCode) {
+		t.Fatalf("wrong code: err = %v, want ErrMFAInvalidCode", err)
+	}
+	var locked *LockedError
+	if _, err := auth.CompleteMFA(ctx, token, wrongCode(t, secret), "", testIP); !errors.As(err, &locked) {
+		t.Fatalf("after the threshold: err = %v, want a LockedError", err)
+	}
+	if _, err := auth.Login(ctx, testTenant, "alice", "correct horse", testIP); !errors.As(err, &locked) {
+		t.Fatalf("login after the threshold: err = %v, want a LockedError", err)
+	}
+}
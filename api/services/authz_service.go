@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// DefaultAuthZTTL bounds how long a user's resolved permissions are reused
+// before user_roles and role_permissions are read again.
+const DefaultAuthZTTL = 30 * time.Second
+
+// AuthZService resolves a user's effective permissions: the names of every
+// permission granted to any role the user holds. Results are cached per
+// user for a short TTL; changes made through this service invalidate the
+// affected entries at once, and changes made elsewhere show up once the
+// TTL lapses.
+type AuthZService struct {
+	permRepo     repos.PermissionRepo
+	rolePermRepo repos.RolePermissionRepo
+	userRoleRepo repos.UserRoleRepo
+	ttl          time.Duration
+	now          func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]authzEntry
+	// gen is bumped by every invalidation so that a lookup which started
+	// before it does not cache what it read.
+	gen uint64
+}
+
+type authzEntry struct {
+	perms   []string
+	expires time.Time
+}
+
+func NewAuthZService(pr repos.PermissionRepo, rpr repos.RolePermissionRepo, urr repos.UserRoleRepo) *AuthZService {
+	return &AuthZService{
+		permRepo:     pr,
+		rolePermRepo: rpr,
+		userRoleRepo: urr,
+		ttl:          DefaultAuthZTTL,
+		now:          time.Now,
+		cache:        map[string]authzEntry{},
+	}
+}
+
+// SetTTL changes how long resolved permissions are cached; zero disables
+// the cache.
+func (s *AuthZService) SetTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+	s.cache = map[string]authzEntry{}
+	s.gen++
+}
+
+// Permissions returns the user's effective permission names, sorted.
+func (s *AuthZService) Permissions(ctx context.Context, tenantID string, userID int64) ([]string, error) {
+	key := tenantID + "/" + strconv.FormatInt(userID, 10)
+	s.mu.Lock()
+	if e, ok := s.cache[key]; ok && s.now().Before(e.expires) {
+		s.mu.Unlock()
+		return e.perms, nil
+	}
+	gen := s.gen
+	s.mu.Unlock()
+
+	perms, err := s.resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.gen == gen && s.ttl > 0 {
+		s.cache[key] = authzEntry{perms: perms, expires: s.now().Add(s.ttl)}
+	}
+	s.mu.Unlock()
+	return perms, nil
+}
+
+// HasAny reports whether the user holds at least one of perms.
+func (s *AuthZService) HasAny(ctx context.Context, tenantID string, userID int64, perms ...string) (bool, error) {
+	have, err := s.Permissions(ctx, tenantID, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range perms {
+		if _, ok := slices.BinarySearch(have, p); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolve reads the user's roles and the permissions they grant.
+func (s *AuthZService) resolve(ctx context.Context, userID int64) ([]string, error) {
+	roleIDs, err := s.userRoleRepo.ListRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.PermissionsOfRoles(ctx, roleIDs, nil)
+}
+
+// PermissionsOfRoles returns the sorted permission names granted by
+// roleIDs. override replaces the stored permission IDs of the roles it
+// holds, so a change can be judged before it is made. The three tables may
+// live in different databases, so they are joined here rather than in SQL.
+func (s *AuthZService) PermissionsOfRoles(ctx context.Context, roleIDs []int64, override map[int64][]int64) ([]string, error) {
+	if len(roleIDs) == 0 {
+		return []string{}, nil
+	}
+	granted := map[int64]bool{}
+	for _, roleID := range roleIDs {
+		permIDs, ok := override[roleID]
+		if !ok {
+			var err error
+			if permIDs, err = s.rolePermRepo.ListByRole(ctx, roleID); err != nil {
+				return nil, err
+			}
+		}
+		for _, id := range permIDs {
+			granted[id] = true
+		}
+	}
+	all, err := s.permRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms := []string{}
+	for _, p := range all {
+		if granted[p.ID] {
+			perms = append(perms, p.Name)
+		}
+	}
+	slices.Sort(perms)
+	return slices.Compact(perms), nil
+}
+
+// Invalidate drops the cached permissions of one user in every tenant.
+func (s *AuthZService) Invalidate(userID int64) {
+	suffix := "/" + strconv.FormatInt(userID, 10)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.cache {
+		if strings.HasSuffix(k, suffix) {
+			delete(s.cache, k)
+		}
+	}
+	s.gen++
+}
+
+// InvalidateAll drops every cached entry, for changes such as a role's
+// permissions that may affect any user.
+func (s *AuthZService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[string]authzEntry{}
+	s.gen++
+}
+
+// AssignRole gives the user a role.
+func (s *AuthZService) AssignRole(ctx context.Context, userID, roleID int64) error {
+	defer s.Invalidate(userID)
+	return s.userRoleRepo.Add(ctx, &models.UserRole{UserID: userID, RoleID: roleID})
+}
+
+// UnassignRole takes a role away from the user.
+func (s *AuthZService) UnassignRole(ctx context.Context, userID, roleID int64) error {
+	defer s.Invalidate(userID)
+	return s.userRoleRepo.Remove(ctx, userID, roleID)
+}
+
+// GrantPermission adds a permission to a role.
+func (s *AuthZService) GrantPermission(ctx context.Context, roleID, permissionID int64) error {
+	defer s.InvalidateAll()
+	return s.rolePermRepo.Add(ctx, &models.RolePermission{RoleID: roleID, PermissionID: permissionID})
+}
+
+// RevokePermission removes a permission from a role.
+func (s *AuthZService) RevokePermission(ctx context.Context, roleID, permissionID int64) error {
+	defer s.InvalidateAll()
+	return s.rolePermRepo.Remove(ctx, roleID, permissionID)
+}
+
+// EnsurePermissions adds any of names missing from the permission catalog.
+func (s *AuthZService) EnsurePermissions(ctx context.Context, names ...string) error {
+	all, err := s.permRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for _, p := range all {
+		have[p.Name] = true
+	}
+	for _, name := range names {
+		if have[name] {
+			continue
+		}
+		if _, err := s.permRepo.Create(ctx, &models.Permission{Name: name}); err != nil {
+			return err
+		}
+		have[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// DefaultDuplicateScore is the minimum score FindDuplicates reports when the
+// caller doesn't choose one. A shared email alone clears it; a shared phone
+// needs a similar name as well.
+const DefaultDuplicateScore = 0.5
+
+// Duplicate scoring weights. Scores are capped at 1.
+const (
+	dupEmailWeight = 0.6
+	dupPhoneWeight = 0.4
+	dupNameWeight  = 0.3
+	// dupNameMin is the Jaro-Winkler similarity below which names don't
+	// count towards the score at all.
+	dupNameMin = 0.85
+)
+
+// FindDuplicates scores every pair of live buyers on normalized email,
+// E.164 phone and fuzzy name similarity and returns the pairs scoring at
+// least minScore, best first. The older record of each pair is reported as
+// Buyer, the newer as Duplicate.
+func (s *BuyerService) FindDuplicates(ctx context.Context, tenantID string, minScore float64) ([]models.BuyerDuplicate, error) {
+	if minScore <= 0 {
+		minScore = DefaultDuplicateScore
+	}
+	buyers, err := s.ListBuyers(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(buyers, func(i, j int) bool { return buyers[i].ID < buyers[j].ID })
+
+	type key struct{ email, phone, name string }
+	keys := make([]key, len(buyers))
+	for i, b := range buyers {
+		keys[i] = key{
+			email: normalizeEmail(b.Email),
+			phone: normalizePhoneE164(b.Phone, s.callingCode),
+			name:  normalizeName(b.FirstName + " " + b.LastName),
+		}
+	}
+
+	var out []models.BuyerDuplicate
+	for i := range buyers {
+		for j := i + 1; j < len(buyers); j++ {
+			a, b := keys[i], keys[j]
+			var score float64
+			var reasons []string
+			if a.email != "" && a.email == b.email {
+				score += dupEmailWeight
+				reasons = append(reasons, "email")
+			}
+			if a.phone != "" && a.phone == b.phone {
+				score += dupPhoneWeight
+				reasons = append(reasons, "phone")
+			}
+			if sim := nameSimilarity(a.name, b.name); sim >= dupNameMin {
+				score += dupNameWeight * sim
+				reasons = append(reasons, "name")
+			}
+			if score > 1 {
+				score = 1
+			}
+			if score >= minScore {
+				out = append(out, models.BuyerDuplicate{
+					Buyer:     buyers[i],
+					Duplicate: buyers[j],
+					Score:     float64(int(score*100+0.5)) / 100,
+					Reasons:   reasons,
+				})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
+
+// MergeBuyers folds duplicateID into survivorID: plans, sales, offers, KYC
+// and attachments move to the survivor, the duplicate is soft-deleted and a
+// merge record keeps a snapshot of it.
+func (s *BuyerService) MergeBuyers(ctx context.Context, tenantID, currentUser string, survivorID, duplicateID int64, reason string) (*models.BuyerMerge, error) {
+	if survivorID == 0 || duplicateID == 0 {
+		return nil, errors.New("survivor_id and duplicate_id are required")
+	}
+	if survivorID == duplicateID {
+		return nil, errors.New("cannot merge a buyer into itself")
+	}
+	survivor, err := s.repo.GetByID(ctx, tenantID, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	dup, err := s.repo.GetByID(ctx, tenantID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor.Deleted || dup.Deleted {
+		return nil, repos.ErrNotFound
+	}
+	snapshot, err := json.Marshal(dup)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &models.BuyerMerge{
+		TenantID:          tenantID,
+		SurvivorID:        survivorID,
+		DuplicateID:       duplicateID,
+		DuplicateSnapshot: snapshot,
+		Reassigned:        map[string]int64{},
+		Reason:            reason,
+		MergedBy:          currentUser,
+		MergedAt:          time.Now().UTC(),
+	}
+	id, err := s.mergeRepo.Merge(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	m.ID = id
+	return m, nil
+}
+
+// ListMerges returns the tenant's merge history, newest first.
+func (s *BuyerService) ListMerges(ctx context.Context, tenantID string) ([]models.BuyerMerge, error) {
+	rows, err := s.mergeRepo.ListMerges(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.BuyerMerge, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+// normalizeEmail lower-cases the address and drops any "+tag"; for Gmail
+// addresses dots in the local part are ignored as well.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if i := strings.IndexByte(local, '+'); i > 0 {
+		local = local[:i]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// normalizePhoneE164 returns phone as "+<digits>". Numbers written with a
+// leading 00 are treated as international; a single leading 0 is a national
+// trunk prefix replaced by callingCode. Returns "" when there aren't enough
+// digits to compare.
+func normalizePhoneE164(phone, callingCode string) string {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+	switch {
+	case international:
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case strings.HasPrefix(d, "0") && callingCode != "":
+		d = callingCode + d[1:]
+	case callingCode != "" && !strings.HasPrefix(d, callingCode):
+		d = callingCode + d
+	}
+	if len(d) < 7 || len(d) > 15 {
+		return ""
+	}
+	return "+" + d
+}
+
+// normalizeName strips accents, punctuation and case, and sorts the name
+// parts so "Smith, John" and "john smith" compare equal.
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	parts := strings.Fields(b.String())
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// nameSimilarity is the Jaro-Winkler similarity of two normalized names.
+func nameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchA := make([]bool, len(ra))
+	matchB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchB[j] && ra[i] == rb[j] {
+				matchA[i], matchB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, k := 0, 0
+	for i := range ra {
+		if !matchA[i] {
+			continue
+		}
+		for !matchB[k] {
+			k++
+		}
+		if ra[i] != rb[k] {
+			transpositions++
+		}
+		k++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
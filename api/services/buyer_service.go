@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
@@ -10,11 +11,15 @@ import (
 )
 
 type BuyerService struct {
-	repo repos.BuyerRepo
+	repo      repos.BuyerRepo
+	mergeRepo repos.BuyerMergeRepo
+	// callingCode is the country calling code (digits only, e.g. "44")
+	// assumed for phone numbers entered in national format.
+	callingCode string
 }
 
-func NewBuyerService(r repos.BuyerRepo) *BuyerService {
-	return &BuyerService{repo: r}
+func NewBuyerService(r repos.BuyerRepo, mr repos.BuyerMergeRepo, callingCode string) *BuyerService {
+	return &BuyerService{repo: r, mergeRepo: mr, callingCode: strings.TrimPrefix(callingCode, "+")}
 }
 
 func (s *BuyerService) CreateBuyer(ctx context.Context, tenantID, currentUser string, b models.Buyer) (int64, error) {
@@ -55,6 +60,7 @@ func (s *BuyerService) UpdateBuyer(ctx context.Context, tenantID, currentUser st
 		return repos.ErrNotFound
 	}
 	now := time.Now().UTC()
+	b.AssignedTo = assignee(ctx, existing.AssignedTo, b.AssignedTo)
 	b.TenantID = tenantID
 	b.ID = id
 	b.ModifiedBy = currentUser
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/ical"
+)
+
+// Calendar feed window. Recent past events are kept so they don't vanish
+// from subscribers' calendars the moment they pass.
+const (
+	FeedLookback = 30 * 24 * time.Hour
+	FeedHorizon  = 18 * 30 * 24 * time.Hour
+)
+
+// CalendarFeedService publishes per-user iCalendar feeds: installment due
+// dates on the user's plans, lease end dates and yearly rent reviews on the
+// user's lettings, and the user's viewing appointments.
+//
+// Plans and lettings belong to the user who created them. Event UIDs are
+// derived from record IDs so a client refreshing the feed updates events in
+// place.
+type CalendarFeedService struct {
+	repo         repos.CalendarFeedRepo
+	userRepo     repos.UserRepo
+	planRepo     repos.InstallmentPlanRepo
+	instRepo     repos.InstallmentRepo
+	lettingsRepo repos.LettingsRepo
+	appointments *AppointmentService
+}
+
+func NewCalendarFeedService(
+	r repos.CalendarFeedRepo,
+	ur repos.UserRepo,
+	pr repos.InstallmentPlanRepo,
+	ir repos.InstallmentRepo,
+	lr repos.LettingsRepo,
+	as *AppointmentService,
+) *CalendarFeedService {
+	return &CalendarFeedService{repo: r, userRepo: ur, planRepo: pr, instRepo: ir, lettingsRepo: lr, appointments: as}
+}
+
+// IssueToken revokes the user's existing feed token and returns a new one.
+// Only its hash is stored, so the caller must hand it out now.
+func (s *CalendarFeedService) IssueToken(ctx context.Context, tenantID string, userID int64) (string, error) {
+	if _, err := s.userRepo.GetByID(ctx, tenantID, userID); err != nil {
+		return "", err
+	}
+	if err := s.repo.RevokeForUser(ctx, tenantID, userID); err != nil {
+		return "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	_, err := s.repo.Create(ctx, &models.CalendarFeedToken{
+		TenantID:  tenantID,
+		UserID:    userID,
+		TokenHash: hashFeedToken(token),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeToken disables the user's feed.
+func (s *CalendarFeedService) RevokeToken(ctx context.Context, tenantID string, userID int64) error {
+	return s.repo.RevokeForUser(ctx, tenantID, userID)
+}
+
+// WriteFeed writes the calendar of the user owning token. Unknown or
+// revoked tokens return repos.ErrNotFound.
+func (s *CalendarFeedService) WriteFeed(ctx context.Context, token string, w io.Writer) error {
+	t, err := s.repo.GetByHash(ctx, hashFeedToken(token))
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, t.TenantID, t.UserID)
+	if err != nil {
+		return err
+	}
+	_ = s.repo.Touch(ctx, t.ID)
+
+	now := time.Now().UTC()
+	from, to := now.Add(-FeedLookback), now.Add(FeedHorizon)
+	cal := &ical.Calendar{ProdID: ICalProdID, Name: "Realtor: " + user.UserName}
+
+	events, err := s.installmentEvents(ctx, user, from, to)
+	if err != nil {
+		return err
+	}
+	cal.Events = append(cal.Events, events...)
+
+	events, err = s.lettingEvents(ctx, user, from, to)
+	if err != nil {
+		return err
+	}
+	cal.Events = append(cal.Events, events...)
+
+	appts, err := s.appointments.repo.List(ctx, user.TenantID, models.AppointmentFilter{
+		From:             from,
+		To:               to,
+		AgentUserID:      user.ID,
+		IncludeCancelled: true,
+	})
+	if err != nil {
+		return err
+	}
+	props := map[int64]*models.Property{}
+	for _, a := range appts {
+		cal.Events = append(cal.Events, s.appointments.appointmentEvent(ctx, user.TenantID, a, props))
+	}
+	return cal.Write(w)
+}
+
+func (s *CalendarFeedService) installmentEvents(ctx context.Context, user *models.User, from, to time.Time) ([]ical.Event, error) {
+	plans, err := s.planRepo.ListAll(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	var out []ical.Event
+	for _, p := range plans {
+		if p.CreatedBy != user.UserName {
+			continue
+		}
+		insts, err := s.instRepo.ListByPlan(ctx, user.TenantID, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range insts {
+			if inst.DueDate.Before(from) || inst.DueDate.After(to) {
+				continue
+			}
+			status := "CONFIRMED"
+			if inst.Status == models.InstallmentPaid {
+				status = "CANCELLED"
+			}
+			out = append(out, ical.Event{
+				UID:     fmt.Sprintf("installment-%d-%s@realtorinstall", inst.ID, user.TenantID),
+				Summary: fmt.Sprintf("Installment %d due: %.2f (plan #%d)", inst.SequenceNumber, inst.AmountDue-inst.AmountPaid, p.ID),
+				Description: fmt.Sprintf("Buyer #%d, property #%d. Amount due %.2f, paid %.2f, status %s.",
+					p.BuyerID, p.PropertyID, inst.AmountDue, inst.AmountPaid, inst.Status),
+				Start:   inst.DueDate,
+				AllDay:  true,
+				Updated: inst.LastModified,
+				Status:  status,
+			})
+		}
+	}
+	return out, nil
+}
+
+func (s *CalendarFeedService) lettingEvents(ctx context.Context, user *models.User, from, to time.Time) ([]ical.Event, error) {
+	lettings, err := s.lettingsRepo.ListAll(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	var out []ical.Event
+	for _, l := range lettings {
+		if l.CreatedBy != user.UserName {
+			continue
+		}
+		if !l.EndDate.IsZero() && !l.EndDate.Before(from) && !l.EndDate.After(to) {
+			out = append(out, ical.Event{
+				UID:         fmt.Sprintf("letting-%d-end-%s@realtorinstall", l.ID, user.TenantID),
+				Summary:     fmt.Sprintf("Lease ends: property #%d", l.PropertyID),
+				Description: fmt.Sprintf("Letting #%d to tenant #%d, rent %.2f %s.", l.ID, l.TenantUserID, l.RentAmount, l.RentCycle),
+				Start:       l.EndDate,
+				AllDay:      true,
+				Updated:     l.LastModified,
+			})
+		}
+		// Rent is reviewed on each anniversary of the start date while the
+		// lease runs.
+		for n := 1; !l.StartDate.IsZero(); n++ {
+			review := l.StartDate.AddDate(n, 0, 0)
+			if review.After(to) || (!l.EndDate.IsZero() && !review.Before(l.EndDate)) {
+				break
+			}
+			if review.Before(from) {
+				continue
+			}
+			out = append(out, ical.Event{
+				UID:         fmt.Sprintf("letting-%d-review-%d-%s@realtorinstall", l.ID, n, user.TenantID),
+				Summary:     fmt.Sprintf("Rent review: property #%d", l.PropertyID),
+				Description: fmt.Sprintf("Letting #%d, current rent %.2f %s.", l.ID, l.RentAmount, l.RentCycle),
+				Start:       review,
+				AllDay:      true,
+				Updated:     l.LastModified,
+			})
+		}
+	}
+	return out, nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
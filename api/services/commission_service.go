@@ -15,6 +15,7 @@ type CommissionService struct {
 	lettingRepo repos.LettingsRepo
 	introRepo   repos.IntroductionsRepo
 	userRepo    repos.UserRepo
+	events      EventPublisher
 }
 
 func NewCommissionService(
@@ -23,6 +24,7 @@ func NewCommissionService(
 	lr repos.LettingsRepo,
 	ir repos.IntroductionsRepo,
 	ur repos.UserRepo,
+	events EventPublisher,
 ) *CommissionService {
 	return &CommissionService{
 		repo:        cr,
@@ -30,6 +32,7 @@ func NewCommissionService(
 		lettingRepo: lr,
 		introRepo:   ir,
 		userRepo:    ur,
+		events:      orNop(events),
 	}
 }
 
@@ -147,6 +150,8 @@ func (s *CommissionService) UpdateCommission(
 	comm.CreatedAt = existing.CreatedAt
 	comm.CreatedBy = existing.CreatedBy
 	comm.Deleted = existing.Deleted
+	comm.ApprovedBy = existing.ApprovedBy
+	comm.ApprovedAt = existing.ApprovedAt
 
 	// Recompute calculated_amount if percentage
 	if comm.CommissionType == "percentage" {
@@ -174,6 +179,36 @@ func (s *CommissionService) UpdateCommission(
 	return s.repo.Update(ctx, &comm)
 }
 
+// ApproveCommission signs off a commission for payout and publishes
+// commission.approved. A commission can only be approved once.
+func (s *CommissionService) ApproveCommission(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+) error {
+	existing, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if existing.Deleted {
+		return repos.ErrNotFound
+	}
+	if existing.ApprovedBy != "" {
+		return repos.ErrCommissionAlreadyApproved
+	}
+	now := time.Now().UTC()
+	existing.ApprovedBy = currentUser
+	existing.ApprovedAt = &now
+	existing.ModifiedBy = currentUser
+	existing.LastModified = now
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, tenantID, models.EventCommissionApproved, existing)
+	return nil
+}
+
 func (s *CommissionService) DeleteCommission(
 	ctx context.Context,
 	tenantID string,
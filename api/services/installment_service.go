@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"log"
 	"time"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
@@ -12,10 +13,11 @@ import (
 type InstallmentService struct {
 	repo        repos.InstallmentRepo
 	paymentRepo repos.PaymentRepo
+	events      EventPublisher
 }
 
-func NewInstallmentService(r repos.InstallmentRepo, pr repos.PaymentRepo) *InstallmentService {
-	return &InstallmentService{repo: r, paymentRepo: pr}
+func NewInstallmentService(r repos.InstallmentRepo, pr repos.PaymentRepo, events EventPublisher) *InstallmentService {
+	return &InstallmentService{repo: r, paymentRepo: pr, events: orNop(events)}
 }
 
 func (s *InstallmentService) CreateInstallment(ctx context.Context, tenantID, currentUser string, inst models.Installment) (int64, error) {
@@ -85,3 +87,59 @@ func (s *InstallmentService) DeleteInstallment(ctx context.Context, tenantID, cu
 	existing.LastModified = time.Now().UTC()
 	return s.repo.Update(ctx, existing)
 }
+
+// MarkOverdue flags unpaid installments of every tenant that were due
+// before today and publishes installment.overdue for each newly overdue
+// one. It returns how many changed.
+func (s *InstallmentService) MarkOverdue(ctx context.Context, now time.Time) (int, error) {
+	insts, err := s.repo.ListUnpaidDueBefore(ctx, truncateDay(now.UTC()))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, inst := range insts {
+		if inst.Status == models.InstallmentOverdue {
+			continue
+		}
+		inst.Status = models.InstallmentOverdue
+		inst.ModifiedBy = "system"
+		if err := s.repo.Update(ctx, inst); err != nil {
+			return n, err
+		}
+		n++
+		s.events.Publish(ctx, inst.TenantID, models.EventInstallmentOverdue, inst)
+	}
+	return n, nil
+}
+
+// Start runs MarkOverdue every interval until ctx is cancelled.
+func (s *InstallmentService) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if n, err := s.MarkOverdue(ctx, time.Now()); err != nil {
+			log.Printf("installments: overdue sweep: %v", err)
+		} else if n > 0 {
+			log.Printf("installments: %d newly overdue", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// ConsumerInstallmentPayments names the outbox consumer that keeps
+// installment balances in step with the payments database.
+const ConsumerInstallmentPayments = "installments.payments"
+
+// ApplyPaymentEvent is the outbox handler for models.TopicPaymentApplied.
+func (s *InstallmentService) ApplyPaymentEvent(ctx context.Context, ev *models.OutboxEvent) error {
+	var p models.PaymentApplied
+	if err := decodeOutboxPayload(ev, &p); err != nil {
+		return err
+	}
+	_, err := s.repo.ApplyPayment(ctx, ConsumerInstallmentPayments, ev.ID, ev.TenantID, p.InstallmentID, p.Delta, p.PaidDate, p.ModifiedBy)
+	return err
+}
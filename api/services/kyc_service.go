@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// KYCOverrideTTL is how long an unused override stays valid.
+const KYCOverrideTTL = 24 * time.Hour
+
+// KYCService manages buyer identity / source-of-funds verification and the
+// check run before a buyer can be put on a plan or sold a property.
+type KYCService struct {
+	repo           repos.KYCRepo
+	buyerRepo      repos.BuyerRepo
+	attachmentRepo repos.AttachmentRepo
+}
+
+func NewKYCService(r repos.KYCRepo, br repos.BuyerRepo, ar repos.AttachmentRepo) *KYCService {
+	return &KYCService{repo: r, buyerRepo: br, attachmentRepo: ar}
+}
+
+// GetKYC returns the buyer's KYC record.
+func (s *KYCService) GetKYC(ctx context.Context, tenantID string, buyerID int64) (*models.BuyerKYC, error) {
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByBuyer(ctx, tenantID, buyerID)
+}
+
+// SubmitKYC creates or replaces the buyer's KYC details. Any change puts the
+// record back to pending until it is verified again. Document attachment IDs,
+// when given, must be attachments uploaded against this buyer.
+func (s *KYCService) SubmitKYC(ctx context.Context, tenantID, currentUser string, buyerID int64, k models.BuyerKYC) (int64, error) {
+	if k.IDDocumentType == "" || k.IDDocumentNumber == "" || k.IDDocumentExpiry.IsZero() {
+		return 0, errors.New("ID document type, number and expiry are required")
+	}
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return 0, err
+	}
+	for _, id := range []int64{k.IDDocumentAttachmentID, k.AddressProofAttachmentID} {
+		if err := s.checkDocument(ctx, tenantID, buyerID, id); err != nil {
+			return 0, err
+		}
+	}
+
+	k.TenantID = tenantID
+	k.BuyerID = buyerID
+	k.Status = models.KYCPending
+	k.VerifiedBy = ""
+	k.VerifiedAt = nil
+	k.ModifiedBy = currentUser
+	k.Deleted = false
+
+	existing, err := s.repo.GetByBuyer(ctx, tenantID, buyerID)
+	if err == repos.ErrNotFound {
+		k.CreatedBy = currentUser
+		return s.repo.Create(ctx, &k)
+	}
+	if err != nil {
+		return 0, err
+	}
+	k.ID = existing.ID
+	k.CreatedBy = existing.CreatedBy
+	k.CreatedAt = existing.CreatedAt
+	return k.ID, s.repo.Update(ctx, &k)
+}
+
+// VerifyKYC approves or rejects the buyer's pending KYC, recording the
+// verifying user. A record already verified must be resubmitted first.
+func (s *KYCService) VerifyKYC(ctx context.Context, tenantID, currentUser string, buyerID int64, status, notes string) error {
+	if status != models.KYCApproved && status != models.KYCRejected {
+		return errors.New("status must be approved or rejected")
+	}
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return err
+	}
+	k, err := s.repo.GetByBuyer(ctx, tenantID, buyerID)
+	if err != nil {
+		return err
+	}
+	if k.Status != models.KYCPending {
+		return repos.ErrKYCNotPending
+	}
+	if status == models.KYCApproved && !k.IDDocumentExpiry.After(time.Now().UTC()) {
+		return repos.ErrKYCExpired
+	}
+	now := time.Now().UTC()
+	k.Status = status
+	k.VerifiedBy = currentUser
+	k.VerifiedAt = &now
+	if notes != "" {
+		k.Notes = notes
+	}
+	k.ModifiedBy = currentUser
+	return s.repo.Update(ctx, k)
+}
+
+// GrantOverride lets one action go ahead for a buyer whose KYC would
+// otherwise block it. The override is single-use and expires after
+// KYCOverrideTTL; the grant and its use are both recorded.
+func (s *KYCService) GrantOverride(ctx context.Context, tenantID, currentUser string, buyerID int64, action, reason string) (int64, error) {
+	if action != models.KYCActionCreatePlan && action != models.KYCActionCreateSale {
+		return 0, errors.New("action must be create_plan or create_sale")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return 0, errors.New("an override reason is required")
+	}
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	return s.repo.CreateOverride(ctx, &models.KYCOverride{
+		TenantID:  tenantID,
+		BuyerID:   buyerID,
+		Action:    action,
+		Reason:    strings.TrimSpace(reason),
+		GrantedBy: currentUser,
+		GrantedAt: now,
+		ExpiresAt: now.Add(KYCOverrideTTL),
+	})
+}
+
+// ListOverrides returns every override granted for the buyer, newest first.
+func (s *KYCService) ListOverrides(ctx context.Context, tenantID string, buyerID int64) ([]models.KYCOverride, error) {
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, buyerID); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListOverrides(ctx, tenantID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.KYCOverride, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+// CheckBuyer returns nil if the buyer's KYC is approved and the ID document
+// is still valid, or if an outstanding override for the action exists;
+// otherwise it returns ErrKYCNotApproved or ErrKYCExpired. The function it
+// returns consumes the override. Callers run it last in the transaction
+// that writes the plan or sale, so a failed write leaves the override
+// unspent and an override spent concurrently rolls the write back. Without
+// an override it does nothing.
+func (s *KYCService) CheckBuyer(ctx context.Context, tenantID, currentUser string, buyerID int64, action string) (func(ctx context.Context) error, error) {
+	now := time.Now().UTC()
+	k, err := s.repo.GetByBuyer(ctx, tenantID, buyerID)
+	if err != nil && err != repos.ErrNotFound {
+		return nil, err
+	}
+	var reason error
+	switch {
+	case k == nil || k.Status != models.KYCApproved:
+		reason = repos.ErrKYCNotApproved
+	case !k.IDDocumentExpiry.After(now):
+		reason = repos.ErrKYCExpired
+	default:
+		return noKYCOverride, nil
+	}
+
+	o, err := s.repo.FindUsableOverride(ctx, tenantID, buyerID, action, now)
+	if err == repos.ErrNotFound {
+		return nil, reason
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		err := s.repo.UseOverride(ctx, tenantID, o.ID, currentUser, time.Now().UTC())
+		if err == repos.ErrNotFound {
+			// Spent concurrently by another request.
+			return reason
+		}
+		return err
+	}, nil
+}
+
+func noKYCOverride(context.Context) error { return nil }
+
+// checkDocument verifies attachmentID (if non-zero) is one of the buyer's
+// uploads.
+func (s *KYCService) checkDocument(ctx context.Context, tenantID string, buyerID, attachmentID int64) error {
+	if attachmentID == 0 {
+		return nil
+	}
+	a, err := s.attachmentRepo.GetByID(ctx, tenantID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if a.EntityType != AttachmentEntityBuyer || a.EntityID != buyerID {
+		return errors.New("document is not attached to this buyer")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// failingPlans is a plan repo whose creates fail.
+type failingPlans struct {
+	repos.InstallmentPlanRepo
+}
+
+var errPlanCreate = errors.New("plan write failed")
+
+func (failingPlans) Create(context.Context, *models.InstallmentPlan) (int64, error) {
+	return 0, errPlanCreate
+}
+
+// An override is only spent by a plan that is actually written.
+func TestKYCOverrideSpentOnlyByWrittenPlan(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kycRepo := repos.NewDBKYCRepo(db, "sqlite")
+	planRepo := repos.NewDBInstallmentPlanRepo(db, "sqlite")
+	kyc := NewKYCService(kycRepo, buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+
+	buyerID, err := buyerRepo.Create(ctx, &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kyc.GrantOverride(ctx, testTenant, "alice", buyerID, models.KYCActionCreatePlan, "director sign-off"); err != nil {
+		t.Fatal(err)
+	}
+	plan := models.InstallmentPlan{
+		PropertyID: 1, BuyerID: buyerID, TotalPrice: 1000, NumInstallments: 2,
+		Frequency: "Monthly", FirstInstallment: time.Now(),
+	}
+
+	failing := NewPlanService(failingPlans{planRepo}, repos.NewDBInstallmentRepo(db, "sqlite"), repos.NewDBSalesRepo(db, "sqlite"), kyc, repos.NewTransactor(db))
+	if _, err := failing.CreatePlan(ctx, testTenant, "bob", plan); !errors.Is(err, errPlanCreate) {
+		t.Fatalf("err = %v, want the plan failure", err)
+	}
+	if _, err := kycRepo.FindUsableOverride(ctx, testTenant, buyerID, models.KYCActionCreatePlan, time.Now()); err != nil {
+		t.Fatalf("override spent by a failed plan: %v", err)
+	}
+
+	svc := NewPlanService(planRepo, repos.NewDBInstallmentRepo(db, "sqlite"), repos.NewDBSalesRepo(db, "sqlite"), kyc, repos.NewTransactor(db))
+	if _, err := svc.CreatePlan(ctx, testTenant, "bob", plan); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreatePlan(ctx, testTenant, "bob", plan); !errors.Is(err, repos.ErrKYCNotApproved) {
+		t.Fatalf("second plan: err = %v, want ErrKYCNotApproved", err)
+	}
+}
+
+// An agent cannot read, verify or audit the KYC of a buyer they cannot see.
+func TestKYCFollowsBuyerScope(t *testing.T) {
+	db := newTestDB(t)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+
+	owner := asUser(1)
+	buyerID, err := buyerRepo.Create(owner, &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kyc.SubmitKYC(owner, testTenant, "alice", buyerID, models.BuyerKYC{
+		IDDocumentType: "passport", IDDocumentNumber: "X1", IDDocumentExpiry: time.Now().AddDate(1, 0, 0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := asUser(2)
+	if _, err := kyc.GetKYC(other, testTenant, buyerID); !errors.Is(err, repos.ErrNotFound) {
+		t.Errorf("GetKYC: err = %v, want ErrNotFound", err)
+	}
+	if err := kyc.VerifyKYC(other, testTenant, "bob", buyerID, models.KYCApproved, ""); !errors.Is(err, repos.ErrNotFound) {
+		t.Errorf("VerifyKYC: err = %v, want ErrNotFound", err)
+	}
+	if _, err := kyc.ListOverrides(other, testTenant, buyerID); !errors.Is(err, repos.ErrNotFound) {
+		t.Errorf("ListOverrides: err = %v, want ErrNotFound", err)
+	}
+	if _, err := kyc.GetKYC(owner, testTenant, buyerID); err != nil {
+		t.Errorf("GetKYC by the owner: %v", err)
+	}
+}
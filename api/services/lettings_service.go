@@ -10,11 +10,12 @@ import (
 )
 
 type LettingsService struct {
-	repo repos.LettingsRepo
+	repo   repos.LettingsRepo
+	events EventPublisher
 }
 
-func NewLettingsService(r repos.LettingsRepo) *LettingsService {
-	return &LettingsService{repo: r}
+func NewLettingsService(r repos.LettingsRepo, events EventPublisher) *LettingsService {
+	return &LettingsService{repo: r, events: orNop(events)}
 }
 
 func (s *LettingsService) CreateLetting(
@@ -35,7 +36,13 @@ func (s *LettingsService) CreateLetting(
 	l.ModifiedBy = currentUser
 	l.Deleted = false
 
-	return s.repo.Create(ctx, &l)
+	id, err := s.repo.Create(ctx, &l)
+	if err != nil {
+		return 0, err
+	}
+	l.ID = id
+	s.events.Publish(ctx, tenantID, models.EventLettingCreated, l)
+	return id, nil
 }
 
 func (s *LettingsService) ListLettings(
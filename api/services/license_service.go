@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/license"
+)
+
+// PermManageLicense allows installing the tenant's license file.
+const PermManageLicense = "manage_license"
+
+const (
+	// DefaultLicenseGrace is how long a tenant keeps working after its
+	// license expires.
+	DefaultLicenseGrace = 14 * 24 * time.Hour
+	// licenseWarnWindow is how long before expiry responses start
+	// carrying a warning.
+	licenseWarnWindow = 30 * 24 * time.Hour
+	// licenseCacheTTL bounds how long a verified license is reused before
+	// it is read again; Install applies at once.
+	licenseCacheTTL = time.Minute
+)
+
+// LicenseService checks tenants' signed licenses: expiry with a grace
+// period, the modules they include and their seat count. Without a
+// vendor public key licensing is off and every check passes; the
+// platform tenant is never licensed.
+type LicenseService struct {
+	repo     repos.LicenseRepo
+	userRepo repos.UserRepo
+	key      ed25519.PublicKey
+	grace    time.Duration
+	exempt   string
+
+	mu    sync.Mutex
+	cache map[string]cachedLicense
+}
+
+type cachedLicense struct {
+	lic     *license.License
+	err     error
+	expires time.Time
+}
+
+// NewLicenseService verifies licenses against key. A zero grace uses
+// DefaultLicenseGrace; exemptTenant (the platform tenant) needs no
+// license.
+func NewLicenseService(r repos.LicenseRepo, ur repos.UserRepo, key ed25519.PublicKey, grace time.Duration, exemptTenant string) *LicenseService {
+	if grace == 0 {
+		grace = DefaultLicenseGrace
+	}
+	return &LicenseService{
+		repo:     r,
+		userRepo: ur,
+		key:      key,
+		grace:    grace,
+		exempt:   exemptTenant,
+		cache:    map[string]cachedLicense{},
+	}
+}
+
+// Enabled reports whether licenses are enforced for tenantID.
+func (s *LicenseService) Enabled(tenantID string) bool {
+	return s.key != nil && tenantID != s.exempt
+}
+
+// Install verifies data as a license file for tenantID and makes it the
+// tenant's license. Files that are not signed by the vendor, are for
+// another tenant or have already run out are refused.
+func (s *LicenseService) Install(ctx context.Context, tenantID, currentUser string, data []byte) (*models.LicenseStatus, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("%w: licensing is not configured", repos.ErrLicenseInvalid)
+	}
+	lic, err := license.Verify(s.key, data)
+	if err != nil {
+		return nil, repos.ErrLicenseInvalid
+	}
+	if lic.Tenant != tenantID {
+		return nil, repos.ErrLicenseTenantMismatch
+	}
+	if lic.Status(time.Now(), licenseWarnWindow, s.grace) == license.StatusExpired {
+		return nil, repos.ErrLicenseExpired
+	}
+	if err := s.repo.Save(ctx, &models.TenantLicense{
+		TenantID:    tenantID,
+		LicenseID:   lic.ID,
+		Data:        string(data),
+		InstalledBy: currentUser,
+		InstalledAt: time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.cache, tenantID)
+	s.mu.Unlock()
+	return s.Status(ctx, tenantID)
+}
+
+// Status describes the tenant's license and how many seats are in use.
+func (s *LicenseService) Status(ctx context.Context, tenantID string) (*models.LicenseStatus, error) {
+	st := &models.LicenseStatus{Status: license.StatusValid, Modules: []string{}}
+	users, err := s.userRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	st.SeatsUsed = len(users)
+	if !s.Enabled(tenantID) {
+		st.Modules = []string{license.ModuleLettings, license.ModuleCommissions, license.ModuleReports}
+		return st, nil
+	}
+	lic, err := s.load(ctx, tenantID)
+	if errors.Is(err, repos.ErrNoLicense) {
+		st.Status = license.StatusExpired
+		st.Warning = repos.ErrNoLicense.Error()
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st.Licensed = true
+	st.LicenseID = lic.ID
+	st.Licensee = lic.Licensee
+	st.Seats = lic.Seats
+	st.Modules = append(st.Modules, lic.Modules...)
+	exp, graceEnds := lic.ExpiresAt, lic.ExpiresAt.Add(s.grace)
+	st.ExpiresAt, st.GraceEnds = &exp, &graceEnds
+	st.Status = lic.Status(time.Now(), licenseWarnWindow, s.grace)
+	st.Warning = s.warning(lic, st.Status)
+	return st, nil
+}
+
+// Check is run on every authenticated request. It returns ErrNoLicense,
+// ErrLicenseInvalid or ErrLicenseExpired once the grace period is over;
+// otherwise a warning to pass on while the license is close to or past
+// its expiry.
+func (s *LicenseService) Check(ctx context.Context, tenantID string) (string, error) {
+	if !s.Enabled(tenantID) {
+		return "", nil
+	}
+	lic, err := s.load(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	status := lic.Status(time.Now(), licenseWarnWindow, s.grace)
+	if status == license.StatusExpired {
+		return "", repos.ErrLicenseExpired
+	}
+	return s.warning(lic, status), nil
+}
+
+// CheckModule returns ErrModuleNotLicensed unless the tenant's license
+// includes module.
+func (s *LicenseService) CheckModule(ctx context.Context, tenantID, module string) error {
+	if !s.Enabled(tenantID) {
+		return nil
+	}
+	lic, err := s.load(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !lic.HasModule(module) {
+		return repos.ErrModuleNotLicensed
+	}
+	return nil
+}
+
+// Seats returns the seat limit new users of the tenant are created
+// within, for UserRepo.CreateWithinSeats; 0 means no limit.
+func (s *LicenseService) Seats(ctx context.Context, tenantID string) (int, error) {
+	if !s.Enabled(tenantID) {
+		return 0, nil
+	}
+	lic, err := s.load(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return lic.Seats, nil
+}
+
+func (s *LicenseService) warning(lic *license.License, status string) string {
+	switch status {
+	case license.StatusExpiring:
+		return fmt.Sprintf("license expires on %s", lic.ExpiresAt.Format("2006-01-02"))
+	case license.StatusGrace:
+		return fmt.Sprintf("license expired on %s; service stops on %s unless a new license is installed",
+			lic.ExpiresAt.Format("2006-01-02"), lic.ExpiresAt.Add(s.grace).Format("2006-01-02"))
+	}
+	return ""
+}
+
+// load returns the tenant's verified license. A stored file that no
+// longer verifies, say after the vendor key changed, is ErrLicenseInvalid.
+func (s *LicenseService) load(ctx context.Context, tenantID string) (*license.License, error) {
+	s.mu.Lock()
+	e, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.lic, e.err
+	}
+	e = cachedLicense{expires: time.Now().Add(licenseCacheTTL)}
+	stored, err := s.repo.Get(ctx, tenantID)
+	switch {
+	case errors.Is(err, repos.ErrNotFound):
+		e.err = repos.ErrNoLicense
+	case err != nil:
+		return nil, err
+	default:
+		e.lic, err = license.Verify(s.key, []byte(stored.Data))
+		if err != nil || e.lic.Tenant != tenantID {
+			e.lic, e.err = nil, repos.ErrLicenseInvalid
+		}
+	}
+	s.mu.Lock()
+	s.cache[tenantID] = e
+	s.mu.Unlock()
+	return e.lic, e.err
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/license"
+)
+
+// slowUserList widens the window between counting a tenant's users and
+// adding one, as a loaded database would.
+type slowUserList struct {
+	repos.UserRepo
+}
+
+func (r slowUserList) ListAll(ctx context.Context, tenantID string) ([]*models.User, error) {
+	users, err := r.UserRepo.ListAll(ctx, tenantID)
+	time.Sleep(20 * time.Millisecond)
+	return users, err
+}
+
+func newLicenseTest(t *testing.T) (*LicenseService, *UserService, ed25519.PrivateKey) {
+	t.Helper()
+	db := newTestDB(t)
+	now := time.Now().UTC()
+	if err := repos.NewDBTenantRepo(db, "sqlite").Create(context.Background(), &models.Tenant{
+		ID: testTenant, Name: "Acme", Status: models.TenantActive,
+		CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo := slowUserList{repos.NewDBUserRepo(db, "sqlite")}
+	licenses := NewLicenseService(repos.NewDBLicenseRepo(db, "sqlite"), userRepo, pub, 0, "platform")
+	users := NewUserService(userRepo, licenses, repos.NewDBSessionRepo(db, "sqlite"), nil, repos.NewTransactor(db))
+	return licenses, users, priv
+}
+
+func signLicense(t *testing.T, key ed25519.PrivateKey, seats int, expires time.Time) []byte {
+	t.Helper()
+	data, err := license.Sign(key, license.License{
+		ID: "lic-1", Tenant: testTenant, Seats: seats,
+		IssuedAt: time.Now().UTC(), ExpiresAt: expires,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Concurrent creations cannot take the tenant past its seat count.
+func TestSeatLimitHoldsUnderConcurrency(t *testing.T) {
+	licenses, users, key := newLicenseTest(t)
+	ctx := context.Background()
+	if _, err := licenses.Install(ctx, testTenant, "admin", signLicense(t, key, 3, time.Now().AddDate(1, 0, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = users.CreateUser(ctx, testTenant, "admin", models.User{
+				UserName: fmt.Sprintf("user%d", i), PasswordHash: "x", FirstName: "U", LastName: "Ser",
+				Email: fmt.Sprintf("user%d@example.com", i), Role: "agent",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, repos.ErrSeatLimit):
+			t.Errorf("CreateUser: %v", err)
+		}
+	}
+	if created != 3 {
+		t.Fatalf("created %d users on a 3-seat license", created)
+	}
+	st, err := licenses.Status(ctx, testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.SeatsUsed != 3 {
+		t.Fatalf("SeatsUsed = %d, want 3", st.SeatsUsed)
+	}
+}
+
+func TestLicenseExpiry(t *testing.T) {
+	licenses, _, key := newLicenseTest(t)
+	ctx := context.Background()
+
+	if _, err := licenses.Check(ctx, testTenant); !errors.Is(err, repos.ErrNoLicense) {
+		t.Fatalf("no license: err = %v, want ErrNoLicense", err)
+	}
+	past := time.Now().Add(-DefaultLicenseGrace - time.Hour)
+	if _, err := licenses.Install(ctx, testTenant, "admin", signLicense(t, key, 0, past)); !errors.Is(err, repos.ErrLicenseExpired) {
+		t.Fatalf("installing a run-out license: err = %v, want ErrLicenseExpired", err)
+	}
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	if _, err := licenses.Install(ctx, testTenant, "admin", signLicense(t, otherKey, 0, time.Now().AddDate(1, 0, 0))); !errors.Is(err, repos.ErrLicenseInvalid) {
+		t.Fatalf("installing a license from another key: err = %v, want ErrLicenseInvalid", err)
+	}
+
+	// Expired a day ago: still working, with a warning, during the grace period.
+	st, err := licenses.Install(ctx, testTenant, "admin", signLicense(t, key, 0, time.Now().Add(-24*time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Status != license.StatusGrace {
+		t.Errorf("Status = %s, want %s", st.Status, license.StatusGrace)
+	}
+	if warning, err := licenses.Check(ctx, testTenant); err != nil || warning == "" {
+		t.Errorf("Check in grace = %q, %v; want a warning and no error", warning, err)
+	}
+
+	// Shrinking the grace period puts the same license past it.
+	licenses.grace = time.Nanosecond
+	licenses.cache = map[string]cachedLicense{}
+	if _, err := licenses.Check(ctx, testTenant); !errors.Is(err, repos.ErrLicenseExpired) {
+		t.Fatalf("Check after grace: err = %v, want ErrLicenseExpired", err)
+	}
+	if _, err := licenses.Check(ctx, "platform"); err != nil {
+		t.Fatalf("exempt tenant: %v", err)
+	}
+}
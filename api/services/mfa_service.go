@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/totp"
+)
+
+// PermManageMFA guards resetting other users' enrolments and the tenant
+// MFA policy.
+const PermManageMFA = "manage_mfa"
+
+// RecoveryCodeCount is how many single-use recovery codes a user is given
+// when enrolment is confirmed or the codes are regenerated.
+const RecoveryCodeCount = 10
+
+// totpSkew accepts codes from one time step either side of now, to allow
+// for clock drift on the user's device.
+const totpSkew = 1
+
+// MFAService manages TOTP enrolment, verification and recovery codes, and
+// the tenant policy that makes MFA mandatory for users holding sensitive
+// permissions.
+type MFAService struct {
+	repo   repos.MFARepo
+	authz  *AuthZService
+	issuer string
+	now    func() time.Time
+}
+
+func NewMFAService(repo repos.MFARepo, authz *AuthZService, issuer string) *MFAService {
+	return &MFAService{repo: repo, authz: authz, issuer: issuer, now: time.Now}
+}
+
+// Enabled reports whether the user has a confirmed TOTP enrolment.
+func (s *MFAService) Enabled(ctx context.Context, tenantID string, userID int64) (bool, error) {
+	m, err := s.repo.GetTOTP(ctx, tenantID, userID)
+	if errors.Is(err, repos.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return m.ConfirmedAt != nil, nil
+}
+
+// Required reports whether tenant policy makes MFA mandatory for the user,
+// i.e. whether they hold any of the policy's permissions.
+func (s *MFAService) Required(ctx context.Context, tenantID string, userID int64) (bool, error) {
+	p, err := s.repo.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	if len(p.RequiredPermissions) == 0 {
+		return false, nil
+	}
+	return s.authz.HasAny(ctx, tenantID, userID, p.RequiredPermissions...)
+}
+
+// Status returns whether the user has MFA enabled and whether it is
+// required of them.
+func (s *MFAService) Status(ctx context.Context, tenantID string, userID int64) (enabled, required bool, err error) {
+	if enabled, err = s.Enabled(ctx, tenantID, userID); err != nil {
+		return false, false, err
+	}
+	required, err = s.Required(ctx, tenantID, userID)
+	return enabled, required, err
+}
+
+// BeginEnrolment generates a new secret for the user, replacing any
+// pending one. It takes effect once confirmed with a code.
+func (s *MFAService) BeginEnrolment(ctx context.Context, tenantID string, userID int64, account string) (*models.MFAEnrolment, error) {
+	enabled, err := s.Enabled(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, repos.ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.SaveTOTP(ctx, &models.MFASecret{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: s.now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.MFAEnrolment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, account, secret),
+	}, nil
+}
+
+// ConfirmEnrolment enables the pending enrolment if code is valid for it
+// and returns a fresh set of recovery codes, shown to the user only now.
+func (s *MFAService) ConfirmEnrolment(ctx context.Context, tenantID string, userID int64, code string) ([]string, error) {
+	m, err := s.repo.GetTOTP(ctx, tenantID, userID)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.ConfirmedAt != nil {
+		return nil, repos.ErrMFAAlreadyEnabled
+	}
+	if err := s.checkCode(ctx, m, code); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	m.ConfirmedAt = &now
+	if err := s.repo.SaveTOTP(ctx, m); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, tenantID, userID)
+}
+
+// Verify checks a TOTP code for a user with MFA enabled. Each code is
+// accepted once.
+func (s *MFAService) Verify(ctx context.Context, tenantID string, userID int64, code string) error {
+	m, err := s.confirmed(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	return s.checkCode(ctx, m, code)
+}
+
+// RedeemRecoveryCode spends one of the user's recovery codes in place of a
+// TOTP code.
+func (s *MFAService) RedeemRecoveryCode(ctx context.Context, tenantID string, userID int64, code string) error {
+	if _, err := s.confirmed(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	ok, err := s.repo.UseRecoveryCode(ctx, tenantID, userID, hashRecoveryCode(code), s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return repos.ErrMFAInvalidCode
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after
+// checking a current TOTP code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, tenantID string, userID int64, code string) ([]string, error) {
+	if err := s.Verify(ctx, tenantID, userID, code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, tenantID, userID)
+}
+
+// Disable turns MFA off for the user after checking a current TOTP code.
+// It is refused while tenant policy requires MFA of them.
+func (s *MFAService) Disable(ctx context.Context, tenantID string, userID int64, code string) error {
+	required, err := s.Required(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return repos.ErrMFARequired
+	}
+	if err := s.Verify(ctx, tenantID, userID, code); err != nil {
+		return err
+	}
+	return s.repo.DeleteTOTP(ctx, tenantID, userID)
+}
+
+// Reset removes a user's enrolment and recovery codes, for an
+// administrator helping a user who lost their device. If policy requires
+// MFA of the user they must enrol again at their next login.
+func (s *MFAService) Reset(ctx context.Context, tenantID string, userID int64) error {
+	return s.repo.DeleteTOTP(ctx, tenantID, userID)
+}
+
+func (s *MFAService) Policy(ctx context.Context, tenantID string) (*models.MFAPolicy, error) {
+	return s.repo.GetPolicy(ctx, tenantID)
+}
+
+// SetPolicy replaces the set of permissions whose holders must use MFA.
+func (s *MFAService) SetPolicy(ctx context.Context, tenantID, currentUser string, perms []string) (*models.MFAPolicy, error) {
+	clean := []string{}
+	for _, p := range perms {
+		if p = strings.TrimSpace(p); p != "" {
+			if strings.Contains(p, ",") {
+				return nil, repos.ErrUnknownPermission
+			}
+			clean = append(clean, p)
+		}
+	}
+	slices.Sort(clean)
+	p := &models.MFAPolicy{
+		TenantID:            tenantID,
+		RequiredPermissions: slices.Compact(clean),
+		ModifiedBy:          currentUser,
+		LastModified:        s.now().UTC(),
+	}
+	return p, s.repo.SavePolicy(ctx, p)
+}
+
+func (s *MFAService) confirmed(ctx context.Context, tenantID string, userID int64) (*models.MFASecret, error) {
+	m, err := s.repo.GetTOTP(ctx, tenantID, userID)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.ConfirmedAt == nil {
+		return nil, repos.ErrMFANotEnrolled
+	}
+	return m, nil
+}
+
+// checkCode validates code against m and records its time step, so the
+// same code cannot be replayed.
+func (s *MFAService) checkCode(ctx context.Context, m *models.MFASecret, code string) error {
+	counter, ok := totp.Validate(m.Secret, code, s.now(), totpSkew)
+	if !ok {
+		return repos.ErrMFAInvalidCode
+	}
+	ok, err := s.repo.UseTOTPCounter(ctx, m.TenantID, m.UserID, counter)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return repos.ErrMFAInvalidCode
+	}
+	m.LastCounter = counter
+	return nil
+}
+
+func (s *MFAService) newRecoveryCodes(ctx context.Context, tenantID string, userID int64) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := randomHex(5)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, tenantID, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so a code can be typed
+// the way it was written down.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashSessionToken(code)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/notify"
+)
+
+// NotificationService delivers messages to buyers and users over the
+// configured channels, honouring their preferences and opt-outs. Failed
+// sends are retried with backoff and every attempt is logged.
+//
+// Email is on by default, addressed to the buyer's or user's email. Other
+// channels are used only once a preference enables them; SMS falls back
+// to the stored phone number, webhooks need an explicit URL.
+type NotificationService struct {
+	repo      repos.NotificationRepo
+	buyerRepo repos.BuyerRepo
+	userRepo  repos.UserRepo
+	policy    notify.RetryPolicy
+	notifiers map[string]notify.Notifier
+}
+
+func NewNotificationService(
+	r repos.NotificationRepo,
+	br repos.BuyerRepo,
+	ur repos.UserRepo,
+	policy notify.RetryPolicy,
+	notifiers ...notify.Notifier,
+) *NotificationService {
+	if policy.MaxAttempts <= 0 {
+		policy = notify.DefaultRetryPolicy
+	}
+	s := &NotificationService{repo: r, buyerRepo: br, userRepo: ur, policy: policy, notifiers: map[string]notify.Notifier{}}
+	for _, n := range notifiers {
+		s.notifiers[n.Channel()] = n
+	}
+	return s
+}
+
+// ErrNoNotifiers is returned by Notify when no channel is configured.
+var ErrNoNotifiers = errors.New("no notification channels configured")
+
+// contact is the default addressing for a notification subject.
+type contact struct {
+	email, phone string
+}
+
+func (s *NotificationService) contact(ctx context.Context, tenantID, subjectType string, subjectID int64) (contact, error) {
+	switch subjectType {
+	case models.NotifySubjectBuyer:
+		b, err := s.buyerRepo.GetByID(ctx, tenantID, subjectID)
+		if err != nil {
+			return contact{}, err
+		}
+		return contact{email: b.Email, phone: b.Phone}, nil
+	case models.NotifySubjectUser:
+		u, err := s.userRepo.GetByID(ctx, tenantID, subjectID)
+		if err != nil {
+			return contact{}, err
+		}
+		return contact{email: u.Email, phone: u.Phone}, nil
+	default:
+		return contact{}, fmt.Errorf("unknown notification subject %q", subjectType)
+	}
+}
+
+func (s *NotificationService) ListPreferences(ctx context.Context, tenantID, subjectType string, subjectID int64) ([]*models.NotificationPreference, error) {
+	if _, err := s.contact(ctx, tenantID, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPreferences(ctx, tenantID, subjectType, subjectID)
+}
+
+// SetPreference sets the address and opt-out flag for one channel. The
+// opt-out time is kept while the subject stays opted out.
+func (s *NotificationService) SetPreference(ctx context.Context, tenantID, currentUser, subjectType string, subjectID int64, channel string, p models.NotificationPreference) error {
+	switch channel {
+	case notify.ChannelEmail, notify.ChannelSMS:
+	case notify.ChannelWebhook:
+		if !p.OptedOut {
+			u, err := url.Parse(p.Address)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return errors.New("webhook address must be an http(s) URL")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown channel %q", channel)
+	}
+	if _, err := s.contact(ctx, tenantID, subjectType, subjectID); err != nil {
+		return err
+	}
+	existing, err := s.repo.ListPreferences(ctx, tenantID, subjectType, subjectID)
+	if err != nil {
+		return err
+	}
+	p.ID = 0
+	p.CreatedAt = time.Time{}
+	p.OptedOutAt = nil
+	for _, e := range existing {
+		if e.Channel == channel {
+			p.CreatedBy, p.CreatedAt = e.CreatedBy, e.CreatedAt
+			if e.OptedOut {
+				p.OptedOutAt = e.OptedOutAt
+			}
+		}
+	}
+	if p.OptedOut && p.OptedOutAt == nil {
+		now := time.Now().UTC()
+		p.OptedOutAt = &now
+	}
+	if !p.OptedOut {
+		p.OptedOutAt = nil
+	}
+	p.TenantID = tenantID
+	p.SubjectType = subjectType
+	p.SubjectID = subjectID
+	p.Channel = channel
+	p.ModifiedBy = currentUser
+	return s.repo.SavePreference(ctx, &p)
+}
+
+// Notify sends subject/body to every channel the recipient hasn't opted
+// out of and returns how many channels delivered. An error is returned if
+// any channel failed after its retries; zero deliveries with a nil error
+// means the recipient had no usable channel.
+func (s *NotificationService) Notify(ctx context.Context, tenantID, subjectType string, subjectID int64, subject, body string) (int, error) {
+	if len(s.notifiers) == 0 {
+		return 0, ErrNoNotifiers
+	}
+	who, err := s.contact(ctx, tenantID, subjectType, subjectID)
+	if err != nil {
+		return 0, err
+	}
+	prefs, err := s.repo.ListPreferences(ctx, tenantID, subjectType, subjectID)
+	if err != nil {
+		return 0, err
+	}
+	targets := map[string]string{notify.ChannelEmail: who.email}
+	for _, p := range prefs {
+		if p.OptedOut {
+			delete(targets, p.Channel)
+			continue
+		}
+		addr := p.Address
+		if addr == "" {
+			switch p.Channel {
+			case notify.ChannelEmail:
+				addr = who.email
+			case notify.ChannelSMS:
+				addr = who.phone
+			}
+		}
+		targets[p.Channel] = addr
+	}
+
+	delivered := 0
+	var errs []error
+	for channel, addr := range targets {
+		n, ok := s.notifiers[channel]
+		if !ok || addr == "" {
+			continue
+		}
+		a := models.NotificationAttempt{
+			TenantID:    tenantID,
+			SubjectType: subjectType,
+			SubjectID:   subjectID,
+			Channel:     channel,
+			Recipient:   addr,
+			Subject:     subject,
+		}
+		if err := s.deliver(ctx, n, a, notify.Message{To: addr, Subject: subject, Body: body}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		delivered++
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// deliver sends m, retrying transient failures, and logs each attempt.
+func (s *NotificationService) deliver(ctx context.Context, n notify.Notifier, a models.NotificationAttempt, m notify.Message) error {
+	var err error
+	for attempt := 1; attempt <= s.policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.policy.Backoff(attempt - 1)):
+			}
+		}
+		err = n.Notify(ctx, m)
+		a.Attempt = attempt
+		a.Status, a.Error = models.NotifyAttemptSent, ""
+		if err != nil {
+			a.Status, a.Error = models.NotifyAttemptFailed, err.Error()
+		}
+		if _, lerr := s.repo.LogAttempt(ctx, &a); lerr != nil {
+			log.Printf("notifications: log attempt: %v", lerr)
+		}
+		if err == nil || notify.IsPermanent(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// ListAttempts returns recent delivery attempts, optionally for one subject.
+func (s *NotificationService) ListAttempts(ctx context.Context, tenantID, subjectType string, subjectID int64, limit int) ([]*models.NotificationAttempt, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListAttempts(ctx, tenantID, subjectType, subjectID, limit)
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// OfferService runs the sales pipeline: offers, counter-offers, acceptance
+// and the conveyancing milestones that end in a completed Sales record.
+type OfferService struct {
+	repo         repos.OfferRepo
+	salesRepo    repos.SalesRepo
+	propertyRepo repos.PropertyRepo
+	buyerRepo    repos.BuyerRepo
+	kyc          *KYCService
+	events       EventPublisher
+	uow          UnitOfWork
+	salesTx      repos.Transactor
+}
+
+// NewOfferService builds the service. salesTx is the transactor of the
+// database holding offers and sales.
+func NewOfferService(r repos.OfferRepo, sr repos.SalesRepo, pr repos.PropertyRepo, br repos.BuyerRepo, kyc *KYCService, events EventPublisher, uow UnitOfWork, salesTx repos.Transactor) *OfferService {
+	return &OfferService{repo: r, salesRepo: sr, propertyRepo: pr, buyerRepo: br, kyc: kyc, events: orNop(events), uow: orDirect(uow), salesTx: salesTx}
+}
+
+// CreateOffer records a new pending offer on an unsold property.
+func (s *OfferService) CreateOffer(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	o models.Offer,
+) (int64, error) {
+	if o.PropertyID == 0 || o.BuyerID == 0 || o.Amount <= 0 {
+		return 0, errors.New("property, buyer, and a positive amount must be specified")
+	}
+	if o.ExpiresAt != nil && !o.ExpiresAt.After(time.Now().UTC()) {
+		return 0, repos.ErrOfferExpiresInPast
+	}
+	prop, err := s.propertyRepo.GetByID(ctx, tenantID, o.PropertyID)
+	if err != nil {
+		return 0, err
+	}
+	if prop.Status == models.PropertySold {
+		return 0, repos.ErrPropertyUnavailable
+	}
+	if _, err := s.buyerRepo.GetByID(ctx, tenantID, o.BuyerID); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	o.ID = 0
+	o.TenantID = tenantID
+	o.Status = models.OfferPending
+	o.Stage = ""
+	o.SaleID = 0
+	o.CounterAmount = 0
+	o.CreatedBy = currentUser
+	o.ModifiedBy = currentUser
+	o.CreatedAt = now
+	o.LastModified = now
+	o.Deleted = false
+	return s.repo.Create(ctx, &o)
+}
+
+// ListOffers returns the tenant's offers, optionally for a single property.
+func (s *OfferService) ListOffers(
+	ctx context.Context,
+	tenantID string,
+	propertyID int64,
+) ([]models.Offer, error) {
+	rows, err := s.repo.ListAll(ctx, tenantID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Offer, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+// GetOffer returns one offer together with its milestone history.
+func (s *OfferService) GetOffer(
+	ctx context.Context,
+	tenantID string,
+	id int64,
+) (*models.Offer, error) {
+	o, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := s.repo.ListMilestones(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	o.Milestones = ms
+	return o, nil
+}
+
+// CounterOffer records the seller's counter amount on a live offer.
+func (s *OfferService) CounterOffer(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+	amount float64,
+) error {
+	if amount <= 0 {
+		return errors.New("counter amount must be positive")
+	}
+	o, err := s.liveOffer(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	o.CounterAmount = amount
+	o.Status = models.OfferCountered
+	o.ModifiedBy = currentUser
+	return s.repo.Update(ctx, o)
+}
+
+// AcceptOffer accepts a pending or countered offer. A countered offer is
+// accepted at the counter amount. Only one offer per property may be
+// accepted at a time, which the offers table enforces against concurrent
+// acceptances; the property moves to "under_offer".
+func (s *OfferService) AcceptOffer(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+) error {
+	o, err := s.liveOffer(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	prop, err := s.propertyRepo.GetByID(ctx, tenantID, o.PropertyID)
+	if err != nil {
+		return err
+	}
+	if prop.Status != models.PropertyAvailable && prop.Status != "" {
+		return repos.ErrPropertyUnavailable
+	}
+	others, err := s.repo.ListAll(ctx, tenantID, o.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != o.ID && other.Status == models.OfferAccepted {
+			return repos.ErrPropertyUnavailable
+		}
+	}
+
+	if o.Status == models.OfferCountered {
+		o.Amount = o.CounterAmount
+	}
+	o.Status = models.OfferAccepted
+	o.ModifiedBy = currentUser
+	return s.uow.Run(ctx, func(ctx context.Context) error {
+		evs, err := propertyStatusEvents(ctx, tenantID, currentUser, prop, models.PropertyUnderOffer)
+		if err != nil {
+			return err
+		}
+		return s.repo.UpdateWithEvents(ctx, o, evs...)
+	})
+}
+
+// RejectOffer rejects a pending or countered offer.
+func (s *OfferService) RejectOffer(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+) error {
+	o, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if o.Status != models.OfferPending && o.Status != models.OfferCountered {
+		return repos.ErrInvalidOfferTransition
+	}
+	o.Status = models.OfferRejected
+	o.ModifiedBy = currentUser
+	return s.repo.Update(ctx, o)
+}
+
+// WithdrawOffer withdraws an offer before exchange. Withdrawing an accepted
+// offer puts the property back on the market.
+func (s *OfferService) WithdrawOffer(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+) error {
+	o, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	switch o.Status {
+	case models.OfferPending, models.OfferCountered:
+	case models.OfferAccepted:
+		if milestoneIndex(o.Stage) >= milestoneIndex(models.OfferMilestoneExchange) {
+			return repos.ErrInvalidOfferTransition
+		}
+	default:
+		return repos.ErrInvalidOfferTransition
+	}
+	var prop *models.Property
+	if o.Status == models.OfferAccepted {
+		if prop, err = s.propertyRepo.GetByID(ctx, tenantID, o.PropertyID); err != nil {
+			return err
+		}
+	}
+	o.Status = models.OfferWithdrawn
+	o.ModifiedBy = currentUser
+	return s.uow.Run(ctx, func(ctx context.Context) error {
+		var evs []*models.OutboxEvent
+		if prop != nil {
+			var err error
+			if evs, err = propertyStatusEvents(ctx, tenantID, currentUser, prop, models.PropertyAvailable); err != nil {
+				return err
+			}
+		}
+		return s.repo.UpdateWithEvents(ctx, o, evs...)
+	})
+}
+
+// AdvanceMilestone moves an accepted offer forward to the given conveyancing
+// milestone. Milestones may be skipped but never revisited, and completion
+// requires exchange. Reaching completion creates the Sales record, marks the
+// property sold and closes the offer.
+func (s *OfferService) AdvanceMilestone(
+	ctx context.Context,
+	tenantID string,
+	currentUser string,
+	id int64,
+	milestone string,
+	reachedAt time.Time,
+	notes string,
+) error {
+	target := milestoneIndex(milestone)
+	if target < 0 {
+		return errors.New("unknown milestone: " + milestone)
+	}
+	o, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if o.Status != models.OfferAccepted || target <= milestoneIndex(o.Stage) {
+		return repos.ErrInvalidOfferTransition
+	}
+	if milestone == models.OfferMilestoneCompletion && o.Stage != models.OfferMilestoneExchange {
+		return repos.ErrInvalidOfferTransition
+	}
+	if reachedAt.IsZero() {
+		reachedAt = time.Now().UTC()
+	}
+
+	useOverride := noKYCOverride
+	if milestone == models.OfferMilestoneCompletion {
+		if useOverride, err = s.kyc.CheckBuyer(ctx, tenantID, currentUser, o.BuyerID, models.KYCActionCreateSale); err != nil {
+			return err
+		}
+	}
+
+	// The sale, the milestone, the offer and the property status change
+	// form one unit of work. The first three share the sales database and
+	// commit together even when the property lives in another. Any KYC
+	// override is spent last, once they have been written.
+	var completed *models.Sales
+	err = s.uow.Run(ctx, func(ctx context.Context) error {
+		return s.salesTx.InTx(ctx, func(ctx context.Context) error {
+			if milestone == models.OfferMilestoneCompletion {
+				now := time.Now().UTC()
+				sale := models.Sales{
+					TenantID:     tenantID,
+					PropertyID:   o.PropertyID,
+					BuyerID:      o.BuyerID,
+					SalePrice:    o.Amount,
+					SaleDate:     reachedAt,
+					SaleType:     o.SaleType,
+					CreatedBy:    currentUser,
+					CreatedAt:    now,
+					ModifiedBy:   currentUser,
+					LastModified: now,
+				}
+				saleID, err := s.salesRepo.Create(ctx, &sale)
+				if err != nil {
+					return err
+				}
+				sale.ID = saleID
+				completed = &sale
+				o.SaleID = saleID
+				o.Status = models.OfferCompleted
+			}
+
+			if _, err := s.repo.AddMilestone(ctx, &models.OfferMilestone{
+				TenantID:  tenantID,
+				OfferID:   o.ID,
+				Milestone: milestone,
+				ReachedAt: reachedAt,
+				Notes:     notes,
+				CreatedBy: currentUser,
+			}); err != nil {
+				return err
+			}
+			o.Stage = milestone
+			o.ModifiedBy = currentUser
+			var evs []*models.OutboxEvent
+			if milestone == models.OfferMilestoneCompletion {
+				prop, err := s.propertyRepo.GetByID(ctx, tenantID, o.PropertyID)
+				if err != nil {
+					return err
+				}
+				if evs, err = propertyStatusEvents(ctx, tenantID, currentUser, prop, models.PropertySold); err != nil {
+					return err
+				}
+			}
+			if err := s.repo.UpdateWithEvents(ctx, o, evs...); err != nil {
+				return err
+			}
+			return useOverride(ctx)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if completed != nil {
+		s.events.Publish(ctx, tenantID, models.EventSaleCompleted, *completed)
+	}
+	return nil
+}
+
+// liveOffer loads an offer that can still be countered or accepted.
+func (s *OfferService) liveOffer(ctx context.Context, tenantID string, id int64) (*models.Offer, error) {
+	o, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != models.OfferPending && o.Status != models.OfferCountered {
+		return nil, repos.ErrInvalidOfferTransition
+	}
+	if o.ExpiresAt != nil && time.Now().UTC().After(*o.ExpiresAt) {
+		return nil, repos.ErrOfferExpired
+	}
+	return o, nil
+}
+
+// propertyStatusEvents asks the properties database to move p to status,
+// committed with the offer change that caused it. It returns nothing if p
+// is already there.
+func propertyStatusEvents(ctx context.Context, tenantID, currentUser string, p *models.Property, status string) ([]*models.OutboxEvent, error) {
+	if p.Status == status {
+		return nil, nil
+	}
+	ev, err := newOutboxEvent(ctx, tenantID, models.TopicPropertyStatusChanged, "property", p.ID, models.PropertyStatusChanged{
+		PropertyID: p.ID,
+		Status:     status,
+		ModifiedBy: currentUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*models.OutboxEvent{ev}, nil
+}
+
+// milestoneIndex returns the position of m in models.OfferMilestones, or -1
+// for "" and unknown names.
+func milestoneIndex(m string) int {
+	for i, name := range models.OfferMilestones {
+		if name == m {
+			return i
+		}
+	}
+	return -1
+}
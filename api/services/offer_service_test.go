@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// failingMilestones is an offer repo whose milestone writes fail.
+type failingMilestones struct {
+	repos.OfferRepo
+}
+
+var errMilestone = errors.New("milestone write failed")
+
+func (failingMilestones) AddMilestone(context.Context, *models.OfferMilestone) (int64, error) {
+	return 0, errMilestone
+}
+
+// With per-domain databases the sale is written before the milestone; a
+// failed milestone must take the sale with it.
+func TestAdvanceMilestoneFailureLeavesNoSale(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kycRepo := repos.NewDBKYCRepo(db, "sqlite")
+	salesRepo := repos.NewDBSalesRepo(db, "sqlite")
+	offerRepo := repos.NewDBOfferRepo(db, "sqlite")
+	kyc := NewKYCService(kycRepo, buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+	svc := NewOfferService(failingMilestones{offerRepo}, salesRepo, repos.NewDBPropertyRepo(db, "sqlite"), buyerRepo, kyc,
+		nil, NewOutboxService(), repos.NewTransactor(db))
+
+	buyerID, err := buyerRepo.Create(ctx, &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kycRepo.Create(ctx, &models.BuyerKYC{
+		TenantID: testTenant, BuyerID: buyerID, IDDocumentType: "passport", IDDocumentNumber: "X1",
+		IDDocumentExpiry: time.Now().AddDate(1, 0, 0), Status: models.KYCApproved, CreatedBy: "alice", ModifiedBy: "alice",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	offerID, err := offerRepo.Create(ctx, &models.Offer{
+		TenantID: testTenant, PropertyID: 1, BuyerID: buyerID, Amount: 250000, SaleType: models.SaleTypeInstallment,
+		Status: models.OfferAccepted, Stage: models.OfferMilestoneExchange, CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = svc.AdvanceMilestone(ctx, testTenant, "alice", offerID, models.OfferMilestoneCompletion, time.Now(), "")
+	if !errors.Is(err, errMilestone) {
+		t.Fatalf("err = %v, want the milestone failure", err)
+	}
+	sales, err := salesRepo.ListAll(ctx, testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sales) != 0 {
+		t.Fatalf("%d sales left behind", len(sales))
+	}
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/oidc"
+)
+
+// PermManageSSO allows configuring the tenant's identity provider.
+const PermManageSSO = "manage_sso"
+
+// oidcLoginTTL is how long a user has to log in at the provider.
+const oidcLoginTTL = 10 * time.Minute
+
+// oidcCreatedBy is recorded as the creator of users provisioned at their
+// first single sign-on.
+const oidcCreatedBy = "sso"
+
+// oidcNoPassword is the password hash of users provisioned at single
+// sign-on. No password matches it, so they log in through the provider
+// unless they set a password with a reset.
+const oidcNoPassword = "!sso"
+
+// OIDCService logs users in through their tenant's OpenID Connect
+// provider with the authorization code flow and PKCE. Users are matched
+// by the provider's subject and created on first login, or linked to an
+// existing account by verified email where the tenant allows it, and get
+// our own tokens as with a password login. Second factors are left to the provider.
+type OIDCService struct {
+	repo     repos.OIDCRepo
+	userRepo repos.UserRepo
+	roleRepo repos.RoleRepo
+	access   *AccessService
+	tenants  *TenantService
+	licenses *LicenseService
+	auth     *AuthService
+	client   *oidc.Client
+}
+
+func NewOIDCService(
+	r repos.OIDCRepo,
+	ur repos.UserRepo,
+	rr repos.RoleRepo,
+	access *AccessService,
+	tenants *TenantService,
+	licenses *LicenseService,
+	auth *AuthService,
+	client *oidc.Client,
+) *OIDCService {
+	return &OIDCService{
+		repo:     r,
+		userRepo: ur,
+		roleRepo: rr,
+		access:   access,
+		tenants:  tenants,
+		licenses: licenses,
+		auth:     auth,
+		client:   client,
+	}
+}
+
+// Config returns the tenant's provider settings without the client
+// secret.
+func (s *OIDCService) Config(ctx context.Context, tenantID string) (*models.OIDCConfig, error) {
+	c, err := s.repo.GetConfig(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	c.ClientSecret = ""
+	return c, nil
+}
+
+// SaveConfig sets the tenant's provider. An empty client secret keeps the
+// one already saved. The issuer must answer discovery and mapped roles
+// must exist in the tenant.
+func (s *OIDCService) SaveConfig(ctx context.Context, tenantID, currentUser string, c models.OIDCConfig) (*models.OIDCConfig, error) {
+	c.TenantID = tenantID
+	c.Issuer = strings.TrimSuffix(strings.TrimSpace(c.Issuer), "/")
+	c.ClientID = strings.TrimSpace(c.ClientID)
+	if u, err := url.Parse(c.Issuer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || c.ClientID == "" {
+		return nil, repos.ErrInvalidOIDCConfig
+	}
+	if c.ClientSecret == "" {
+		if existing, err := s.repo.GetConfig(ctx, tenantID); err == nil {
+			c.ClientSecret = existing.ClientSecret
+		} else if !errors.Is(err, repos.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if c.Scopes == nil {
+		c.Scopes = []string{}
+	}
+	if c.RoleMappings == nil {
+		c.RoleMappings = map[string]string{}
+	}
+	roles, err := s.roleIDs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{c.DefaultRole}
+	for _, name := range c.RoleMappings {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if _, ok := roles[name]; name != "" && !ok {
+			return nil, fmt.Errorf("%w: no role named %q", repos.ErrInvalidOIDCConfig, name)
+		}
+	}
+	if _, err := s.client.Discover(ctx, c.Issuer); err != nil {
+		return nil, fmt.Errorf("%w: %v", repos.ErrInvalidOIDCConfig, err)
+	}
+	c.ModifiedBy = currentUser
+	c.LastModified = time.Now().UTC()
+	if err := s.repo.SaveConfig(ctx, &c); err != nil {
+		return nil, err
+	}
+	c.ClientSecret = ""
+	return &c, nil
+}
+
+func (s *OIDCService) DeleteConfig(ctx context.Context, tenantID string) error {
+	return s.repo.DeleteConfig(ctx, tenantID)
+}
+
+// Begin starts a login for tenantID and returns the provider URL to send
+// the user to. The provider sends them back to redirectURI.
+func (s *OIDCService) Begin(ctx context.Context, tenantID, redirectURI string) (string, error) {
+	if err := s.tenants.CheckActive(ctx, tenantID); errors.Is(err, repos.ErrNotFound) {
+		return "", repos.ErrSSONotConfigured
+	} else if err != nil {
+		return "", err
+	}
+	c, err := s.repo.GetConfig(ctx, tenantID)
+	if errors.Is(err, repos.ErrNotFound) || (err == nil && !c.Enabled) {
+		return "", repos.ErrSSONotConfigured
+	}
+	if err != nil {
+		return "", err
+	}
+	p, err := s.client.Discover(ctx, c.Issuer)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	if err := s.repo.DeleteExpiredStates(ctx, now); err != nil {
+		return "", err
+	}
+	st := &models.OIDCLoginState{
+		State:        randomHex(16),
+		TenantID:     tenantID,
+		Nonce:        randomHex(16),
+		CodeVerifier: randomHex(32),
+		RedirectURI:  redirectURI,
+		ExpiresAt:    now.Add(oidcLoginTTL),
+	}
+	if err := s.repo.CreateState(ctx, st); err != nil {
+		return "", err
+	}
+	scopes := append([]string{"openid"}, c.Scopes...)
+	if len(c.Scopes) == 0 {
+		scopes = append(scopes, "profile", "email")
+	}
+	return p.AuthCodeURL(c.ClientID, redirectURI, scopes, st.State, st.Nonce, st.CodeVerifier), nil
+}
+
+// Complete finishes a login when the provider redirects back with code
+// and state. Failures at the provider are logged and reported as
+// ErrInvalidSSOLogin.
+func (s *OIDCService) Complete(ctx context.Context, state, code string) (*models.LoginResult, error) {
+	st, err := s.repo.TakeState(ctx, state)
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil, repos.ErrInvalidSSOLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(st.ExpiresAt) {
+		return nil, repos.ErrInvalidSSOLogin
+	}
+	c, err := s.repo.GetConfig(ctx, st.TenantID)
+	if errors.Is(err, repos.ErrNotFound) || (err == nil && !c.Enabled) {
+		return nil, repos.ErrSSONotConfigured
+	}
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.client.Discover(ctx, c.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := s.client.Exchange(ctx, p, c.ClientID, c.ClientSecret, st.RedirectURI, code, st.CodeVerifier)
+	if err != nil {
+		log.Printf("oidc: tenant %s: %v", st.TenantID, err)
+		return nil, repos.ErrInvalidSSOLogin
+	}
+	claims, err := s.client.VerifyIDToken(ctx, p, c.ClientID, idToken, st.Nonce)
+	if err != nil {
+		log.Printf("oidc: tenant %s: %v", st.TenantID, err)
+		return nil, repos.ErrInvalidSSOLogin
+	}
+	if c.TenantClaim != "" && !slices.Contains(claimStrings(claims, c.TenantClaim), c.TenantClaimValue) {
+		log.Printf("oidc: tenant %s: %s claim does not match", st.TenantID, c.TenantClaim)
+		return nil, repos.ErrInvalidSSOLogin
+	}
+
+	user, created, err := s.user(ctx, c, claims)
+	if err != nil {
+		return nil, err
+	}
+	if c.RoleClaim != "" || created {
+		if err := s.syncRoles(ctx, c, user, claims); err != nil {
+			return nil, err
+		}
+	}
+	pair, err := s.auth.StartSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{Tokens: pair}, nil
+}
+
+// user finds the user the ID token is for, linking or provisioning one on
+// their first login, and reports whether it was created.
+func (s *OIDCService) user(ctx context.Context, c *models.OIDCConfig, claims oidc.Claims) (*models.User, bool, error) {
+	subject, _ := claims["sub"].(string)
+	ident, err := s.repo.GetIdentity(ctx, c.TenantID, c.Issuer, subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(ctx, c.TenantID, ident.UserID)
+		if errors.Is(err, repos.ErrNotFound) || (err == nil && user.Deleted) {
+			return nil, false, repos.ErrInvalidSSOLogin
+		}
+		return user, false, err
+	}
+	if !errors.Is(err, repos.ErrNotFound) {
+		return nil, false, err
+	}
+
+	usernameClaim := c.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	username := claimString(claims, usernameClaim)
+	if username == "" {
+		username = claimString(claims, "email")
+	}
+	if username == "" {
+		log.Printf("oidc: tenant %s: no %s or email claim for %s", c.TenantID, usernameClaim, subject)
+		return nil, false, repos.ErrInvalidSSOLogin
+	}
+
+	created := false
+	user, err := s.userByVerifiedEmail(ctx, c, claims)
+	if errors.Is(err, repos.ErrNotFound) {
+		// An existing account is never taken over by username: anyone who
+		// can pick their username at the provider could claim it.
+		if _, err := s.userRepo.GetByUsername(ctx, c.TenantID, username); err == nil {
+			log.Printf("oidc: tenant %s: username %q for %s belongs to an existing user", c.TenantID, username, subject)
+			return nil, false, repos.ErrInvalidSSOLogin
+		} else if !errors.Is(err, repos.ErrNotFound) {
+			return nil, false, err
+		}
+		email := claimString(claims, "email")
+		if email == "" {
+			log.Printf("oidc: tenant %s: no email claim to create %s with", c.TenantID, subject)
+			return nil, false, repos.ErrInvalidSSOLogin
+		}
+		seats, err := s.licenses.Seats(ctx, c.TenantID)
+		if err != nil {
+			return nil, false, err
+		}
+		now := time.Now().UTC()
+		user = &models.User{
+			TenantID:     c.TenantID,
+			UserName:     username,
+			PasswordHash: oidcNoPassword,
+			FirstName:    claimString(claims, "given_name"),
+			LastName:     claimString(claims, "family_name"),
+			Email:        email,
+			Role:         c.DefaultRole,
+			CreatedBy:    oidcCreatedBy,
+			CreatedAt:    now,
+			ModifiedBy:   oidcCreatedBy,
+			LastModified: now,
+		}
+		if user.FirstName == "" {
+			user.FirstName = username
+		}
+		if user.LastName == "" {
+			user.LastName = "-"
+		}
+		if user.Role == "" {
+			user.Role = "user"
+		}
+		if user.ID, err = s.userRepo.CreateWithinSeats(ctx, user, seats); err != nil {
+			return nil, false, err
+		}
+		created = true
+	} else if err != nil {
+		return nil, false, err
+	}
+	if err := s.repo.LinkIdentity(ctx, &models.OIDCIdentity{
+		TenantID:  c.TenantID,
+		Issuer:    c.Issuer,
+		Subject:   subject,
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return nil, false, err
+	}
+	return user, created, nil
+}
+
+// userByVerifiedEmail returns the one user whose email is the ID token's,
+// if the tenant links by email and the provider has verified it, and
+// ErrNotFound otherwise.
+func (s *OIDCService) userByVerifiedEmail(ctx context.Context, c *models.OIDCConfig, claims oidc.Claims) (*models.User, error) {
+	email := claimString(claims, "email")
+	if !c.LinkVerifiedEmail || email == "" || !claimBool(claims, "email_verified") {
+		return nil, repos.ErrNotFound
+	}
+	users, err := s.userRepo.ListAll(ctx, c.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	var found *models.User
+	for _, u := range users {
+		if !strings.EqualFold(u.Email, email) {
+			continue
+		}
+		if found != nil {
+			log.Printf("oidc: tenant %s: several users have the email %s, not linking", c.TenantID, email)
+			return nil, repos.ErrNotFound
+		}
+		found = u
+	}
+	if found == nil {
+		return nil, repos.ErrNotFound
+	}
+	return found, nil
+}
+
+// syncRoles sets the user's roles to those their role claim maps to, or
+// the default role if it maps to none.
+func (s *OIDCService) syncRoles(ctx context.Context, c *models.OIDCConfig, user *models.User, claims oidc.Claims) error {
+	var names []string
+	if c.RoleClaim != "" {
+		for _, v := range claimStrings(claims, c.RoleClaim) {
+			if name, ok := c.RoleMappings[v]; ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 && c.DefaultRole != "" {
+		names = []string{c.DefaultRole}
+	}
+	roles, err := s.roleIDs(ctx, c.TenantID)
+	if err != nil {
+		return err
+	}
+	ids := []int64{}
+	for _, name := range names {
+		if id, ok := roles[name]; ok {
+			ids = append(ids, id)
+		} else {
+			log.Printf("oidc: tenant %s: mapped role %q does not exist", c.TenantID, name)
+		}
+	}
+	return s.access.SyncUserRoles(ctx, c.TenantID, "oidc", user.ID, ids)
+}
+
+func (s *OIDCService) roleIDs(ctx context.Context, tenantID string) (map[string]int64, error) {
+	roles, err := s.roleRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]int64{}
+	for _, r := range roles {
+		ids[r.Name] = r.ID
+	}
+	return ids, nil
+}
+
+func claimString(claims oidc.Claims, name string) string {
+	s, _ := claims[name].(string)
+	return strings.TrimSpace(s)
+}
+
+// claimBool reads a boolean claim, which some providers send as a string.
+func claimBool(claims oidc.Claims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// claimStrings reads a claim that may be a single string or a list.
+func claimStrings(claims oidc.Claims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
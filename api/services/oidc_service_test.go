@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/oidc"
+	"github.com/newssourcecrawler/realtorinstall/internal/oidc/oidctest"
+)
+
+type oidcTest struct {
+	svc      *OIDCService
+	repo     repos.OIDCRepo
+	userRepo repos.UserRepo
+	provider *oidctest.Provider
+	config   *models.OIDCConfig
+}
+
+// newOIDCTest sets up testTenant to log in through a mock provider.
+func newOIDCTest(t *testing.T) *oidcTest {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userRepo := repos.NewDBUserRepo(db, "sqlite")
+	roleRepo := repos.NewDBRoleRepo(db, "sqlite")
+	permRepo := repos.NewDBPermissionRepo(db, "sqlite")
+	rolePermRepo := repos.NewDBRolePermissionRepo(db, "sqlite")
+	userRoleRepo := repos.NewDBUserRoleRepo(db, "sqlite")
+	authz := NewAuthZService(permRepo, rolePermRepo, userRoleRepo)
+	access := NewAccessService(roleRepo, permRepo, rolePermRepo, userRoleRepo, userRepo, repos.NewDBAccessAuditRepo(db, "sqlite"), authz, repos.NewTransactor(db))
+	tenants := NewTenantService(repos.NewDBTenantRepo(db, "sqlite"), userRepo, roleRepo, permRepo, access, authz, nil)
+	licenses := NewLicenseService(repos.NewDBLicenseRepo(db, "sqlite"), userRepo, nil, 0, "")
+	auth := NewAuthService(userRepo, authz, repos.NewDBSessionRepo(db, "sqlite"), nil, nil, tenants, licenses, nil, "secret", time.Minute)
+
+	now := time.Now().UTC()
+	if err := repos.NewDBTenantRepo(db, "sqlite").Create(ctx, &models.Tenant{
+		ID: testTenant, Name: "Acme", Status: models.TenantActive,
+		CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := oidctest.NewProvider("", "realtor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(provider)
+	t.Cleanup(srv.Close)
+	provider.Issuer = srv.URL
+
+	o := &oidcTest{
+		svc:      NewOIDCService(repos.NewDBOIDCRepo(db, "sqlite"), userRepo, roleRepo, access, tenants, licenses, auth, oidc.NewClient(nil)),
+		repo:     repos.NewDBOIDCRepo(db, "sqlite"),
+		userRepo: userRepo,
+		provider: provider,
+		config: &models.OIDCConfig{
+			TenantID: testTenant, Enabled: true, Issuer: srv.URL, ClientID: "realtor",
+			Scopes: []string{}, RoleMappings: map[string]string{}, ModifiedBy: "test", LastModified: now,
+		},
+	}
+	o.saveConfig(t)
+	return o
+}
+
+func (o *oidcTest) saveConfig(t *testing.T) {
+	t.Helper()
+	if err := o.repo.SaveConfig(context.Background(), o.config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (o *oidcTest) createUser(t *testing.T, username, email string) int64 {
+	t.Helper()
+	now := time.Now().UTC()
+	id, err := o.userRepo.Create(context.Background(), &models.User{
+		TenantID: testTenant, UserName: username, PasswordHash: "x", FirstName: username, LastName: "Local",
+		Email: email, Role: "admin", CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// login goes through the provider as username and returns the user the
+// provider's subject was linked to.
+func (o *oidcTest) login(t *testing.T, username string) (int64, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, err := o.svc.Begin(ctx, testTenant, "http://app.test/oidc/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	q.Set("login_hint", username)
+	u.RawQuery = q.Encode()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	back, err := resp.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.svc.Complete(ctx, back.Query().Get("state"), back.Query().Get("code")); err != nil {
+		return 0, err
+	}
+	ident, err := o.repo.GetIdentity(ctx, testTenant, o.config.Issuer, "mock|"+username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ident.UserID, nil
+}
+
+// Whoever can pick their username at the provider must not get the local
+// account of that name.
+func TestOIDCDoesNotLinkByUsername(t *testing.T) {
+	o := newOIDCTest(t)
+	o.createUser(t, "admin", "root@corp.example")
+
+	for _, link := range []bool{false, true} {
+		o.config.LinkVerifiedEmail = link
+		o.saveConfig(t)
+		o.provider.EmailVerified = true
+		if _, err := o.login(t, "admin"); !errors.Is(err, repos.ErrInvalidSSOLogin) {
+			t.Errorf("link_verified_email=%v: err = %v, want ErrInvalidSSOLogin", link, err)
+		}
+		_, err := o.repo.GetIdentity(context.Background(), testTenant, o.config.Issuer, "mock|admin")
+		if !errors.Is(err, repos.ErrNotFound) {
+			t.Errorf("link_verified_email=%v: identity linked (err = %v)", link, err)
+		}
+	}
+}
+
+func TestOIDCLinksVerifiedEmailOnlyWhenAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		link, verified bool
+		wantLinked     bool
+	}{
+		{"not allowed", false, true, false},
+		{"not verified", true, false, false},
+		{"allowed and verified", true, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOIDCTest(t)
+			local := o.createUser(t, "alovelace", "ada@example.com")
+			o.config.LinkVerifiedEmail = tc.link
+			o.saveConfig(t)
+			o.provider.EmailVerified = tc.verified
+
+			got, err := o.login(t, "ada")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if linked := got == local; linked != tc.wantLinked {
+				t.Errorf("linked to local user = %v, want %v", linked, tc.wantLinked)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/notify"
+)
+
+// Outbox dispatch schedule: retries back off from 5s to at most ten
+// minutes, and an event is dead after MaxOutboxAttempts failures.
+const MaxOutboxAttempts = 10
+
+var outboxRetry = notify.RetryPolicy{MaxAttempts: MaxOutboxAttempts, Initial: 5 * time.Second, Max: 10 * time.Minute}
+
+// OutboxHandler applies one event to the database that consumes its topic.
+// Handlers must be idempotent on ev.ID, which the repos achieve by claiming
+// the ID in the same transaction as the change. Errors are retried unless
+// they are repos.ErrNotFound or a notify.PermanentError.
+type OutboxHandler func(ctx context.Context, ev *models.OutboxEvent) error
+
+// UnitOfWork runs a service operation that writes to more than one domain.
+type UnitOfWork interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type directUnitOfWork struct{}
+
+func (directUnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// orDirect lets services be built without a unit of work.
+func orDirect(u UnitOfWork) UnitOfWork {
+	if u == nil {
+		return directUnitOfWork{}
+	}
+	return u
+}
+
+// OutboxService drains the outboxes of the domain databases, handing each
+// event to the handler registered for its topic. An event is marked
+// dispatched only after its handler succeeds, so a crash in between means
+// a redelivery the handler ignores rather than a lost change.
+//
+// In single-database mode (UseSharedDatabase) it is also the services'
+// UnitOfWork: Run wraps an operation in one transaction and applies the
+// events it raised before committing, so nothing is left for the
+// dispatcher.
+type OutboxService struct {
+	sources  map[string]repos.OutboxRepo
+	domains  []string
+	handlers map[string]OutboxHandler
+	shared   repos.Transactor
+}
+
+func NewOutboxService() *OutboxService {
+	return &OutboxService{sources: map[string]repos.OutboxRepo{}, handlers: map[string]OutboxHandler{}}
+}
+
+// AddSource registers the outbox of the named domain database.
+func (s *OutboxService) AddSource(domain string, r repos.OutboxRepo) {
+	if _, ok := s.sources[domain]; !ok {
+		s.domains = append(s.domains, domain)
+	}
+	s.sources[domain] = r
+}
+
+// UseSharedDatabase switches to single-database mode, where every domain
+// and so the one outbox live in the database behind t.
+func (s *OutboxService) UseSharedDatabase(t repos.Transactor, outbox repos.OutboxRepo) {
+	s.shared = t
+	s.sources = map[string]repos.OutboxRepo{}
+	s.domains = nil
+	s.AddSource("shared", outbox)
+}
+
+// Run executes fn as one unit of work. With per-domain databases fn runs
+// as is, wrapping the writes it makes to one database in a transaction of
+// that database, and its events are applied later by the dispatcher; with
+// a shared database fn and its events commit or fail together.
+func (s *OutboxService) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.shared == nil {
+		return fn(ctx)
+	}
+	return s.shared.InTx(ctx, func(ctx context.Context) error {
+		c := &outboxCollector{}
+		if err := fn(context.WithValue(ctx, outboxCollectorKey{}, c)); err != nil {
+			return err
+		}
+		return s.applyInline(ctx, c.events)
+	})
+}
+
+// applyInline runs the handlers for evs inside the caller's transaction
+// and marks them dispatched. Any failure fails the unit of work.
+func (s *OutboxService) applyInline(ctx context.Context, evs []*models.OutboxEvent) error {
+	outbox := s.sources[s.domains[0]]
+	for _, ev := range evs {
+		h, ok := s.handlers[ev.Topic]
+		if !ok {
+			return fmt.Errorf("no handler for outbox topic %q", ev.Topic)
+		}
+		if err := h(ctx, ev); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		ev.Status = models.OutboxDispatched
+		ev.Attempts = 1
+		ev.DispatchedAt = &now
+		if err := outbox.Update(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outboxCollector gathers the events created during a shared-database
+// unit of work.
+type outboxCollector struct {
+	events []*models.OutboxEvent
+}
+
+type outboxCollectorKey struct{}
+
+// Handle registers the consumer of topic.
+func (s *OutboxService) Handle(topic string, h OutboxHandler) {
+	s.handlers[topic] = h
+}
+
+// Start dispatches due events until ctx is cancelled, polling every
+// interval.
+func (s *OutboxService) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// DispatchDue makes one attempt at every due event in every source.
+func (s *OutboxService) DispatchDue(ctx context.Context) error {
+	for _, domain := range s.domains {
+		r := s.sources[domain]
+		for {
+			due, err := r.ListDue(ctx, time.Now(), 50)
+			if err != nil {
+				return fmt.Errorf("%s: %w", domain, err)
+			}
+			if len(due) == 0 {
+				break
+			}
+			for _, ev := range due {
+				if err := s.dispatch(ctx, r, ev); err != nil {
+					return fmt.Errorf("%s: %w", domain, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dispatch runs ev's handler once and records the outcome.
+func (s *OutboxService) dispatch(ctx context.Context, r repos.OutboxRepo, ev *models.OutboxEvent) error {
+	var err error
+	if h, ok := s.handlers[ev.Topic]; ok {
+		err = h(ctx, ev)
+	} else {
+		err = &notify.PermanentError{Err: fmt.Errorf("no handler for topic %q", ev.Topic)}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	now := time.Now().UTC()
+	ev.Attempts++
+	switch {
+	case err == nil:
+		ev.Status = models.OutboxDispatched
+		ev.DispatchedAt = &now
+		ev.LastError = ""
+	case errors.Is(err, repos.ErrNotFound) || notify.IsPermanent(err) || ev.Attempts >= MaxOutboxAttempts:
+		ev.Status = models.OutboxDead
+		ev.LastError = err.Error()
+		log.Printf("outbox: %s %s is dead: %v", ev.Topic, ev.ID, err)
+	default:
+		ev.NextAttemptAt = now.Add(outboxRetry.Backoff(ev.Attempts))
+		ev.LastError = err.Error()
+	}
+	return r.Update(ctx, ev)
+}
+
+// List returns the tenant's outbox events, newest first, from one domain
+// or from all when domain is empty.
+func (s *OutboxService) List(ctx context.Context, tenantID, domain, status string, limit int) ([]*models.OutboxEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	domains := s.domains
+	if domain != "" {
+		if _, ok := s.sources[domain]; !ok {
+			return nil, fmt.Errorf("unknown outbox domain %q", domain)
+		}
+		domains = []string{domain}
+	}
+	var out []*models.OutboxEvent
+	for _, d := range domains {
+		evs, err := s.sources[d].List(ctx, tenantID, status, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range evs {
+			ev.Domain = d
+		}
+		out = append(out, evs...)
+	}
+	slices.SortFunc(out, func(a, b *models.OutboxEvent) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// Retry requeues an event, typically a dead one, with a fresh set of
+// retries. Its consumer still ignores it if it was already applied.
+func (s *OutboxService) Retry(ctx context.Context, tenantID, domain, id string) error {
+	r, ok := s.sources[domain]
+	if !ok {
+		return repos.ErrNotFound
+	}
+	ev, err := r.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	ev.Status = models.OutboxPending
+	ev.Attempts = 0
+	ev.NextAttemptAt = time.Now().UTC()
+	ev.DispatchedAt = nil
+	return r.Update(ctx, ev)
+}
+
+// newOutboxEvent builds an event for the owning repo to write alongside
+// its change.
+func newOutboxEvent(ctx context.Context, tenantID, topic, aggregateType string, aggregateID int64, payload any) (*models.OutboxEvent, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	ev := &models.OutboxEvent{
+		ID:            "obx_" + randomHex(12),
+		TenantID:      tenantID,
+		Topic:         topic,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       b,
+	}
+	if c, ok := ctx.Value(outboxCollectorKey{}).(*outboxCollector); ok {
+		c.events = append(c.events, ev)
+	}
+	return ev, nil
+}
+
+// decodeOutboxPayload unmarshals ev's payload; a payload that cannot be
+// decoded will never succeed, so the error is permanent.
+func decodeOutboxPayload(ev *models.OutboxEvent, v any) error {
+	if err := json.Unmarshal(ev.Payload, v); err != nil {
+		return &notify.PermanentError{Err: fmt.Errorf("decode %s payload: %w", ev.Topic, err)}
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/mail"
+)
+
+// PasswordResetTTL is how long an emailed reset token stays valid.
+const PasswordResetTTL = time.Hour
+
+// PermUnlockUsers guards listing lockouts and unlocking accounts.
+const PermUnlockUsers = "unlock_users"
+
+// lockoutWindow is how long a failure is remembered: a failure after a
+// quiet spell this long starts the count again.
+const lockoutWindow = 24 * time.Hour
+
+// PasswordPolicy is what a new password must satisfy. Zero values take
+// the defaults.
+type PasswordPolicy struct {
+	MinLength int // default 10
+	History   int // previous passwords that cannot be reused, default 5
+	// Breached holds lower-cased passwords known from breaches, which are
+	// refused whatever their length.
+	Breached map[string]bool
+}
+
+// LockoutPolicy is how failed logins are throttled. After Threshold
+// consecutive failures for a username from one IP, that address is locked
+// out for Base, doubling with each further failure up to Max. The same
+// applies to the username from every address after UserThreshold
+// failures, and to the address for every username after IPThreshold.
+type LockoutPolicy struct {
+	Threshold     int           // default 5
+	UserThreshold int           // default 4 * Threshold
+	IPThreshold   int           // default 10 * Threshold
+	Base          time.Duration // default 30s
+	Max           time.Duration // default 1h
+}
+
+// LockedError is returned by login while an account is locked out.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s (locked until %s)", repos.ErrAccountLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *LockedError) Is(target error) bool { return target == repos.ErrAccountLocked }
+
+// LoadBreachedPasswords reads a breached-password list, one password per
+// line; blank lines and lines starting with # are skipped.
+func LoadBreachedPasswords(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out[strings.ToLower(line)] = true
+	}
+	return out, sc.Err()
+}
+
+// PasswordService enforces the password policy and history, runs the
+// forgotten-password flow and throttles failed logins.
+type PasswordService struct {
+	repo     repos.PasswordRepo
+	userRepo repos.UserRepo
+	sessions repos.SessionRepo
+	mailer   mail.Sender
+	resetURL string
+	policy   PasswordPolicy
+	lockout  LockoutPolicy
+	now      func() time.Time
+}
+
+// NewPasswordService builds the service. mailer may be nil, in which case
+// reset requests are logged and dropped.
+func NewPasswordService(
+	r repos.PasswordRepo,
+	ur repos.UserRepo,
+	sessions repos.SessionRepo,
+	mailer mail.Sender,
+	resetURL string,
+	policy PasswordPolicy,
+	lockout LockoutPolicy,
+) *PasswordService {
+	if policy.MinLength <= 0 {
+		policy.MinLength = 10
+	}
+	if policy.History <= 0 {
+		policy.History = 5
+	}
+	if lockout.Threshold <= 0 {
+		lockout.Threshold = 5
+	}
+	if lockout.UserThreshold <= 0 {
+		lockout.UserThreshold = 4 * lockout.Threshold
+	}
+	if lockout.IPThreshold <= 0 {
+		lockout.IPThreshold = 10 * lockout.Threshold
+	}
+	if lockout.Base <= 0 {
+		lockout.Base = 30 * time.Second
+	}
+	if lockout.Max <= 0 {
+		lockout.Max = time.Hour
+	}
+	return &PasswordService{
+		repo:     r,
+		userRepo: ur,
+		sessions: sessions,
+		mailer:   mailer,
+		resetURL: resetURL,
+		policy:   policy,
+		lockout:  lockout,
+		now:      time.Now,
+	}
+}
+
+// Check refuses a password that is too short, equal to the username, on
+// the breached list, or among the user's recent passwords. userID 0 skips
+// the history check, for a user not yet created.
+func (s *PasswordService) Check(ctx context.Context, tenantID string, userID int64, username, raw string) error {
+	if len([]rune(raw)) < s.policy.MinLength {
+		return fmt.Errorf("%w: use at least %d characters", repos.ErrWeakPassword, s.policy.MinLength)
+	}
+	if strings.EqualFold(raw, username) {
+		return fmt.Errorf("%w: must differ from the username", repos.ErrWeakPassword)
+	}
+	if s.policy.Breached[strings.ToLower(raw)] {
+		return fmt.Errorf("%w: it appears in a list of breached passwords", repos.ErrWeakPassword)
+	}
+	if userID == 0 {
+		return nil
+	}
+	hashes, err := s.repo.ListHistory(ctx, tenantID, userID, s.policy.History)
+	if err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(h), []byte(raw)) == nil {
+			return repos.ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Hash checks raw against the policy and returns its bcrypt hash.
+func (s *PasswordService) Hash(ctx context.Context, tenantID string, userID int64, username, raw string) (string, error) {
+	if err := s.Check(ctx, tenantID, userID, username, raw); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(raw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Remember adds a password hash to the user's history.
+func (s *PasswordService) Remember(ctx context.Context, tenantID string, userID int64, hash string) error {
+	return s.repo.AddHistory(ctx, tenantID, userID, hash, s.now().UTC())
+}
+
+// Change sets a new password for a logged-in user who knows the current
+// one. A wrong current password from ip counts as a failed login, so
+// guessing it is throttled like logins.
+func (s *PasswordService) Change(ctx context.Context, tenantID string, userID int64, current, next, ip string) error {
+	u, err := s.userRepo.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.CheckLockout(ctx, tenantID, u.UserName, ip); err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(current)) != nil {
+		if err := s.RecordFailure(ctx, tenantID, u.UserName, ip); err != nil {
+			return err
+		}
+		return repos.ErrInvalidCredentials
+	}
+	if err := s.set(ctx, u, next, u.UserName); err != nil {
+		return err
+	}
+	return s.RecordSuccess(ctx, tenantID, u.UserName, ip)
+}
+
+// RequestReset emails a reset link to the user if the username exists and
+// has an email address. It reports nothing either way, so it cannot be
+// used to discover usernames.
+func (s *PasswordService) RequestReset(ctx context.Context, tenantID, username string) error {
+	u, err := s.userRepo.GetByUsername(ctx, tenantID, username)
+	if errors.Is(err, repos.ErrNotFound) || (err == nil && (u.Deleted || u.Email == "")) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.mailer == nil {
+		log.Printf("password: reset requested for user %d in tenant %s but no mailer is configured", u.ID, u.TenantID)
+		return nil
+	}
+	token := "pr_" + randomHex(32)
+	now := s.now().UTC()
+	_, err = s.repo.CreateResetToken(ctx, &models.PasswordResetToken{
+		TenantID:  u.TenantID,
+		UserID:    u.ID,
+		TokenHash: hashSessionToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(PasswordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{u.Email},
+		Subject: "Reset your password",
+		Body:    s.resetBody(u, token),
+	})
+}
+
+func (s *PasswordService) resetBody(u *models.User, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\nSomeone asked to reset the password for %s. ", u.FirstName, u.UserName)
+	if s.resetURL != "" {
+		sep := "?"
+		if strings.Contains(s.resetURL, "?") {
+			sep = "&"
+		}
+		fmt.Fprintf(&b, "To choose a new password, open:\n\n%s%stoken=%s\n\n", s.resetURL, sep, token)
+	} else {
+		fmt.Fprintf(&b, "To choose a new password, use this reset token:\n\n%s\n\n", token)
+	}
+	fmt.Fprintf(&b, "The link works once and expires in %d minutes. If you did not ask for this, ignore this email.\n", int(PasswordResetTTL/time.Minute))
+	return b.String()
+}
+
+// Reset spends a reset token and sets the new password. All the user's
+// sessions are ended and any lockout on the account is lifted.
+func (s *PasswordService) Reset(ctx context.Context, token, next string) error {
+	t, err := s.repo.GetResetToken(ctx, hashSessionToken(token))
+	if errors.Is(err, repos.ErrNotFound) {
+		return repos.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	if t.UsedAt != nil || !now.Before(t.ExpiresAt) {
+		return repos.ErrInvalidResetToken
+	}
+	u, err := s.userRepo.GetByID(ctx, t.TenantID, t.UserID)
+	if err != nil || u.Deleted {
+		return repos.ErrInvalidResetToken
+	}
+	// Check the policy before spending the token, so a rejected password
+	// can be retried with the same link.
+	if err := s.Check(ctx, u.TenantID, u.ID, u.UserName, next); err != nil {
+		return err
+	}
+	ok, err := s.repo.UseResetToken(ctx, t.ID, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return repos.ErrInvalidResetToken
+	}
+	if err := s.set(ctx, u, next, u.UserName); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeUser(ctx, u.TenantID, u.ID, now, u.UserName); err != nil {
+		return err
+	}
+	return s.repo.ClearLoginFailures(ctx, u.TenantID, u.UserName, "")
+}
+
+// set stores a new password for u and records it in the history. Any
+// outstanding reset tokens stop working.
+func (s *PasswordService) set(ctx context.Context, u *models.User, raw, currentUser string) error {
+	hash, err := s.Hash(ctx, u.TenantID, u.ID, u.UserName, raw)
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	u.PasswordHash = hash
+	u.ModifiedBy = currentUser
+	u.LastModified = now
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return err
+	}
+	if err := s.repo.ExpireResetTokens(ctx, u.TenantID, u.ID, now); err != nil {
+		return err
+	}
+	return s.Remember(ctx, u.TenantID, u.ID, hash)
+}
+
+// lockoutCounter is one of the failure counters a login counts against.
+// An empty username or ip stands for any.
+type lockoutCounter struct {
+	username, ip string
+	threshold    int
+}
+
+// counters returns the counters for username from ip: the pair, the
+// username from any address and the address for any username.
+func (s *PasswordService) counters(username, ip string) []lockoutCounter {
+	return []lockoutCounter{
+		{username, ip, s.lockout.Threshold},
+		{username, "", s.lockout.UserThreshold},
+		{"", ip, s.lockout.IPThreshold},
+	}
+}
+
+// CheckLockout returns a *LockedError if logins for username from ip are
+// locked out.
+func (s *PasswordService) CheckLockout(ctx context.Context, tenantID, username, ip string) error {
+	var locked *LockedError
+	for _, c := range s.counters(username, ip) {
+		f, err := s.repo.GetLoginFailure(ctx, tenantID, c.username, c.ip)
+		if errors.Is(err, repos.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if f.LockedUntil != nil && s.now().Before(*f.LockedUntil) && (locked == nil || f.LockedUntil.After(locked.Until)) {
+			locked = &LockedError{Until: *f.LockedUntil}
+		}
+	}
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the address, the username
+// or both out once a threshold is reached, for longer with every further
+// failure.
+func (s *PasswordService) RecordFailure(ctx context.Context, tenantID, username, ip string) error {
+	now := s.now().UTC()
+	for _, c := range s.counters(username, ip) {
+		f, err := s.repo.GetLoginFailure(ctx, tenantID, c.username, c.ip)
+		if errors.Is(err, repos.ErrNotFound) {
+			f = &models.LoginFailure{TenantID: tenantID, UserName: c.username, IP: c.ip}
+		} else if err != nil {
+			return err
+		}
+		if now.Sub(f.LastFailure) > lockoutWindow {
+			f.Failures = 0
+			f.LockedUntil = nil
+		}
+		f.Failures++
+		f.LastFailure = now
+		if over := f.Failures - c.threshold; over >= 0 {
+			d := s.lockout.Max
+			if over < 30 && s.lockout.Base<<over < s.lockout.Max {
+				d = s.lockout.Base << over
+			}
+			until := now.Add(d)
+			f.LockedUntil = &until
+			log.Printf("password: %d failed logins for %q in tenant %s from %q; locked until %s", f.Failures, c.username, tenantID, c.ip, until.Format(time.RFC3339))
+		}
+		if err := s.repo.SaveLoginFailure(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSuccess clears the failure counts for username from ip, and from
+// any address, once a login has completed, including its second factor.
+// The address's count for other usernames stands.
+func (s *PasswordService) RecordSuccess(ctx context.Context, tenantID, username, ip string) error {
+	if err := s.repo.ClearLoginFailures(ctx, tenantID, username, ip); err != nil {
+		return err
+	}
+	f, err := s.repo.GetLoginFailure(ctx, tenantID, username, "")
+	if errors.Is(err, repos.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f.Failures = 0
+	f.LockedUntil = nil
+	return s.repo.SaveLoginFailure(ctx, f)
+}
+
+// Lockouts lists the usernames and addresses currently locked out.
+func (s *PasswordService) Lockouts(ctx context.Context, tenantID string) ([]*models.LoginFailure, error) {
+	list, err := s.repo.ListLockouts(ctx, tenantID, s.now().UTC())
+	if list == nil && err == nil {
+		list = []*models.LoginFailure{}
+	}
+	return list, err
+}
+
+// Unlock lifts every lockout on the user's username.
+func (s *PasswordService) Unlock(ctx context.Context, tenantID string, userID int64) error {
+	u, err := s.userRepo.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.ClearLoginFailures(ctx, tenantID, u.UserName, "")
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestPasswordService(t *testing.T) (*PasswordService, repos.UserRepo) {
+	db := newTestDB(t)
+	userRepo := repos.NewDBUserRepo(db, "sqlite")
+	svc := NewPasswordService(repos.NewDBPasswordRepo(db, "sqlite"), userRepo, repos.NewDBSessionRepo(db, "sqlite"), nil, "",
+		PasswordPolicy{}, LockoutPolicy{Threshold: 3, UserThreshold: 5, IPThreshold: 5})
+	return svc, userRepo
+}
+
+func wantLocked(t *testing.T, what string, err error) {
+	t.Helper()
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Errorf("%s: err = %v, want a LockedError", what, err)
+	}
+}
+
+func TestLockoutPerPair(t *testing.T) {
+	svc, _ := newTestPasswordService(t)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := svc.RecordFailure(ctx, testTenant, "alice", "192.0.2.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantLocked(t, "alice from the same address", svc.CheckLockout(ctx, testTenant, "alice", "192.0.2.1"))
+	if err := svc.CheckLockout(ctx, testTenant, "alice", "192.0.2.2"); err != nil {
+		t.Errorf("alice from another address: %v", err)
+	}
+	if err := svc.CheckLockout(ctx, testTenant, "bob", "192.0.2.1"); err != nil {
+		t.Errorf("bob from the same address: %v", err)
+	}
+}
+
+// Guessing one username from many addresses locks the username.
+func TestLockoutPerUsername(t *testing.T) {
+	svc, _ := newTestPasswordService(t)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := svc.RecordFailure(ctx, testTenant, "alice", fmt.Sprintf("192.0.2.%d", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantLocked(t, "alice from a new address", svc.CheckLockout(ctx, testTenant, "alice", "198.51.100.1"))
+	if err := svc.CheckLockout(ctx, testTenant, "bob", "198.51.100.1"); err != nil {
+		t.Errorf("bob: %v", err)
+	}
+}
+
+// Spraying many usernames from one address locks the address, and a
+// successful login there does not lift it.
+func TestLockoutPerAddress(t *testing.T) {
+	svc, _ := newTestPasswordService(t)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := svc.RecordFailure(ctx, testTenant, fmt.Sprintf("user%d", i), "192.0.2.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantLocked(t, "a new username from the address", svc.CheckLockout(ctx, testTenant, "carol", "192.0.2.1"))
+	if err := svc.RecordSuccess(ctx, testTenant, "user0", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	wantLocked(t, "after a success from the address", svc.CheckLockout(ctx, testTenant, "carol", "192.0.2.1"))
+	if err := svc.CheckLockout(ctx, testTenant, "carol", "192.0.2.2"); err != nil {
+		t.Errorf("carol from another address: %v", err)
+	}
+}
+
+// Guessing the current password through Change is throttled like login.
+func TestChangePasswordThrottled(t *testing.T) {
+	svc, userRepo := newTestPasswordService(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID, err := userRepo.Create(ctx, &models.User{
+		TenantID: testTenant, UserName: "alice", PasswordHash: string(hash), FirstName: "Alice", LastName: "Smith",
+		Email: "alice@example.com", Role: "agent", CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		err := svc.Change(ctx, testTenant, userID, "guess", "a much better password", "192.0.2.1")
+		if !errors.Is(err, repos.ErrInvalidCredentials) {
+			t.Fatalf("guess %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	wantLocked(t, "right password after the guesses",
+		svc.Change(ctx, testTenant, userID, "correct horse", "a much better password", "192.0.2.1"))
+}
@@ -13,10 +13,12 @@ import (
 type PaymentService struct {
 	repo               repos.PaymentRepo
 	installmentService *InstallmentService
+	events             EventPublisher
+	uow                UnitOfWork
 }
 
-func NewPaymentService(r repos.PaymentRepo, ir *InstallmentService) *PaymentService {
-	return &PaymentService{repo: r, installmentService: ir}
+func NewPaymentService(r repos.PaymentRepo, ir *InstallmentService, events EventPublisher, uow UnitOfWork) *PaymentService {
+	return &PaymentService{repo: r, installmentService: ir, events: orNop(events), uow: orDirect(uow)}
 }
 
 func (s *PaymentService) CreatePayment(ctx context.Context, tenantID, currentUser string, p models.Payment) (int64, error) {
@@ -30,7 +32,21 @@ func (s *PaymentService) CreatePayment(ctx context.Context, tenantID, currentUse
 	p.CreatedBy = currentUser
 	p.ModifiedBy = currentUser
 	p.Deleted = false
-	return s.repo.Create(ctx, &p)
+	var id int64
+	err := s.uow.Run(ctx, func(ctx context.Context) error {
+		ev, err := paymentAppliedEvent(ctx, tenantID, currentUser, 0, p.InstallmentID, p.AmountPaid, p.PaymentDate)
+		if err != nil {
+			return err
+		}
+		id, err = s.repo.CreateWithEvents(ctx, &p, ev)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	p.ID = id
+	s.events.Publish(ctx, tenantID, models.EventPaymentReceived, p)
+	return id, nil
 }
 
 func (s *PaymentService) ListPayments(ctx context.Context, tenantID string) ([]models.Payment, error) {
@@ -45,6 +61,9 @@ func (s *PaymentService) ListPayments(ctx context.Context, tenantID string) ([]m
 	return out, nil
 }
 
+// UpdatePayment saves p over payment id and moves the paid amount between
+// installments to match. It returns ErrPaymentChanged if a concurrent
+// request changed or deleted the payment first.
 func (s *PaymentService) UpdatePayment(ctx context.Context, tenantID, currentUser string, id int64, p models.Payment) error {
 	existing, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
@@ -58,9 +77,34 @@ func (s *PaymentService) UpdatePayment(ctx context.Context, tenantID, currentUse
 	p.ID = id
 	p.ModifiedBy = currentUser
 	p.LastModified = now
-	return s.repo.Update(ctx, &p)
+
+	return s.uow.Run(ctx, func(ctx context.Context) error {
+		// Move the paid amount between installments, or adjust it in place.
+		var evs []*models.OutboxEvent
+		if existing.InstallmentID != p.InstallmentID {
+			out, err := paymentAppliedEvent(ctx, tenantID, currentUser, id, existing.InstallmentID, -existing.AmountPaid, p.PaymentDate)
+			if err != nil {
+				return err
+			}
+			in, err := paymentAppliedEvent(ctx, tenantID, currentUser, id, p.InstallmentID, p.AmountPaid, p.PaymentDate)
+			if err != nil {
+				return err
+			}
+			evs = append(evs, out, in)
+		} else if delta := p.AmountPaid - existing.AmountPaid; delta != 0 {
+			ev, err := paymentAppliedEvent(ctx, tenantID, currentUser, id, p.InstallmentID, delta, p.PaymentDate)
+			if err != nil {
+				return err
+			}
+			evs = append(evs, ev)
+		}
+		return s.repo.UpdateWithEvents(ctx, &p, existing, evs...)
+	})
 }
 
+// DeletePayment deletes payment id and takes its amount off the
+// installment, returning ErrPaymentChanged if a concurrent request changed
+// or deleted it first.
 func (s *PaymentService) DeletePayment(ctx context.Context, tenantID, currentUser string, id int64) error {
 	existing, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
@@ -69,8 +113,26 @@ func (s *PaymentService) DeletePayment(ctx context.Context, tenantID, currentUse
 	if existing.Deleted {
 		return repos.ErrNotFound
 	}
-	existing.Deleted = true
-	existing.ModifiedBy = currentUser
-	existing.LastModified = time.Now().UTC()
-	return s.repo.Update(ctx, existing)
+	deleted := *existing
+	deleted.Deleted = true
+	deleted.ModifiedBy = currentUser
+	deleted.LastModified = time.Now().UTC()
+	return s.uow.Run(ctx, func(ctx context.Context) error {
+		ev, err := paymentAppliedEvent(ctx, tenantID, currentUser, id, existing.InstallmentID, -existing.AmountPaid, existing.PaymentDate)
+		if err != nil {
+			return err
+		}
+		return s.repo.UpdateWithEvents(ctx, &deleted, existing, ev)
+	})
+}
+
+// paymentAppliedEvent asks the installments database to move an
+// installment's paid amount by delta.
+func paymentAppliedEvent(ctx context.Context, tenantID, currentUser string, paymentID, installmentID int64, delta float64, paidDate time.Time) (*models.OutboxEvent, error) {
+	return newOutboxEvent(ctx, tenantID, models.TopicPaymentApplied, "payment", paymentID, models.PaymentApplied{
+		InstallmentID: installmentID,
+		Delta:         delta,
+		PaidDate:      paidDate,
+		ModifiedBy:    currentUser,
+	})
 }
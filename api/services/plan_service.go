@@ -4,25 +4,50 @@ package services
 import (
 	"context"
 	"errors"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 	"github.com/newssourcecrawler/realtorinstall/api/repos"
 )
 
+// MaxPlanInstallments bounds the schedule generated for a plan.
+const MaxPlanInstallments = 600
+
 type PlanService struct {
 	repo        repos.InstallmentPlanRepo
 	installRepo repos.InstallmentRepo
+	salesRepo   repos.SalesRepo
+	kyc         *KYCService
+	planTx      repos.Transactor
+	shared      bool // installments are in the plans database
+}
+
+func NewPlanService(r repos.InstallmentPlanRepo, ir repos.InstallmentRepo, sr repos.SalesRepo, kyc *KYCService, planTx repos.Transactor) *PlanService {
+	return &PlanService{repo: r, installRepo: ir, salesRepo: sr, kyc: kyc, planTx: planTx}
 }
 
-func NewPlanService(r repos.InstallmentPlanRepo, ir repos.InstallmentRepo) *PlanService {
-	return &PlanService{repo: r, installRepo: ir}
+// UseSharedDatabase switches to single-database mode, where a plan made
+// from a sale is written in one transaction with its schedule.
+func (s *PlanService) UseSharedDatabase() {
+	s.shared = true
 }
 
 func (s *PlanService) CreatePlan(ctx context.Context, tenantID, currentUser string, p models.InstallmentPlan) (int64, error) {
+	return s.createPlan(ctx, tenantID, currentUser, p, nil)
+}
+
+// createPlan writes p and, if schedule is set, calls it with the new plan
+// in the same transaction.
+func (s *PlanService) createPlan(ctx context.Context, tenantID, currentUser string, p models.InstallmentPlan, schedule func(context.Context, *models.InstallmentPlan) error) (int64, error) {
 	if p.PropertyID == 0 || p.BuyerID == 0 {
 		return 0, errors.New("property and buyer must be specified")
 	}
+	useOverride, err := s.kyc.CheckBuyer(ctx, tenantID, currentUser, p.BuyerID, models.KYCActionCreatePlan)
+	if err != nil {
+		return 0, err
+	}
 	now := time.Now().UTC()
 	p.TenantID = tenantID
 	p.CreatedAt = now
@@ -30,7 +55,24 @@ func (s *PlanService) CreatePlan(ctx context.Context, tenantID, currentUser stri
 	p.CreatedBy = currentUser
 	p.ModifiedBy = currentUser
 	p.Deleted = false
-	return s.repo.Create(ctx, &p)
+	var id int64
+	err = s.planTx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.Create(ctx, &p); err != nil {
+			return err
+		}
+		if schedule != nil {
+			p.ID = id
+			if err := schedule(ctx, &p); err != nil {
+				return err
+			}
+		}
+		return useOverride(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
 }
 
 func (s *PlanService) ListPlans(ctx context.Context, tenantID string) ([]models.InstallmentPlan, error) {
@@ -54,6 +96,7 @@ func (s *PlanService) UpdatePlan(ctx context.Context, tenantID, currentUser stri
 		return repos.ErrNotFound
 	}
 	now := time.Now().UTC()
+	p.AssignedTo = assignee(ctx, existing.AssignedTo, p.AssignedTo)
 	p.TenantID = tenantID
 	p.ID = id
 	p.ModifiedBy = currentUser
@@ -74,3 +117,160 @@ func (s *PlanService) DeletePlan(ctx context.Context, tenantID, currentUser stri
 	existing.LastModified = time.Now().UTC()
 	return s.repo.Update(ctx, existing)
 }
+
+// CreatePlanFromSale creates an installment plan for an installment sale,
+// taking property, buyer and price from the sale and the remaining terms
+// (down payment, number of installments, frequency, first installment date,
+// interest rate) from terms. The installment schedule is generated and the
+// plan is linked to the sale; a sale may only have one active plan. With
+// a shared database the plan and its schedule are written together;
+// otherwise the plan is deleted again if the schedule cannot be written.
+func (s *PlanService) CreatePlanFromSale(ctx context.Context, tenantID, currentUser string, saleID int64, terms models.InstallmentPlan) (*models.InstallmentPlan, error) {
+	sale, err := s.salesRepo.GetByID(ctx, tenantID, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if sale.Deleted {
+		return nil, repos.ErrNotFound
+	}
+	if !strings.EqualFold(sale.SaleType, models.SaleTypeInstallment) {
+		return nil, repos.ErrSaleNotInstallment
+	}
+	// The plan may be assigned to someone the caller cannot see.
+	if _, err := s.repo.GetActiveBySale(repos.Unscoped(ctx), tenantID, saleID); err == nil {
+		return nil, repos.ErrSaleHasActivePlan
+	} else if err != repos.ErrNotFound {
+		return nil, err
+	}
+
+	p := terms
+	p.ID = 0
+	p.SaleID = sale.ID
+	p.PropertyID = sale.PropertyID
+	p.BuyerID = sale.BuyerID
+	p.TotalPrice = sale.SalePrice
+	// The plan goes to whoever handles the sale.
+	p.AssignedTo = sale.AssignedTo
+	if p.AssignedTo == 0 {
+		p.AssignedTo = sale.OwnerID
+	}
+	if p.Frequency == "" {
+		p.Frequency = "Monthly"
+	}
+	if p.NumInstallments <= 0 || p.NumInstallments > MaxPlanInstallments || p.FirstInstallment.IsZero() || p.DownPayment < 0 || p.DownPayment >= p.TotalPrice || p.InterestRate < 0 {
+		return nil, repos.ErrInvalidPlanTerms
+	}
+	if _, ok := periodsPerYear(p.Frequency); !ok {
+		return nil, errors.New("unsupported frequency: " + p.Frequency)
+	}
+
+	schedule := func(ctx context.Context, p *models.InstallmentPlan) error {
+		return s.generateSchedule(ctx, tenantID, currentUser, p)
+	}
+	if !s.shared {
+		id, err := s.createPlan(ctx, tenantID, currentUser, p, nil)
+		if err != nil {
+			return nil, err
+		}
+		p.ID = id
+		p.TenantID = tenantID
+		if err := schedule(ctx, &p); err != nil {
+			// Don't leave a half-scheduled plan blocking the sale.
+			_ = s.DeletePlan(ctx, tenantID, currentUser, id)
+			return nil, err
+		}
+		return s.repo.GetByID(ctx, tenantID, id)
+	}
+	id, err := s.createPlan(ctx, tenantID, currentUser, p, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// generateSchedule creates p.NumInstallments installments for the financed
+// amount (TotalPrice less DownPayment). With a zero InterestRate the amount
+// is split evenly; otherwise InterestRate is an annual percentage and the
+// installments are level annuity payments. Amounts are rounded to cents and
+// the final installment absorbs the rounding difference.
+func (s *PlanService) generateSchedule(ctx context.Context, tenantID, currentUser string, p *models.InstallmentPlan) error {
+	perYear, _ := periodsPerYear(p.Frequency)
+	n := p.NumInstallments
+	balance := p.TotalPrice - p.DownPayment
+	rate := p.InterestRate / 100 / float64(perYear)
+
+	payment := balance / float64(n)
+	if rate > 0 {
+		payment = balance * rate / (1 - math.Pow(1+rate, -float64(n)))
+	}
+	payment = roundCents(payment)
+
+	var created []int64
+	for i := 1; i <= n; i++ {
+		interest := roundCents(balance * rate)
+		amount := payment
+		if i == n {
+			amount = roundCents(balance + interest)
+		}
+		balance = roundCents(balance + interest - amount)
+
+		id, err := s.installRepo.Create(ctx, &models.Installment{
+			TenantID:       tenantID,
+			PlanID:         p.ID,
+			SequenceNumber: i,
+			DueDate:        dueDate(p.FirstInstallment, p.Frequency, i-1),
+			AmountDue:      amount,
+			Status:         models.InstallmentPending,
+			CreatedBy:      currentUser,
+			ModifiedBy:     currentUser,
+		})
+		if err != nil {
+			for _, cid := range created {
+				_ = s.installRepo.Delete(ctx, tenantID, cid)
+			}
+			return err
+		}
+		created = append(created, id)
+	}
+	return nil
+}
+
+// periodsPerYear maps a plan Frequency to installments per year.
+func periodsPerYear(frequency string) (int, bool) {
+	switch strings.ToLower(frequency) {
+	case "weekly":
+		return 52, true
+	case "fortnightly", "biweekly":
+		return 26, true
+	case "monthly":
+		return 12, true
+	case "quarterly":
+		return 4, true
+	case "semiannually", "semi-annually":
+		return 2, true
+	case "annually", "yearly":
+		return 1, true
+	}
+	return 0, false
+}
+
+// dueDate returns the date of the installment k periods after first.
+func dueDate(first time.Time, frequency string, k int) time.Time {
+	switch strings.ToLower(frequency) {
+	case "weekly":
+		return first.AddDate(0, 0, 7*k)
+	case "fortnightly", "biweekly":
+		return first.AddDate(0, 0, 14*k)
+	case "quarterly":
+		return first.AddDate(0, 3*k, 0)
+	case "semiannually", "semi-annually":
+		return first.AddDate(0, 6*k, 0)
+	case "annually", "yearly":
+		return first.AddDate(k, 0, 0)
+	}
+	return first.AddDate(0, k, 0)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
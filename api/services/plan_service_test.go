@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+func newTestPlanService(t *testing.T) (*PlanService, repos.SalesRepo, repos.InstallmentPlanRepo) {
+	db := newTestDB(t)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	planRepo := repos.NewDBInstallmentPlanRepo(db, "sqlite")
+	salesRepo := repos.NewDBSalesRepo(db, "sqlite")
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+	return NewPlanService(planRepo, repos.NewDBInstallmentRepo(db, "sqlite"), salesRepo, kyc, repos.NewTransactor(db)), salesRepo, planRepo
+}
+
+// A second plan must not be created for a sale whose plan is assigned to
+// someone the caller cannot see.
+func TestCreatePlanFromSaleSeesHiddenPlan(t *testing.T) {
+	svc, salesRepo, planRepo := newTestPlanService(t)
+	ctx := context.Background()
+	saleID, err := salesRepo.Create(ctx, &models.Sales{
+		TenantID: testTenant, PropertyID: 1, BuyerID: 1, SalePrice: 100000, SaleDate: time.Now(),
+		SaleType: models.SaleTypeInstallment, OwnerID: 2, CreatedBy: "bob", ModifiedBy: "bob",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := planRepo.Create(ctx, &models.InstallmentPlan{
+		TenantID: testTenant, SaleID: saleID, PropertyID: 1, BuyerID: 1, TotalPrice: 100000,
+		NumInstallments: 12, Frequency: "Monthly", FirstInstallment: time.Now(),
+		OwnerID: 1, AssignedTo: 1, CreatedBy: "alice", ModifiedBy: "alice",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = svc.CreatePlanFromSale(asUser(2), testTenant, "bob", saleID, models.InstallmentPlan{
+		NumInstallments: 12, FirstInstallment: time.Now(),
+	})
+	if !errors.Is(err, repos.ErrSaleHasActivePlan) {
+		t.Fatalf("err = %v, want ErrSaleHasActivePlan", err)
+	}
+}
+
+// failingInstallments is an installment repo whose creates fail after the
+// first few.
+type failingInstallments struct {
+	repos.InstallmentRepo
+	left int
+}
+
+var errInstallmentCreate = errors.New("installment write failed")
+
+func (f *failingInstallments) Create(ctx context.Context, inst *models.Installment) (int64, error) {
+	if f.left == 0 {
+		return 0, errInstallmentCreate
+	}
+	f.left--
+	return f.InstallmentRepo.Create(ctx, inst)
+}
+
+// newInstallmentSale creates an installment sale to a buyer whose KYC has
+// been overridden for one plan.
+func newInstallmentSale(t *testing.T, db *sql.DB) int64 {
+	ctx := context.Background()
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	buyerID, err := buyerRepo.Create(ctx, &models.Buyer{
+		TenantID: testTenant, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+	if _, err := kyc.GrantOverride(ctx, testTenant, "alice", buyerID, models.KYCActionCreatePlan, "director sign-off"); err != nil {
+		t.Fatal(err)
+	}
+	saleID, err := repos.NewDBSalesRepo(db, "sqlite").Create(ctx, &models.Sales{
+		TenantID: testTenant, PropertyID: 1, BuyerID: buyerID, SalePrice: 100000, SaleDate: time.Now(),
+		SaleType: models.SaleTypeInstallment, CreatedBy: "alice", ModifiedBy: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saleID
+}
+
+func TestCreatePlanFromSaleCapsInstallments(t *testing.T) {
+	db := newTestDB(t)
+	saleID := newInstallmentSale(t, db)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+	svc := NewPlanService(repos.NewDBInstallmentPlanRepo(db, "sqlite"), repos.NewDBInstallmentRepo(db, "sqlite"),
+		repos.NewDBSalesRepo(db, "sqlite"), kyc, repos.NewTransactor(db))
+
+	_, err := svc.CreatePlanFromSale(context.Background(), testTenant, "alice", saleID, models.InstallmentPlan{
+		NumInstallments: MaxPlanInstallments + 1, FirstInstallment: time.Now(),
+	})
+	if !errors.Is(err, repos.ErrInvalidPlanTerms) {
+		t.Fatalf("err = %v, want ErrInvalidPlanTerms", err)
+	}
+}
+
+// With a shared database a schedule that cannot be written leaves no plan,
+// no installments and the KYC override unspent.
+func TestCreatePlanFromSaleSharedRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	saleID := newInstallmentSale(t, db)
+	buyerRepo := repos.NewDBBuyerRepo(db, "sqlite")
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), buyerRepo, repos.NewDBAttachmentRepo(db, "sqlite"))
+	planRepo := repos.NewDBInstallmentPlanRepo(db, "sqlite")
+	installRepo := repos.NewDBInstallmentRepo(db, "sqlite")
+	terms := models.InstallmentPlan{NumInstallments: 12, FirstInstallment: time.Now()}
+
+	failing := NewPlanService(planRepo, &failingInstallments{InstallmentRepo: installRepo, left: 5},
+		repos.NewDBSalesRepo(db, "sqlite"), kyc, repos.NewTransactor(db))
+	failing.UseSharedDatabase()
+	if _, err := failing.CreatePlanFromSale(ctx, testTenant, "alice", saleID, terms); !errors.Is(err, errInstallmentCreate) {
+		t.Fatalf("err = %v, want the installment failure", err)
+	}
+	plans, err := planRepo.ListAll(ctx, testTenant)
+	if err != nil || len(plans) != 0 {
+		t.Fatalf("plans = %d (err %v), want none", len(plans), err)
+	}
+	insts, err := installRepo.ListAll(ctx, testTenant)
+	if err != nil || len(insts) != 0 {
+		t.Fatalf("installments = %d (err %v), want none", len(insts), err)
+	}
+
+	svc := NewPlanService(planRepo, installRepo, repos.NewDBSalesRepo(db, "sqlite"), kyc, repos.NewTransactor(db))
+	svc.UseSharedDatabase()
+	plan, err := svc.CreatePlanFromSale(ctx, testTenant, "alice", saleID, terms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insts, err := installRepo.ListByPlan(ctx, testTenant, plan.ID); err != nil || len(insts) != 12 {
+		t.Fatalf("installments = %d (err %v), want 12", len(insts), err)
+	}
+}
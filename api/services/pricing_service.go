@@ -3,19 +3,27 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/360EntSecGroup-Skylar/excelize"
+
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 	"github.com/newssourcecrawler/realtorinstall/api/repos"
 )
 
 type PricingService struct {
 	repo repos.LocationPricingRepo
+	tx   repos.Transactor
 }
 
-func NewPricingService(r repos.LocationPricingRepo) *PricingService {
-	return &PricingService{repo: r}
+func NewPricingService(r repos.LocationPricingRepo, tx repos.Transactor) *PricingService {
+	return &PricingService{repo: r, tx: tx}
 }
 
 func (s *PricingService) CreateLocationPricing(ctx context.Context, tenantID, currentUser string, lp models.LocationPricing) (int64, error) {
@@ -73,3 +81,198 @@ func (s *PricingService) DeleteLocationPricing(ctx context.Context, tenantID, cu
 	existing.LastModified = time.Now().UTC()
 	return s.repo.Update(ctx, existing)
 }
+
+// ImportLocationPricing validates every row of a CSV or XLSX sheet and works
+// out which pricing records it would insert or update. Rows are matched to
+// existing records on (zip_code, effective_date). Nothing is written when
+// dryRun is set or when any row is invalid; otherwise all changes are applied
+// in a single transaction.
+func (s *PricingService) ImportLocationPricing(ctx context.Context, tenantID, currentUser, format string, r io.Reader, dryRun bool) (*models.PricingImportResult, error) {
+	rows, err := readPricingSheet(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("import file is empty")
+	}
+	cols, err := pricingImportColumns(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// The existing records are read in the transaction that writes the
+	// changes, so rows are not matched against records another import has
+	// since changed.
+	var result *models.PricingImportResult
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.importPricingRows(ctx, tenantID, currentUser, rows, cols, dryRun)
+		return err
+	})
+	return result, err
+}
+
+// importPricingRows does the work of ImportLocationPricing once the header
+// has been read.
+func (s *PricingService) importPricingRows(ctx context.Context, tenantID, currentUser string, rows [][]string, cols map[string]int, dryRun bool) (*models.PricingImportResult, error) {
+	existing, err := s.repo.ListAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.LocationPricing, len(existing))
+	for _, lp := range existing {
+		byKey[pricingImportKey(lp.ZipCode, lp.EffectiveDate)] = lp
+	}
+
+	result := &models.PricingImportResult{DryRun: dryRun}
+	var inserts, updates []*models.LocationPricing
+	seen := make(map[string]int)
+	for i, rec := range rows[1:] {
+		rowNum := i + 2
+		if isBlankRow(rec) {
+			continue
+		}
+		lp, err := parsePricingRow(rec, cols)
+		if err != nil {
+			result.Errors = append(result.Errors, models.PricingImportRowError{Row: rowNum, Error: err.Error()})
+			continue
+		}
+		key := pricingImportKey(lp.ZipCode, lp.EffectiveDate)
+		if first, dup := seen[key]; dup {
+			result.Errors = append(result.Errors, models.PricingImportRowError{
+				Row:   rowNum,
+				Error: fmt.Sprintf("duplicate of row %d", first),
+			})
+			continue
+		}
+		seen[key] = rowNum
+
+		lp.TenantID = tenantID
+		lp.ModifiedBy = currentUser
+		if prev, ok := byKey[key]; ok {
+			if prev.City == lp.City && prev.PricePerSqFt == lp.PricePerSqFt {
+				result.Unchanged++
+				continue
+			}
+			lp.ID = prev.ID
+			lp.CreatedBy = prev.CreatedBy
+			lp.CreatedAt = prev.CreatedAt
+			updates = append(updates, lp)
+			result.Updates = append(result.Updates, models.PricingImportChange{Row: rowNum, Before: *prev, After: *lp})
+			continue
+		}
+		lp.CreatedBy = currentUser
+		inserts = append(inserts, lp)
+		result.Inserts = append(result.Inserts, *lp)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, repos.ErrImportRowsInvalid
+	}
+	if dryRun || (len(inserts) == 0 && len(updates) == 0) {
+		return result, nil
+	}
+	if err := s.repo.ImportBatch(ctx, inserts, updates); err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	for i, lp := range inserts {
+		result.Inserts[i] = *lp
+	}
+	for i, lp := range updates {
+		result.Updates[i].After = *lp
+	}
+	return result, nil
+}
+
+// readPricingSheet returns the raw cell values of a CSV file or of the first
+// worksheet of an XLSX workbook.
+func readPricingSheet(format string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return cr.ReadAll()
+	case "xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return f.GetRows(f.GetSheetName(1)), nil
+	default:
+		return nil, repos.ErrUnsupportedImportFormat
+	}
+}
+
+// pricingImportColumns maps the expected column names to their index in the
+// header row. Column order is free; every column is required.
+func pricingImportColumns(header []string) (map[string]int, error) {
+	cols := make(map[string]int)
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, req := range []string{"zip_code", "city", "price_per_sqft", "effective_date"} {
+		if _, ok := cols[req]; !ok {
+			return nil, fmt.Errorf("missing required column %q", req)
+		}
+	}
+	return cols, nil
+}
+
+func parsePricingRow(rec []string, cols map[string]int) (*models.LocationPricing, error) {
+	cell := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	lp := &models.LocationPricing{
+		ZipCode: cell("zip_code"),
+		City:    cell("city"),
+	}
+	if lp.ZipCode == "" {
+		return nil, errors.New("zip_code is required")
+	}
+	if lp.City == "" {
+		return nil, errors.New("city is required")
+	}
+	price, err := strconv.ParseFloat(cell("price_per_sqft"), 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("invalid price_per_sqft %q", cell("price_per_sqft"))
+	}
+	lp.PricePerSqFt = price
+	eff, err := parseImportDate(cell("effective_date"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective_date %q", cell("effective_date"))
+	}
+	lp.EffectiveDate = eff
+	return lp, nil
+}
+
+// parseImportDate accepts ISO dates as well as the day serial numbers Excel
+// stores for date cells without a display format.
+func parseImportDate(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, errors.New("unrecognised date")
+}
+
+func pricingImportKey(zip string, eff time.Time) string {
+	return strings.ToUpper(zip) + "|" + eff.UTC().Format("2006-01-02")
+}
+
+func isBlankRow(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
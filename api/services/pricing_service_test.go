@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+func newTestPricingService(t *testing.T) (*PricingService, repos.LocationPricingRepo) {
+	db := newTestDB(t)
+	repo := repos.NewDBLocationRepo(db, "sqlite")
+	return NewPricingService(repo, repos.NewTransactor(db)), repo
+}
+
+// A row without a city is reported and nothing is imported.
+func TestImportLocationPricingRequiresCity(t *testing.T) {
+	svc, repo := newTestPricingService(t)
+	ctx := context.Background()
+	sheet := "zip_code,city,price_per_sqft,effective_date\n" +
+		"10001,New York,950,2026-01-01\n" +
+		"10002,,900,2026-01-01\n"
+
+	result, err := svc.ImportLocationPricing(ctx, testTenant, "alice", "csv", strings.NewReader(sheet), false)
+	if !errors.Is(err, repos.ErrImportRowsInvalid) {
+		t.Fatalf("err = %v, want ErrImportRowsInvalid", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 3 || result.Errors[0].Error != "city is required" {
+		t.Fatalf("errors = %+v, want row 3 missing its city", result.Errors)
+	}
+	if all, err := repo.ListAll(ctx, testTenant); err != nil || len(all) != 0 {
+		t.Fatalf("imported %d records (err %v), want none", len(all), err)
+	}
+
+	_, err = svc.ImportLocationPricing(ctx, testTenant, "alice", "csv",
+		strings.NewReader("zip_code,price_per_sqft,effective_date\n10001,950,2026-01-01\n"), false)
+	if err == nil || !strings.Contains(err.Error(), `"city"`) {
+		t.Fatalf("sheet without a city column: err = %v", err)
+	}
+}
+
+func TestImportLocationPricingWritesRows(t *testing.T) {
+	svc, repo := newTestPricingService(t)
+	ctx := context.Background()
+	sheet := "zip_code,city,price_per_sqft,effective_date\n10001,New York,950,2026-01-01\n"
+
+	result, err := svc.ImportLocationPricing(ctx, testTenant, "alice", "csv", strings.NewReader(sheet), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Committed || len(result.Inserts) != 1 {
+		t.Fatalf("result = %+v, want one committed insert", result)
+	}
+	all, err := repo.ListAll(ctx, testTenant)
+	if err != nil || len(all) != 1 || all[0].City != "New York" {
+		t.Fatalf("records = %+v (err %v), want the New York row", all, err)
+	}
+}
@@ -2,20 +2,31 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/newssourcecrawler/realtorinstall/api/models"
 	"github.com/newssourcecrawler/realtorinstall/api/repos"
 )
 
+// maxSearchRadiusKm bounds radius searches so a typo cannot scan a continent.
+const maxSearchRadiusKm = 500
+
 type PropertyService struct {
-	repo        repos.PropertyRepo
-	userRepo    repos.UserRepo
-	pricingRepo repos.LocationPricingRepo
+	repo         repos.PropertyRepo
+	userRepo     repos.UserRepo
+	pricingRepo  repos.LocationPricingRepo
+	centroidRepo repos.PostalCentroidRepo
 }
 
-func NewPropertyService(r repos.PropertyRepo, u repos.UserRepo, pr repos.LocationPricingRepo) *PropertyService {
-	return &PropertyService{repo: r, userRepo: u, pricingRepo: pr}
+func NewPropertyService(r repos.PropertyRepo, u repos.UserRepo, pr repos.LocationPricingRepo, cr repos.PostalCentroidRepo) *PropertyService {
+	return &PropertyService{repo: r, userRepo: u, pricingRepo: pr, centroidRepo: cr}
 }
 
 func (s *PropertyService) CreateProperty(ctx context.Context, tenantID, currentUser string, p models.Property) (int64, error) {
@@ -25,6 +36,12 @@ func (s *PropertyService) CreateProperty(ctx context.Context, tenantID, currentU
 	if p.ListingDate.IsZero() {
 		p.ListingDate = time.Now().UTC()
 	}
+	if p.Status == "" {
+		p.Status = models.PropertyAvailable
+	}
+	if err := s.geocode(ctx, tenantID, &p); err != nil {
+		return 0, err
+	}
 	now := time.Now().UTC()
 	p.TenantID = tenantID
 	p.CreatedAt = now
@@ -51,11 +68,22 @@ func (s *PropertyService) UpdateProperty(ctx context.Context, tenantID, currentU
 	if p.ID == 0 {
 		return repos.ErrIDNotFound
 	}
+	existing, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if existing.Deleted {
+		return repos.ErrNotFound
+	}
+	if err := s.geocode(ctx, tenantID, &p); err != nil {
+		return err
+	}
+	p.AssignedTo = assignee(ctx, existing.AssignedTo, p.AssignedTo)
 	p.TenantID = tenantID
 	p.ID = id
 	p.ModifiedBy = currentUser
 	p.LastModified = time.Now().UTC()
-	err := s.repo.Update(ctx, &p)
+	err = s.repo.Update(ctx, &p)
 	if err == repos.ErrNotFound {
 		return repos.ErrNotFound
 	}
@@ -72,3 +100,128 @@ func (s *PropertyService) DeleteProperty(ctx context.Context, tenantID, currentU
 	p.LastModified = time.Now().UTC()
 	return s.repo.Update(ctx, p)
 }
+
+// geocode fills in the property's coordinates. Manually entered coordinates
+// win; otherwise the tenant's centroid for the property's ZIP is used. A
+// property whose ZIP has no centroid is saved without coordinates.
+func (s *PropertyService) geocode(ctx context.Context, tenantID string, p *models.Property) error {
+	if p.Latitude != nil || p.Longitude != nil {
+		if p.Latitude == nil || p.Longitude == nil {
+			return errors.New("latitude and longitude must be given together")
+		}
+		if err := validateCoordinates(*p.Latitude, *p.Longitude); err != nil {
+			return err
+		}
+		p.GeoSource = "manual"
+		if s.matchesCentroid(ctx, tenantID, p) {
+			p.GeoSource = "zip_centroid"
+		}
+		return nil
+	}
+	p.GeoSource = ""
+	c, err := s.centroidRepo.GetByPostalCode(ctx, tenantID, normalizePostalCode(p.ZIP))
+	if err == repos.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p.Latitude, p.Longitude = &c.Latitude, &c.Longitude
+	p.GeoSource = "zip_centroid"
+	return nil
+}
+
+// matchesCentroid reports whether p's coordinates are exactly its ZIP's
+// centroid, as when a client echoes back a previously geocoded record.
+func (s *PropertyService) matchesCentroid(ctx context.Context, tenantID string, p *models.Property) bool {
+	c, err := s.centroidRepo.GetByPostalCode(ctx, tenantID, normalizePostalCode(p.ZIP))
+	return err == nil && c.Latitude == *p.Latitude && c.Longitude == *p.Longitude
+}
+
+// SearchProperties returns geocoded properties within radiusKm of (lat, lng),
+// nearest first. Only available properties are returned unless status names
+// another status, or is "any".
+func (s *PropertyService) SearchProperties(ctx context.Context, tenantID string, lat, lng, radiusKm float64, status string) ([]models.PropertySearchResult, error) {
+	if err := validateCoordinates(lat, lng); err != nil {
+		return nil, err
+	}
+	if !(radiusKm > 0 && radiusKm <= maxSearchRadiusKm) {
+		return nil, fmt.Errorf("radius_km must be between 0 and %d", maxSearchRadiusKm)
+	}
+	switch status {
+	case "":
+		status = models.PropertyAvailable
+	case "any":
+		status = ""
+	case models.PropertyAvailable, models.PropertyUnderOffer, models.PropertySold:
+	default:
+		return nil, errors.New("unknown status: " + status)
+	}
+	return s.repo.SearchByRadius(ctx, tenantID, lat, lng, radiusKm, status)
+}
+
+// ImportPostalCentroids loads a CSV with the header
+// postal_code,latitude,longitude into the tenant's centroid table, replacing
+// existing entries for the same codes. Every row is validated before anything
+// is written. It returns the number of centroids stored.
+func (s *PropertyService) ImportPostalCentroids(ctx context.Context, tenantID string, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(records) < 2 {
+		return 0, errors.New("centroid file has no data rows")
+	}
+	cols := make(map[string]int)
+	for i, h := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, req := range []string{"postal_code", "latitude", "longitude"} {
+		if _, ok := cols[req]; !ok {
+			return 0, fmt.Errorf("missing required column %q", req)
+		}
+	}
+
+	out := make([]*models.PostalCentroid, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		code := normalizePostalCode(rec[cols["postal_code"]])
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(rec[cols["latitude"]]), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(rec[cols["longitude"]]), 64)
+		if code == "" || latErr != nil || lngErr != nil || validateCoordinates(lat, lng) != nil {
+			return 0, fmt.Errorf("row %d: invalid postal code or coordinates", i+2)
+		}
+		out = append(out, &models.PostalCentroid{TenantID: tenantID, PostalCode: code, Latitude: lat, Longitude: lng})
+	}
+	if err := s.centroidRepo.UpsertBatch(ctx, out); err != nil {
+		return 0, err
+	}
+	return len(out), nil
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return errors.New("latitude must be within ±90 and longitude within ±180")
+	}
+	return nil
+}
+
+// normalizePostalCode makes "sw1a 1aa" and "SW1A1AA" the same key.
+func normalizePostalCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// ConsumerPropertyStatus names the outbox consumer that applies property
+// status changes raised by the sales pipeline.
+const ConsumerPropertyStatus = "properties.status"
+
+// ApplyStatusEvent is the outbox handler for models.TopicPropertyStatusChanged.
+func (s *PropertyService) ApplyStatusEvent(ctx context.Context, ev *models.OutboxEvent) error {
+	var p models.PropertyStatusChanged
+	if err := decodeOutboxPayload(ev, &p); err != nil {
+		return err
+	}
+	_, err := s.repo.ApplyStatus(ctx, ConsumerPropertyStatus, ev.ID, ev.TenantID, p.PropertyID, p.Status, p.ModifiedBy)
+	return err
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+func newTestPropertyService(t *testing.T) (*PropertyService, repos.PropertyRepo) {
+	db := newTestDB(t)
+	repo := repos.NewDBPropertyRepo(db, "sqlite")
+	return NewPropertyService(repo, repos.NewDBUserRepo(db, "sqlite"), repos.NewDBLocationRepo(db, "sqlite"),
+		repos.NewDBPostalCentroidRepo(db, "sqlite")), repo
+}
+
+func TestSearchPropertiesDefaultsToAvailable(t *testing.T) {
+	svc, repo := newTestPropertyService(t)
+	ctx := context.Background()
+	lat, lng := 51.5, -0.12
+	for _, status := range []string{models.PropertyAvailable, models.PropertySold} {
+		if _, err := repo.Create(ctx, &models.Property{
+			TenantID: testTenant, Address: status + " house", City: "London", ZIP: "SW1A1AA", Status: status,
+			Latitude: &lat, Longitude: &lng, CreatedBy: "alice", ModifiedBy: "alice",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for status, want := range map[string]int{"": 1, models.PropertySold: 1, "any": 2} {
+		got, err := svc.SearchProperties(ctx, testTenant, lat, lng, 1, status)
+		if err != nil {
+			t.Fatalf("status %q: %v", status, err)
+		}
+		if len(got) != want {
+			t.Errorf("status %q: %d results, want %d", status, len(got), want)
+		}
+		if status == "" && len(got) == 1 && got[0].Status != models.PropertyAvailable {
+			t.Errorf("default search returned a %s property", got[0].Status)
+		}
+	}
+	if _, err := svc.SearchProperties(ctx, testTenant, lat, lng, 1, "demolished"); err == nil {
+		t.Error("unknown status accepted")
+	}
+}
+
+func TestSearchPropertiesRejectsNonFiniteInput(t *testing.T) {
+	svc, _ := newTestPropertyService(t)
+	nan, inf := math.NaN(), math.Inf(1)
+	for _, c := range []struct{ lat, lng, radius float64 }{
+		{nan, 0, 1},
+		{0, nan, 1},
+		{inf, 0, 1},
+		{0, -inf, 1},
+		{0, 0, nan},
+		{0, 0, inf},
+	} {
+		if _, err := svc.SearchProperties(context.Background(), testTenant, c.lat, c.lng, c.radius, ""); err == nil {
+			t.Errorf("lat %v, lng %v, radius %v accepted", c.lat, c.lng, c.radius)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// Record scoping permissions. Holders of PermAllRecords see every
+// property, buyer, plan, sale and commission in the tenant; holders of
+// PermTeamRecords those of everyone in their team; everyone else only the
+// records they created or are assigned to. Only holders of
+// PermAssignRecords may change who a record they can see is assigned to.
+const (
+	PermAllRecords    = "scope_all_records"
+	PermTeamRecords   = "scope_team_records"
+	PermAssignRecords = "assign_records"
+)
+
+// RecordAccessService works out the repos.AccessPolicy a request runs
+// under.
+type RecordAccessService struct {
+	userRepo     repos.UserRepo
+	permRepo     repos.PermissionRepo
+	rolePermRepo repos.RolePermissionRepo
+	authz        *AuthZService
+}
+
+func NewRecordAccessService(ur repos.UserRepo, pr repos.PermissionRepo, rpr repos.RolePermissionRepo, authz *AuthZService) *RecordAccessService {
+	return &RecordAccessService{userRepo: ur, permRepo: pr, rolePermRepo: rpr, authz: authz}
+}
+
+// Policy returns what userID may see. keyPerms, when not nil, are the
+// permissions of the API key the request is made with; a key only
+// widens its scope with the scope permissions it was given.
+func (s *RecordAccessService) Policy(ctx context.Context, tenantID string, userID int64, keyPerms []string) (*repos.AccessPolicy, error) {
+	perms, err := s.authz.Permissions(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	has := func(p string) bool {
+		return slices.Contains(perms, p) && (keyPerms == nil || slices.Contains(keyPerms, p))
+	}
+	p := &repos.AccessPolicy{UserID: userID, UserIDs: []int64{userID}, Assign: has(PermAssignRecords)}
+	switch {
+	case has(PermAllRecords):
+		p.All = true
+	case has(PermTeamRecords):
+		me, err := s.userRepo.GetByID(ctx, tenantID, userID)
+		if errors.Is(err, repos.ErrNotFound) {
+			return p, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if me.Team == "" {
+			return p, nil
+		}
+		users, err := s.userRepo.ListAll(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if u.Team == me.Team && u.ID != userID {
+				p.UserIDs = append(p.UserIDs, u.ID)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Bootstrap adds the scope permissions to the catalog. Those of
+// PermAllRecords and PermAssignRecords that are new are granted to every
+// role that can manage roles, so the admins of existing tenants keep seeing
+// and assigning all their records.
+func (s *RecordAccessService) Bootstrap(ctx context.Context) error {
+	ids, err := s.permissionIDs(ctx)
+	if err != nil {
+		return err
+	}
+	var added []string
+	for _, p := range []string{PermAllRecords, PermAssignRecords} {
+		if _, ok := ids[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	if err := s.authz.EnsurePermissions(ctx, PermAllRecords, PermTeamRecords, PermAssignRecords); err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if ids, err = s.permissionIDs(ctx); err != nil {
+		return err
+	}
+	manage, ok := ids[PermManageRoles]
+	if !ok {
+		return nil
+	}
+	grants, err := s.rolePermRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range grants {
+		if g.PermissionID != manage {
+			continue
+		}
+		for _, p := range added {
+			if err := s.authz.GrantPermission(ctx, g.RoleID, ids[p]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// assignee is the assigned user an update of a record may write: the
+// requested one if the caller may reassign records and named someone,
+// otherwise the record's current one. A context without a policy, as used
+// by background jobs, may reassign.
+func assignee(ctx context.Context, current, requested int64) int64 {
+	if requested == 0 {
+		return current
+	}
+	if p := repos.AccessPolicyFrom(ctx); p != nil && !p.Assign {
+		return current
+	}
+	return requested
+}
+
+func (s *RecordAccessService) permissionIDs(ctx context.Context) (map[string]int64, error) {
+	perms, err := s.permRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int64, len(perms))
+	for _, p := range perms {
+		ids[p.Name] = p.ID
+	}
+	return ids, nil
+}
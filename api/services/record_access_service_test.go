@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+func TestUpdateKeepsAssigneeWithoutAssignPermission(t *testing.T) {
+	db := newTestDB(t)
+	propRepo := repos.NewDBPropertyRepo(db, "sqlite")
+	salesRepo := repos.NewDBSalesRepo(db, "sqlite")
+	props := NewPropertyService(propRepo, repos.NewDBUserRepo(db, "sqlite"), repos.NewDBLocationRepo(db, "sqlite"),
+		repos.NewDBPostalCentroidRepo(db, "sqlite"))
+	kyc := NewKYCService(repos.NewDBKYCRepo(db, "sqlite"), repos.NewDBBuyerRepo(db, "sqlite"), repos.NewDBAttachmentRepo(db, "sqlite"))
+	sales := NewSalesService(salesRepo, kyc, nil, repos.NewTransactor(db))
+
+	agent := asUser(1)
+	lead := repos.WithAccessPolicy(context.Background(), &repos.AccessPolicy{UserID: 9, All: true, Assign: true})
+	prop := models.Property{TenantID: testTenant, Address: "1 High St", City: "London", ZIP: "N1", Status: models.PropertyAvailable,
+		AssignedTo: 1, CreatedBy: "alice", ModifiedBy: "alice"}
+	propID, err := propRepo.Create(agent, &prop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sale := models.Sales{TenantID: testTenant, PropertyID: propID, BuyerID: 1, SalePrice: 1000, SaleDate: time.Now(),
+		SaleType: models.SaleTypeInstallment, AssignedTo: 1, CreatedBy: "alice", ModifiedBy: "alice"}
+	saleID, err := salesRepo.Create(agent, &sale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		ctx      context.Context
+		assignTo int64
+		want     int64
+	}{
+		{"agent omits it", agent, 0, 1},
+		{"agent reassigns", agent, 2, 1},
+		{"lead omits it", lead, 0, 1},
+		{"lead reassigns", lead, 2, 2},
+	} {
+		prop.ID, prop.AssignedTo = propID, c.assignTo
+		if err := props.UpdateProperty(c.ctx, testTenant, "alice", propID, prop); err != nil {
+			t.Fatalf("%s: property: %v", c.name, err)
+		}
+		sale.AssignedTo = c.assignTo
+		if err := sales.UpdateSale(c.ctx, testTenant, "alice", saleID, sale); err != nil {
+			t.Fatalf("%s: sale: %v", c.name, err)
+		}
+		gotProp, err := propRepo.GetByID(context.Background(), testTenant, propID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotSale, err := salesRepo.GetByID(context.Background(), testTenant, saleID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotProp.AssignedTo != c.want || gotSale.AssignedTo != c.want {
+			t.Errorf("%s: property assigned to %d, sale to %d, want %d", c.name, gotProp.AssignedTo, gotSale.AssignedTo, c.want)
+		}
+	}
+}
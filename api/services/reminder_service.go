@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// Default reminder templates, used until a tenant saves its own.
+var defaultReminderTemplates = map[string]models.ReminderTemplate{
+	models.ReminderUpcoming: {
+		Kind:    models.ReminderUpcoming,
+		Subject: "Installment {{.InstallmentNumber}} due on {{.DueDate}}",
+		Body: `Dear {{.BuyerName}},
+
+This is a reminder that installment {{.InstallmentNumber}} of {{.NumInstallments}} on plan #{{.PlanID}} is due on {{.DueDate}}.
+
+Amount outstanding: {{.Outstanding}}
+
+If you have already paid, please ignore this message.
+`,
+	},
+	models.ReminderOverdue: {
+		Kind:    models.ReminderOverdue,
+		Subject: "Installment {{.InstallmentNumber}} is overdue",
+		Body: `Dear {{.BuyerName}},
+
+Installment {{.InstallmentNumber}} of {{.NumInstallments}} on plan #{{.PlanID}} was due on {{.DueDate}} and is now {{.DaysOverdue}} day(s) overdue.
+
+Amount outstanding: {{.Outstanding}}{{if ne .LateFee "0.00"}} plus a late fee of {{.LateFee}}{{end}}
+
+Please contact us to arrange payment.
+`,
+	},
+}
+
+// ReminderService notifies buyers before an installment falls due and again
+// once it is overdue, over whichever channels they haven't opted out of. Every (installment, kind) pair is claimed in the send
+// log before delivery, so each reminder goes out at most once.
+type ReminderService struct {
+	repo       repos.ReminderRepo
+	instRepo   repos.InstallmentRepo
+	planRepo   repos.InstallmentPlanRepo
+	buyerRepo  repos.BuyerRepo
+	notifier   *NotificationService
+	daysBefore int
+}
+
+// NewReminderService sends upcoming reminders daysBefore days ahead of the
+// due date.
+func NewReminderService(
+	r repos.ReminderRepo,
+	ir repos.InstallmentRepo,
+	pr repos.InstallmentPlanRepo,
+	br repos.BuyerRepo,
+	notifier *NotificationService,
+	daysBefore int,
+) *ReminderService {
+	if daysBefore <= 0 {
+		daysBefore = 3
+	}
+	return &ReminderService{repo: r, instRepo: ir, planRepo: pr, buyerRepo: br, notifier: notifier, daysBefore: daysBefore}
+}
+
+// ListTemplates returns the effective template for every kind: the
+// tenant's own where saved, otherwise the default (ID 0).
+func (s *ReminderService) ListTemplates(ctx context.Context, tenantID string) ([]models.ReminderTemplate, error) {
+	saved, err := s.repo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	byKind := map[string]models.ReminderTemplate{}
+	for _, t := range saved {
+		byKind[t.Kind] = *t
+	}
+	var out []models.ReminderTemplate
+	for _, kind := range []string{models.ReminderUpcoming, models.ReminderOverdue} {
+		t, ok := byKind[kind]
+		if !ok {
+			t = defaultReminderTemplates[kind]
+			t.TenantID = tenantID
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// SaveTemplate validates and stores a tenant's template for kind.
+func (s *ReminderService) SaveTemplate(ctx context.Context, tenantID, currentUser, kind string, t models.ReminderTemplate) error {
+	if _, ok := defaultReminderTemplates[kind]; !ok {
+		return fmt.Errorf("unknown reminder kind %q", kind)
+	}
+	if strings.TrimSpace(t.Subject) == "" || strings.TrimSpace(t.Body) == "" {
+		return errors.New("subject and body are required")
+	}
+	t.TenantID = tenantID
+	t.Kind = kind
+	t.ModifiedBy = currentUser
+	// Render against sample data so typos in placeholders fail here rather
+	// than at send time.
+	if _, _, err := renderReminder(t, models.ReminderData{}); err != nil {
+		return err
+	}
+	if existing, err := s.repo.GetTemplate(ctx, tenantID, kind); err == nil {
+		t.CreatedBy = existing.CreatedBy
+		t.CreatedAt = existing.CreatedAt
+	} else if err != repos.ErrNotFound {
+		return err
+	}
+	return s.repo.SaveTemplate(ctx, &t)
+}
+
+func (s *ReminderService) ListSends(ctx context.Context, tenantID string, installmentID int64) ([]*models.ReminderSend, error) {
+	return s.repo.ListSends(ctx, tenantID, installmentID)
+}
+
+// Run sends every reminder that is due as of now across all tenants.
+func (s *ReminderService) Run(ctx context.Context, now time.Time) (models.ReminderRunResult, error) {
+	var res models.ReminderRunResult
+	if len(s.notifier.notifiers) == 0 {
+		return res, ErrNoNotifiers
+	}
+	today := truncateDay(now.UTC())
+	insts, err := s.instRepo.ListUnpaidDueBefore(ctx, today.AddDate(0, 0, s.daysBefore+1))
+	if err != nil {
+		return res, err
+	}
+	for _, inst := range insts {
+		sent, err := s.remind(ctx, inst, today)
+		switch {
+		case err != nil:
+			res.Failed++
+			res.Errors = append(res.Errors, fmt.Sprintf("installment %d: %v", inst.ID, err))
+		case sent:
+			res.Sent++
+		default:
+			res.Skipped++
+		}
+	}
+	return res, nil
+}
+
+// Start runs reminders every interval until ctx is cancelled.
+func (s *ReminderService) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		res, err := s.Run(ctx, time.Now())
+		if err != nil {
+			log.Printf("reminders: %v", err)
+		} else if res.Sent > 0 || res.Failed > 0 {
+			log.Printf("reminders: sent %d, failed %d", res.Sent, res.Failed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *ReminderService) remind(ctx context.Context, inst *models.Installment, today time.Time) (bool, error) {
+	due := truncateDay(inst.DueDate.UTC())
+	kind := models.ReminderUpcoming
+	if due.Before(today) {
+		kind = models.ReminderOverdue
+	}
+	plan, err := s.planRepo.GetByID(ctx, inst.TenantID, inst.PlanID)
+	if err != nil {
+		return false, err
+	}
+	buyer, err := s.buyerRepo.GetByID(ctx, inst.TenantID, plan.BuyerID)
+	if err != nil {
+		return false, err
+	}
+	t, err := s.repo.GetTemplate(ctx, inst.TenantID, kind)
+	if err == repos.ErrNotFound {
+		def := defaultReminderTemplates[kind]
+		t = &def
+	} else if err != nil {
+		return false, err
+	}
+	days := int(due.Sub(today).Hours() / 24)
+	data := models.ReminderData{
+		BuyerName:         strings.TrimSpace(buyer.FirstName + " " + buyer.LastName),
+		PlanID:            plan.ID,
+		PropertyID:        plan.PropertyID,
+		InstallmentNumber: inst.SequenceNumber,
+		NumInstallments:   plan.NumInstallments,
+		DueDate:           inst.DueDate.Format("2006-01-02"),
+		AmountDue:         fmt.Sprintf("%.2f", inst.AmountDue),
+		AmountPaid:        fmt.Sprintf("%.2f", inst.AmountPaid),
+		Outstanding:       fmt.Sprintf("%.2f", inst.AmountDue-inst.AmountPaid),
+		LateFee:           fmt.Sprintf("%.2f", inst.LateFee),
+		DaysUntilDue:      max(days, 0),
+		DaysOverdue:       max(-days, 0),
+	}
+	subject, body, err := renderReminder(*t, data)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := s.repo.Claim(ctx, &models.ReminderSend{
+		TenantID:      inst.TenantID,
+		InstallmentID: inst.ID,
+		Kind:          kind,
+		Recipient:     fmt.Sprintf("buyer #%d", buyer.ID),
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+	// A partial delivery counts as sent; retrying would repeat it on the
+	// channels that worked.
+	delivered, err := s.notifier.Notify(ctx, inst.TenantID, models.NotifySubjectBuyer, buyer.ID, subject, body)
+	if delivered == 0 {
+		if rerr := s.repo.Release(ctx, inst.TenantID, inst.ID, kind); rerr != nil {
+			log.Printf("reminders: release claim for installment %d: %v", inst.ID, rerr)
+		}
+		return false, err
+	}
+	if err != nil {
+		log.Printf("reminders: installment %d: %v", inst.ID, err)
+	}
+	return true, nil
+}
+
+func renderReminder(t models.ReminderTemplate, data models.ReminderData) (string, string, error) {
+	var out [2]bytes.Buffer
+	for i, src := range []string{t.Subject, t.Body} {
+		tmpl, err := template.New(t.Kind).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return "", "", fmt.Errorf("template: %w", err)
+		}
+		if err := tmpl.Execute(&out[i], data); err != nil {
+			return "", "", fmt.Errorf("template: %w", err)
+		}
+	}
+	// Headers can't span lines.
+	subject := strings.Join(strings.Fields(out[0].String()), " ")
+	return subject, out[1].String(), nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
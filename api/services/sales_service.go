@@ -10,11 +10,14 @@ import (
 )
 
 type SalesService struct {
-	repo repos.SalesRepo
+	repo   repos.SalesRepo
+	kyc    *KYCService
+	events EventPublisher
+	tx     repos.Transactor
 }
 
-func NewSalesService(r repos.SalesRepo) *SalesService {
-	return &SalesService{repo: r}
+func NewSalesService(r repos.SalesRepo, kyc *KYCService, events EventPublisher, tx repos.Transactor) *SalesService {
+	return &SalesService{repo: r, kyc: kyc, events: orNop(events), tx: tx}
 }
 
 func (s *SalesService) CreateSale(
@@ -27,6 +30,10 @@ func (s *SalesService) CreateSale(
 	if sale.PropertyID == 0 || sale.BuyerID == 0 || sale.SalePrice <= 0 || sale.SaleType == "" {
 		return 0, errors.New("property, buyer, sale price, and sale type must be specified")
 	}
+	useOverride, err := s.kyc.CheckBuyer(ctx, tenantID, currentUser, sale.BuyerID, models.KYCActionCreateSale)
+	if err != nil {
+		return 0, err
+	}
 	now := time.Now().UTC()
 	sale.TenantID = tenantID
 	sale.CreatedAt = now
@@ -35,7 +42,20 @@ func (s *SalesService) CreateSale(
 	sale.ModifiedBy = currentUser
 	sale.Deleted = false
 
-	return s.repo.Create(ctx, &sale)
+	var id int64
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.Create(ctx, &sale); err != nil {
+			return err
+		}
+		return useOverride(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	sale.ID = id
+	s.events.Publish(ctx, tenantID, models.EventSaleCompleted, sale)
+	return id, nil
 }
 
 func (s *SalesService) ListSales(
@@ -74,6 +94,7 @@ func (s *SalesService) UpdateSale(
 	sale.CreatedAt = existing.CreatedAt
 	sale.CreatedBy = existing.CreatedBy
 	sale.Deleted = existing.Deleted
+	sale.AssignedTo = assignee(ctx, existing.AssignedTo, sale.AssignedTo)
 
 	now := time.Now().UTC()
 	sale.ModifiedBy = currentUser
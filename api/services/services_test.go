@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/migrate"
+)
+
+const testTenant = "acme"
+
+// newTestDB returns an in-memory sqlite database with every migration
+// applied, as used in single-database mode.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	for _, dir := range []string{"../../migrations/sqlite", "../../migrations/single/sqlite"} {
+		if err := migrate.MigrateSQL(db, dir); err != nil {
+			t.Fatalf("%s: %v", dir, err)
+		}
+	}
+	return db
+}
+
+// asUser returns a context scoped to the records of userID alone.
+func asUser(userID int64) context.Context {
+	return repos.WithAccessPolicy(context.Background(), &repos.AccessPolicy{UserID: userID, UserIDs: []int64{userID}})
+}
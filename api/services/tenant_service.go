@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// PermManageTenants guards tenant provisioning and suspension. It is only
+// honoured for users of the platform tenant and is never granted to the
+// roles seeded for other tenants.
+const PermManageTenants = "manage_tenants"
+
+// tenantStatusTTL bounds how long a tenant's status is reused before it is
+// read again; changes made through TenantService apply at once.
+const tenantStatusTTL = 30 * time.Second
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// defaultRoles are created in every new tenant. Each grants the catalog
+// permissions its filter accepts.
+var defaultRoles = []struct {
+	name, description string
+	grants            func(perm string) bool
+}{
+	{"admin", "Full access within the tenant", func(p string) bool {
+		return p != PermManageTenants
+	}},
+	{"agent", "Day-to-day sales, lettings and buyer work", agentGrants},
+	{"team_lead", "Agent work plus the records of the lead's team", func(p string) bool {
+		return p == PermTeamRecords || p == PermAssignRecords || agentGrants(p)
+	}},
+	{"viewer", "Read-only access", func(p string) bool {
+		return strings.HasPrefix(p, "view_")
+	}},
+}
+
+// agentGrants is the agent role's filter: viewing, creating and updating
+// everything but users, pricing, commissions and reports.
+func agentGrants(p string) bool {
+	for _, exclude := range []string{"_user", "_pricing", "_commission", "_report"} {
+		if strings.HasSuffix(p, exclude) {
+			return false
+		}
+	}
+	return strings.HasPrefix(p, "view_") || strings.HasPrefix(p, "create_") || strings.HasPrefix(p, "update_")
+}
+
+// TenantService provisions tenants and tracks whether they are active.
+type TenantService struct {
+	repo      repos.TenantRepo
+	userRepo  repos.UserRepo
+	roleRepo  repos.RoleRepo
+	permRepo  repos.PermissionRepo
+	access    *AccessService
+	authz     *AuthZService
+	passwords *PasswordService
+
+	mu     sync.Mutex
+	status map[string]tenantStatus
+}
+
+type tenantStatus struct {
+	status  string
+	expires time.Time
+}
+
+func NewTenantService(
+	r repos.TenantRepo,
+	ur repos.UserRepo,
+	rr repos.RoleRepo,
+	pr repos.PermissionRepo,
+	access *AccessService,
+	authz *AuthZService,
+	passwords *PasswordService,
+) *TenantService {
+	return &TenantService{
+		repo:      r,
+		userRepo:  ur,
+		roleRepo:  rr,
+		permRepo:  pr,
+		access:    access,
+		authz:     authz,
+		passwords: passwords,
+		status:    map[string]tenantStatus{},
+	}
+}
+
+func (s *TenantService) List(ctx context.Context) ([]*models.Tenant, error) {
+	list, err := s.repo.ListAll(ctx)
+	if list == nil && err == nil {
+		list = []*models.Tenant{}
+	}
+	return list, err
+}
+
+func (s *TenantService) Get(ctx context.Context, id string) (*models.Tenant, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// CheckActive returns ErrNotFound for an unknown or half-provisioned
+// tenant and ErrTenantSuspended for a suspended one.
+func (s *TenantService) CheckActive(ctx context.Context, id string) error {
+	s.mu.Lock()
+	e, ok := s.status[id]
+	s.mu.Unlock()
+	if !ok || !time.Now().Before(e.expires) {
+		t, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		e = tenantStatus{status: t.Status, expires: time.Now().Add(tenantStatusTTL)}
+		s.mu.Lock()
+		s.status[id] = e
+		s.mu.Unlock()
+	}
+	switch e.status {
+	case models.TenantActive:
+		return nil
+	case models.TenantSuspended:
+		return repos.ErrTenantSuspended
+	default:
+		return repos.ErrNotFound
+	}
+}
+
+// Provision creates a tenant with the default roles and its first admin
+// user. The steps span several databases, so a tenant left in
+// "provisioning" by a failure can be provisioned again with the same
+// request to finish the job.
+func (s *TenantService) Provision(ctx context.Context, currentUser string, req models.TenantProvisionRequest) (*models.Tenant, error) {
+	return s.provision(ctx, currentUser, req, false)
+}
+
+func (s *TenantService) provision(ctx context.Context, currentUser string, req models.TenantProvisionRequest, platform bool) (*models.Tenant, error) {
+	req.ID = strings.ToLower(strings.TrimSpace(req.ID))
+	req.Name = strings.TrimSpace(req.Name)
+	if !tenantIDPattern.MatchString(req.ID) {
+		return nil, repos.ErrInvalidTenantID
+	}
+	if req.Name == "" {
+		req.Name = req.ID
+	}
+	a := req.Admin
+	if a.UserName == "" || a.FirstName == "" || a.LastName == "" {
+		return nil, repos.ErrInvalidRegistration
+	}
+	if err := s.passwords.Check(ctx, req.ID, 0, a.UserName, a.Password); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	t := &models.Tenant{
+		ID:           req.ID,
+		Name:         req.Name,
+		Status:       models.TenantProvisioning,
+		CreatedBy:    currentUser,
+		CreatedAt:    now,
+		ModifiedBy:   currentUser,
+		LastModified: now,
+	}
+	if err := s.repo.Create(ctx, t); errors.Is(err, repos.ErrTenantExists) {
+		existing, gerr := s.repo.GetByID(ctx, req.ID)
+		if gerr != nil {
+			return nil, gerr
+		}
+		if existing.Status != models.TenantProvisioning {
+			return nil, repos.ErrTenantExists
+		}
+		t = existing
+	} else if err != nil {
+		return nil, err
+	}
+
+	roleIDs, err := s.seedRoles(ctx, t.ID, currentUser, platform)
+	if err != nil {
+		return nil, fmt.Errorf("seeding roles: %w", err)
+	}
+	admin, err := s.userRepo.GetByUsername(ctx, t.ID, a.UserName)
+	if errors.Is(err, repos.ErrNotFound) {
+		hash, herr := s.passwords.Hash(ctx, t.ID, 0, a.UserName, a.Password)
+		if herr != nil {
+			return nil, herr
+		}
+		admin = &models.User{
+			TenantID:     t.ID,
+			UserName:     a.UserName,
+			PasswordHash: hash,
+			FirstName:    a.FirstName,
+			LastName:     a.LastName,
+			Role:         "admin",
+			Email:        a.Email,
+			CreatedBy:    currentUser,
+			CreatedAt:    now,
+			ModifiedBy:   currentUser,
+			LastModified: now,
+		}
+		if admin.ID, err = s.userRepo.Create(ctx, admin); err != nil {
+			return nil, fmt.Errorf("creating admin user: %w", err)
+		}
+		if err := s.passwords.Remember(ctx, t.ID, admin.ID, hash); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.access.AssignRole(ctx, t.ID, currentUser, admin.ID, roleIDs["admin"]); err != nil {
+		return nil, fmt.Errorf("assigning admin role: %w", err)
+	}
+
+	t.Status = models.TenantActive
+	t.LastModified = time.Now().UTC()
+	if err := s.save(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// seedRoles creates any default role the tenant lacks, granting it the
+// permissions its filter accepts, and returns the role IDs by name. In the
+// platform tenant the admin role also gets PermManageTenants.
+func (s *TenantService) seedRoles(ctx context.Context, tenantID, currentUser string, platform bool) (map[string]int64, error) {
+	if platform {
+		if err := s.authz.EnsurePermissions(ctx, PermManageTenants); err != nil {
+			return nil, err
+		}
+	}
+	perms, err := s.permRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.roleRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]int64{}
+	for _, r := range existing {
+		ids[r.Name] = r.ID
+	}
+	for _, d := range defaultRoles {
+		if _, ok := ids[d.name]; ok {
+			continue
+		}
+		var grant []int64
+		for _, p := range perms {
+			if d.grants(p.Name) || (platform && d.name == "admin" && p.Name == PermManageTenants) {
+				grant = append(grant, p.ID)
+			}
+		}
+		id, err := s.access.SeedRole(ctx, tenantID, currentUser, models.Role{Name: d.name, Description: d.description}, grant)
+		if err != nil {
+			return nil, err
+		}
+		ids[d.name] = id
+	}
+	return ids, nil
+}
+
+// Suspend stops the tenant's users from logging in or using their tokens.
+// Administrators cannot suspend their own tenant.
+func (s *TenantService) Suspend(ctx context.Context, currentTenant, currentUser, id, reason string) (*models.Tenant, error) {
+	if id == currentTenant {
+		return nil, repos.ErrSuspendOwnTenant
+	}
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	t.Status = models.TenantSuspended
+	t.SuspendedAt = &now
+	t.SuspendedReason = strings.TrimSpace(reason)
+	t.ModifiedBy = currentUser
+	t.LastModified = now
+	return t, s.save(ctx, t)
+}
+
+// Reactivate lifts a suspension.
+func (s *TenantService) Reactivate(ctx context.Context, currentUser, id string) (*models.Tenant, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.TenantSuspended {
+		return t, nil
+	}
+	t.Status = models.TenantActive
+	t.SuspendedAt = nil
+	t.SuspendedReason = ""
+	t.ModifiedBy = currentUser
+	t.LastModified = time.Now().UTC()
+	return t, s.save(ctx, t)
+}
+
+func (s *TenantService) save(ctx context.Context, t *models.Tenant) error {
+	if err := s.repo.Update(ctx, t); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.status, t.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+// Bootstrap provisions the platform tenant, whose admin may manage the
+// other tenants, on first start. Without a password it only logs how to
+// do so.
+func (s *TenantService) Bootstrap(ctx context.Context, platformID, adminUser, adminPassword string) error {
+	if t, err := s.repo.GetByID(ctx, platformID); err == nil && t.Status != models.TenantProvisioning {
+		return nil
+	} else if err != nil && !errors.Is(err, repos.ErrNotFound) {
+		return err
+	}
+	if adminPassword == "" {
+		log.Printf("tenants: platform tenant %q does not exist; set PLATFORM_ADMIN_PASSWORD to create it", platformID)
+		return nil
+	}
+	req := models.TenantProvisionRequest{ID: platformID, Name: "Platform"}
+	req.Admin.UserName = adminUser
+	req.Admin.FirstName = "Platform"
+	req.Admin.LastName = "Administrator"
+	req.Admin.Password = adminPassword
+	if _, err := s.provision(ctx, "system", req, true); err != nil {
+		return err
+	}
+	log.Printf("tenants: created platform tenant %q with admin %q", platformID, adminUser)
+	return nil
+}
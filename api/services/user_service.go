@@ -10,17 +10,25 @@ import (
 )
 
 type UserService struct {
-	repo repos.UserRepo
+	repo     repos.UserRepo
+	licenses *LicenseService
+	sessions repos.SessionRepo
+	access   *AccessService
+	tx       repos.Transactor // over the users database
 }
 
-func NewUserService(r repos.UserRepo) *UserService {
-	return &UserService{repo: r}
+func NewUserService(r repos.UserRepo, licenses *LicenseService, sessions repos.SessionRepo, access *AccessService, tx repos.Transactor) *UserService {
+	return &UserService{repo: r, licenses: licenses, sessions: sessions, access: access, tx: tx}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, tenantID, currentUser string, b models.User) (int64, error) {
 	if b.FirstName == "" || b.LastName == "" || b.Email == "" {
 		return 0, repos.ErrNameEmailNotFound
 	}
+	seats, err := s.licenses.Seats(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
 	now := time.Now().UTC()
 	b.TenantID = tenantID
 	b.CreatedAt = now
@@ -28,7 +36,7 @@ func (s *UserService) CreateUser(ctx context.Context, tenantID, currentUser stri
 	b.CreatedBy = currentUser
 	b.ModifiedBy = currentUser
 	b.Deleted = false
-	return s.repo.Create(ctx, &b)
+	return s.repo.CreateWithinSeats(ctx, &b, seats)
 }
 
 func (s *UserService) ListUsers(ctx context.Context, tenantID string) ([]models.User, error) {
@@ -57,11 +65,16 @@ func (s *UserService) UpdateUser(ctx context.Context, tenantID, currentUser stri
 	now := time.Now().UTC()
 	b.TenantID = tenantID
 	b.ID = id
+	// Passwords change only through the password endpoints.
+	b.PasswordHash = existing.PasswordHash
 	b.ModifiedBy = currentUser
 	b.LastModified = now
 	return s.repo.Update(ctx, &b)
 }
 
+// DeleteUser marks the user deleted and revokes their sessions in the
+// same transaction, so tokens already issued stop working, then removes
+// their roles.
 func (s *UserService) DeleteUser(ctx context.Context, tenantID, currentUser string, id int64) error {
 	existing, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
@@ -70,8 +83,18 @@ func (s *UserService) DeleteUser(ctx context.Context, tenantID, currentUser stri
 	if existing.Deleted {
 		return repos.ErrNotFound
 	}
-	existing.Deleted = true
-	existing.ModifiedBy = currentUser
-	existing.LastModified = time.Now().UTC()
-	return s.repo.Update(ctx, existing)
+	now := time.Now().UTC()
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		existing.Deleted = true
+		existing.ModifiedBy = currentUser
+		existing.LastModified = now
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		return s.sessions.RevokeUser(ctx, tenantID, id, now, currentUser)
+	})
+	if err != nil {
+		return err
+	}
+	return s.access.RemoveUserRoles(ctx, tenantID, currentUser, id)
 }
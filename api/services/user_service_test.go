@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+)
+
+// A deleted user's tokens and roles stop working at once.
+func TestDeleteUserRevokesAccess(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	userRepo := repos.NewDBUserRepo(db, "sqlite")
+	roleRepo := repos.NewDBRoleRepo(db, "sqlite")
+	permRepo := repos.NewDBPermissionRepo(db, "sqlite")
+	rolePermRepo := repos.NewDBRolePermissionRepo(db, "sqlite")
+	userRoleRepo := repos.NewDBUserRoleRepo(db, "sqlite")
+	sessions := repos.NewDBSessionRepo(db, "sqlite")
+	authz := NewAuthZService(permRepo, rolePermRepo, userRoleRepo)
+	access := NewAccessService(roleRepo, permRepo, rolePermRepo, userRoleRepo, userRepo, repos.NewDBAccessAuditRepo(db, "sqlite"), authz, repos.NewTransactor(db))
+	tenants := NewTenantService(repos.NewDBTenantRepo(db, "sqlite"), userRepo, roleRepo, permRepo, access, authz, nil)
+	licenses := NewLicenseService(repos.NewDBLicenseRepo(db, "sqlite"), userRepo, nil, 0, "")
+	auth := NewAuthService(userRepo, authz, sessions, nil, nil, tenants, licenses, nil, "secret", time.Minute)
+	users := NewUserService(userRepo, licenses, sessions, access, repos.NewTransactor(db))
+
+	if err := repos.NewDBTenantRepo(db, "sqlite").Create(ctx, &models.Tenant{
+		ID: testTenant, Name: "Acme", Status: models.TenantActive,
+		CreatedBy: "test", CreatedAt: now, ModifiedBy: "test", LastModified: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	userID, err := users.CreateUser(ctx, testTenant, "admin", models.User{
+		UserName: "bob", PasswordHash: "x", FirstName: "Bob", LastName: "Jones", Email: "bob@example.com", Role: "agent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleID, err := roleRepo.Create(ctx, &models.Role{TenantID: testTenant, Name: "agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := access.SetUserRoles(ctx, testTenant, "admin", 0, userID, []int64{roleID}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := userRepo.GetByID(ctx, testTenant, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := auth.StartSession(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(ctx, pair.AccessToken); err != nil {
+		t.Fatalf("token before the delete: %v", err)
+	}
+
+	if err := users.DeleteUser(ctx, testTenant, "admin", userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(ctx, pair.AccessToken); !errors.Is(err, repos.ErrTokenRevoked) {
+		t.Errorf("access token after the delete: err = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := auth.Refresh(ctx, pair.RefreshToken); !errors.Is(err, repos.ErrInvalidRefreshToken) {
+		t.Errorf("refresh after the delete: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if roles, err := userRoleRepo.ListRoles(ctx, userID); err != nil || len(roles) != 0 {
+		t.Errorf("roles after the delete = %v (err %v), want none", roles, err)
+	}
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+	"github.com/newssourcecrawler/realtorinstall/api/repos"
+	"github.com/newssourcecrawler/realtorinstall/internal/notify"
+)
+
+// EventPublisher receives domain events from the services. Publishing is
+// best effort: failures are logged and never fail the operation that
+// raised the event.
+type EventPublisher interface {
+	Publish(ctx context.Context, tenantID, eventType string, data any)
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, string, string, any) {}
+
+// orNop lets services be built without a publisher.
+func orNop(p EventPublisher) EventPublisher {
+	if p == nil {
+		return nopPublisher{}
+	}
+	return p
+}
+
+// Webhook delivery schedule: retries back off from 30s to at most an hour,
+// and a delivery is dead-lettered after MaxWebhookAttempts failures.
+const MaxWebhookAttempts = 8
+
+var webhookRetry = notify.RetryPolicy{MaxAttempts: MaxWebhookAttempts, Initial: 30 * time.Second, Max: time.Hour}
+
+// WebhookService manages tenants' webhook subscriptions and delivers
+// domain events to them. Publish only queues deliveries; Start runs the
+// dispatcher that sends them.
+type WebhookService struct {
+	repo          repos.WebhookRepo
+	client        *http.Client
+	allowInsecure bool
+	wake          chan struct{}
+}
+
+// NewWebhookService builds the service. Unless allowInsecure is set, for
+// local development, subscription URLs must use https and may not name
+// an internal address. client defaults to NewWebhookClient(allowInsecure).
+func NewWebhookService(r repos.WebhookRepo, client *http.Client, allowInsecure bool) *WebhookService {
+	if client == nil {
+		client = NewWebhookClient(allowInsecure)
+	}
+	return &WebhookService{repo: r, client: client, allowInsecure: allowInsecure, wake: make(chan struct{}, 1)}
+}
+
+// NewWebhookClient returns the client deliveries are sent with. Unless
+// allowInsecure is set it refuses to connect to internal addresses,
+// checked once the host name has been resolved, so DNS cannot point a
+// subscription at them.
+func NewWebhookClient(allowInsecure bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowInsecure {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+				return fmt.Errorf("webhooks: refusing to connect to internal address %s", host)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: 15 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// internalIP reports whether ip is loopback, private, link-local or
+// otherwise not a public unicast address.
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip)
+}
+
+// cgnat is the shared address space of RFC 6598.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Publish queues eventType for every active subscription of the tenant
+// that wants it.
+func (s *WebhookService) Publish(ctx context.Context, tenantID, eventType string, data any) {
+	if err := s.publish(ctx, tenantID, eventType, data); err != nil {
+		log.Printf("webhooks: publish %s for tenant %s: %v", eventType, tenantID, err)
+	}
+}
+
+func (s *WebhookService) publish(ctx context.Context, tenantID, eventType string, data any) error {
+	subs, err := s.repo.ListSubscriptions(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	var targets []*models.WebhookSubscription
+	for _, sub := range subs {
+		if sub.Active && sub.Wants(eventType) {
+			targets = append(targets, sub)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	ev := models.DomainEvent{
+		ID:         "evt_" + randomHex(12),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: now,
+		Data:       data,
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	for _, sub := range targets {
+		if _, err := s.repo.CreateDelivery(ctx, &models.WebhookDelivery{
+			TenantID:       tenantID,
+			SubscriptionID: sub.ID,
+			EventID:        ev.ID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  now,
+		}); err != nil {
+			return err
+		}
+	}
+	s.poke()
+	return nil
+}
+
+func (s *WebhookService) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start delivers queued events until ctx is cancelled, polling every
+// interval for retries that have come due.
+func (s *WebhookService) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// DeliverDue makes one attempt at every delivery whose time has come.
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	for {
+		due, err := s.repo.ListDue(ctx, time.Now(), 50)
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		for _, d := range due {
+			if err := s.attempt(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// attempt sends d once and records the outcome.
+func (s *WebhookService) attempt(ctx context.Context, d *models.WebhookDelivery) error {
+	now := time.Now().UTC()
+	d.Attempts++
+	sub, err := s.repo.GetSubscription(ctx, d.TenantID, d.SubscriptionID)
+	switch {
+	case err == repos.ErrNotFound || (err == nil && !sub.Active):
+		d.Status = models.DeliveryDead
+		d.LastError = "subscription deleted or disabled"
+		return s.repo.UpdateDelivery(ctx, d)
+	case err != nil:
+		return err
+	}
+
+	code, err := s.post(ctx, sub, d)
+	d.LastStatusCode = code
+	if err == nil {
+		d.Status = models.DeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	} else {
+		d.LastError = err.Error()
+		if d.Attempts >= MaxWebhookAttempts {
+			d.Status = models.DeliveryDead
+		} else {
+			d.NextAttemptAt = now.Add(webhookRetry.Backoff(d.Attempts))
+		}
+	}
+	return s.repo.UpdateDelivery(ctx, d)
+}
+
+// post sends the payload signed as described for notify.WebhookNotifier,
+// plus X-Event-ID and X-Event-Type headers.
+func (s *WebhookService) post(ctx context.Context, sub *models.WebhookSubscription, d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", d.EventID)
+	req.Header.Set("X-Event-Type", d.EventType)
+	req.Header.Set("X-Webhook-Timestamp", ts)
+	req.Header.Set("X-Webhook-Signature", notify.Sign(sub.Secret, ts, d.Payload))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// CreateSubscription registers a webhook and returns its ID and signing
+// secret. The secret is only returned here.
+func (s *WebhookService) CreateSubscription(ctx context.Context, tenantID, currentUser string, sub models.WebhookSubscription) (int64, string, error) {
+	if err := s.validateSubscription(&sub); err != nil {
+		return 0, "", err
+	}
+	sub.TenantID = tenantID
+	sub.Secret = "whsec_" + randomHex(24)
+	sub.Active = true
+	sub.CreatedBy = currentUser
+	sub.ModifiedBy = currentUser
+	id, err := s.repo.CreateSubscription(ctx, &sub)
+	if err != nil {
+		return 0, "", err
+	}
+	return id, sub.Secret, nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx, tenantID)
+}
+
+// UpdateSubscription changes the URL, event filter and active flag.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, tenantID, currentUser string, id int64, sub models.WebhookSubscription) error {
+	existing, err := s.repo.GetSubscription(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.validateSubscription(&sub); err != nil {
+		return err
+	}
+	existing.URL = sub.URL
+	existing.Events = sub.Events
+	existing.Active = sub.Active
+	existing.ModifiedBy = currentUser
+	return s.repo.UpdateSubscription(ctx, existing)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, tenantID, currentUser string, id int64) error {
+	existing, err := s.repo.GetSubscription(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	existing.Deleted = true
+	existing.Active = false
+	existing.ModifiedBy = currentUser
+	return s.repo.UpdateSubscription(ctx, existing)
+}
+
+// ListDeliveries lists recent deliveries; status "dead" gives the
+// dead-letter log.
+func (s *WebhookService) ListDeliveries(ctx context.Context, tenantID, status string, subscriptionID int64, limit int) ([]*models.WebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListDeliveries(ctx, tenantID, status, subscriptionID, limit)
+}
+
+// Replay requeues a delivery, whatever its status, with a fresh set of
+// retries. The event ID is unchanged so receivers can deduplicate.
+func (s *WebhookService) Replay(ctx context.Context, tenantID string, id int64) error {
+	d, err := s.repo.GetDelivery(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.GetSubscription(ctx, tenantID, d.SubscriptionID); err != nil {
+		return err
+	}
+	d.Status = models.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now().UTC()
+	d.DeliveredAt = nil
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		return err
+	}
+	s.poke()
+	return nil
+}
+
+func (s *WebhookService) validateSubscription(sub *models.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	if !s.allowInsecure {
+		if u.Scheme != "https" {
+			return errors.New("url must use https")
+		}
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		if ip := net.ParseIP(host); (ip != nil && internalIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return errors.New("url must not point at an internal address")
+		}
+	}
+	for _, e := range sub.Events {
+		if !slices.Contains(models.EventTypes, e) {
+			return fmt.Errorf("unknown event type %q", e)
+		}
+	}
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+func TestWebhookSubscriptionURL(t *testing.T) {
+	strict := NewWebhookService(nil, nil, false)
+	dev := NewWebhookService(nil, nil, true)
+	for url, ok := range map[string]bool{
+		"https://hooks.example.com/realtor": true,
+		"https://203.0.113.10/hook":         true,
+		"http://hooks.example.com/realtor":  false,
+		"https://localhost/hook":            false,
+		"https://api.localhost./hook":       false,
+		"https://127.0.0.1/hook":            false,
+		"https://10.1.2.3/hook":             false,
+		"https://192.168.0.1/hook":          false,
+		"https://169.254.169.254/latest":    false,
+		"https://100.64.0.1/hook":           false,
+		"https://[::1]/hook":                false,
+		"https://[fe80::1]/hook":            false,
+		"https://[::ffff:10.0.0.1]/hook":    false,
+		"https://0.0.0.0/hook":              false,
+	} {
+		err := strict.validateSubscription(&models.WebhookSubscription{URL: url})
+		if (err == nil) != ok {
+			t.Errorf("%s: err = %v, want ok = %v", url, err, ok)
+		}
+		if err := dev.validateSubscription(&models.WebhookSubscription{URL: url}); err != nil {
+			t.Errorf("%s with insecure URLs allowed: %v", url, err)
+		}
+	}
+}
+
+// The delivery client refuses internal addresses once resolved.
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if resp, err := NewWebhookClient(false).Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("connected to a loopback server")
+	}
+	resp, err := NewWebhookClient(true).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("insecure client: %v", err)
+	}
+	resp.Body.Close()
+}
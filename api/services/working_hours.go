@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/api/models"
+)
+
+// WorkingHours is the weekly window appointments may be booked in.
+type WorkingHours struct {
+	Days     [7]bool       // indexed by time.Weekday
+	Start    time.Duration // offset from local midnight
+	End      time.Duration
+	Location *time.Location
+}
+
+// ParseWorkingHours builds WorkingHours from config strings: start and end
+// as "HH:MM", days as a comma-separated list ("mon,tue,wed" or "mon-fri")
+// and tz as an IANA zone name. Empty values default to 09:00–17:30,
+// Monday to Friday, server local time.
+func ParseWorkingHours(start, end, days, tz string) (WorkingHours, error) {
+	var w WorkingHours
+	var err error
+	if w.Start, err = parseClock(start, "09:00"); err != nil {
+		return w, err
+	}
+	if w.End, err = parseClock(end, "17:30"); err != nil {
+		return w, err
+	}
+	if w.End <= w.Start {
+		return w, fmt.Errorf("working hours end %q must be after start %q", end, start)
+	}
+	if tz == "" {
+		w.Location = time.Local
+	} else if w.Location, err = time.LoadLocation(tz); err != nil {
+		return w, err
+	}
+	if days == "" {
+		days = "mon-fri"
+	}
+	for _, part := range strings.Split(strings.ToLower(days), ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		a, ok := weekdays[from]
+		if !ok {
+			return w, fmt.Errorf("unknown working day %q", from)
+		}
+		b := a
+		if isRange {
+			if b, ok = weekdays[to]; !ok {
+				return w, fmt.Errorf("unknown working day %q", to)
+			}
+		}
+		for d := a; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == b {
+				break
+			}
+		}
+	}
+	return w, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func parseClock(s, def string) (time.Duration, error) {
+	if s == "" {
+		s = def
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether [start, end) falls inside a single working day.
+func (w WorkingHours) Contains(start, end time.Time) bool {
+	for _, win := range w.Windows(start, end) {
+		if !start.Before(win.Start) && !end.After(win.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Windows returns the working periods overlapping [from, to), clipped to it.
+func (w WorkingHours) Windows(from, to time.Time) []models.TimeSlot {
+	var out []models.TimeSlot
+	lf := from.In(w.Location)
+	day := time.Date(lf.Year(), lf.Month(), lf.Day(), 0, 0, 0, 0, w.Location)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !w.Days[day.Weekday()] {
+			continue
+		}
+		ws, we := atClock(day, w.Start), atClock(day, w.End)
+		if ws.Before(from) {
+			ws = from
+		}
+		if we.After(to) {
+			we = to
+		}
+		if ws.Before(we) {
+			out = append(out, models.TimeSlot{Start: ws.UTC(), End: we.UTC()})
+		}
+	}
+	return out
+}
+
+// atClock returns the wall-clock time d after midnight on day, so working
+// hours stay put across DST changes.
+func atClock(day time.Time, d time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(d/time.Hour), int(d%time.Hour/time.Minute), 0, 0, day.Location())
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -15,8 +16,12 @@ var HTTPClient *http.Client
 // BaseURL is the address of your API server; can be updated at runtime
 var BaseURL = "https://localhost:8443"
 
-// internal storage of the JWT
-var authToken string
+// internal storage of the JWT and of the refresh token that renews it
+var (
+	authMu       sync.Mutex
+	authToken    string
+	refreshToken string
+)
 
 // SetupHTTPClient configures your HTTPClient with TLS settings
 func SetupHTTPClient(cfg *tls.Config) {
@@ -28,26 +33,85 @@ func SetupHTTPClient(cfg *tls.Config) {
 
 // LoginRequest is the JSON payload for /login
 type LoginRequest struct {
+	Tenant   string `json:"tenant,omitempty"`
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
 // LoginResponse is what the server returns on success
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// Set instead of the tokens when a second factor is needed
+	MFARequired       bool     `json:"mfa_required"`
+	MFAToken          string   `json:"mfa_token"`
+	EnrolmentRequired bool     `json:"enrolment_required"`
+	RecoveryCodes     []string `json:"recovery_codes"`
+}
+
+// MFAChallenge is returned by Login when the user must also enter an
+// authenticator code; pass Token and the code to LoginMFA.
+type MFAChallenge struct {
+	Token             string
+	EnrolmentRequired bool
+}
+
+func (e *MFAChallenge) Error() string {
+	return "authentication code required"
+}
+
+// MFAEnrolment is the secret to add to an authenticator app.
+type MFAEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
 }
 
-// Login posts to /login, parses the JWT, and returns it (or an error)
-func Login(username, password string) (string, error) {
+// Login posts to /login, parses the JWT, and returns it (or an error).
+// tenant may be empty if the server resolves it from its host name. If
+// the user has MFA the error is an *MFAChallenge.
+func Login(tenant, username, password string) (string, error) {
+	return postLogin("/login", LoginRequest{Tenant: tenant, Username: username, Password: password}, nil)
+}
+
+// LoginMFA completes a login with the challenge token and a TOTP or
+// recovery code. If the code confirmed a new enrolment, recoveryCodes
+// receives the codes to show the user.
+func LoginMFA(mfaToken, code string, recoveryCodes *[]string) (string, error) {
+	return postLogin("/login/mfa", map[string]string{"mfa_token": mfaToken, "code": code}, recoveryCodes)
+}
+
+// EnrolMFA starts authenticator setup for a challenge that requires it.
+func EnrolMFA(mfaToken string) (*MFAEnrolment, error) {
+	buf, err := json.Marshal(map[string]string{"mfa_token": mfaToken})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := HTTPClient.Post(BaseURL+"/login/mfa/enroll", "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		return nil, fmt.Errorf("enrolment request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var errObj map[string]string
+		_ = json.NewDecoder(resp.Body).Decode(&errObj)
+		return nil, fmt.Errorf("enrolment failed: %s", errObj["error"])
+	}
+	var e MFAEnrolment
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return nil, fmt.Errorf("failed to decode enrolment response: %w", err)
+	}
+	return &e, nil
+}
+
+func postLogin(path string, reqBody any, recoveryCodes *[]string) (string, error) {
 	// Prepare JSON body
-	reqBody := LoginRequest{Username: username, Password: password}
 	buf, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal login request: %w", err)
 	}
 
 	// Send request
-	resp, err := HTTPClient.Post(BaseURL+"/login", "application/json", bytes.NewBuffer(buf))
+	resp, err := HTTPClient.Post(BaseURL+path, "application/json", bytes.NewBuffer(buf))
 	if err != nil {
 		return "", fmt.Errorf("login request failed: %w", err)
 	}
@@ -65,33 +129,123 @@ func Login(username, password string) (string, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
 		return "", fmt.Errorf("failed to decode login response: %w", err)
 	}
+	if lr.MFARequired {
+		return "", &MFAChallenge{Token: lr.MFAToken, EnrolmentRequired: lr.EnrolmentRequired}
+	}
+	if recoveryCodes != nil {
+		*recoveryCodes = lr.RecoveryCodes
+	}
 
+	authMu.Lock()
+	refreshToken = lr.RefreshToken
+	authMu.Unlock()
 	return lr.Token, nil
 }
 
-// SetAuthToken stores the JWT and wraps HTTPClient to include it on every request
+// LicenseWarning returns the warning the server gives when the tenant's
+// license is about to expire or has lapsed, or "" if there is none.
+func LicenseWarning() (string, error) {
+	resp, err := HTTPClient.Get(BaseURL + "/license")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("license request failed: %s", resp.Status)
+	}
+	var st struct {
+		Warning string `json:"warning"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return "", err
+	}
+	return st.Warning, nil
+}
+
+// SetAuthToken stores the JWT and wraps HTTPClient to include it on every
+// request. The access token is short-lived, so on a 401 the transport
+// renews it once with the refresh token from Login and retries.
 func SetAuthToken(token string) {
+	authMu.Lock()
 	authToken = token
+	authMu.Unlock()
 
 	// Wrap the existing Transport so we inject the Bearer header
 	base := HTTPClient.Transport
 	if base == nil {
 		base = http.DefaultTransport
 	}
-	HTTPClient.Transport = &authTransport{
-		base:  base,
-		token: authToken,
+	if at, ok := base.(*authTransport); ok {
+		base = at.base
 	}
+	HTTPClient.Transport = &authTransport{base: base}
 }
 
 // authTransport is an http.RoundTripper that adds Authorization headers
 type authTransport struct {
-	base  http.RoundTripper
-	token string
+	base http.RoundTripper
 }
 
 func (a *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authMu.Lock()
+	token := authToken
+	authMu.Unlock()
+
 	// Inject the header on every outgoing request
-	req.Header.Set("Authorization", "Bearer "+a.token)
-	return a.base.RoundTrip(req)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := a.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// A body that cannot be replayed cannot be retried.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	fresh, err := a.refresh(token)
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+fresh)
+	return a.base.RoundTrip(retry)
+}
+
+// refresh swaps the refresh token for a new token pair, unless another
+// request already replaced stale.
+func (a *authTransport) refresh(stale string) (string, error) {
+	authMu.Lock()
+	defer authMu.Unlock()
+	if authToken != stale {
+		return authToken, nil
+	}
+	if refreshToken == "" {
+		return "", fmt.Errorf("no refresh token")
+	}
+	buf, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req, err := http.NewRequest(http.MethodPost, BaseURL+"/token/refresh", bytes.NewReader(buf))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.base.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		refreshToken = ""
+		return "", fmt.Errorf("refresh failed: %s", resp.Status)
+	}
+	var lr LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		return "", err
+	}
+	authToken, refreshToken = lr.Token, lr.RefreshToken
+	return authToken, nil
 }
@@ -5,12 +5,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -88,33 +90,90 @@ func showLogin(a fyne.App) {
 	w := a.NewWindow("Login")
 	w.Resize(fyne.NewSize(300, 200))
 
+	tenant := widget.NewEntry()
+	tenant.SetPlaceHolder("Agency (tenant)")
 	username := widget.NewEntry()
 	username.SetPlaceHolder("Username")
 	password := widget.NewPasswordEntry()
 	password.SetPlaceHolder("Password")
 	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	// Shown when the server asks for an authenticator code
+	code := widget.NewEntry()
+	code.SetPlaceHolder("Authentication or recovery code")
+	code.Hide()
+	var challenge *client.MFAChallenge
 
 	loginBtn := widget.NewButton("Login", func() {
-		token, err := client.Login(username.Text, password.Text)
+		var token string
+		var err error
+		var recovery []string
+		if challenge != nil {
+			token, err = client.LoginMFA(challenge.Token, code.Text, &recovery)
+		} else {
+			token, err = client.Login(tenant.Text, username.Text, password.Text)
+		}
+		var ch *client.MFAChallenge
+		if errors.As(err, &ch) {
+			challenge = ch
+			code.Show()
+			status.SetText("Enter the code from your authenticator app")
+			if ch.EnrolmentRequired {
+				e, err := client.EnrolMFA(ch.Token)
+				if err != nil {
+					status.SetText("❌ " + err.Error())
+					return
+				}
+				status.SetText("Your account requires MFA. Add this key to your authenticator app, then enter its code:\n" + e.Secret)
+			}
+			return
+		}
 		if err != nil {
 			status.SetText("❌ " + err.Error())
 			return
 		}
 		client.SetAuthToken(token)
 		w.Close()
+		if len(recovery) > 0 {
+			showRecoveryCodes(a, recovery)
+		}
+		if warning, _ := client.LicenseWarning(); warning != "" {
+			showLicenseWarning(a, warning)
+		}
 		showMain(a)
 	})
 
 	w.SetContent(container.NewVBox(
 		widget.NewLabelWithStyle("Realtor Sales, Lettings and Installment Suite", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		tenant,
 		username,
 		password,
+		code,
 		loginBtn,
 		status,
 	))
 	w.ShowAndRun()
 }
 
+// showRecoveryCodes shows the recovery codes issued when MFA enrolment is
+// confirmed; the server does not show them again.
+func showRecoveryCodes(a fyne.App, codes []string) {
+	w := a.NewWindow("Recovery codes")
+	text := widget.NewLabel("Store these somewhere safe. Each can be used once instead of an authenticator code:\n\n" + strings.Join(codes, "\n"))
+	w.SetContent(container.NewVBox(text, widget.NewButton("Done", w.Close)))
+	w.Show()
+}
+
+// showLicenseWarning tells the user their agency's license needs
+// renewing.
+func showLicenseWarning(a fyne.App, warning string) {
+	w := a.NewWindow("License")
+	text := widget.NewLabel("⚠ " + warning + "\n\nAsk your administrator to install a renewed license.")
+	w.SetContent(container.NewVBox(text, widget.NewButton("OK", w.Close)))
+	w.Show()
+}
+
 // showMain builds your tabbed main UI after login.
 func showMain(a fyne.App) {
 	w := a.NewWindow("Realtor Sales, Lettings and Installment Suite")
@@ -2770,7 +2770,9 @@ func AccessControlTab(win fyne.Window) fyne.CanvasObject {
 			return
 		}
 		defer r.Body.Close()
-		var urs []struct{ RoleID int64 }
+		var urs []struct {
+			RoleID int64 `json:"role_id"`
+		}
 		json.NewDecoder(r.Body).Decode(&urs)
 		assigned := map[int64]bool{}
 		for _, ur := range urs {
@@ -2801,8 +2803,8 @@ func AccessControlTab(win fyne.Window) fyne.CanvasObject {
 		}
 		// POST body
 		body := struct {
-			UserID  int64
-			RoleIDs []int64
+			UserID  int64   `json:"user_id"`
+			RoleIDs []int64 `json:"role_ids"`
 		}{uid, rids}
 		buf, _ := json.Marshal(body)
 		r, err := client.HTTPClient.Post(client.BaseURL+"/userroles/bulk", "application/json", bytes.NewReader(buf))
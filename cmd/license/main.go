@@ -0,0 +1,150 @@
+// cmd/license/main.go
+//
+// license mints and checks the signed license files the API verifies.
+//
+//	license keygen -out vendor.key
+//	license mint -key vendor.key -tenant acme -expires 2027-01-31 -seats 25 \
+//	    -modules lettings,commissions,reports -out acme.lic
+//	license verify -pub <base64 public key> acme.lic
+//
+// Keep the private key offline; the API only needs the public key
+// (LICENSE_PUBLIC_KEY).
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/internal/license"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "mint":
+		err = mint(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: license keygen|mint|verify [flags]")
+	os.Exit(2)
+}
+
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", "vendor.key", "file to write the private key to")
+	fs.Parse(args)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, []byte(base64.StdEncoding.EncodeToString(priv)+"\n"), 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("private key written to %s\n", *out)
+	fmt.Printf("LICENSE_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pub))
+	return nil
+}
+
+func mint(args []string) error {
+	fs := flag.NewFlagSet("mint", flag.ExitOnError)
+	keyFile := fs.String("key", "vendor.key", "private key file from keygen")
+	tenant := fs.String("tenant", "", "tenant ID the license is for (required)")
+	licensee := fs.String("licensee", "", "customer name shown to the tenant")
+	expires := fs.String("expires", "", "expiry date, YYYY-MM-DD (required)")
+	seats := fs.Int("seats", 0, "maximum active users, 0 for unlimited")
+	modules := fs.String("modules", "", "comma-separated modules: lettings,commissions,reports")
+	out := fs.String("out", "", "license file to write (default <tenant>.lic)")
+	fs.Parse(args)
+
+	if *tenant == "" || *expires == "" {
+		return fmt.Errorf("-tenant and -expires are required")
+	}
+	exp, err := time.Parse("2006-01-02", *expires)
+	if err != nil {
+		return fmt.Errorf("bad -expires: %w", err)
+	}
+	keyData, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+	key, err := license.ParsePrivateKey(string(keyData))
+	if err != nil {
+		return err
+	}
+	var mods []string
+	for _, m := range strings.Split(*modules, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			mods = append(mods, m)
+		}
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	l := license.License{
+		ID:        "lic_" + hex.EncodeToString(id),
+		Tenant:    *tenant,
+		Licensee:  *licensee,
+		Seats:     *seats,
+		Modules:   mods,
+		IssuedAt:  time.Now().UTC().Truncate(time.Second),
+		ExpiresAt: exp.UTC(),
+	}
+	data, err := license.Sign(key, l)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = *tenant + ".lic"
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s for %s, %d seats, modules %v, expires %s\n", *out, l.ID, l.Tenant, l.Seats, l.Modules, l.ExpiresAt.Format("2006-01-02"))
+	return nil
+}
+
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubKey := fs.String("pub", os.Getenv("LICENSE_PUBLIC_KEY"), "base64 public key")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: license verify -pub <key> <file>")
+	}
+	pub, err := license.ParsePublicKey(*pubKey)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	l, err := license.Verify(pub, data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s for %s, %d seats, modules %v, expires %s (%s)\n", l.ID, l.Tenant, l.Seats, l.Modules,
+		l.ExpiresAt.Format("2006-01-02"), l.Status(time.Now(), 0, 0))
+	return nil
+}
@@ -0,0 +1,50 @@
+// cmd/mockoidc/main.go
+//
+// mockoidc is a stand-in OpenID provider for trying single sign-on
+// locally. It logs everyone in without asking, as -user or as the
+// login_hint the authorization request carries, and issues RS256 ID
+// tokens with the configured claims.
+//
+//	go run ./cmd/mockoidc -addr :9000 -client-id realtor -groups agents
+//
+// then configure the tenant with issuer http://localhost:9000 and open
+// /oidc/login?tenant=<id> on the API.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/newssourcecrawler/realtorinstall/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL, as the API reaches this server")
+	clientID := flag.String("client-id", "realtor", "client ID to accept")
+	clientSecret := flag.String("client-secret", "", "client secret to require, if any")
+	user := flag.String("user", "jdoe", "username to log in as when no login_hint is given")
+	domain := flag.String("email-domain", "example.com", "domain of the users' email addresses")
+	emailVerified := flag.Bool("email-verified", false, "mark the users' email addresses as verified")
+	groups := flag.String("groups", "agents", "comma-separated groups claim")
+	tenantClaim := flag.String("tenant-claim", "", "name=value claim added to every token, e.g. org=acme")
+	flag.Parse()
+
+	p, err := oidctest.NewProvider(*issuer, *clientID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.ClientSecret = *clientSecret
+	p.DefaultUser = *user
+	p.EmailDomain = *domain
+	p.EmailVerified = *emailVerified
+	p.Groups = strings.Split(*groups, ",")
+	if name, value, ok := strings.Cut(*tenantClaim, "="); ok {
+		p.Claims = map[string]any{name: value}
+	}
+
+	log.Printf("mock OpenID provider %s listening on %s", *issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, p))
+}
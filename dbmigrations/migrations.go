@@ -21,6 +21,7 @@ CREATE TABLE IF NOT EXISTS users (
   last_name     TEXT    NOT NULL,
   email         TEXT    NOT NULL,
   phone         TEXT,
+  team          TEXT    NOT NULL DEFAULT '',
   created_by    TEXT    NOT NULL,
   created_at    DATETIME NOT NULL,
   modified_by   TEXT    NOT NULL,
@@ -35,8 +36,10 @@ CREATE INDEX IF NOT EXISTS idx_users_tenant ON users(tenant_id);
 		sql: `
 CREATE TABLE IF NOT EXISTS roles (
   id          INTEGER PRIMARY KEY AUTOINCREMENT,
-  name        TEXT NOT NULL UNIQUE,
-  description TEXT
+  tenant_id   TEXT NOT NULL DEFAULT '',
+  name        TEXT NOT NULL,
+  description TEXT,
+  UNIQUE (tenant_id, name)
 );
 `,
 	},
@@ -80,6 +83,8 @@ CREATE TABLE IF NOT EXISTS user_roles (
 	  sale_price    REAL    NOT NULL,
 	  sale_date     DATETIME NOT NULL,
 	  sale_type     TEXT    NOT NULL,
+	  owner_id      INTEGER NOT NULL DEFAULT 0,
+	  assigned_to   INTEGER NOT NULL DEFAULT 0,
 	  created_by    TEXT    NOT NULL,
 	  created_at    DATETIME NOT NULL,
 	  modified_by   TEXT    NOT NULL,
@@ -100,6 +105,12 @@ CREATE TABLE IF NOT EXISTS user_roles (
 	  city TEXT NOT NULL,
 	  zip TEXT NOT NULL,
 	  listing_date DATETIME NOT NULL,
+	  status TEXT NOT NULL DEFAULT 'available',
+	  latitude REAL,
+	  longitude REAL,
+	  geo_source TEXT NOT NULL DEFAULT '',
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
 	  created_by TEXT NOT NULL,
 	  created_at DATETIME NOT NULL,
 	  modified_by TEXT NOT NULL,
@@ -107,6 +118,7 @@ CREATE TABLE IF NOT EXISTS user_roles (
 	  deleted INTEGER NOT NULL DEFAULT 0
 	);
 	CREATE INDEX IF NOT EXISTS idx_properties_tenant ON properties(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_properties_geo ON properties(tenant_id, latitude, longitude);
 	`,
 	}, {
 		name: "create_payments_table",
@@ -208,6 +220,9 @@ CREATE TABLE IF NOT EXISTS user_roles (
 	  frequency TEXT NOT NULL,
 	  first_installment DATETIME NOT NULL,
 	  interest_rate REAL NOT NULL,
+	  sale_id INTEGER NOT NULL DEFAULT 0,
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
 	  created_by TEXT NOT NULL,
 	  created_at DATETIME NOT NULL,
 	  modified_by TEXT NOT NULL,
@@ -216,6 +231,7 @@ CREATE TABLE IF NOT EXISTS user_roles (
 	);
 	CREATE INDEX IF NOT EXISTS idx_installmentplans_tenant ON installment_plans(tenant_id);
 	CREATE INDEX IF NOT EXISTS idx_installmentplans_property ON installment_plans(property_id);
+	CREATE INDEX IF NOT EXISTS idx_installmentplans_sale ON installment_plans(tenant_id, sale_id);
 	`,
 	},
 	{
@@ -255,6 +271,9 @@ CREATE TABLE IF NOT EXISTS commissions (
   rate_or_amount    REAL    NOT NULL,
   calculated_amount REAL    NOT NULL,
   memo              TEXT,
+  approved_by       TEXT    NOT NULL DEFAULT '',
+  approved_at       DATETIME,
+  owner_id          INTEGER NOT NULL DEFAULT 0,
   created_by        TEXT    NOT NULL,
   created_at        DATETIME NOT NULL,
   modified_by       TEXT    NOT NULL,
@@ -266,6 +285,524 @@ CREATE INDEX IF NOT EXISTS idx_comm_txn    ON commissions(transaction_type, tran
 CREATE INDEX IF NOT EXISTS idx_comm_benef  ON commissions(beneficiary_id);
 `,
 	},
+	{
+		name: "create_attachments_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS attachments (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  entity_type   TEXT    NOT NULL,
+	  entity_id     INTEGER NOT NULL,
+	  category      TEXT    NOT NULL DEFAULT '',
+	  file_name     TEXT    NOT NULL,
+	  mime_type     TEXT    NOT NULL,
+	  size_bytes    INTEGER NOT NULL,
+	  checksum      TEXT    NOT NULL,
+	  storage_key   TEXT    NOT NULL,
+	  thumbnail_key TEXT    NOT NULL DEFAULT '',
+	  uploaded_by   TEXT    NOT NULL,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_attachments_tenant ON attachments(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_attachments_entity ON attachments(tenant_id, entity_type, entity_id);
+	`,
+	},
+	{
+		name: "create_postal_centroids_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS postal_centroids (
+	  tenant_id   TEXT NOT NULL,
+	  postal_code TEXT NOT NULL,
+	  latitude    REAL NOT NULL,
+	  longitude   REAL NOT NULL,
+	  PRIMARY KEY (tenant_id, postal_code)
+	);
+	`,
+	},
+	{
+		name: "create_offers_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS offers (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL,
+	  amount         REAL    NOT NULL,
+	  counter_amount REAL    NOT NULL DEFAULT 0,
+	  conditions     TEXT    NOT NULL DEFAULT '',
+	  sale_type      TEXT    NOT NULL,
+	  expires_at     DATETIME,
+	  status         TEXT    NOT NULL,
+	  stage          TEXT    NOT NULL DEFAULT '',
+	  sale_id        INTEGER NOT NULL DEFAULT 0,
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_offers_tenant   ON offers(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_offers_property ON offers(tenant_id, property_id);
+
+	CREATE TABLE IF NOT EXISTS offer_milestones (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  offer_id    INTEGER NOT NULL REFERENCES offers(id),
+	  milestone   TEXT    NOT NULL,
+	  reached_at  DATETIME NOT NULL,
+	  notes       TEXT    NOT NULL DEFAULT '',
+	  created_by  TEXT    NOT NULL,
+	  created_at  DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_offer_milestones_offer ON offer_milestones(tenant_id, offer_id);
+	`,
+	},
+	{
+		name: "create_buyer_kyc_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS buyer_kyc (
+	  id                          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id                   TEXT    NOT NULL,
+	  buyer_id                    INTEGER NOT NULL,
+	  id_document_type            TEXT    NOT NULL,
+	  id_document_number          TEXT    NOT NULL,
+	  id_document_expiry          DATETIME NOT NULL,
+	  id_document_attachment_id   INTEGER NOT NULL DEFAULT 0,
+	  address_proof_type          TEXT    NOT NULL DEFAULT '',
+	  address_proof_attachment_id INTEGER NOT NULL DEFAULT 0,
+	  source_of_funds             TEXT    NOT NULL DEFAULT '',
+	  status                      TEXT    NOT NULL DEFAULT 'pending',
+	  verified_by                 TEXT    NOT NULL DEFAULT '',
+	  verified_at                 DATETIME,
+	  notes                       TEXT    NOT NULL DEFAULT '',
+	  created_by                  TEXT    NOT NULL,
+	  created_at                  DATETIME NOT NULL,
+	  modified_by                 TEXT    NOT NULL,
+	  last_modified               DATETIME NOT NULL,
+	  deleted                     INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_buyer_kyc_buyer ON buyer_kyc(tenant_id, buyer_id);
+
+	CREATE TABLE IF NOT EXISTS kyc_overrides (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  buyer_id   INTEGER NOT NULL,
+	  action     TEXT    NOT NULL,
+	  reason     TEXT    NOT NULL,
+	  granted_by TEXT    NOT NULL,
+	  granted_at DATETIME NOT NULL,
+	  expires_at DATETIME NOT NULL,
+	  used_by    TEXT    NOT NULL DEFAULT '',
+	  used_at    DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_kyc_overrides_buyer ON kyc_overrides(tenant_id, buyer_id);
+	`,
+	},
+	{
+		name: "create_buyer_merges_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS buyer_merges (
+	  id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id          TEXT    NOT NULL,
+	  survivor_id        INTEGER NOT NULL,
+	  duplicate_id       INTEGER NOT NULL,
+	  duplicate_snapshot TEXT    NOT NULL,
+	  reassigned         TEXT    NOT NULL,
+	  reason             TEXT    NOT NULL DEFAULT '',
+	  merged_by          TEXT    NOT NULL,
+	  merged_at          DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_buyer_merges_tenant ON buyer_merges(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_buyer_merges_duplicate ON buyer_merges(tenant_id, duplicate_id);
+	`,
+	},
+	{
+		name: "create_activities_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS activities (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  subject_type  TEXT    NOT NULL,
+	  subject_id    INTEGER NOT NULL,
+	  letting_id    INTEGER NOT NULL DEFAULT 0,
+	  type          TEXT    NOT NULL,
+	  occurred_at   DATETIME NOT NULL,
+	  author        TEXT    NOT NULL,
+	  body          TEXT    NOT NULL DEFAULT '',
+	  due_date      DATETIME,
+	  completed_at  DATETIME,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_activities_subject ON activities(tenant_id, subject_type, subject_id);
+	CREATE INDEX IF NOT EXISTS idx_activities_due ON activities(tenant_id, due_date);
+	`,
+	},
+	{
+		name: "create_appointments_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS appointments (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL DEFAULT 0,
+	  prospect_name  TEXT    NOT NULL DEFAULT '',
+	  prospect_phone TEXT    NOT NULL DEFAULT '',
+	  prospect_email TEXT    NOT NULL DEFAULT '',
+	  agent_user_id  INTEGER NOT NULL,
+	  starts_at      DATETIME NOT NULL,
+	  ends_at        DATETIME NOT NULL,
+	  status         TEXT    NOT NULL DEFAULT 'scheduled',
+	  notes          TEXT    NOT NULL DEFAULT '',
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_appointments_agent ON appointments(tenant_id, agent_user_id, starts_at);
+	CREATE INDEX IF NOT EXISTS idx_appointments_property ON appointments(tenant_id, property_id, starts_at);
+	CREATE INDEX IF NOT EXISTS idx_appointments_buyer ON appointments(tenant_id, buyer_id);
+	`,
+	},
+	{
+		name: "create_calendar_feed_tokens_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+	  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id    TEXT    NOT NULL,
+	  user_id      INTEGER NOT NULL,
+	  token_hash   TEXT    NOT NULL UNIQUE,
+	  created_at   DATETIME NOT NULL,
+	  last_used_at DATETIME,
+	  revoked_at   DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_calendar_feed_tokens_user ON calendar_feed_tokens(tenant_id, user_id);
+	`,
+	},
+	{
+		name: "create_reminder_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS reminder_templates (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  kind          TEXT    NOT NULL,
+	  subject       TEXT    NOT NULL,
+	  body          TEXT    NOT NULL,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  UNIQUE (tenant_id, kind)
+	);
+	CREATE TABLE IF NOT EXISTS reminder_sends (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  installment_id INTEGER NOT NULL,
+	  kind           TEXT    NOT NULL,
+	  recipient      TEXT    NOT NULL,
+	  sent_at        DATETIME NOT NULL,
+	  UNIQUE (tenant_id, installment_id, kind)
+	);
+	`,
+	},
+	{
+		name: "create_notification_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  subject_type  TEXT    NOT NULL,
+	  subject_id    INTEGER NOT NULL,
+	  channel       TEXT    NOT NULL,
+	  address       TEXT    NOT NULL DEFAULT '',
+	  opted_out     INTEGER NOT NULL DEFAULT 0,
+	  opted_out_at  DATETIME,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  UNIQUE (tenant_id, subject_type, subject_id, channel)
+	);
+	CREATE TABLE IF NOT EXISTS notification_attempts (
+	  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id    TEXT    NOT NULL,
+	  subject_type TEXT    NOT NULL,
+	  subject_id   INTEGER NOT NULL,
+	  channel      TEXT    NOT NULL,
+	  recipient    TEXT    NOT NULL,
+	  subject      TEXT    NOT NULL DEFAULT '',
+	  attempt      INTEGER NOT NULL,
+	  status       TEXT    NOT NULL,
+	  error        TEXT    NOT NULL DEFAULT '',
+	  attempted_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_notification_attempts_subject ON notification_attempts(tenant_id, subject_type, subject_id);
+	`,
+	},
+	{
+		name: "create_webhook_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  url           TEXT    NOT NULL,
+	  secret        TEXT    NOT NULL,
+	  events        TEXT    NOT NULL DEFAULT '',
+	  active        INTEGER NOT NULL DEFAULT 1,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	  id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id        TEXT    NOT NULL,
+	  subscription_id  INTEGER NOT NULL,
+	  event_id         TEXT    NOT NULL,
+	  event_type       TEXT    NOT NULL,
+	  payload          TEXT    NOT NULL,
+	  status           TEXT    NOT NULL,
+	  attempts         INTEGER NOT NULL DEFAULT 0,
+	  next_attempt_at  DATETIME NOT NULL,
+	  last_error       TEXT    NOT NULL DEFAULT '',
+	  last_status_code INTEGER NOT NULL DEFAULT 0,
+	  delivered_at     DATETIME,
+	  created_at       DATETIME NOT NULL,
+	  UNIQUE (subscription_id, event_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant ON webhook_deliveries(tenant_id, status);
+	`,
+	},
+	{
+		name: "create_outbox_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS outbox_events (
+	  id              TEXT    PRIMARY KEY,
+	  tenant_id       TEXT    NOT NULL,
+	  topic           TEXT    NOT NULL,
+	  aggregate_type  TEXT    NOT NULL,
+	  aggregate_id    INTEGER NOT NULL,
+	  payload         TEXT    NOT NULL,
+	  status          TEXT    NOT NULL DEFAULT 'pending',
+	  attempts        INTEGER NOT NULL DEFAULT 0,
+	  next_attempt_at DATETIME NOT NULL,
+	  last_error      TEXT    NOT NULL DEFAULT '',
+	  created_at      DATETIME NOT NULL,
+	  dispatched_at   DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events(status, next_attempt_at);
+	CREATE TABLE IF NOT EXISTS processed_events (
+	  consumer     TEXT     NOT NULL,
+	  event_id     TEXT     NOT NULL,
+	  processed_at DATETIME NOT NULL,
+	  PRIMARY KEY (consumer, event_id)
+	);
+	`,
+	},
+	{
+		name: "create_access_audit_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS access_audit (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  actor       TEXT    NOT NULL,
+	  action      TEXT    NOT NULL,
+	  target_type TEXT    NOT NULL,
+	  target_id   INTEGER NOT NULL,
+	  detail      TEXT    NOT NULL DEFAULT '',
+	  created_at  DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_access_audit_tenant ON access_audit(tenant_id, created_at);
+	`,
+	},
+	{
+		name: "create_session_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS refresh_tokens (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  family_id  TEXT    NOT NULL,
+	  token_hash TEXT    NOT NULL UNIQUE,
+	  created_at DATETIME NOT NULL,
+	  expires_at DATETIME NOT NULL,
+	  used_at    DATETIME,
+	  revoked_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS session_revocations (
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  revoked_at DATETIME NOT NULL,
+	  revoked_by TEXT    NOT NULL,
+	  PRIMARY KEY (tenant_id, user_id)
+	);
+	`,
+	},
+	{
+		name: "create_mfa_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS mfa_secrets (
+	  tenant_id    TEXT    NOT NULL,
+	  user_id      INTEGER NOT NULL,
+	  secret       TEXT    NOT NULL,
+	  confirmed_at DATETIME,
+	  last_counter INTEGER NOT NULL DEFAULT 0,
+	  created_at   DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  code_hash  TEXT    NOT NULL,
+	  used_at    DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS mfa_policies (
+	  tenant_id            TEXT    PRIMARY KEY,
+	  required_permissions TEXT    NOT NULL DEFAULT '',
+	  modified_by          TEXT    NOT NULL,
+	  last_modified        DATETIME NOT NULL
+	);
+	`,
+	},
+	{
+		name: "create_password_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS password_reset_tokens (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  user_id     INTEGER NOT NULL,
+	  token_hash  TEXT    NOT NULL UNIQUE,
+	  created_at  DATETIME NOT NULL,
+	  expires_at  DATETIME NOT NULL,
+	  used_at     DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS password_history (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  user_id       INTEGER NOT NULL,
+	  password_hash TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS login_failures (
+	  tenant_id    TEXT    NOT NULL,
+	  username     TEXT    NOT NULL,
+	  ip           TEXT    NOT NULL,
+	  failures     INTEGER NOT NULL DEFAULT 0,
+	  locked_until DATETIME,
+	  last_failure DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, username, ip)
+	);
+	`,
+	},
+	{
+		name: "create_tenants_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS tenants (
+	  id               TEXT    PRIMARY KEY,
+	  name             TEXT    NOT NULL,
+	  status           TEXT    NOT NULL DEFAULT 'active',
+	  suspended_at     DATETIME,
+	  suspended_reason TEXT    NOT NULL DEFAULT '',
+	  created_by       TEXT    NOT NULL,
+	  created_at       DATETIME NOT NULL,
+	  modified_by      TEXT    NOT NULL,
+	  last_modified    DATETIME NOT NULL
+	);
+	INSERT OR IGNORE INTO tenants (id, name, status, created_by, created_at, modified_by, last_modified)
+	SELECT DISTINCT tenant_id, tenant_id, 'active', 'migration', CURRENT_TIMESTAMP, 'migration', CURRENT_TIMESTAMP
+	FROM users
+	WHERE tenant_id <> '';
+	`,
+	},
+	{
+		name: "create_tenant_licenses_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS tenant_licenses (
+	  tenant_id    TEXT     PRIMARY KEY,
+	  license_id   TEXT     NOT NULL,
+	  data         TEXT     NOT NULL,
+	  installed_by TEXT     NOT NULL,
+	  installed_at DATETIME NOT NULL
+	);
+	`,
+	},
+	{
+		name: "create_api_keys_table",
+		sql: `
+	CREATE TABLE IF NOT EXISTS api_keys (
+	  id            INTEGER  PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT     NOT NULL,
+	  user_id       INTEGER  NOT NULL,
+	  name          TEXT     NOT NULL,
+	  prefix        TEXT     NOT NULL UNIQUE,
+	  secret_hash   TEXT     NOT NULL,
+	  permissions   TEXT     NOT NULL DEFAULT '',
+	  allowed_ips   TEXT     NOT NULL DEFAULT '',
+	  expires_at    DATETIME,
+	  last_used_at  DATETIME,
+	  last_used_ip  TEXT     NOT NULL DEFAULT '',
+	  created_by    TEXT     NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT     NOT NULL,
+	  last_modified DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant_id);
+	`,
+	},
+	{
+		name: "create_oidc_tables",
+		sql: `
+	CREATE TABLE IF NOT EXISTS oidc_configs (
+	  tenant_id          TEXT     PRIMARY KEY,
+	  enabled            INTEGER  NOT NULL DEFAULT 1,
+	  issuer             TEXT     NOT NULL,
+	  client_id          TEXT     NOT NULL,
+	  client_secret      TEXT     NOT NULL DEFAULT '',
+	  scopes             TEXT     NOT NULL DEFAULT '',
+	  username_claim     TEXT     NOT NULL DEFAULT '',
+	  tenant_claim       TEXT     NOT NULL DEFAULT '',
+	  tenant_claim_value TEXT     NOT NULL DEFAULT '',
+	  role_claim         TEXT     NOT NULL DEFAULT '',
+	  role_mappings      TEXT     NOT NULL DEFAULT '{}',
+	  default_role       TEXT     NOT NULL DEFAULT '',
+	  link_verified_email INTEGER NOT NULL DEFAULT 0,
+	  modified_by        TEXT     NOT NULL,
+	  last_modified      DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS oidc_login_states (
+	  state         TEXT     PRIMARY KEY,
+	  tenant_id     TEXT     NOT NULL,
+	  nonce         TEXT     NOT NULL,
+	  code_verifier TEXT     NOT NULL,
+	  redirect_uri  TEXT     NOT NULL,
+	  expires_at    DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS oidc_identities (
+	  tenant_id  TEXT     NOT NULL,
+	  issuer     TEXT     NOT NULL,
+	  subject    TEXT     NOT NULL,
+	  user_id    INTEGER  NOT NULL,
+	  created_at DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, issuer, subject)
+	);
+	`,
+	},
 }
 
 func ApplyMigrations(db *sql.DB) error {
@@ -5,9 +5,18 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 type Config struct {
+	// Database layout: "split" (default) gives every domain its own
+	// database below; "single" opens DBDriver/DBDSN once and shares that
+	// connection between all domains, adding real foreign keys and letting
+	// cross-domain writes commit in one transaction.
+	DBMode   string `json:"db_mode"`
+	DBDriver string `json:"db_driver"`
+	DBDSN    string `json:"db_dsn"`
+
 	// Per-domain DB drivers & DSNs
 	UserDBDriver           string `json:"user_db_driver"`
 	UserDBDSN              string `json:"user_db_dsn"`
@@ -39,6 +48,80 @@ type Config struct {
 	RoleDBDSN              string `json:"role_db_dsn"`
 	UserRoleDBDriver       string `json:"userrole_db_driver"`
 	UserRoleDBDSN          string `json:"userrole_db_dsn"`
+	AttachmentDBDriver     string `json:"attachment_db_driver"`
+	AttachmentDBDSN        string `json:"attachment_db_dsn"`
+
+	// Attachment content storage ("local" or "s3")
+	StorageBackend  string `json:"storage_backend"`
+	StorageLocalDir string `json:"storage_local_dir"`
+	S3Endpoint      string `json:"s3_endpoint"`
+	S3Region        string `json:"s3_region"`
+	S3Bucket        string `json:"s3_bucket"`
+	S3AccessKey     string `json:"s3_access_key"`
+	S3SecretKey     string `json:"s3_secret_key"`
+	S3UsePathStyle  bool   `json:"s3_use_path_style"`
+
+	// Viewing appointment working hours: "HH:MM" times, days such as
+	// "mon-fri" or "mon,wed,sat", and an IANA time zone.
+	WorkingHoursStart string `json:"working_hours_start"`
+	WorkingHoursEnd   string `json:"working_hours_end"`
+	WorkingDays       string `json:"working_days"`
+	WorkingTimezone   string `json:"working_timezone"`
+
+	// Outgoing mail for installment reminders. SMTPUser may be empty for
+	// relays without authentication, such as a local sink.
+	SMTPHost string `json:"SMTPHost"`
+	SMTPPort int    `json:"SMTPPort"`
+	SMTPUser string `json:"SMTPUser"`
+	SMTPPass string `json:"SMTPPass"`
+	SMTPFrom string `json:"smtp_from"`
+	// Days before the due date to send the upcoming-installment reminder.
+	ReminderDaysBefore int `json:"reminder_days_before"`
+
+	// SMS gateway (JSON POST of {"to", "from", "message"}) and the HMAC
+	// secret for signing outbound notification webhooks.
+	SMSGatewayURL        string `json:"sms_gateway_url"`
+	SMSGatewayToken      string `json:"sms_gateway_token"`
+	SMSFrom              string `json:"sms_from"`
+	WebhookSecret        string `json:"notify_webhook_secret"`
+	WebhookAllowInsecure bool   `json:"webhook_allow_insecure"`
+
+	// Country calling code assumed for phone numbers in national format
+	// when matching duplicate buyers, e.g. "44".
+	DefaultCallingCode string `json:"default_calling_code"`
+
+	// Password policy: minimum length, a file of breached passwords (one
+	// per line) that are refused, and how many previous passwords cannot
+	// be reused. PasswordResetURL is the page reset emails link to; the
+	// token is appended as ?token=.
+	PasswordMinLength    int    `json:"password_min_length"`
+	PasswordBreachedList string `json:"password_breached_list"`
+	PasswordHistory      int    `json:"password_history"`
+	PasswordResetURL     string `json:"password_reset_url"`
+	// Failed logins per username and IP before a lockout, and the first
+	// lockout's length in seconds; each further failure doubles it.
+	LoginLockoutThreshold int `json:"login_lockout_threshold"`
+	LoginLockoutSeconds   int `json:"login_lockout_seconds"`
+
+	// Tenant resolution for login: a request's tenant is taken from its
+	// body, the X-Tenant-ID header, or the subdomain of TenantBaseDomain
+	// (acme.app.example.com), falling back to DefaultTenant.
+	TenantBaseDomain string `json:"tenant_base_domain"`
+	DefaultTenant    string `json:"default_tenant"`
+	// The platform tenant's users may hold manage_tenants. Its admin is
+	// created on first start when PlatformAdminPassword is set.
+	PlatformTenant        string `json:"platform_tenant"`
+	PlatformAdminUser     string `json:"platform_admin_username"`
+	PlatformAdminPassword string `json:"platform_admin_password"`
+	// Tenant licenses are verified against the vendor's base64 Ed25519
+	// public key; without one licensing is off. An expired license keeps
+	// working for LicenseGraceDays (default 14).
+	LicensePublicKey string `json:"license_public_key"`
+	LicenseGraceDays int    `json:"license_grace_days"`
+	// OIDCCallbackURL is this API's /oidc/callback as users' browsers
+	// reach it, registered with each tenant's identity provider. Empty
+	// derives it from the login request.
+	OIDCCallbackURL string `json:"oidc_callback_url"`
 
 	AppJWTSecret string `json:"app_jwt_secret"`
 	APICertFile  string `json:"api_cert_file"`
@@ -57,6 +140,15 @@ func Load() (*Config, error) {
 	}
 
 	// 2) Override from ENV
+	if v := os.Getenv("DB_MODE"); v != "" {
+		cfg.DBMode = v
+	}
+	if v := os.Getenv("DB_DRIVER"); v != "" {
+		cfg.DBDriver = v
+	}
+	if v := os.Getenv("DB_DSN"); v != "" {
+		cfg.DBDSN = v
+	}
 	if v := os.Getenv("USER_DB_DRIVER"); v != "" {
 		cfg.UserDBDriver = v
 	}
@@ -147,6 +239,142 @@ func Load() (*Config, error) {
 	if v := os.Getenv("USERROLE_DB_DSN"); v != "" {
 		cfg.UserRoleDBDSN = v
 	}
+	if v := os.Getenv("ATTACHMENT_DB_DRIVER"); v != "" {
+		cfg.AttachmentDBDriver = v
+	}
+	if v := os.Getenv("ATTACHMENT_DB_DSN"); v != "" {
+		cfg.AttachmentDBDSN = v
+	}
+
+	if v := os.Getenv("STORAGE_BACKEND"); v != "" {
+		cfg.StorageBackend = v
+	}
+	if v := os.Getenv("STORAGE_LOCAL_DIR"); v != "" {
+		cfg.StorageLocalDir = v
+	}
+	if v := os.Getenv("S3_ENDPOINT"); v != "" {
+		cfg.S3Endpoint = v
+	}
+	if v := os.Getenv("S3_REGION"); v != "" {
+		cfg.S3Region = v
+	}
+	if v := os.Getenv("S3_BUCKET"); v != "" {
+		cfg.S3Bucket = v
+	}
+	if v := os.Getenv("S3_ACCESS_KEY"); v != "" {
+		cfg.S3AccessKey = v
+	}
+	if v := os.Getenv("S3_SECRET_KEY"); v != "" {
+		cfg.S3SecretKey = v
+	}
+	if v := os.Getenv("S3_USE_PATH_STYLE"); v != "" {
+		cfg.S3UsePathStyle = v == "1" || v == "true"
+	}
+
+	if v := os.Getenv("WORKING_HOURS_START"); v != "" {
+		cfg.WorkingHoursStart = v
+	}
+	if v := os.Getenv("WORKING_HOURS_END"); v != "" {
+		cfg.WorkingHoursEnd = v
+	}
+	if v := os.Getenv("WORKING_DAYS"); v != "" {
+		cfg.WorkingDays = v
+	}
+	if v := os.Getenv("WORKING_TIMEZONE"); v != "" {
+		cfg.WorkingTimezone = v
+	}
+	if v := os.Getenv("SMTP_HOST"); v != "" {
+		cfg.SMTPHost = v
+	}
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.SMTPPort = n
+		}
+	}
+	if v := os.Getenv("SMTP_USER"); v != "" {
+		cfg.SMTPUser = v
+	}
+	if v := os.Getenv("SMTP_PASS"); v != "" {
+		cfg.SMTPPass = v
+	}
+	if v := os.Getenv("SMTP_FROM"); v != "" {
+		cfg.SMTPFrom = v
+	}
+	if v := os.Getenv("REMINDER_DAYS_BEFORE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.ReminderDaysBefore = n
+		}
+	}
+	if v := os.Getenv("SMS_GATEWAY_URL"); v != "" {
+		cfg.SMSGatewayURL = v
+	}
+	if v := os.Getenv("SMS_GATEWAY_TOKEN"); v != "" {
+		cfg.SMSGatewayToken = v
+	}
+	if v := os.Getenv("SMS_FROM"); v != "" {
+		cfg.SMSFrom = v
+	}
+	if v := os.Getenv("NOTIFY_WEBHOOK_SECRET"); v != "" {
+		cfg.WebhookSecret = v
+	}
+	if v := os.Getenv("WEBHOOK_ALLOW_INSECURE"); v != "" {
+		cfg.WebhookAllowInsecure = v == "1" || v == "true"
+	}
+	if v := os.Getenv("DEFAULT_CALLING_CODE"); v != "" {
+		cfg.DefaultCallingCode = v
+	}
+	if v := os.Getenv("PASSWORD_MIN_LENGTH"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.PasswordMinLength = n
+		}
+	}
+	if v := os.Getenv("PASSWORD_BREACHED_LIST"); v != "" {
+		cfg.PasswordBreachedList = v
+	}
+	if v := os.Getenv("PASSWORD_HISTORY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.PasswordHistory = n
+		}
+	}
+	if v := os.Getenv("PASSWORD_RESET_URL"); v != "" {
+		cfg.PasswordResetURL = v
+	}
+	if v := os.Getenv("LOGIN_LOCKOUT_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.LoginLockoutThreshold = n
+		}
+	}
+	if v := os.Getenv("LOGIN_LOCKOUT_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.LoginLockoutSeconds = n
+		}
+	}
+	if v := os.Getenv("TENANT_BASE_DOMAIN"); v != "" {
+		cfg.TenantBaseDomain = v
+	}
+	if v := os.Getenv("DEFAULT_TENANT"); v != "" {
+		cfg.DefaultTenant = v
+	}
+	if v := os.Getenv("PLATFORM_TENANT"); v != "" {
+		cfg.PlatformTenant = v
+	}
+	if v := os.Getenv("PLATFORM_ADMIN_USERNAME"); v != "" {
+		cfg.PlatformAdminUser = v
+	}
+	if v := os.Getenv("PLATFORM_ADMIN_PASSWORD"); v != "" {
+		cfg.PlatformAdminPassword = v
+	}
+	if v := os.Getenv("LICENSE_PUBLIC_KEY"); v != "" {
+		cfg.LicensePublicKey = v
+	}
+	if v := os.Getenv("OIDC_CALLBACK_URL"); v != "" {
+		cfg.OIDCCallbackURL = v
+	}
+	if v := os.Getenv("LICENSE_GRACE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.LicenseGraceDays = n
+		}
+	}
 
 	if v := os.Getenv("APP_JWT_SECRET"); v != "" {
 		cfg.AppJWTSecret = v
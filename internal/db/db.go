@@ -40,3 +40,30 @@ func Open(cfg Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("unsupported driver: %s", cfg.Driver)
 	}
 }
+
+// SharedConfig is the configuration of the one database used by every
+// domain in single-database mode. SQLite only enforces foreign keys when
+// asked to, and a busy timeout lets the domains' concurrent writers queue
+// for the file lock instead of failing.
+func SharedConfig(driver, dsn string) Config {
+	if driver == "" {
+		driver = "sqlite"
+	}
+	if dsn == "" && driver == "sqlite" {
+		dsn = "data/realtorinstall.db"
+	}
+	if driver == "sqlite" {
+		for _, opt := range []string{"_foreign_keys=1", "_busy_timeout=5000"} {
+			key := opt[:strings.Index(opt, "=")+1]
+			if strings.Contains(dsn, key) {
+				continue
+			}
+			if strings.Contains(dsn, "?") {
+				dsn += "&" + opt
+			} else {
+				dsn += "?" + opt
+			}
+		}
+	}
+	return Config{Driver: driver, DSN: dsn}
+}
@@ -0,0 +1,118 @@
+// internal/ical/ical.go
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a single VEVENT. UID must be stable across exports so calendar
+// clients update rather than duplicate events.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time // zero = no DTEND
+	AllDay      bool      // Start/End are dates; End is exclusive
+	Updated     time.Time // LAST-MODIFIED, zero = omitted
+	Status      string    // "CONFIRMED", "TENTATIVE" or "CANCELLED"; "" = omitted
+}
+
+// Calendar is a VCALENDAR holding events.
+type Calendar struct {
+	ProdID string // e.g. "-//Realtor Installment Assistant//EN"
+	Name   string // X-WR-CALNAME, optional
+	Events []Event
+}
+
+// ContentType is the MIME type for .ics responses.
+const ContentType = "text/calendar; charset=utf-8"
+
+// Write renders c as RFC 5545 text: CRLF line endings, escaped text values
+// and lines folded at 75 octets.
+func (c *Calendar) Write(w io.Writer) error {
+	lw := &lineWriter{w: w}
+	stamp := time.Now().UTC()
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + c.ProdID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	for _, e := range c.Events {
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.UID)
+		lw.line("DTSTAMP:" + formatUTC(stamp))
+		if e.AllDay {
+			lw.line("DTSTART;VALUE=DATE:" + e.Start.Format("20060102"))
+			end := e.End
+			if end.IsZero() {
+				end = e.Start.AddDate(0, 0, 1)
+			}
+			lw.line("DTEND;VALUE=DATE:" + end.Format("20060102"))
+		} else {
+			lw.line("DTSTART:" + formatUTC(e.Start))
+			if !e.End.IsZero() {
+				lw.line("DTEND:" + formatUTC(e.End))
+			}
+		}
+		lw.line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Location != "" {
+			lw.line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.Status != "" {
+			lw.line("STATUS:" + e.Status)
+		}
+		if !e.Updated.IsZero() {
+			lw.line("LAST-MODIFIED:" + formatUTC(e.Updated))
+		}
+		lw.line("END:VEVENT")
+	}
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes a TEXT value (RFC 5545 §3.3.11).
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// lineWriter writes content lines, folding them at 75 octets without
+// splitting UTF-8 sequences (RFC 5545 §3.1).
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+	const limit = 75
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
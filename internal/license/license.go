@@ -0,0 +1,131 @@
+// internal/license/license.go
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Modules a license can enable.
+const (
+	ModuleLettings    = "lettings"
+	ModuleCommissions = "commissions"
+	ModuleReports     = "reports"
+)
+
+// Statuses reported by License.Status.
+const (
+	StatusValid    = "valid"
+	StatusExpiring = "expiring" // valid, but expires within the warning window
+	StatusGrace    = "grace"    // expired, but within the grace period
+	StatusExpired  = "expired"
+)
+
+var (
+	ErrMalformed    = errors.New("license: malformed license file")
+	ErrBadSignature = errors.New("license: signature does not verify")
+)
+
+// License is what the vendor signs for one tenant.
+type License struct {
+	ID        string    `json:"id"`
+	Tenant    string    `json:"tenant"`
+	Licensee  string    `json:"licensee,omitempty"`
+	Seats     int       `json:"seats"` // 0 means unlimited
+	Modules   []string  `json:"modules"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// file is the on-disk form: the exact payload bytes that were signed,
+// with the signature, both base64.
+type file struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// Sign encodes l and signs it with key, returning the license file.
+func Sign(key ed25519.PrivateKey, l License) ([]byte, error) {
+	payload, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(file{
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}, "", "  ")
+}
+
+// Verify checks a license file against the vendor's public key and
+// returns the license it carries. It does not check expiry.
+func Verify(pub ed25519.PublicKey, data []byte) (*License, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, ErrMalformed
+	}
+	payload, err := base64.StdEncoding.DecodeString(f.Payload)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := base64.StdEncoding.DecodeString(f.Signature)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, payload, sig) {
+		return nil, ErrBadSignature
+	}
+	var l License
+	if err := json.Unmarshal(payload, &l); err != nil {
+		return nil, ErrMalformed
+	}
+	if l.Tenant == "" || l.ExpiresAt.IsZero() {
+		return nil, ErrMalformed
+	}
+	return &l, nil
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("license: public key must be %d base64-encoded bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// ParsePrivateKey decodes a base64 Ed25519 private key (seed and public
+// key, as written by the mint tool).
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("license: private key must be %d base64-encoded bytes", ed25519.PrivateKeySize)
+	}
+	return ed25519.PrivateKey(b), nil
+}
+
+// HasModule reports whether the license enables module.
+func (l *License) HasModule(module string) bool {
+	return slices.Contains(l.Modules, module)
+}
+
+// Status places now relative to the license's expiry: warn is how long
+// before expiry to start warning, grace how long after it the license
+// keeps working.
+func (l *License) Status(now time.Time, warn, grace time.Duration) string {
+	switch {
+	case now.Before(l.ExpiresAt.Add(-warn)):
+		return StatusValid
+	case now.Before(l.ExpiresAt):
+		return StatusExpiring
+	case now.Before(l.ExpiresAt.Add(grace)):
+		return StatusGrace
+	default:
+		return StatusExpired
+	}
+}
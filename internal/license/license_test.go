@@ -0,0 +1,105 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testLicense() License {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return License{
+		ID: "lic-1", Tenant: "acme", Seats: 5, Modules: []string{ModuleLettings},
+		IssuedAt: now, ExpiresAt: now.AddDate(1, 0, 0),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Sign(priv, testLicense())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Verify(pub, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Tenant != "acme" || l.Seats != 5 || !l.HasModule(ModuleLettings) || l.HasModule(ModuleReports) {
+		t.Fatalf("Verify = %+v", l)
+	}
+
+	if _, err := Verify(otherPub, data); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("another vendor key: err = %v, want ErrBadSignature", err)
+	}
+
+	// Raising the seat count invalidates the signature.
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	forged := testLicense()
+	forged.Seats = 500
+	payload, _ := json.Marshal(forged)
+	f.Payload = base64.StdEncoding.EncodeToString(payload)
+	tampered, _ := json.Marshal(f)
+	if _, err := Verify(pub, tampered); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered payload: err = %v, want ErrBadSignature", err)
+	}
+
+	if _, err := Verify(pub, []byte("not json")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("garbage: err = %v, want ErrMalformed", err)
+	}
+	if _, err := Verify(pub[:10], data); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("short key: err = %v, want ErrBadSignature", err)
+	}
+	noTenant := testLicense()
+	noTenant.Tenant = ""
+	data, _ = Sign(priv, noTenant)
+	if _, err := Verify(pub, data); !errors.Is(err, ErrMalformed) {
+		t.Errorf("no tenant: err = %v, want ErrMalformed", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	l := testLicense()
+	warn, grace := 30*24*time.Hour, 14*24*time.Hour
+	for _, c := range []struct {
+		at   time.Time
+		want string
+	}{
+		{l.ExpiresAt.Add(-warn - time.Second), StatusValid},
+		{l.ExpiresAt.Add(-warn), StatusExpiring},
+		{l.ExpiresAt.Add(-time.Second), StatusExpiring},
+		{l.ExpiresAt, StatusGrace},
+		{l.ExpiresAt.Add(grace - time.Second), StatusGrace},
+		{l.ExpiresAt.Add(grace), StatusExpired},
+	} {
+		if got := l.Status(c.at, warn, grace); got != c.want {
+			t.Errorf("Status(%s) = %s, want %s", c.at, got, c.want)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	if got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub)); err != nil || !got.Equal(pub) {
+		t.Errorf("ParsePublicKey: %v", err)
+	}
+	if got, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(priv) + "\n"); err != nil || !got.Equal(priv) {
+		t.Errorf("ParsePrivateKey: %v", err)
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString(priv)); err == nil {
+		t.Error("ParsePublicKey accepted a private key")
+	}
+}
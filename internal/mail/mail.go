@@ -0,0 +1,100 @@
+// internal/mail/mail.go
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SMTPSender sends through an SMTP relay. STARTTLS is used when the server
+// offers it; authentication is skipped when User is empty, which is what a
+// local development sink such as MailHog expects.
+type SMTPSender struct {
+	Host string
+	Port int
+	User string
+	Pass string
+	From string // default From address
+}
+
+func NewSMTPSender(host string, port int, user, pass, from string) *SMTPSender {
+	if port == 0 {
+		port = 25
+	}
+	if from == "" {
+		from = user
+	}
+	return &SMTPSender{Host: host, Port: port, User: user, Pass: pass, From: from}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, m Message) error {
+	if m.From == "" {
+		m.From = s.From
+	}
+	if m.From == "" || len(m.To) == 0 {
+		return errors.New("mail: missing sender or recipient")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.User != "" {
+		auth = smtp.PlainAuth("", s.User, s.Pass, s.Host)
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	return smtp.SendMail(addr, auth, m.From, m.To, Format(m, time.Now()))
+}
+
+// Format renders m as an RFC 5322 message with CRLF line endings.
+func Format(m Message, date time.Time) []byte {
+	var b strings.Builder
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(m.From))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		// Dot-stuffing is done by net/smtp; only normalise line endings.
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.Trim(from[i+1:], "> ")
+	}
+	raw := make([]byte, 12)
+	rand.Read(raw)
+	return "<" + hex.EncodeToString(raw) + "@" + domain + ">"
+}
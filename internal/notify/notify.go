@@ -0,0 +1,162 @@
+// internal/notify/notify.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/newssourcecrawler/realtorinstall/internal/mail"
+)
+
+// Channel names.
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+// Message is a channel-neutral notification. To is the channel address:
+// an email address, a phone number or a webhook URL.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages over one channel.
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, m Message) error
+}
+
+// PermanentError marks a failure that retrying won't fix, such as a
+// rejected recipient.
+type PermanentError struct{ Err error }
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// IsPermanent reports whether err should not be retried.
+func IsPermanent(err error) bool {
+	var p *PermanentError
+	return errors.As(err, &p)
+}
+
+// EmailNotifier sends through a mail.Sender.
+type EmailNotifier struct {
+	Sender mail.Sender
+}
+
+func (n *EmailNotifier) Channel() string { return ChannelEmail }
+
+func (n *EmailNotifier) Notify(ctx context.Context, m Message) error {
+	return n.Sender.Send(ctx, mail.Message{To: []string{m.To}, Subject: m.Subject, Body: m.Body})
+}
+
+// SMSNotifier posts {"to", "from", "message"} as JSON to a generic HTTP SMS
+// gateway, authenticating with a bearer token when one is set.
+type SMSNotifier struct {
+	URL    string
+	Token  string
+	From   string
+	Client *http.Client
+}
+
+func (n *SMSNotifier) Channel() string { return ChannelSMS }
+
+func (n *SMSNotifier) Notify(ctx context.Context, m Message) error {
+	text := m.Body
+	if m.Subject != "" {
+		text = m.Subject + "\n" + m.Body
+	}
+	body, _ := json.Marshal(map[string]string{"to": m.To, "from": n.From, "message": text})
+	header := http.Header{"Content-Type": {"application/json"}}
+	if n.Token != "" {
+		header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return post(ctx, n.Client, n.URL, header, body)
+}
+
+// WebhookNotifier posts the message as JSON to the recipient URL, e.g. a
+// chat incoming-webhook. Each request carries X-Webhook-Timestamp and
+// X-Webhook-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+type WebhookNotifier struct {
+	Secret string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Channel() string { return ChannelWebhook }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, m Message) error {
+	body, _ := json.Marshal(map[string]string{"subject": m.Subject, "text": m.Body})
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{
+		"Content-Type":        {"application/json"},
+		"X-Webhook-Timestamp": {ts},
+		"X-Webhook-Signature": {Sign(n.Secret, ts, body)},
+	}
+	return post(ctx, n.Client, m.To, header, body)
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &PermanentError{err}
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s: %s %s", url, resp.Status, bytes.TrimSpace(snippet))
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+		return &PermanentError{err}
+	}
+	return err
+}
+
+// RetryPolicy retries failed sends with exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Initial     time.Duration
+	Max         time.Duration
+}
+
+// DefaultRetryPolicy makes three attempts, waiting 1s then 2s.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Initial: time.Second, Max: 30 * time.Second}
+
+// Backoff returns the wait before attempt number attempt+1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.Initial
+	for i := 1; i < attempt && d < p.Max; i++ {
+		d *= 2
+	}
+	return min(d, p.Max)
+}
@@ -0,0 +1,281 @@
+// internal/oidc/oidc.go
+//
+// Package oidc is the relying-party side of OpenID Connect's
+// authorization code flow with PKCE: provider discovery, the
+// authorization URL, the code exchange and ID token verification against
+// the provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// discoveryTTL is how long a provider's metadata and keys are reused; an
+// ID token signed with an unknown key refreshes the keys at once.
+const discoveryTTL = time.Hour
+
+var (
+	ErrDiscovery    = errors.New("oidc: provider discovery failed")
+	ErrExchange     = errors.New("oidc: code exchange failed")
+	ErrInvalidToken = errors.New("oidc: invalid ID token")
+)
+
+// Claims are the claims of a verified ID token.
+type Claims = jwt.MapClaims
+
+// Provider is an issuer's discovery document and signing keys.
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	fetched time.Time
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+}
+
+// Client talks to OpenID providers, caching what it discovers.
+type Client struct {
+	http *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+// NewClient uses hc for all requests; nil means a client with a 10 second
+// timeout.
+func NewClient(hc *http.Client) *Client {
+	if hc == nil {
+		hc = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{http: hc, providers: map[string]*Provider{}}
+}
+
+// Discover reads issuer's /.well-known/openid-configuration.
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	c.mu.Lock()
+	p, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(p.fetched) < discoveryTTL {
+		return p, nil
+	}
+	p = &Provider{}
+	if err := c.getJSON(ctx, issuer+"/.well-known/openid-configuration", p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if strings.TrimSuffix(p.Issuer, "/") != issuer || p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete or mismatched discovery document", ErrDiscovery)
+	}
+	p.fetched = time.Now()
+	c.mu.Lock()
+	c.providers[issuer] = p
+	c.mu.Unlock()
+	return p, nil
+}
+
+// PKCEChallenge derives the S256 code challenge for verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL is where to send the user's browser to log in.
+func (p *Provider) AuthCodeURL(clientID, redirectURI string, scopes []string, state, nonce, verifier string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {PKCEChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Exchange swaps an authorization code for the user's ID token.
+func (c *Client) Exchange(ctx context.Context, p *Provider, clientID, clientSecret, redirectURI, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s: %s", ErrExchange, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil || tok.IDToken == "" {
+		return "", fmt.Errorf("%w: no id_token in response", ErrExchange)
+	}
+	return tok.IDToken, nil
+}
+
+// VerifyIDToken checks the token's signature, issuer, audience, expiry
+// and nonce, and returns its claims.
+func (c *Client) VerifyIDToken(ctx context.Context, p *Provider, clientID, raw, nonce string) (Claims, error) {
+	claims := Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}))
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, p, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(p.Issuer, "/") {
+		return nil, fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+	}
+	if !claims.VerifyAudience(clientID, true) {
+		return nil, fmt.Errorf("%w: wrong audience", ErrInvalidToken)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// key returns the provider's signing key kid, fetching the key set again
+// once if it is unknown.
+func (c *Client) key(ctx context.Context, p *Provider, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if p.keys != nil {
+			if k, ok := p.keys[kid]; ok {
+				return k, nil
+			}
+			// A single key without an ID matches any token.
+			if len(p.keys) == 1 && kid == "" {
+				for _, k := range p.keys {
+					return k, nil
+				}
+			}
+			if attempt == 1 {
+				break
+			}
+		}
+		keys, err := c.fetchKeys(ctx, p.JWKSURI)
+		if err != nil {
+			return nil, err
+		}
+		p.keys = keys
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (c *Client) fetchKeys(ctx context.Context, uri string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, uri, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			default:
+				continue
+			}
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, uri string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", uri, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,211 @@
+// Package oidctest is a stand-in OpenID provider for trying single sign-on
+// locally and in tests. It logs everyone in without asking, as DefaultUser
+// or as the login_hint the authorization request carries, and issues
+// RS256 ID tokens with the configured claims.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const kid = "mock-1"
+
+// Provider serves discovery, authorization, token and key endpoints. Its
+// fields may be changed until it starts serving; Issuer may be set once the
+// listening address is known.
+type Provider struct {
+	Issuer       string // as the API reaches this server
+	ClientID     string // client ID to accept
+	ClientSecret string // client secret to require, if any
+	DefaultUser  string // username to log in as when no login_hint is given
+	EmailDomain  string // domain of the users' email addresses
+	// EmailVerified is the email_verified claim of every token.
+	EmailVerified bool
+	Groups        []string
+	// Claims are added to every token, replacing the standard ones.
+	Claims map[string]any
+
+	key    *rsa.PrivateKey
+	mux    *http.ServeMux
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+type grant struct {
+	user, nonce, challenge, redirectURI, clientID string
+	expires                                       time.Time
+}
+
+// NewProvider returns a provider with a fresh signing key.
+func NewProvider(issuer, clientID string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{
+		Issuer:      issuer,
+		ClientID:    clientID,
+		DefaultUser: "jdoe",
+		EmailDomain: "example.com",
+		key:         key,
+		mux:         http.NewServeMux(),
+		grants:      map[string]grant{},
+	}
+	p.mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	p.mux.HandleFunc("/jwks", p.jwks)
+	p.mux.HandleFunc("/authorize", p.authorize)
+	p.mux.HandleFunc("/token", p.token)
+	return p, nil
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}}})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "bad redirect_uri", http.StatusBadRequest)
+		return
+	}
+	who := q.Get("login_hint")
+	if who == "" {
+		who = p.DefaultUser
+	}
+	code := randomHex(16)
+	p.mu.Lock()
+	p.grants[code] = grant{
+		user:        who,
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		redirectURI: q.Get("redirect_uri"),
+		clientID:    q.Get("client_id"),
+		expires:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+	back := redirect.Query()
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+	redirect.RawQuery = back.Encode()
+	log.Printf("authorized %s, redirecting to %s", who, redirect.Host)
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	} else {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	}
+	if id != p.ClientID || (p.ClientSecret != "" && secret != p.ClientSecret) {
+		tokenError(w, "invalid_client")
+		return
+	}
+	p.mu.Lock()
+	g, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || time.Now().After(g.expires) || g.clientID != id || g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                p.Issuer,
+		"sub":                "mock|" + g.user,
+		"aud":                p.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              g.nonce,
+		"preferred_username": g.user,
+		"email":              g.user + "@" + p.EmailDomain,
+		"email_verified":     p.EmailVerified,
+		"given_name":         strings.ToUpper(g.user[:1]) + g.user[1:],
+		"family_name":        "Mock",
+		"groups":             p.Groups,
+	}
+	for name, value := range p.Claims {
+		claims[name] = value
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t.Header["kid"] = kid
+	signed, err := t.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"access_token": randomHex(16),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
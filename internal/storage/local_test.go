@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Keys with .. segments stay beneath the storage root.
+func TestLocalKeysCannotEscapeRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("outside"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../secret.txt", "a/../../secret.txt", "/../secret.txt", "..\\secret.txt"} {
+		if rc, err := s.Get(ctx, key); err == nil {
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(b) == "outside" {
+				t.Errorf("Get(%q) read a file outside the root", key)
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): %v", key, err)
+		}
+		if err := s.Put(ctx, key, strings.NewReader("inside"), 6, ""); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%q): %v", key, err)
+		}
+	}
+	if b, err := os.ReadFile(secret); err != nil || string(b) != "outside" {
+		t.Fatalf("file outside the root = %q (err %v), want it untouched", b, err)
+	}
+	for _, key := range []string{"", "/", "..", "a/.."} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) accepted", key)
+		}
+	}
+}
+
+func TestLocalPutGetDelete(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.Put(ctx, "acme/buyers/7/id.txt", strings.NewReader("passport"), 8, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := s.Get(ctx, "acme/buyers/7/id.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(b) != "passport" {
+		t.Fatalf("Get = %q", b)
+	}
+	if err := s.Delete(ctx, "acme/buyers/7/id.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "acme/buyers/7/id.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,56 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage is a flat blob store addressed by slash-separated keys.
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object stored under key. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the object under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures a Storage backend.
+type Config struct {
+	Backend string // "local" or "s3"
+
+	// Local filesystem backend
+	LocalDir string
+
+	// S3-compatible backend (AWS S3, MinIO, ...)
+	S3Endpoint     string // e.g. "https://s3.eu-west-1.amazonaws.com" or "http://localhost:9000"
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool // address the bucket as endpoint/bucket/key instead of bucket.endpoint/key
+}
+
+// New returns the backend named by cfg.Backend.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		dir := cfg.LocalDir
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
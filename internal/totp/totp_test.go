@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// The SHA-1 test vectors of RFC 6238 appendix B, truncated to six digits.
+func TestRFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	for _, v := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	} {
+		at := time.Unix(v.unix, 0)
+		code, err := Code(secret, Counter(at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("T=%d: code %s, want %s", v.unix, code, v.code)
+		}
+		if _, ok := Validate(secret, v.code, at, 0); !ok {
+			t.Errorf("T=%d: %s not valid", v.unix, v.code)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	prev, err := Code(secret, Counter(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(secret, prev, now, 1); !ok {
+		t.Error("previous step refused with a skew of 1")
+	}
+	if _, ok := Validate(secret, prev, now, 0); ok {
+		t.Error("previous step accepted with no skew")
+	}
+}
@@ -9,17 +9,38 @@ import (
 	"strings"
 )
 
-// MigrateSQL will run every .sql file in dir (in alphabetical order).
+// renamed maps migrations that were renumbered to their former names, so a
+// database that applied one under its old name does not run it again.
+var renamed = map[string]string{
+	"040_add_role_tenants_and_access_audit.sql":    "027_add_role_tenants_and_access_audit.sql",
+	"0040_add_role_tenants_and_access_audit.pgsql": "0027_add_role_tenants_and_access_audit.pgsql",
+}
+
+// MigrateSQL will run every .sql or .pgsql file in dir (in alphabetical
+// order) that has not been applied before. Each file runs in its own
+// transaction and applied file names are recorded in schema_migrations so
+// non-idempotent statements such as ALTER TABLE only ever run once.
 func MigrateSQL(db *sql.DB, dir string) error {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return fmt.Errorf("reading migrations dir: %w", err)
 	}
 
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name       VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
 	// Collect .sql files
 	var files []string
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+		if !e.IsDir() && (strings.HasSuffix(e.Name(), ".sql") || strings.HasSuffix(e.Name(), ".pgsql")) {
 			files = append(files, e.Name())
 		}
 	}
@@ -27,21 +48,72 @@ func MigrateSQL(db *sql.DB, dir string) error {
 
 	// Apply each
 	for _, fname := range files {
+		if applied[fname] || applied[renamed[fname]] {
+			continue
+		}
 		path := filepath.Join(dir, fname)
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return fmt.Errorf("read %s: %w", fname, err)
 		}
-		stmts := strings.Split(string(content), ";")
-		for _, stmt := range stmts {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" || strings.HasPrefix(stmt, "--") {
-				continue
-			}
-			if _, err := db.Exec(stmt); err != nil {
-				return fmt.Errorf("%s: exec %q: %w", fname, stmt, err)
-			}
+		if err := applyFile(db, fname, string(content)); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// applyFile runs one migration file and records it, all in one
+// transaction.
+func applyFile(db *sql.DB, fname, content string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range strings.Split(content, ";") {
+		stmt = stripComments(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("%s: exec %q: %w", fname, stmt, err)
+		}
+	}
+	// File names are ours, but quote defensively; the literal keeps this
+	// portable across ? and $1 placeholder styles.
+	record := fmt.Sprintf(`INSERT INTO schema_migrations (name) VALUES ('%s')`, strings.ReplaceAll(fname, "'", "''"))
+	if _, err := tx.Exec(record); err != nil {
+		return fmt.Errorf("record %s: %w", fname, err)
+	}
+	return tx.Commit()
+}
+
+// stripComments drops whole-line "--" comments, so a statement that
+// follows a comment header is still run.
+func stripComments(stmt string) string {
+	var lines []string
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
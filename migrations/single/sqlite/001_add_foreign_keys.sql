@@ -0,0 +1,294 @@
+-- Single-database mode only: every domain shares this database, so
+-- references between domains become real foreign keys. SQLite cannot add
+-- a constraint to an existing table, so each table is rebuilt with it.
+
+-- installment_plans
+CREATE TABLE installment_plans_new (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id TEXT NOT NULL,
+	  property_id INTEGER NOT NULL,
+	  buyer_id INTEGER NOT NULL,
+	  total_price REAL NOT NULL,
+	  down_payment REAL NOT NULL,
+	  num_installments INTEGER NOT NULL,
+	  frequency TEXT NOT NULL,
+	  first_installment DATETIME NOT NULL,
+	  interest_rate REAL NOT NULL,
+	  created_by TEXT NOT NULL,
+	  created_at DATETIME NOT NULL,
+	  modified_by TEXT NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted INTEGER NOT NULL DEFAULT 0
+	, sale_id INTEGER NOT NULL DEFAULT 0,
+	  owner_id INTEGER NOT NULL DEFAULT 0,
+	  assigned_to INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (property_id) REFERENCES properties(id),
+	  FOREIGN KEY (buyer_id) REFERENCES buyers(id)
+	);
+INSERT INTO installment_plans_new (id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, created_by, created_at, modified_by, last_modified, deleted, sale_id, owner_id, assigned_to)
+SELECT id, tenant_id, property_id, buyer_id, total_price, down_payment, num_installments, frequency, first_installment, interest_rate, created_by, created_at, modified_by, last_modified, deleted, sale_id, owner_id, assigned_to FROM installment_plans;
+DROP TABLE installment_plans;
+ALTER TABLE installment_plans_new RENAME TO installment_plans;
+CREATE INDEX IF NOT EXISTS idx_installmentplans_tenant ON installment_plans(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_installmentplans_property ON installment_plans(property_id);
+CREATE INDEX IF NOT EXISTS idx_installmentplans_sale ON installment_plans(tenant_id, sale_id);
+CREATE INDEX IF NOT EXISTS idx_installmentplans_owner ON installment_plans(tenant_id, owner_id, assigned_to);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_installmentplans_active_sale ON installment_plans(tenant_id, sale_id) WHERE deleted = 0 AND sale_id <> 0;
+
+-- installments
+CREATE TABLE installments_new (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id TEXT NOT NULL,
+	  plan_id INTEGER NOT NULL,
+	  sequence_number INTEGER NOT NULL,
+	  due_date DATETIME NOT NULL,
+	  amount_due REAL NOT NULL,
+	  amount_paid REAL NOT NULL,
+	  status TEXT NOT NULL,
+	  late_fee REAL NOT NULL,
+	  paid_date DATETIME,
+	  created_by TEXT NOT NULL,
+	  created_at DATETIME NOT NULL,
+	  modified_by TEXT NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (plan_id) REFERENCES installment_plans(id)
+	);
+INSERT INTO installments_new (id, tenant_id, plan_id, sequence_number, due_date, amount_due, amount_paid, status, late_fee, paid_date, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, plan_id, sequence_number, due_date, amount_due, amount_paid, status, late_fee, paid_date, created_by, created_at, modified_by, last_modified, deleted FROM installments;
+DROP TABLE installments;
+ALTER TABLE installments_new RENAME TO installments;
+CREATE INDEX IF NOT EXISTS idx_installments_tenant ON installments(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_installments_plan ON installments(plan_id);
+
+-- payments
+CREATE TABLE payments_new (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id TEXT NOT NULL,
+	  installment_id INTEGER NOT NULL,
+	  amount_paid REAL NOT NULL,
+	  payment_date DATETIME NOT NULL,
+	  payment_method TEXT NOT NULL,
+	  transaction_ref TEXT,
+	  created_by TEXT NOT NULL,
+	  created_at DATETIME NOT NULL,
+	  modified_by TEXT NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (installment_id) REFERENCES installments(id)
+	);
+INSERT INTO payments_new (id, tenant_id, installment_id, amount_paid, payment_date, payment_method, transaction_ref, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, installment_id, amount_paid, payment_date, payment_method, transaction_ref, created_by, created_at, modified_by, last_modified, deleted FROM payments;
+DROP TABLE payments;
+ALTER TABLE payments_new RENAME TO payments;
+CREATE INDEX IF NOT EXISTS idx_payments_tenant ON payments(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_payments_installment ON payments(installment_id);
+
+-- sales
+CREATE TABLE sales_new (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  property_id   INTEGER NOT NULL,
+	  buyer_id      INTEGER NOT NULL,
+	  sale_price    REAL    NOT NULL,
+	  sale_date     DATETIME NOT NULL,
+	  sale_type     TEXT    NOT NULL,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0,
+	  owner_id      INTEGER NOT NULL DEFAULT 0,
+	  assigned_to   INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (property_id) REFERENCES properties(id),
+	  FOREIGN KEY (buyer_id) REFERENCES buyers(id)
+	);
+INSERT INTO sales_new (id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type, created_by, created_at, modified_by, last_modified, deleted, owner_id, assigned_to)
+SELECT id, tenant_id, property_id, buyer_id, sale_price, sale_date, sale_type, created_by, created_at, modified_by, last_modified, deleted, owner_id, assigned_to FROM sales;
+DROP TABLE sales;
+ALTER TABLE sales_new RENAME TO sales;
+CREATE INDEX IF NOT EXISTS idx_sales_tenant     ON sales(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_sales_property   ON sales(property_id);
+CREATE INDEX IF NOT EXISTS idx_sales_buyer      ON sales(buyer_id);
+CREATE INDEX IF NOT EXISTS idx_sales_owner      ON sales(tenant_id, owner_id, assigned_to);
+
+-- lettings
+CREATE TABLE lettings_new (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  tenant_user_id INTEGER NOT NULL,
+	  rent_amount    REAL    NOT NULL,
+	  rent_term      INTEGER NOT NULL,
+	  rent_cycle     TEXT    NOT NULL,
+	  memo           TEXT,
+	  start_date     DATETIME NOT NULL,
+	  end_date       DATETIME,
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (property_id) REFERENCES properties(id),
+	  FOREIGN KEY (tenant_user_id) REFERENCES buyers(id)
+	);
+INSERT INTO lettings_new (id, tenant_id, property_id, tenant_user_id, rent_amount, rent_term, rent_cycle, memo, start_date, end_date, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, property_id, tenant_user_id, rent_amount, rent_term, rent_cycle, memo, start_date, end_date, created_by, created_at, modified_by, last_modified, deleted FROM lettings;
+DROP TABLE lettings;
+ALTER TABLE lettings_new RENAME TO lettings;
+CREATE INDEX IF NOT EXISTS idx_lettings_tenant    ON lettings(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_lettings_property  ON lettings(property_id);
+CREATE INDEX IF NOT EXISTS idx_lettings_tenantuser ON lettings(tenant_user_id);
+
+-- introductions
+CREATE TABLE introductions_new (
+	  id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id         TEXT    NOT NULL,
+	  introducer_id     INTEGER NOT NULL,
+	  introduced_party  TEXT    NOT NULL,
+	  property_id       INTEGER NOT NULL,
+	  transaction_id    INTEGER,
+	  transaction_type	TEXT NOT NULL,
+	  intro_date        DATETIME NOT NULL,
+	  agreed_fee        REAL    NOT NULL,
+	  fee_type          TEXT    NOT NULL,
+	  created_by        TEXT    NOT NULL,
+	  created_at        DATETIME NOT NULL,
+	  modified_by       TEXT    NOT NULL,
+	  last_modified     DATETIME NOT NULL,
+	  deleted           INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (introducer_id) REFERENCES buyers(id),
+	  FOREIGN KEY (property_id) REFERENCES properties(id)
+	);
+INSERT INTO introductions_new (id, tenant_id, introducer_id, introduced_party, property_id, transaction_id, transaction_type, intro_date, agreed_fee, fee_type, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, introducer_id, introduced_party, property_id, transaction_id, transaction_type, intro_date, agreed_fee, fee_type, created_by, created_at, modified_by, last_modified, deleted FROM introductions;
+DROP TABLE introductions;
+ALTER TABLE introductions_new RENAME TO introductions;
+CREATE INDEX IF NOT EXISTS idx_introductions_tenant ON introductions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_introductions_introducer ON introductions(introducer_id);
+CREATE INDEX IF NOT EXISTS idx_introductions_property ON introductions(property_id);
+
+-- buyer_kyc
+CREATE TABLE buyer_kyc_new (
+	  id                          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id                   TEXT    NOT NULL,
+	  buyer_id                    INTEGER NOT NULL,
+	  id_document_type            TEXT    NOT NULL,
+	  id_document_number          TEXT    NOT NULL,
+	  id_document_expiry          DATETIME NOT NULL,
+	  id_document_attachment_id   INTEGER NOT NULL DEFAULT 0,
+	  address_proof_type          TEXT    NOT NULL DEFAULT '',
+	  address_proof_attachment_id INTEGER NOT NULL DEFAULT 0,
+	  source_of_funds             TEXT    NOT NULL DEFAULT '',
+	  status                      TEXT    NOT NULL DEFAULT 'pending',
+	  verified_by                 TEXT    NOT NULL DEFAULT '',
+	  verified_at                 DATETIME,
+	  notes                       TEXT    NOT NULL DEFAULT '',
+	  created_by                  TEXT    NOT NULL,
+	  created_at                  DATETIME NOT NULL,
+	  modified_by                 TEXT    NOT NULL,
+	  last_modified               DATETIME NOT NULL,
+	  deleted                     INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (buyer_id) REFERENCES buyers(id)
+	);
+INSERT INTO buyer_kyc_new (id, tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id, address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, buyer_id, id_document_type, id_document_number, id_document_expiry, id_document_attachment_id, address_proof_type, address_proof_attachment_id, source_of_funds, status, verified_by, verified_at, notes, created_by, created_at, modified_by, last_modified, deleted FROM buyer_kyc;
+DROP TABLE buyer_kyc;
+ALTER TABLE buyer_kyc_new RENAME TO buyer_kyc;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_buyer_kyc_buyer ON buyer_kyc(tenant_id, buyer_id);
+
+-- kyc_overrides
+CREATE TABLE kyc_overrides_new (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  buyer_id   INTEGER NOT NULL,
+	  action     TEXT    NOT NULL,
+	  reason     TEXT    NOT NULL,
+	  granted_by TEXT    NOT NULL,
+	  granted_at DATETIME NOT NULL,
+	  expires_at DATETIME NOT NULL,
+	  used_by    TEXT    NOT NULL DEFAULT '',
+	  used_at    DATETIME,
+	  FOREIGN KEY (buyer_id) REFERENCES buyers(id)
+	);
+INSERT INTO kyc_overrides_new (id, tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at)
+SELECT id, tenant_id, buyer_id, action, reason, granted_by, granted_at, expires_at, used_by, used_at FROM kyc_overrides;
+DROP TABLE kyc_overrides;
+ALTER TABLE kyc_overrides_new RENAME TO kyc_overrides;
+CREATE INDEX IF NOT EXISTS idx_kyc_overrides_buyer ON kyc_overrides(tenant_id, buyer_id);
+
+-- appointments
+CREATE TABLE appointments_new (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL DEFAULT 0,
+	  prospect_name  TEXT    NOT NULL DEFAULT '',
+	  prospect_phone TEXT    NOT NULL DEFAULT '',
+	  prospect_email TEXT    NOT NULL DEFAULT '',
+	  agent_user_id  INTEGER NOT NULL,
+	  starts_at      DATETIME NOT NULL,
+	  ends_at        DATETIME NOT NULL,
+	  status         TEXT    NOT NULL DEFAULT 'scheduled',
+	  notes          TEXT    NOT NULL DEFAULT '',
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (property_id) REFERENCES properties(id)
+	);
+INSERT INTO appointments_new (id, tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id, starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, property_id, buyer_id, prospect_name, prospect_phone, prospect_email, agent_user_id, starts_at, ends_at, status, notes, created_by, created_at, modified_by, last_modified, deleted FROM appointments;
+DROP TABLE appointments;
+ALTER TABLE appointments_new RENAME TO appointments;
+CREATE INDEX IF NOT EXISTS idx_appointments_agent ON appointments(tenant_id, agent_user_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_property ON appointments(tenant_id, property_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_buyer ON appointments(tenant_id, buyer_id);
+
+-- offers and offer_milestones are rebuilt together: the milestone copy
+-- points at the offers copy, so the originals can be dropped in turn and
+-- renaming offers_new carries the reference over.
+CREATE TABLE offers_new (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL,
+	  amount         REAL    NOT NULL,
+	  counter_amount REAL    NOT NULL DEFAULT 0,
+	  conditions     TEXT    NOT NULL DEFAULT '',
+	  sale_type      TEXT    NOT NULL,
+	  expires_at     DATETIME,
+	  status         TEXT    NOT NULL,
+	  stage          TEXT    NOT NULL DEFAULT '',
+	  sale_id        INTEGER NOT NULL DEFAULT 0,
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0,
+	  FOREIGN KEY (property_id) REFERENCES properties(id),
+	  FOREIGN KEY (buyer_id) REFERENCES buyers(id)
+	);
+CREATE TABLE offer_milestones_new (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  offer_id    INTEGER NOT NULL REFERENCES offers_new(id),
+	  milestone   TEXT    NOT NULL,
+	  reached_at  DATETIME NOT NULL,
+	  notes       TEXT    NOT NULL DEFAULT '',
+	  created_by  TEXT    NOT NULL,
+	  created_at  DATETIME NOT NULL
+	);
+INSERT INTO offers_new (id, tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type, expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted)
+SELECT id, tenant_id, property_id, buyer_id, amount, counter_amount, conditions, sale_type, expires_at, status, stage, sale_id, created_by, created_at, modified_by, last_modified, deleted FROM offers;
+INSERT INTO offer_milestones_new (id, tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at)
+SELECT id, tenant_id, offer_id, milestone, reached_at, notes, created_by, created_at FROM offer_milestones;
+DROP TABLE offer_milestones;
+DROP TABLE offers;
+ALTER TABLE offers_new RENAME TO offers;
+ALTER TABLE offer_milestones_new RENAME TO offer_milestones;
+CREATE INDEX IF NOT EXISTS idx_offers_tenant   ON offers(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_offers_property ON offers(tenant_id, property_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offers_accepted_property ON offers(tenant_id, property_id) WHERE status = 'accepted' AND deleted = 0;
+CREATE INDEX IF NOT EXISTS idx_offer_milestones_offer ON offer_milestones(tenant_id, offer_id);
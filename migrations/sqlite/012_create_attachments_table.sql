@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS attachments (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  entity_type   TEXT    NOT NULL,
+	  entity_id     INTEGER NOT NULL,
+	  category      TEXT    NOT NULL DEFAULT '',
+	  file_name     TEXT    NOT NULL,
+	  mime_type     TEXT    NOT NULL,
+	  size_bytes    INTEGER NOT NULL,
+	  checksum      TEXT    NOT NULL,
+	  storage_key   TEXT    NOT NULL,
+	  thumbnail_key TEXT    NOT NULL DEFAULT '',
+	  uploaded_by   TEXT    NOT NULL,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_attachments_tenant ON attachments(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_attachments_entity ON attachments(tenant_id, entity_type, entity_id);
@@ -0,0 +1,12 @@
+ALTER TABLE properties ADD COLUMN latitude REAL;
+	ALTER TABLE properties ADD COLUMN longitude REAL;
+	ALTER TABLE properties ADD COLUMN geo_source TEXT NOT NULL DEFAULT '';
+	CREATE INDEX IF NOT EXISTS idx_properties_geo ON properties(tenant_id, latitude, longitude);
+
+	CREATE TABLE IF NOT EXISTS postal_centroids (
+	  tenant_id   TEXT NOT NULL,
+	  postal_code TEXT NOT NULL,
+	  latitude    REAL NOT NULL,
+	  longitude   REAL NOT NULL,
+	  PRIMARY KEY (tenant_id, postal_code)
+	);
@@ -0,0 +1,2 @@
+ALTER TABLE properties ADD COLUMN status TEXT NOT NULL DEFAULT 'available';
+	CREATE INDEX IF NOT EXISTS idx_properties_status ON properties(tenant_id, status);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS offers (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL,
+	  amount         REAL    NOT NULL,
+	  counter_amount REAL    NOT NULL DEFAULT 0,
+	  conditions     TEXT    NOT NULL DEFAULT '',
+	  sale_type      TEXT    NOT NULL,
+	  expires_at     DATETIME,
+	  status         TEXT    NOT NULL,
+	  stage          TEXT    NOT NULL DEFAULT '',
+	  sale_id        INTEGER NOT NULL DEFAULT 0,
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_offers_tenant   ON offers(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_offers_property ON offers(tenant_id, property_id);
+
+	CREATE TABLE IF NOT EXISTS offer_milestones (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  offer_id    INTEGER NOT NULL REFERENCES offers(id),
+	  milestone   TEXT    NOT NULL,
+	  reached_at  DATETIME NOT NULL,
+	  notes       TEXT    NOT NULL DEFAULT '',
+	  created_by  TEXT    NOT NULL,
+	  created_at  DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_offer_milestones_offer ON offer_milestones(tenant_id, offer_id);
@@ -0,0 +1,2 @@
+ALTER TABLE installment_plans ADD COLUMN sale_id INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_installmentplans_sale ON installment_plans(tenant_id, sale_id);
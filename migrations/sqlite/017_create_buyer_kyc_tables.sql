@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS buyer_kyc (
+	  id                          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id                   TEXT    NOT NULL,
+	  buyer_id                    INTEGER NOT NULL,
+	  id_document_type            TEXT    NOT NULL,
+	  id_document_number          TEXT    NOT NULL,
+	  id_document_expiry          DATETIME NOT NULL,
+	  id_document_attachment_id   INTEGER NOT NULL DEFAULT 0,
+	  address_proof_type          TEXT    NOT NULL DEFAULT '',
+	  address_proof_attachment_id INTEGER NOT NULL DEFAULT 0,
+	  source_of_funds             TEXT    NOT NULL DEFAULT '',
+	  status                      TEXT    NOT NULL DEFAULT 'pending',
+	  verified_by                 TEXT    NOT NULL DEFAULT '',
+	  verified_at                 DATETIME,
+	  notes                       TEXT    NOT NULL DEFAULT '',
+	  created_by                  TEXT    NOT NULL,
+	  created_at                  DATETIME NOT NULL,
+	  modified_by                 TEXT    NOT NULL,
+	  last_modified               DATETIME NOT NULL,
+	  deleted                     INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_buyer_kyc_buyer ON buyer_kyc(tenant_id, buyer_id);
+
+	CREATE TABLE IF NOT EXISTS kyc_overrides (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  buyer_id   INTEGER NOT NULL,
+	  action     TEXT    NOT NULL,
+	  reason     TEXT    NOT NULL,
+	  granted_by TEXT    NOT NULL,
+	  granted_at DATETIME NOT NULL,
+	  expires_at DATETIME NOT NULL,
+	  used_by    TEXT    NOT NULL DEFAULT '',
+	  used_at    DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_kyc_overrides_buyer ON kyc_overrides(tenant_id, buyer_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS buyer_merges (
+	  id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id          TEXT    NOT NULL,
+	  survivor_id        INTEGER NOT NULL,
+	  duplicate_id       INTEGER NOT NULL,
+	  duplicate_snapshot TEXT    NOT NULL,
+	  reassigned         TEXT    NOT NULL,
+	  reason             TEXT    NOT NULL DEFAULT '',
+	  merged_by          TEXT    NOT NULL,
+	  merged_at          DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_buyer_merges_tenant ON buyer_merges(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_buyer_merges_duplicate ON buyer_merges(tenant_id, duplicate_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS activities (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  subject_type  TEXT    NOT NULL,
+	  subject_id    INTEGER NOT NULL,
+	  letting_id    INTEGER NOT NULL DEFAULT 0,
+	  type          TEXT    NOT NULL,
+	  occurred_at   DATETIME NOT NULL,
+	  author        TEXT    NOT NULL,
+	  body          TEXT    NOT NULL DEFAULT '',
+	  due_date      DATETIME,
+	  completed_at  DATETIME,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_activities_subject ON activities(tenant_id, subject_type, subject_id);
+	CREATE INDEX IF NOT EXISTS idx_activities_due ON activities(tenant_id, due_date);
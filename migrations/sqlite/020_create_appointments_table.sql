@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS appointments (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  property_id    INTEGER NOT NULL,
+	  buyer_id       INTEGER NOT NULL DEFAULT 0,
+	  prospect_name  TEXT    NOT NULL DEFAULT '',
+	  prospect_phone TEXT    NOT NULL DEFAULT '',
+	  prospect_email TEXT    NOT NULL DEFAULT '',
+	  agent_user_id  INTEGER NOT NULL,
+	  starts_at      DATETIME NOT NULL,
+	  ends_at        DATETIME NOT NULL,
+	  status         TEXT    NOT NULL DEFAULT 'scheduled',
+	  notes          TEXT    NOT NULL DEFAULT '',
+	  created_by     TEXT    NOT NULL,
+	  created_at     DATETIME NOT NULL,
+	  modified_by    TEXT    NOT NULL,
+	  last_modified  DATETIME NOT NULL,
+	  deleted        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_appointments_agent ON appointments(tenant_id, agent_user_id, starts_at);
+	CREATE INDEX IF NOT EXISTS idx_appointments_property ON appointments(tenant_id, property_id, starts_at);
+	CREATE INDEX IF NOT EXISTS idx_appointments_buyer ON appointments(tenant_id, buyer_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+	  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id    TEXT    NOT NULL,
+	  user_id      INTEGER NOT NULL,
+	  token_hash   TEXT    NOT NULL UNIQUE,
+	  created_at   DATETIME NOT NULL,
+	  last_used_at DATETIME,
+	  revoked_at   DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_calendar_feed_tokens_user ON calendar_feed_tokens(tenant_id, user_id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS reminder_templates (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  kind          TEXT    NOT NULL,
+	  subject       TEXT    NOT NULL,
+	  body          TEXT    NOT NULL,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  UNIQUE (tenant_id, kind)
+	);
+	CREATE TABLE IF NOT EXISTS reminder_sends (
+	  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id      TEXT    NOT NULL,
+	  installment_id INTEGER NOT NULL,
+	  kind           TEXT    NOT NULL,
+	  recipient      TEXT    NOT NULL,
+	  sent_at        DATETIME NOT NULL,
+	  UNIQUE (tenant_id, installment_id, kind)
+	);
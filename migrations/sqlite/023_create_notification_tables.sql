@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  subject_type  TEXT    NOT NULL,
+	  subject_id    INTEGER NOT NULL,
+	  channel       TEXT    NOT NULL,
+	  address       TEXT    NOT NULL DEFAULT '',
+	  opted_out     INTEGER NOT NULL DEFAULT 0,
+	  opted_out_at  DATETIME,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  UNIQUE (tenant_id, subject_type, subject_id, channel)
+	);
+	CREATE TABLE IF NOT EXISTS notification_attempts (
+	  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id    TEXT    NOT NULL,
+	  subject_type TEXT    NOT NULL,
+	  subject_id   INTEGER NOT NULL,
+	  channel      TEXT    NOT NULL,
+	  recipient    TEXT    NOT NULL,
+	  subject      TEXT    NOT NULL DEFAULT '',
+	  attempt      INTEGER NOT NULL,
+	  status       TEXT    NOT NULL,
+	  error        TEXT    NOT NULL DEFAULT '',
+	  attempted_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_notification_attempts_subject ON notification_attempts(tenant_id, subject_type, subject_id);
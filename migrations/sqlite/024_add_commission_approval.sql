@@ -0,0 +1,2 @@
+ALTER TABLE commissions ADD COLUMN approved_by TEXT NOT NULL DEFAULT '';
+	ALTER TABLE commissions ADD COLUMN approved_at DATETIME;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  url           TEXT    NOT NULL,
+	  secret        TEXT    NOT NULL,
+	  events        TEXT    NOT NULL DEFAULT '',
+	  active        INTEGER NOT NULL DEFAULT 1,
+	  created_by    TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT    NOT NULL,
+	  last_modified DATETIME NOT NULL,
+	  deleted       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	  id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id        TEXT    NOT NULL,
+	  subscription_id  INTEGER NOT NULL,
+	  event_id         TEXT    NOT NULL,
+	  event_type       TEXT    NOT NULL,
+	  payload          TEXT    NOT NULL,
+	  status           TEXT    NOT NULL,
+	  attempts         INTEGER NOT NULL DEFAULT 0,
+	  next_attempt_at  DATETIME NOT NULL,
+	  last_error       TEXT    NOT NULL DEFAULT '',
+	  last_status_code INTEGER NOT NULL DEFAULT 0,
+	  delivered_at     DATETIME,
+	  created_at       DATETIME NOT NULL,
+	  UNIQUE (subscription_id, event_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant ON webhook_deliveries(tenant_id, status);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+	  id              TEXT    PRIMARY KEY,
+	  tenant_id       TEXT    NOT NULL,
+	  topic           TEXT    NOT NULL,
+	  aggregate_type  TEXT    NOT NULL,
+	  aggregate_id    INTEGER NOT NULL,
+	  payload         TEXT    NOT NULL,
+	  status          TEXT    NOT NULL DEFAULT 'pending',
+	  attempts        INTEGER NOT NULL DEFAULT 0,
+	  next_attempt_at DATETIME NOT NULL,
+	  last_error      TEXT    NOT NULL DEFAULT '',
+	  created_at      DATETIME NOT NULL,
+	  dispatched_at   DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events(status, next_attempt_at);
+	CREATE TABLE IF NOT EXISTS processed_events (
+	  consumer     TEXT     NOT NULL,
+	  event_id     TEXT     NOT NULL,
+	  processed_at DATETIME NOT NULL,
+	  PRIMARY KEY (consumer, event_id)
+	);
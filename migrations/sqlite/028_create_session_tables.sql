@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  family_id  TEXT    NOT NULL,
+	  token_hash TEXT    NOT NULL UNIQUE,
+	  created_at DATETIME NOT NULL,
+	  expires_at DATETIME NOT NULL,
+	  used_at    DATETIME,
+	  revoked_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS session_revocations (
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  revoked_at DATETIME NOT NULL,
+	  revoked_by TEXT    NOT NULL,
+	  PRIMARY KEY (tenant_id, user_id)
+	);
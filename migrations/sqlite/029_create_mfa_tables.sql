@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS mfa_secrets (
+	  tenant_id    TEXT    NOT NULL,
+	  user_id      INTEGER NOT NULL,
+	  secret       TEXT    NOT NULL,
+	  confirmed_at DATETIME,
+	  last_counter INTEGER NOT NULL DEFAULT 0,
+	  created_at   DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, user_id)
+	);
+	CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+	  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id  TEXT    NOT NULL,
+	  user_id    INTEGER NOT NULL,
+	  code_hash  TEXT    NOT NULL,
+	  used_at    DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS mfa_policies (
+	  tenant_id            TEXT    PRIMARY KEY,
+	  required_permissions TEXT    NOT NULL DEFAULT '',
+	  modified_by          TEXT    NOT NULL,
+	  last_modified        DATETIME NOT NULL
+	);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  user_id     INTEGER NOT NULL,
+	  token_hash  TEXT    NOT NULL UNIQUE,
+	  created_at  DATETIME NOT NULL,
+	  expires_at  DATETIME NOT NULL,
+	  used_at     DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS password_history (
+	  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT    NOT NULL,
+	  user_id       INTEGER NOT NULL,
+	  password_hash TEXT    NOT NULL,
+	  created_at    DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(tenant_id, user_id);
+	CREATE TABLE IF NOT EXISTS login_failures (
+	  tenant_id    TEXT    NOT NULL,
+	  username     TEXT    NOT NULL,
+	  ip           TEXT    NOT NULL,
+	  failures     INTEGER NOT NULL DEFAULT 0,
+	  locked_until DATETIME,
+	  last_failure DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, username, ip)
+	);
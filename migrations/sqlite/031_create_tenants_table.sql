@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tenants (
+	  id               TEXT    PRIMARY KEY,
+	  name             TEXT    NOT NULL,
+	  status           TEXT    NOT NULL DEFAULT 'active',
+	  suspended_at     DATETIME,
+	  suspended_reason TEXT    NOT NULL DEFAULT '',
+	  created_by       TEXT    NOT NULL,
+	  created_at       DATETIME NOT NULL,
+	  modified_by      TEXT    NOT NULL,
+	  last_modified    DATETIME NOT NULL
+	);
+	INSERT OR IGNORE INTO tenants (id, name, status, created_by, created_at, modified_by, last_modified)
+	SELECT DISTINCT tenant_id, tenant_id, 'active', 'migration', CURRENT_TIMESTAMP, 'migration', CURRENT_TIMESTAMP
+	FROM users
+	WHERE tenant_id <> '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tenant_licenses (
+	  tenant_id    TEXT     PRIMARY KEY,
+	  license_id   TEXT     NOT NULL,
+	  data         TEXT     NOT NULL,
+	  installed_by TEXT     NOT NULL,
+	  installed_at DATETIME NOT NULL
+	);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	  id            INTEGER  PRIMARY KEY AUTOINCREMENT,
+	  tenant_id     TEXT     NOT NULL,
+	  user_id       INTEGER  NOT NULL,
+	  name          TEXT     NOT NULL,
+	  prefix        TEXT     NOT NULL UNIQUE,
+	  secret_hash   TEXT     NOT NULL,
+	  permissions   TEXT     NOT NULL DEFAULT '',
+	  allowed_ips   TEXT     NOT NULL DEFAULT '',
+	  expires_at    DATETIME,
+	  last_used_at  DATETIME,
+	  last_used_ip  TEXT     NOT NULL DEFAULT '',
+	  created_by    TEXT     NOT NULL,
+	  created_at    DATETIME NOT NULL,
+	  modified_by   TEXT     NOT NULL,
+	  last_modified DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant_id);
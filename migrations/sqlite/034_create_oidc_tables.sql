@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS oidc_configs (
+	  tenant_id          TEXT     PRIMARY KEY,
+	  enabled            INTEGER  NOT NULL DEFAULT 1,
+	  issuer             TEXT     NOT NULL,
+	  client_id          TEXT     NOT NULL,
+	  client_secret      TEXT     NOT NULL DEFAULT '',
+	  scopes             TEXT     NOT NULL DEFAULT '',
+	  username_claim     TEXT     NOT NULL DEFAULT '',
+	  tenant_claim       TEXT     NOT NULL DEFAULT '',
+	  tenant_claim_value TEXT     NOT NULL DEFAULT '',
+	  role_claim         TEXT     NOT NULL DEFAULT '',
+	  role_mappings      TEXT     NOT NULL DEFAULT '{}',
+	  default_role       TEXT     NOT NULL DEFAULT '',
+	  modified_by        TEXT     NOT NULL,
+	  last_modified      DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS oidc_login_states (
+	  state         TEXT     PRIMARY KEY,
+	  tenant_id     TEXT     NOT NULL,
+	  nonce         TEXT     NOT NULL,
+	  code_verifier TEXT     NOT NULL,
+	  redirect_uri  TEXT     NOT NULL,
+	  expires_at    DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS oidc_identities (
+	  tenant_id  TEXT     NOT NULL,
+	  issuer     TEXT     NOT NULL,
+	  subject    TEXT     NOT NULL,
+	  user_id    INTEGER  NOT NULL,
+	  created_at DATETIME NOT NULL,
+	  PRIMARY KEY (tenant_id, issuer, subject)
+	);
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN team TEXT NOT NULL DEFAULT '';
+ALTER TABLE properties ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD COLUMN assigned_to INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buyers ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buyers ADD COLUMN assigned_to INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE installment_plans ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE installment_plans ADD COLUMN assigned_to INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN assigned_to INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE commissions ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_properties_owner ON properties(tenant_id, owner_id, assigned_to);
+CREATE INDEX IF NOT EXISTS idx_buyers_owner ON buyers(tenant_id, owner_id, assigned_to);
+CREATE INDEX IF NOT EXISTS idx_installmentplans_owner ON installment_plans(tenant_id, owner_id, assigned_to);
+CREATE INDEX IF NOT EXISTS idx_sales_owner ON sales(tenant_id, owner_id, assigned_to);
+CREATE INDEX IF NOT EXISTS idx_commissions_owner ON commissions(tenant_id, owner_id);
@@ -0,0 +1 @@
+ALTER TABLE oidc_configs ADD COLUMN link_verified_email INTEGER NOT NULL DEFAULT 0;
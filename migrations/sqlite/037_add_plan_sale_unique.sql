@@ -0,0 +1,3 @@
+-- A sale has at most one live plan. Plans not made from a sale have
+-- sale_id 0 and are not constrained.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_installmentplans_active_sale ON installment_plans(tenant_id, sale_id) WHERE deleted = 0 AND sale_id <> 0;
@@ -0,0 +1,2 @@
+-- A property has at most one accepted offer.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offers_accepted_property ON offers(tenant_id, property_id) WHERE status = 'accepted' AND deleted = 0;
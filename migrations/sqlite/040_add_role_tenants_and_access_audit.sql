@@ -0,0 +1,43 @@
+-- Roles belong to a tenant, and names are unique per tenant. SQLite cannot
+-- drop the old UNIQUE (name), so roles is rebuilt, together with the two
+-- tables that reference it. Numbered 040 so that it sorts after
+-- 03_create_roles_permissions.sql, which creates roles.
+CREATE TABLE roles_new (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL DEFAULT '',
+	  name        TEXT    NOT NULL,
+	  description TEXT,
+	  UNIQUE (tenant_id, name)
+	);
+	CREATE TABLE role_permissions_new (
+	  role_id       INTEGER NOT NULL REFERENCES roles_new(id),
+	  permission_id INTEGER NOT NULL REFERENCES permissions(id),
+	  PRIMARY KEY(role_id, permission_id)
+	);
+	CREATE TABLE user_roles_new (
+	  user_id INTEGER NOT NULL REFERENCES users(id),
+	  role_id INTEGER NOT NULL REFERENCES roles_new(id),
+	  PRIMARY KEY(user_id, role_id)
+	);
+	INSERT INTO roles_new (id, name, description) SELECT id, name, description FROM roles;
+	INSERT INTO role_permissions_new (role_id, permission_id) SELECT role_id, permission_id FROM role_permissions;
+	INSERT INTO user_roles_new (user_id, role_id) SELECT user_id, role_id FROM user_roles;
+	DROP TABLE role_permissions;
+	DROP TABLE user_roles;
+	DROP TABLE roles;
+	ALTER TABLE roles_new RENAME TO roles;
+	ALTER TABLE role_permissions_new RENAME TO role_permissions;
+	ALTER TABLE user_roles_new RENAME TO user_roles;
+	CREATE INDEX IF NOT EXISTS idx_role_permissions_role ON role_permissions(role_id);
+	CREATE INDEX IF NOT EXISTS idx_user_roles_user ON user_roles(user_id);
+	CREATE TABLE IF NOT EXISTS access_audit (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  tenant_id   TEXT    NOT NULL,
+	  actor       TEXT    NOT NULL,
+	  action      TEXT    NOT NULL,
+	  target_type TEXT    NOT NULL,
+	  target_id   INTEGER NOT NULL,
+	  detail      TEXT    NOT NULL DEFAULT '',
+	  created_at  DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_access_audit_tenant ON access_audit(tenant_id, created_at);